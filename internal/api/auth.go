@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -41,11 +42,56 @@ func generateState() (string, error) {
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
+// PKCE is a Proof Key for Code Exchange (RFC 7636) pair. The challenge is
+// sent with the authorization request and the verifier with the code
+// exchange, so an intercepted code is useless without the verifier.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+}
+
+// NewPKCE generates a random code verifier and its S256 challenge.
+func NewPKCE() (*PKCE, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// AuthOption customizes an authorization URL or code exchange.
+type AuthOption func(*authOptions)
+
+type authOptions struct {
+	pkce *PKCE
+}
+
+// WithPKCE adds the PKCE challenge to the authorization URL and the
+// verifier to the code exchange. Pass the same pair to both.
+func WithPKCE(pkce *PKCE) AuthOption {
+	return func(o *authOptions) {
+		o.pkce = pkce
+	}
+}
+
+func newAuthOptions(opts []AuthOption) *authOptions {
+	o := &authOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // GetAuthURL generates the authorization URL for OAuth 2.0 flow.
 // Users should be redirected to this URL to grant permissions to your app.
 // If scopes are not provided, defaults to threads_basic and threads_content_publish.
 // Returns the complete authorization URL including all necessary parameters.
-func (c *Client) GetAuthURL(scopes []string) string {
+func (c *Client) GetAuthURL(scopes []string, opts ...AuthOption) string {
 	if len(scopes) == 0 {
 		scopes = []string{"threads_basic", "threads_content_publish"}
 	}
@@ -63,6 +109,10 @@ func (c *Client) GetAuthURL(scopes []string) string {
 		"response_type": {"code"},
 		"state":         {state},
 	}
+	if o := newAuthOptions(opts); o.pkce != nil {
+		params.Set("code_challenge", o.pkce.Challenge)
+		params.Set("code_challenge_method", "S256")
+	}
 
 	authURL := fmt.Sprintf("https://www.threads.net/oauth/authorize?%s", params.Encode())
 	return authURL
@@ -71,8 +121,9 @@ func (c *Client) GetAuthURL(scopes []string) string {
 // ExchangeCodeForToken exchanges an authorization code for an access token.
 // This should be called after the user authorizes your app, and you receive the code
 // from the redirect URI callback. The resulting token is automatically stored
-// in the client and token storage.
-func (c *Client) ExchangeCodeForToken(ctx context.Context, code string) error {
+// in the client and token storage. Pass WithPKCE when the authorization URL
+// carried a code challenge.
+func (c *Client) ExchangeCodeForToken(ctx context.Context, code string, opts ...AuthOption) error {
 	if code == "" {
 		return NewValidationError(400, "Authorization code is required", "Code parameter cannot be empty", "code")
 	}
@@ -84,8 +135,11 @@ func (c *Client) ExchangeCodeForToken(ctx context.Context, code string) error {
 		"redirect_uri":  {c.config.RedirectURI},
 		"code":          {code},
 	}
+	if o := newAuthOptions(opts); o.pkce != nil {
+		data.Set("code_verifier", o.pkce.Verifier)
+	}
 
-	resp, err := c.httpClient.POST("/oauth/access_token", data, "")
+	resp, err := c.httpClient.POST(ctx, "/oauth/access_token", data, "")
 	if err != nil {
 		return NewNetworkError(0, "Failed to exchange code for token", err.Error(), true)
 	}
@@ -154,7 +208,7 @@ func (c *Client) GetLongLivedToken(ctx context.Context) error {
 		"access_token":  {currentToken},
 	}
 
-	resp, err := c.httpClient.GET("/access_token", params, currentToken)
+	resp, err := c.httpClient.GET(ctx, "/access_token", params, currentToken)
 	if err != nil {
 		return NewNetworkError(0, "Failed to get long-lived token", err.Error(), true)
 	}
@@ -230,7 +284,7 @@ func (c *Client) RefreshToken(ctx context.Context) error {
 		"access_token": {currentToken},
 	}
 
-	resp, err := c.httpClient.GET("/refresh_access_token", params, "")
+	resp, err := c.httpClient.GET(ctx, "/refresh_access_token", params, "")
 	if err != nil {
 		return NewNetworkError(0, "Failed to refresh token", err.Error(), true)
 	}
@@ -443,7 +497,7 @@ func (c *Client) DebugToken(ctx context.Context, inputToken string) (*DebugToken
 		"access_token": {accessToken},
 	}
 
-	resp, err := c.httpClient.GET("/debug_token", params, accessToken)
+	resp, err := c.httpClient.GET(ctx, "/debug_token", params, accessToken)
 	if err != nil {
 		return nil, NewNetworkError(0, "Failed to debug token", err.Error(), true)
 	}
@@ -2,6 +2,10 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -317,3 +321,52 @@ func TestGenerateState(t *testing.T) {
 		t.Error("consecutive GetAuthURL calls should generate different states")
 	}
 }
+
+// TestPKCE tests that the challenge is sent with the authorization URL and the
+// verifier with the code exchange
+func TestPKCE(t *testing.T) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE failed: %v", err)
+	}
+	sum := sha256.Sum256([]byte(pkce.Verifier))
+	if pkce.Challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Error("expected the challenge to be the S256 hash of the verifier")
+	}
+	if len(pkce.Verifier) < 43 {
+		t.Errorf("verifier %q is shorter than RFC 7636 allows", pkce.Verifier)
+	}
+
+	var form url.Values
+	client, server := createTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"short-token","token_type":"bearer","expires_in":3600,"user_id":12345}`))
+	})
+	defer server.Close()
+
+	authURL, err := url.Parse(client.GetAuthURL(nil, WithPKCE(pkce)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authURL.Query().Get("code_challenge") != pkce.Challenge || authURL.Query().Get("code_challenge_method") != "S256" {
+		t.Errorf("expected the S256 challenge in the URL, got %q", authURL.RawQuery)
+	}
+
+	if err := client.ExchangeCodeForToken(context.Background(), "auth-code", WithPKCE(pkce)); err != nil {
+		t.Fatalf("ExchangeCodeForToken failed: %v", err)
+	}
+	if form.Get("code_verifier") != pkce.Verifier {
+		t.Errorf("expected the verifier in the exchange, got %q", form.Get("code_verifier"))
+	}
+
+	if err := client.ExchangeCodeForToken(context.Background(), "auth-code"); err != nil {
+		t.Fatalf("ExchangeCodeForToken failed: %v", err)
+	}
+	if form.Has("code_verifier") {
+		t.Error("expected no verifier without PKCE")
+	}
+}
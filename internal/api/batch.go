@@ -0,0 +1,274 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// MaxBatchSize is the most requests the Graph API accepts in one batch
+// request. Larger batches are split.
+const MaxBatchSize = 50
+
+// DefaultBatchConcurrency is how many requests run at once when the API
+// does not accept batch requests and Config.BatchConcurrency is unset.
+const DefaultBatchConcurrency = 4
+
+// PostResult is the outcome of fetching one post with GetPostsBatch.
+// Either Post or Err is set.
+type PostResult struct {
+	ID   PostID
+	Post *Post
+	Err  error
+}
+
+// PostInsightsResult is the outcome of fetching one post's insights with
+// GetPostInsightsBatch. Either Insights or Err is set.
+type PostInsightsResult struct {
+	ID       PostID
+	Insights *InsightsResponse
+	Err      error
+}
+
+// batchGet is one GET request of a batch.
+type batchGet struct {
+	path   string
+	params url.Values
+	cache  CacheEndpoint
+}
+
+// batchResponse is the outcome of a batchGet. Either body or err is set.
+type batchResponse struct {
+	body      []byte
+	requestID string
+	err       error
+}
+
+// GetPostsBatch retrieves several posts, as GetPost does for one, using as
+// few round trips as the API allows. Results are in the order of postIDs,
+// and a post that cannot be fetched only fails its own result. The error
+// is for failures that affect every post, such as an expired token.
+func (c *Client) GetPostsBatch(ctx context.Context, postIDs []PostID) ([]PostResult, error) {
+	if err := c.EnsureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	results := make([]PostResult, len(postIDs))
+	reqs := make([]batchGet, 0, len(postIDs))
+	var indexes []int
+	for i, id := range postIDs {
+		results[i].ID = id
+		if !id.Valid() {
+			results[i].Err = NewValidationError(400, ErrEmptyPostID, "Cannot retrieve post without ID", "post_id")
+			continue
+		}
+		reqs = append(reqs, batchGet{
+			path:   "/" + id.String(),
+			params: url.Values{"fields": {PostExtendedFields}},
+			cache:  CachePost,
+		})
+		indexes = append(indexes, i)
+	}
+
+	for j, resp := range c.batch(ctx, reqs) {
+		i := indexes[j]
+		if resp.err != nil {
+			results[i].Err = resp.err
+			continue
+		}
+		var post Post
+		if err := safeJSONUnmarshal(resp.body, &post, "post response", resp.requestID); err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Post = &post
+	}
+	return results, nil
+}
+
+// GetPostInsightsBatch retrieves insights for several posts, as
+// GetPostInsights does for one, using as few round trips as the API allows.
+// Results are in the order of postIDs, and a post whose insights cannot be
+// fetched only fails its own result. The error is for failures that affect
+// every post, such as an invalid metric or an expired token.
+func (c *Client) GetPostInsightsBatch(ctx context.Context, postIDs []PostID, metrics []string) ([]PostInsightsResult, error) {
+	validMetrics, err := c.postInsightMetrics(metrics)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.EnsureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	results := make([]PostInsightsResult, len(postIDs))
+	reqs := make([]batchGet, 0, len(postIDs))
+	var indexes []int
+	for i, id := range postIDs {
+		results[i].ID = id
+		if !id.Valid() {
+			results[i].Err = NewValidationError(400, ErrEmptyPostID, "postID cannot be empty", "postID")
+			continue
+		}
+		reqs = append(reqs, batchGet{
+			path:   "/" + id.String() + "/insights",
+			params: url.Values{"metric": {strings.Join(validMetrics, ",")}},
+			cache:  CacheInsights,
+		})
+		indexes = append(indexes, i)
+	}
+
+	for j, resp := range c.batch(ctx, reqs) {
+		i := indexes[j]
+		if resp.err != nil {
+			results[i].Err = resp.err
+			continue
+		}
+		var insights InsightsResponse
+		if err := safeJSONUnmarshal(resp.body, &insights, "insights response", resp.requestID); err != nil {
+			results[i].Err = err
+			continue
+		}
+		normalizeInsightsResponse(&insights)
+		results[i].Insights = &insights
+	}
+	return results, nil
+}
+
+// batch sends reqs packed into Graph API batch requests of up to
+// MaxBatchSize. Once the API rejects a batch request, the rest are sent one
+// by one through a pool of Config.BatchConcurrency workers instead.
+// Responses are in the order of reqs.
+func (c *Client) batch(ctx context.Context, reqs []batchGet) []batchResponse {
+	out := make([]batchResponse, len(reqs))
+	for start := 0; start < len(reqs); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(reqs))
+		indexes := make([]int, 0, end-start)
+		for i := start; i < end; i++ {
+			indexes = append(indexes, i)
+		}
+
+		if !c.batchUnsupported.Load() && len(indexes) > 1 && c.sendBatch(ctx, reqs, indexes, out) {
+			continue
+		}
+		c.getEach(ctx, reqs, indexes, out)
+	}
+	return out
+}
+
+// sendBatch sends the requests at indexes as one Graph API batch request
+// and stores their responses in out. It reports false, leaving out
+// untouched, when the API does not accept batch requests.
+func (c *Client) sendBatch(ctx context.Context, reqs []batchGet, indexes []int, out []batchResponse) bool {
+	type batchItem struct {
+		Method      string `json:"method"`
+		RelativeURL string `json:"relative_url"`
+	}
+	items := make([]batchItem, len(indexes))
+	for j, i := range indexes {
+		relativeURL := strings.TrimPrefix(reqs[i].path, "/")
+		if len(reqs[i].params) > 0 {
+			relativeURL += "?" + reqs[i].params.Encode()
+		}
+		items[j] = batchItem{Method: "GET", RelativeURL: relativeURL}
+	}
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return false
+	}
+
+	resp, err := c.httpClient.Do(&RequestOptions{
+		Method:     "POST",
+		Path:       "/",
+		Body:       url.Values{"batch": {string(encoded)}, "include_headers": {"false"}},
+		Context:    ctx,
+		Idempotent: true,
+	}, c.getAccessTokenSafe())
+	if err != nil {
+		// These would fail every request of the batch on its own too
+		if ctx.Err() != nil || IsAuthenticationError(err) || IsRateLimitError(err) {
+			for _, i := range indexes {
+				out[i].err = err
+			}
+			return true
+		}
+		c.disableBatching(err)
+		return false
+	}
+
+	// Each answer is null if the API gave up on that request
+	var answers []*struct {
+		Code int    `json:"code"`
+		Body string `json:"body"`
+	}
+	if errJSON := json.Unmarshal(resp.Body, &answers); errJSON != nil || len(answers) != len(indexes) {
+		c.disableBatching(NewAPIError(resp.StatusCode, "Unexpected batch response", string(resp.Body), resp.RequestID))
+		return false
+	}
+
+	var unanswered []int
+	for j, answer := range answers {
+		i := indexes[j]
+		if answer == nil {
+			unanswered = append(unanswered, i)
+			continue
+		}
+		item := &Response{StatusCode: answer.Code, Body: []byte(answer.Body), RequestID: resp.RequestID}
+		if answer.Code >= 400 {
+			out[i].err = c.httpClient.createErrorFromResponse(item)
+			continue
+		}
+		out[i].body = item.Body
+		out[i].requestID = item.RequestID
+	}
+	c.getEach(ctx, reqs, unanswered, out)
+	return true
+}
+
+// disableBatching stops the client from sending further batch requests.
+func (c *Client) disableBatching(cause error) {
+	c.batchUnsupported.Store(true)
+	if c.config != nil && c.config.Logger != nil {
+		c.config.Logger.Warn("Batch requests unavailable, sending requests individually", "error", cause.Error())
+	}
+}
+
+// getEach sends the requests at indexes one by one, at most
+// Config.BatchConcurrency at a time, and stores their responses in out.
+func (c *Client) getEach(ctx context.Context, reqs []batchGet, indexes []int, out []batchResponse) {
+	workers := DefaultBatchConcurrency
+	if c.config != nil && c.config.BatchConcurrency > 0 {
+		workers = c.config.BatchConcurrency
+	}
+	workers = min(workers, len(indexes))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				resp, err := c.httpClient.Do(&RequestOptions{
+					Method:      "GET",
+					Path:        reqs[i].path,
+					QueryParams: reqs[i].params,
+					Context:     ctx,
+					Cache:       reqs[i].cache,
+				}, c.getAccessTokenSafe())
+				if err != nil {
+					out[i].err = err
+					continue
+				}
+				out[i].body = resp.Body
+				out[i].requestID = resp.RequestID
+			}
+		}()
+	}
+	for _, i := range indexes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
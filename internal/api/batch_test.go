@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetPostsBatch_PacksRequests(t *testing.T) {
+	var batches, gets atomic.Int32
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/" {
+			gets.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		batches.Add(1)
+
+		var items []struct {
+			Method      string `json:"method"`
+			RelativeURL string `json:"relative_url"`
+		}
+		if err := json.Unmarshal([]byte(r.FormValue("batch")), &items); err != nil {
+			t.Errorf("invalid batch parameter: %v", err)
+		}
+		answers := make([]any, len(items))
+		for i, item := range items {
+			id, _, _ := strings.Cut(item.RelativeURL, "?")
+			if item.Method != http.MethodGet || !strings.Contains(item.RelativeURL, "fields=") {
+				t.Errorf("unexpected batch item %+v", item)
+			}
+			if id == "missing" {
+				answers[i] = map[string]any{"code": 404, "body": `{"error":{"message":"Object does not exist","code":100}}`}
+				continue
+			}
+			answers[i] = map[string]any{"code": 200, "body": fmt.Sprintf(`{"id":%q,"text":"post %s"}`, id, id)}
+		}
+		_ = json.NewEncoder(w).Encode(answers)
+	}, nil)
+
+	ids := make([]PostID, 0, MaxBatchSize+2)
+	for i := range MaxBatchSize + 1 {
+		ids = append(ids, PostID(fmt.Sprint(i+1)))
+	}
+	ids = append(ids, "missing")
+
+	results, err := client.GetPostsBatch(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("GetPostsBatch failed: %v", err)
+	}
+	if batches.Load() != 2 || gets.Load() != 0 {
+		t.Errorf("expected 2 batch requests and no single requests, got %d and %d", batches.Load(), gets.Load())
+	}
+	if len(results) != len(ids) {
+		t.Fatalf("expected %d results, got %d", len(ids), len(results))
+	}
+	for i, r := range results[:len(results)-1] {
+		if r.Err != nil || r.Post == nil || r.Post.ID != ids[i].String() {
+			t.Errorf("result %d: unexpected %+v", i, r)
+		}
+	}
+	if last := results[len(results)-1]; last.Post != nil || last.Err == nil {
+		t.Errorf("expected the missing post to fail on its own, got %+v", last)
+	}
+}
+
+func TestGetPostInsightsBatch_FallsBackToSingleRequests(t *testing.T) {
+	var batches, gets atomic.Int32
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			batches.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"Unsupported post request","code":100}}`))
+			return
+		}
+		gets.Add(1)
+		if r.URL.Query().Get("metric") != "views,likes" {
+			t.Errorf("unexpected metrics %q", r.URL.Query().Get("metric"))
+		}
+		_, _ = w.Write([]byte(`{"data":[{"name":"views","period":"lifetime","values":[{"value":7}]}]}`))
+	}, func(cfg *Config) {
+		cfg.BatchConcurrency = 2
+	})
+
+	ids := []PostID{"1", "2", "3"}
+	results, err := client.GetPostInsightsBatch(context.Background(), ids, []string{"views", "likes"})
+	if err != nil {
+		t.Fatalf("GetPostInsightsBatch failed: %v", err)
+	}
+	for i, r := range results {
+		if r.Err != nil || r.ID != ids[i] || len(r.Insights.Data) != 1 || r.Insights.Data[0].Values[0].Value != 7 {
+			t.Errorf("result %d: unexpected %+v", i, r)
+		}
+	}
+	if batches.Load() != 1 || gets.Load() != 3 {
+		t.Errorf("expected 1 rejected batch and 3 single requests, got %d and %d", batches.Load(), gets.Load())
+	}
+
+	// Batching stays off once the API rejected it
+	if _, err := client.GetPostInsightsBatch(context.Background(), ids, []string{"views", "likes"}); err != nil {
+		t.Fatalf("GetPostInsightsBatch failed: %v", err)
+	}
+	if batches.Load() != 1 {
+		t.Errorf("expected no further batch requests, got %d", batches.Load())
+	}
+
+	if _, err := client.GetPostInsightsBatch(context.Background(), ids, []string{"bogus"}); err == nil {
+		t.Error("expected an invalid metric to fail the whole call")
+	}
+}
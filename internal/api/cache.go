@@ -0,0 +1,182 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// CacheEndpoint names a read endpoint whose responses may be cached.
+type CacheEndpoint string
+
+// Cacheable endpoints.
+const (
+	CachePost     CacheEndpoint = "post"
+	CacheUser     CacheEndpoint = "user"
+	CacheInsights CacheEndpoint = "insights"
+	CacheLocation CacheEndpoint = "location"
+)
+
+// DefaultCacheTTLs are how long each endpoint's responses are served from
+// the cache before they are revalidated.
+var DefaultCacheTTLs = map[CacheEndpoint]time.Duration{
+	CachePost:     5 * time.Minute,
+	CacheUser:     time.Hour,
+	CacheInsights: 5 * time.Minute,
+	CacheLocation: 24 * time.Hour,
+}
+
+// CacheMode controls how the response cache is used for a client.
+type CacheMode int
+
+const (
+	// CacheDefault serves fresh entries and revalidates stale ones.
+	CacheDefault CacheMode = iota
+	// CacheRefresh always asks the API, revalidating stored entries.
+	CacheRefresh
+	// CacheOff neither reads nor writes the cache.
+	CacheOff
+)
+
+// CachedResponse is a stored API response.
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+}
+
+// ResponseCache stores GET responses by path and encoded query. Get returns
+// nil and no error on a miss. Invalidate drops every entry for path, and is
+// called after a successful write to it.
+type ResponseCache interface {
+	Get(path, query string) (*CachedResponse, error)
+	Set(path, query string, entry *CachedResponse) error
+	Invalidate(path string) error
+}
+
+// CacheConfig enables the response cache for read endpoints.
+type CacheConfig struct {
+	// Store holds cached responses. It should be scoped to one account.
+	Store ResponseCache
+
+	// TTLs overrides DefaultCacheTTLs per endpoint. A negative TTL disables
+	// caching for the endpoint.
+	TTLs map[CacheEndpoint]time.Duration
+
+	// Mode selects how the cache is used (default: CacheDefault).
+	Mode CacheMode
+}
+
+// cachedHeaders are the response headers kept with a cached response.
+var cachedHeaders = []string{"Content-Type", "ETag", "Last-Modified", "X-Fb-Request-Id"}
+
+// cacheTTL returns how long responses of endpoint may be cached, or 0 when
+// the request is not cacheable.
+func (h *HTTPClient) cacheTTL(opts *RequestOptions) time.Duration {
+	if h.cache == nil || h.cache.Store == nil || h.cache.Mode == CacheOff {
+		return 0
+	}
+	if opts.Cache == "" || (opts.Method != "" && opts.Method != http.MethodGet) {
+		return 0
+	}
+	ttl, ok := h.cache.TTLs[opts.Cache]
+	if !ok {
+		ttl = DefaultCacheTTLs[opts.Cache]
+	}
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// cacheLookup returns the stored entry for a cacheable request, if any.
+func (h *HTTPClient) cacheLookup(opts *RequestOptions) *CachedResponse {
+	if h.cacheTTL(opts) <= 0 {
+		return nil
+	}
+	entry, err := h.cache.Store.Get(opts.Path, opts.QueryParams.Encode())
+	if err != nil {
+		h.logCacheError("read", opts.Path, err)
+		return nil
+	}
+	return entry
+}
+
+// freshCachedResponse returns a cached response that can be served without
+// contacting the API.
+func (h *HTTPClient) freshCachedResponse(opts *RequestOptions) *Response {
+	if h.cache == nil || h.cache.Mode != CacheDefault {
+		return nil
+	}
+	entry := h.cacheLookup(opts)
+	if entry == nil || !time.Now().Before(entry.ExpiresAt) {
+		return nil
+	}
+	return cachedResponse(entry)
+}
+
+// storeResponse caches a successful response, or refreshes entry after a
+// 304 Not Modified.
+func (h *HTTPClient) storeResponse(opts *RequestOptions, resp *Response, entry *CachedResponse) {
+	ttl := h.cacheTTL(opts)
+	if ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		entry.StoredAt = now
+		entry.ExpiresAt = now.Add(ttl)
+	} else {
+		header := http.Header{}
+		for _, key := range cachedHeaders {
+			if v := resp.Header.Get(key); v != "" {
+				header.Set(key, v)
+			}
+		}
+		entry = &CachedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       resp.Body,
+			StoredAt:   now,
+			ExpiresAt:  now.Add(ttl),
+		}
+	}
+
+	if err := h.cache.Store.Set(opts.Path, opts.QueryParams.Encode(), entry); err != nil {
+		h.logCacheError("write", opts.Path, err)
+	}
+}
+
+// invalidateCache drops cached responses for a path after it was written to.
+func (h *HTTPClient) invalidateCache(opts *RequestOptions) {
+	if h.cache == nil || h.cache.Store == nil || opts.Method == "" || opts.Method == http.MethodGet {
+		return
+	}
+	if err := h.cache.Store.Invalidate(opts.Path); err != nil {
+		h.logCacheError("invalidate", opts.Path, err)
+	}
+}
+
+// cachedResponse builds a Response from a cache entry.
+func cachedResponse(entry *CachedResponse) *Response {
+	header := entry.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &Response{
+		Response:   &http.Response{StatusCode: entry.StatusCode, Header: header},
+		Body:       entry.Body,
+		RequestID:  header.Get("X-Fb-Request-Id"),
+		StatusCode: entry.StatusCode,
+		Cached:     true,
+	}
+}
+
+func (h *HTTPClient) logCacheError(op, path string, err error) {
+	if h.logger == nil {
+		return
+	}
+	h.logger.Warn("Response cache "+op+" failed", "path", path, "error", err.Error())
+}
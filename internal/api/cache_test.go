@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]*CachedResponse
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: map[string]*CachedResponse{}}
+}
+
+func (m *memoryCache) Get(path, query string) (*CachedResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.entries[path+"?"+query], nil
+}
+
+func (m *memoryCache) Set(path, query string, entry *CachedResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[path+"?"+query] = entry
+	return nil
+}
+
+func (m *memoryCache) Invalidate(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.entries {
+		if len(key) > len(path) && key[:len(path)+1] == path+"?" {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+func TestHTTPClient_CacheServesFreshEntries(t *testing.T) {
+	calls := 0
+	client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"p1","text":"hello"}`))
+	})
+	defer server.Close()
+	client.httpClient.cache = &CacheConfig{Store: newMemoryCache()}
+
+	for i := 0; i < 2; i++ {
+		post, err := client.GetPost(t.Context(), PostID("p1"))
+		if err != nil {
+			t.Fatalf("GetPost failed: %v", err)
+		}
+		if post.Text != "hello" {
+			t.Errorf("unexpected post text %q", post.Text)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 API call, got %d", calls)
+	}
+
+	// Uncached endpoints always reach the API
+	for i := 0; i < 2; i++ {
+		if _, err := client.httpClient.GET(context.Background(), "/p1", nil, "token"); err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("expected untagged requests to skip the cache, got %d calls", calls)
+	}
+}
+
+func TestHTTPClient_CacheRevalidatesWithETag(t *testing.T) {
+	var calls, notModified int
+	client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"id":"p1","text":"hello"}`))
+	})
+	defer server.Close()
+	store := newMemoryCache()
+	client.httpClient.cache = &CacheConfig{Store: store, Mode: CacheRefresh}
+
+	for i := 0; i < 2; i++ {
+		post, err := client.GetPost(t.Context(), PostID("p1"))
+		if err != nil {
+			t.Fatalf("GetPost failed: %v", err)
+		}
+		if post.Text != "hello" {
+			t.Errorf("request %d: unexpected post text %q", i, post.Text)
+		}
+	}
+	if calls != 2 || notModified != 1 {
+		t.Errorf("expected one full and one conditional request, got %d calls, %d not modified", calls, notModified)
+	}
+}
+
+func TestHTTPClient_CacheExpiryAndModes(t *testing.T) {
+	calls := 0
+	client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"loc1","name":"Cafe"}`))
+	})
+	defer server.Close()
+	store := newMemoryCache()
+	client.httpClient.cache = &CacheConfig{Store: store}
+
+	get := func() {
+		t.Helper()
+		if _, err := client.GetLocation(t.Context(), LocationID("loc1")); err != nil {
+			t.Fatalf("GetLocation failed: %v", err)
+		}
+	}
+
+	get()
+	for _, entry := range store.entries {
+		entry.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	get()
+	if calls != 2 {
+		t.Errorf("expected an expired entry to be fetched again, got %d calls", calls)
+	}
+
+	client.httpClient.cache.Mode = CacheOff
+	get()
+	if calls != 3 {
+		t.Errorf("expected CacheOff to bypass the cache, got %d calls", calls)
+	}
+
+	client.httpClient.cache = &CacheConfig{Store: newMemoryCache(), TTLs: map[CacheEndpoint]time.Duration{CacheLocation: -1}}
+	get()
+	get()
+	if calls != 5 {
+		t.Errorf("expected a negative TTL to disable caching, got %d calls", calls)
+	}
+}
+
+func TestHTTPClient_CacheInvalidatesOnWrite(t *testing.T) {
+	client, server := createTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true}`))
+	})
+	defer server.Close()
+	store := newMemoryCache()
+	client.httpClient.cache = &CacheConfig{Store: store}
+
+	if _, err := client.httpClient.GETCached(context.Background(), CachePost, "/p1", nil, "token"); err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	if len(store.entries) != 1 {
+		t.Fatalf("expected 1 cached entry, got %d", len(store.entries))
+	}
+	if _, err := client.httpClient.DELETE(context.Background(), "/p1", "token"); err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	if len(store.entries) != 0 {
+		t.Errorf("expected DELETE to invalidate the cached post, got %d entries", len(store.entries))
+	}
+}
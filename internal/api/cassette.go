@@ -0,0 +1,325 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+// CassetteMode selects whether HTTP traffic is recorded or replayed.
+type CassetteMode string
+
+const (
+	// CassetteRecord sends requests to the API and appends each exchange to
+	// the cassette.
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay answers requests from the cassette without any network
+	// access.
+	CassetteReplay CassetteMode = "replay"
+)
+
+// redacted replaces secrets in recorded requests and responses.
+const redacted = "[REDACTED]"
+
+// sensitiveParams are query and form parameters scrubbed from cassettes.
+var sensitiveParams = []string{"access_token", "client_secret", "code", "fb_exchange_token", "input_token"}
+
+// sensitiveFields are top-level JSON response fields scrubbed from cassettes.
+var sensitiveFields = []string{"access_token", "refresh_token"}
+
+// CassetteConfig records HTTP traffic to, or replays it from, a cassette
+// file so commands can be tested deterministically without a network.
+type CassetteConfig struct {
+	Path string
+	Mode CassetteMode
+
+	// Account is saved with a recording, so it can be replayed where no
+	// credentials are stored.
+	Account *CassetteAccount
+}
+
+// CassetteAccount identifies the account a cassette was recorded with. It
+// never holds tokens or secrets.
+type CassetteAccount struct {
+	Name     string `json:"name"`
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// CassetteFromEnv returns the cassette selected by THREADS_RECORD or
+// THREADS_REPLAY, or nil when neither is set.
+func CassetteFromEnv() (*CassetteConfig, error) {
+	record := os.Getenv("THREADS_RECORD")
+	replay := os.Getenv("THREADS_REPLAY")
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("THREADS_RECORD and THREADS_REPLAY cannot both be set")
+	case record != "":
+		return &CassetteConfig{Path: record, Mode: CassetteRecord}, nil
+	case replay != "":
+		return &CassetteConfig{Path: replay, Mode: CassetteReplay}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Cassette is a recorded sequence of HTTP exchanges.
+type Cassette struct {
+	Account      *CassetteAccount `json:"account,omitempty"`
+	Interactions []Interaction    `json:"interactions"`
+}
+
+// Interaction is one recorded request and the response it received.
+type Interaction struct {
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+// RecordedRequest is a request with its credentials scrubbed.
+type RecordedRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response with tokens scrubbed from its body.
+type RecordedResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // The cassette path is chosen by the local user
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette atomically, so an interrupted command never
+// leaves a truncated file.
+func (c *Cassette) Save(path string) error {
+	if err := fsutil.WriteJSON(path, c); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// cassetteTransport records exchanges through next, or replays them from
+// the cassette when next is nil.
+type cassetteTransport struct {
+	mu       sync.Mutex
+	path     string
+	cassette *Cassette
+	used     []bool
+	next     http.RoundTripper
+}
+
+// useCassette routes the client's requests through a recording or
+// replaying transport.
+func (h *HTTPClient) useCassette(cfg *CassetteConfig) error {
+	if cfg == nil {
+		return nil
+	}
+
+	t := &cassetteTransport{path: cfg.Path}
+	switch cfg.Mode {
+	case CassetteRecord:
+		t.cassette = &Cassette{}
+		if existing, err := LoadCassette(cfg.Path); err == nil {
+			t.cassette = existing
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if cfg.Account != nil {
+			t.cassette.Account = cfg.Account
+		}
+		t.next = h.transport
+	case CassetteReplay:
+		cassette, err := LoadCassette(cfg.Path)
+		if err != nil {
+			return fmt.Errorf("failed to load cassette: %w", err)
+		}
+		t.cassette = cassette
+		t.used = make([]bool, len(cassette.Interactions))
+	default:
+		return fmt.Errorf("unknown cassette mode %q", cfg.Mode)
+	}
+
+	h.client.Transport = h.chain(t)
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := t.recordRequest(req, body)
+
+	if t.next == nil {
+		return t.replay(req, recorded)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck,gosec // Body fully read
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    sanitizeHeaders(resp.Header),
+			Body:       string(scrubJSON(respBody)),
+		},
+		RecordedAt: time.Now().UTC(),
+	})
+	if err := t.cassette.Save(t.path); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// replay answers req with the first unused interaction for the same method,
+// path and query, falling back to one that only matches method and path so
+// requests with time-based parameters still replay.
+func (t *cassetteTransport) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	match := -1
+	for i, interaction := range t.cassette.Interactions {
+		r := interaction.Request
+		if t.used[i] || r.Method != recorded.Method || r.Path != recorded.Path {
+			continue
+		}
+		if r.Query == recorded.Query {
+			match = i
+			break
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("no recorded response for %s %s in cassette %s", recorded.Method, recorded.Path, t.path)
+	}
+	t.used[match] = true
+
+	recordedResp := t.cassette.Interactions[match].Response
+	header := http.Header{}
+	for key, value := range recordedResp.Headers {
+		header.Set(key, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recordedResp.StatusCode, http.StatusText(recordedResp.StatusCode)),
+		StatusCode:    recordedResp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recordedResp.Body)),
+		ContentLength: int64(len(recordedResp.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *cassetteTransport) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	recorded := RecordedRequest{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Query:   scrubValues(req.URL.Query()).Encode(),
+		Headers: sanitizeHeaders(req.Header),
+	}
+	if len(body) > 0 {
+		if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			if form, err := url.ParseQuery(string(body)); err == nil {
+				body = []byte(scrubValues(form).Encode())
+			}
+		}
+		recorded.Body = string(scrubJSON(body))
+	}
+	return recorded
+}
+
+// readRequestBody reads req's body and leaves an unread copy in its place.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close() //nolint:errcheck,gosec // Body fully read
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// scrubValues returns a copy of values with credentials redacted.
+func scrubValues(values url.Values) url.Values {
+	scrubbed := url.Values{}
+	for key, vals := range values {
+		scrubbed[key] = vals
+	}
+	for _, key := range sensitiveParams {
+		if scrubbed.Has(key) {
+			scrubbed.Set(key, redacted)
+		}
+	}
+	return scrubbed
+}
+
+// scrubJSON redacts token fields of a JSON object. Other bodies are returned
+// unchanged.
+func scrubJSON(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+
+	changed := false
+	for _, key := range sensitiveFields {
+		if _, ok := fields[key]; ok {
+			fields[key] = json.RawMessage(`"` + redacted + `"`)
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	scrubbed, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return scrubbed
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newCassetteClient(t *testing.T, baseURL string, cassette *CassetteConfig) *Client {
+	t.Helper()
+
+	config := NewConfig()
+	config.ClientID = "test-client-id"
+	config.ClientSecret = "test-client-secret"
+	config.RedirectURI = "https://example.com/callback"
+	config.BaseURL = baseURL
+	config.RetryConfig.MaxRetries = 0
+	config.Cassette = cassette
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/me":
+			_, _ = w.Write([]byte(`{"id":"12345","username":"testuser"}`))
+		case "/refresh_access_token":
+			_, _ = w.Write([]byte(`{"access_token":"fresh-secret-token","token_type":"bearer"}`))
+		case "/oauth/access_token":
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	recorder := newCassetteClient(t, server.URL, &CassetteConfig{Path: path, Mode: CassetteRecord})
+	if _, err := recorder.httpClient.GET(context.Background(), "/me", url.Values{"fields": {"id,username"}}, "live-secret-token"); err != nil {
+		t.Fatalf("GET /me failed: %v", err)
+	}
+	if _, err := recorder.httpClient.GET(context.Background(), "/refresh_access_token", url.Values{"access_token": {"live-secret-token"}}, ""); err != nil {
+		t.Fatalf("GET /refresh_access_token failed: %v", err)
+	}
+	if _, err := recorder.httpClient.POST(context.Background(), "/oauth/access_token", url.Values{"client_secret": {"app-secret"}, "code": {"auth-code"}}, ""); err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cassette not written: %v", err)
+	}
+	for _, secret := range []string{"live-secret-token", "fresh-secret-token", "app-secret", "auth-code"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette leaks %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), redacted) {
+		t.Error("expected redacted values in the cassette")
+	}
+
+	// Replay works with the server gone.
+	server.Close()
+	replayer := newCassetteClient(t, server.URL, &CassetteConfig{Path: path, Mode: CassetteReplay})
+	resp, err := replayer.httpClient.GET(context.Background(), "/me", url.Values{"fields": {"id,username"}}, "another-token")
+	if err != nil {
+		t.Fatalf("replayed GET /me failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(resp.Body), `"testuser"`) {
+		t.Errorf("unexpected replayed response: %d %s", resp.StatusCode, resp.Body)
+	}
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected recorded headers, got %v", resp.Header)
+	}
+
+	// Query values that differ (e.g. timestamps) still replay by path.
+	if _, err := replayer.httpClient.GET(context.Background(), "/refresh_access_token", url.Values{"access_token": {"x"}, "t": {"1"}}, ""); err != nil {
+		t.Errorf("expected a path match to replay, got %v", err)
+	}
+
+	// Each interaction is used once.
+	if _, err := replayer.httpClient.GET(context.Background(), "/me", url.Values{"fields": {"id,username"}}, "token"); err == nil {
+		t.Error("expected an error once the recorded /me response was used")
+	}
+	if _, err := replayer.httpClient.GET(context.Background(), "/other", nil, "token"); err == nil {
+		t.Error("expected an error for an unrecorded request")
+	}
+}
+
+func TestCassette_RecordAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		client := newCassetteClient(t, server.URL, &CassetteConfig{Path: path, Mode: CassetteRecord})
+		if _, err := client.httpClient.GET(context.Background(), "/me", nil, "token"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cassette.Interactions) != 2 {
+		t.Errorf("expected recordings to be appended, got %d interactions", len(cassette.Interactions))
+	}
+	if got := cassette.Interactions[0].Request.Headers["Authorization"]; got != redacted {
+		t.Errorf("expected a redacted Authorization header, got %q", got)
+	}
+}
+
+func TestCassette_ReplayMissingFile(t *testing.T) {
+	config := NewConfig()
+	config.ClientID = "id"
+	config.ClientSecret = "secret"
+	config.RedirectURI = "https://example.com/callback"
+	config.Cassette = &CassetteConfig{Path: filepath.Join(t.TempDir(), "missing.json"), Mode: CassetteReplay}
+	if _, err := NewClient(config); err == nil {
+		t.Error("expected an error for a missing cassette")
+	}
+}
+
+func TestCassetteFromEnv(t *testing.T) {
+	t.Setenv("THREADS_RECORD", "")
+	t.Setenv("THREADS_REPLAY", "")
+	if cfg, err := CassetteFromEnv(); cfg != nil || err != nil {
+		t.Errorf("expected no cassette, got %+v, %v", cfg, err)
+	}
+
+	t.Setenv("THREADS_REPLAY", "session.json")
+	if cfg, err := CassetteFromEnv(); err != nil || cfg.Mode != CassetteReplay || cfg.Path != "session.json" {
+		t.Errorf("expected a replay cassette, got %+v, %v", cfg, err)
+	}
+
+	t.Setenv("THREADS_RECORD", "other.json")
+	if _, err := CassetteFromEnv(); err == nil {
+		t.Error("expected an error when both are set")
+	}
+}
@@ -42,6 +42,24 @@
 //	// Direct user to authURL, then exchange code for token
 //	err = client.ExchangeCodeForToken("auth-code-from-callback")
 //
+// # Middleware
+//
+// Every request is sent through a chain of http.RoundTripper middlewares:
+// the built-in RetryMiddleware, throttling, per-attempt timeouts and
+// RateLimitMiddleware, then Config.Middlewares, then LoggingMiddleware, and
+// finally Config.Transport. Add middlewares to sign requests, collect
+// metrics or trace calls:
+//
+//	config.Middlewares = []threads.Middleware{
+//		func(next http.RoundTripper) http.RoundTripper {
+//			return threads.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+//				req = req.Clone(req.Context())
+//				req.Header.Set("X-Request-Source", "my-app")
+//				return next.RoundTrip(req)
+//			})
+//		},
+//	}
+//
 // For complete API documentation: https://developers.facebook.com/docs/threads
 package api
 
@@ -49,11 +67,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -68,6 +88,9 @@ type Client struct {
 	tokenInfo    *TokenInfo
 	tokenStorage TokenStorage
 	mu           sync.RWMutex // Protects token-related fields
+
+	// batchUnsupported is set once the API rejects a batch request
+	batchUnsupported atomic.Bool
 }
 
 // Config holds configuration settings for the Threads API client.
@@ -106,7 +129,7 @@ type Config struct {
 	// Default: 30 seconds. Set to 0 for no timeout (not recommended).
 	HTTPTimeout time.Duration
 
-	// RetryConfig configures retry behavior for failed requests (optional).
+	// RetryConfig is the retry policy for failed requests (optional).
 	// If nil, default retry configuration will be used.
 	RetryConfig *RetryConfig
 
@@ -133,26 +156,58 @@ type Config struct {
 	// Default: false. When true, detailed request/response information
 	// will be logged if a Logger is provided.
 	Debug bool
-}
 
-// RetryConfig defines retry behavior for failed requests with exponential backoff.
-type RetryConfig struct {
-	// MaxRetries is the maximum number of retry attempts (default: 3).
-	// Set to 0 to disable retries. Higher values provide more resilience
-	// but may increase latency for failing requests.
-	MaxRetries int
+	// ContainerPoll configures how container status is polled before
+	// publishing (optional). If nil, DefaultContainerPollConfig is used.
+	ContainerPoll *ContainerPollConfig
+
+	// ContainerJournal records every container the client creates and its
+	// publish state (optional). If nil, nothing is recorded. Use a persistent
+	// journal so an interrupted publish can be resumed with PublishContainer.
+	ContainerJournal ContainerJournal
+
+	// MediaChecker inspects image and video URLs before a container is
+	// created (optional), so media Threads would reject fails fast instead
+	// of after a processing wait. If nil, only the URL format is checked.
+	MediaChecker MediaChecker
+
+	// QuotaLedger tracks posts, replies and deletes against the account's
+	// rolling publishing quotas (optional). If nil, quota is only enforced
+	// by the API.
+	QuotaLedger QuotaLedger
+
+	// Throttle spaces out requests per endpoint class before they are sent
+	// (optional). If nil, requests are only delayed after the API reports
+	// a rate limit.
+	Throttle *ThrottleConfig
 
-	// InitialDelay is the delay before the first retry attempt (default: 1 second).
-	// This delay is multiplied by BackoffFactor for subsequent retries.
-	InitialDelay time.Duration
+	// Cache stores responses of read endpoints (optional). If nil, every
+	// request goes to the API.
+	Cache *CacheConfig
 
-	// MaxDelay is the maximum delay between retry attempts (default: 30 seconds).
-	// This prevents exponential backoff from creating excessively long delays.
-	MaxDelay time.Duration
+	// Cassette records HTTP traffic to a file, or replays it from one
+	// without network access (optional). See CassetteFromEnv.
+	Cassette *CassetteConfig
 
-	// BackoffFactor is the multiplier for exponential backoff (default: 2.0).
-	// Each retry delay is calculated as: min(InitialDelay * BackoffFactor^attempt, MaxDelay)
-	BackoffFactor float64
+	// Transport sends the client's HTTP requests (optional). If nil,
+	// http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// Middlewares wrap every request and response sent through Transport
+	// (optional), the first outermost. They run once per attempt, inside
+	// the built-in retry, throttle and rate limit middlewares and outside
+	// request logging.
+	Middlewares []Middleware
+
+	// Telemetry receives spans and metrics for requests, retries, rate
+	// limit waits and container processing (optional). If nil, nothing is
+	// recorded.
+	Telemetry Telemetry
+
+	// BatchConcurrency is how many requests GetPostsBatch and
+	// GetPostInsightsBatch send at once when the API does not accept batch
+	// requests (default: 4).
+	BatchConcurrency int
 }
 
 // Logger interface for structured logging.
@@ -246,9 +301,10 @@ func NewConfig() *Config {
 			MaxDelay:      30 * time.Second,
 			BackoffFactor: 2.0,
 		},
-		BaseURL:   "https://graph.threads.net",
-		UserAgent: DefaultUserAgent,
-		Debug:     false,
+		ContainerPoll: DefaultContainerPollConfig(),
+		BaseURL:       "https://graph.threads.net",
+		UserAgent:     DefaultUserAgent,
+		Debug:         false,
 	}
 }
 
@@ -330,6 +386,32 @@ func NewConfigFromEnv() (*Config, error) {
 		}
 	}
 
+	// Container polling configuration from environment
+	if pollInterval := os.Getenv("THREADS_POLL_INTERVAL"); pollInterval != "" {
+		if interval, err := time.ParseDuration(pollInterval); err == nil && interval > 0 {
+			config.ContainerPoll.InitialInterval = interval
+		}
+	}
+
+	if pollMaxInterval := os.Getenv("THREADS_POLL_MAX_INTERVAL"); pollMaxInterval != "" {
+		if interval, err := time.ParseDuration(pollMaxInterval); err == nil && interval > 0 {
+			config.ContainerPoll.MaxInterval = interval
+		}
+	}
+
+	if pollMaxAttempts := os.Getenv("THREADS_POLL_MAX_ATTEMPTS"); pollMaxAttempts != "" {
+		if attempts, err := strconv.Atoi(pollMaxAttempts); err == nil && attempts > 0 {
+			config.ContainerPoll.MaxAttempts = attempts
+		}
+	}
+
+	// Record or replay HTTP traffic
+	cassette, err := CassetteFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	config.Cassette = cassette
+
 	return config, nil
 }
 
@@ -402,6 +484,24 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.ContainerPoll != nil {
+		if c.ContainerPoll.InitialInterval <= 0 {
+			return fmt.Errorf("ContainerPoll.InitialInterval must be positive")
+		}
+
+		if c.ContainerPoll.MaxInterval < c.ContainerPoll.InitialInterval {
+			return fmt.Errorf("ContainerPoll.MaxInterval cannot be less than InitialInterval")
+		}
+
+		if c.ContainerPoll.Multiplier < 1 {
+			return fmt.Errorf("ContainerPoll.Multiplier must be at least 1")
+		}
+
+		if c.ContainerPoll.MaxAttempts <= 0 {
+			return fmt.Errorf("ContainerPoll.MaxAttempts must be positive")
+		}
+	}
+
 	if c.BaseURL == "" {
 		return fmt.Errorf("BaseURL is required")
 	}
@@ -432,6 +532,10 @@ func (c *Config) SetDefaults() {
 		}
 	}
 
+	if c.ContainerPoll == nil {
+		c.ContainerPoll = DefaultContainerPollConfig()
+	}
+
 	if c.BaseURL == "" {
 		c.BaseURL = "https://graph.threads.net"
 	}
@@ -474,6 +578,9 @@ func NewClient(config *Config) (*Client, error) {
 
 	// Create HTTP client
 	httpClient := NewHTTPClient(config, rateLimiter)
+	if err := httpClient.useCassette(config.Cassette); err != nil {
+		return nil, fmt.Errorf("failed to set up cassette: %w", err)
+	}
 
 	client := &Client{
 		config:       config,
@@ -728,9 +835,12 @@ func safeJSONUnmarshal(data []byte, v any, context string, requestID string) err
 	return nil
 }
 
-// GetRateLimitStatus returns the current rate limit status
+// GetRateLimitStatus returns the current rate limit status, including
+// client-side throttling metrics when a throttle is configured.
 func (c *Client) GetRateLimitStatus() RateLimitStatus {
-	return c.rateLimiter.GetStatus()
+	status := c.rateLimiter.GetStatus()
+	status.Throttle = c.httpClient.throttle.Stats()
+	return status
 }
 
 // IsNearRateLimit returns true if the client is close to hitting rate limits
@@ -781,11 +891,11 @@ func (c *Client) TestAPICall(method, path string, params map[string]string) (*Re
 
 	switch method {
 	case "GET":
-		return c.httpClient.GET(path, queryParams, token)
+		return c.httpClient.GET(context.Background(), path, queryParams, token)
 	case "POST":
-		return c.httpClient.POST(path, queryParams, token)
+		return c.httpClient.POST(context.Background(), path, queryParams, token)
 	default:
-		return c.httpClient.GET(path, queryParams, token)
+		return c.httpClient.GET(context.Background(), path, queryParams, token)
 	}
 }
 
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -647,3 +650,58 @@ func TestValidationMoreCases(t *testing.T) {
 		}
 	})
 }
+
+func TestCreatePost_ChecksMediaWithRequestContext(t *testing.T) {
+	requests := 0
+	client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	checker := &stubMediaChecker{err: NewValidationError(400, "Video too long", "Video is 400s", "duration")}
+	client.config.MediaChecker = checker
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	_, err := client.CreateVideoPost(ctx, &VideoPostContent{VideoURL: "https://example.com/video.mp4"})
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "duration" {
+		t.Errorf("Expected checker error, got: %v", err)
+	}
+	if checker.mediaURL != "https://example.com/video.mp4" || checker.mediaType != "video" {
+		t.Errorf("Checker called with %q, %q", checker.mediaURL, checker.mediaType)
+	}
+	if checker.ctx == nil || checker.ctx.Value(ctxKey{}) != "request" {
+		t.Error("Expected the checker to run with the request context")
+	}
+	if requests != 0 {
+		t.Errorf("Expected no container to be created, got %d requests", requests)
+	}
+
+	// Validation alone never inspects the media.
+	checker.mediaURL = ""
+	if err := client.ValidateVideoPostContent(&VideoPostContent{VideoURL: "https://example.com/video.mp4"}); err != nil || checker.mediaURL != "" {
+		t.Errorf("Expected validation without calling the checker, got %v", err)
+	}
+
+	// ValidateMediaURL rejects a bad format before the checker fetches anything.
+	if err := client.ValidateMediaURL(ctx, "ftp://example.com/video.mp4", "video"); !errors.As(err, &valErr) || valErr.Field != "media_url" || checker.mediaURL != "" {
+		t.Errorf("Expected a format error without calling the checker, got %v", err)
+	}
+	if err := client.ValidateMediaURL(ctx, "https://example.com/video.mp4", "video"); !errors.As(err, &valErr) || valErr.Field != "duration" {
+		t.Errorf("Expected ValidateMediaURL to run the checker, got %v", err)
+	}
+}
+
+type stubMediaChecker struct {
+	err       error
+	ctx       context.Context
+	mediaURL  string
+	mediaType string
+}
+
+func (s *stubMediaChecker) CheckMedia(ctx context.Context, mediaURL, mediaType string) error {
+	s.ctx, s.mediaURL, s.mediaType = ctx, mediaURL, mediaType
+	return s.err
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// PublishState is a step in the create → poll → publish flow for a container.
+type PublishState string
+
+// Publish states recorded in the container journal.
+const (
+	// PublishStateCreated means the container exists but is not yet known to be ready.
+	PublishStateCreated PublishState = "created"
+	// PublishStateReady means the container finished processing and can be published.
+	PublishStateReady PublishState = "ready"
+	// PublishStatePublished means the container was published as a post.
+	PublishStatePublished PublishState = "published"
+	// PublishStateFailed means processing failed or the container expired.
+	PublishStateFailed PublishState = "failed"
+)
+
+// Container kinds recorded in the container journal. Top-level kinds match
+// the media_type sent to the API; carousel children use MediaTypeCarouselItem.
+const (
+	MediaTypeCarouselItem = "CAROUSEL_ITEM"
+)
+
+// ContainerPollConfig configures how container status is polled while
+// waiting for processing to finish. Intervals grow by Multiplier up to MaxInterval.
+type ContainerPollConfig struct {
+	// InitialInterval is the delay before the second status check (default: 1 second).
+	InitialInterval time.Duration
+
+	// MaxInterval caps the delay between status checks (default: 10 seconds).
+	MaxInterval time.Duration
+
+	// Multiplier grows the interval after each check (default: 1.5).
+	Multiplier float64
+
+	// MaxAttempts is the maximum number of status checks (default: 30).
+	// The context deadline, if any, also bounds the wait.
+	MaxAttempts int
+}
+
+// DefaultContainerPollConfig returns the default container polling settings.
+func DefaultContainerPollConfig() *ContainerPollConfig {
+	return &ContainerPollConfig{
+		InitialInterval: DefaultContainerPollInterval,
+		MaxInterval:     10 * time.Second,
+		Multiplier:      1.5,
+		MaxAttempts:     DefaultContainerPollMaxAttempts,
+	}
+}
+
+// next returns the interval to wait after the given one.
+func (p *ContainerPollConfig) next(interval time.Duration) time.Duration {
+	interval = time.Duration(float64(interval) * p.Multiplier)
+	if interval > p.MaxInterval {
+		interval = p.MaxInterval
+	}
+	return interval
+}
+
+// ContainerRecord is a journal entry for a container the client created.
+type ContainerRecord struct {
+	ContainerID string       `json:"container_id"`
+	UserID      string       `json:"user_id,omitempty"`
+	Kind        string       `json:"kind"`
+	State       PublishState `json:"state"`
+	Children    []string     `json:"children,omitempty"`
+	PostID      string       `json:"post_id,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// ContainerJournal persists container records so a publish interrupted after
+// the container was created can be finished instead of recreated.
+// Record is called on every state transition with the full record.
+type ContainerJournal interface {
+	Record(rec ContainerRecord) error
+}
+
+// journal records a state transition. Journal failures are logged but never
+// fail the publish itself.
+func (c *Client) journal(rec *ContainerRecord, state PublishState, postID string, cause error) {
+	if c.config == nil || c.config.ContainerJournal == nil {
+		return
+	}
+
+	now := time.Now()
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = now
+	}
+	rec.UpdatedAt = now
+	rec.State = state
+	if postID != "" {
+		rec.PostID = postID
+	}
+	if cause != nil {
+		rec.Error = cause.Error()
+	}
+
+	if err := c.config.ContainerJournal.Record(*rec); err != nil && c.config.Logger != nil {
+		c.config.Logger.Warn("Failed to record container state", "container_id", rec.ContainerID, "state", string(state), "error", err)
+	}
+}
+
+// publishFlow runs the create → poll → publish state machine for a
+// top-level container. label names the post type in error messages.
+func (c *Client) publishFlow(ctx context.Context, kind, label string, params url.Values, children []string) (*Post, error) {
+	action := quotaActionFor(params)
+	if err := c.checkQuota(action); err != nil {
+		return nil, err
+	}
+
+	containerID, err := c.createContainer(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s container: %w", label, err)
+	}
+
+	rec := &ContainerRecord{
+		ContainerID: containerID,
+		UserID:      c.getUserID(),
+		Kind:        kind,
+		Children:    children,
+	}
+	c.journal(rec, PublishStateCreated, "", nil)
+
+	if _, errWait := c.WaitForContainer(ctx, ContainerID(containerID)); errWait != nil {
+		if alreadyPublished(errWait) {
+			c.markPublished(rec, "")
+			c.recordQuota(action, "")
+			return nil, fmt.Errorf("%s container was already published: %w", label, ErrPublishedIDUnknown)
+		}
+		if IsContainerError(errWait) {
+			c.journal(rec, PublishStateFailed, "", errWait)
+		}
+		return nil, fmt.Errorf("container not ready for publishing: %w", errWait)
+	}
+	c.journal(rec, PublishStateReady, "", nil)
+
+	post, errPublish := c.publishContainer(ctx, containerID)
+	if errPublish != nil {
+		if alreadyPublished(errPublish) {
+			// The post exists, but its ID was lost with the failed response
+			c.markPublished(rec, "")
+			c.recordQuota(action, "")
+			return nil, fmt.Errorf("%s post: %w", label, ErrPublishedIDUnknown)
+		}
+		return nil, fmt.Errorf("failed to publish %s post: %w", label, errPublish)
+	}
+	c.markPublished(rec, post.ID)
+	c.recordQuota(action, post.ID)
+
+	return post, nil
+}
+
+// markPublished records a container, and any carousel children, as published.
+func (c *Client) markPublished(rec *ContainerRecord, postID string) {
+	c.journal(rec, PublishStatePublished, postID, nil)
+	for _, child := range rec.Children {
+		c.journal(&ContainerRecord{
+			ContainerID: child,
+			UserID:      rec.UserID,
+			Kind:        MediaTypeCarouselItem,
+		}, PublishStatePublished, postID, nil)
+	}
+}
+
+// alreadyPublished reports whether err says the container was published by
+// an earlier request whose response was lost.
+func alreadyPublished(err error) bool {
+	var containerErr *ContainerError
+	return errors.As(err, &containerErr) && containerErr.Status == ContainerStatusPublished
+}
+
+// WaitForContainer polls a container until it has finished processing,
+// backing off between checks according to Config.ContainerPoll. It returns
+// a *ContainerError if processing failed, the container expired, or the
+// maximum number of attempts was reached, and ctx.Err() if ctx is done.
+func (c *Client) WaitForContainer(ctx context.Context, containerID ContainerID) (status *ContainerStatus, err error) {
+	var tel Telemetry = noopTelemetry{}
+	if c.config != nil {
+		tel = telemetryOrNoop(c.config.Telemetry)
+	}
+	ctx, span := tel.StartSpan(ctx, "threads.container.wait", Attr("threads.container_id", containerID.String()))
+	start := time.Now()
+	defer func() {
+		final := ""
+		if status != nil {
+			final = status.Status
+		}
+		span.SetAttributes(Attr("threads.container_status", final))
+		endSpan(span, err)
+		tel.Observe(ctx, MetricContainerWait, float64(time.Since(start).Milliseconds()), Attr("threads.container_status", final))
+	}()
+
+	return c.waitForContainer(ctx, containerID)
+}
+
+// waitForContainer implements WaitForContainer.
+func (c *Client) waitForContainer(ctx context.Context, containerID ContainerID) (*ContainerStatus, error) {
+	poll := DefaultContainerPollConfig()
+	if c.config != nil && c.config.ContainerPoll != nil {
+		poll = c.config.ContainerPoll
+	}
+
+	interval := poll.InitialInterval
+	var status *ContainerStatus
+	for attempt := 0; attempt < poll.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return status, ctx.Err()
+			case <-timer.C:
+			}
+			interval = poll.next(interval)
+		}
+
+		var err error
+		status, err = c.GetContainerStatus(ctx, containerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check container status: %w", err)
+		}
+
+		switch status.Status {
+		case ContainerStatusFinished:
+			return status, nil
+		case ContainerStatusPublished:
+			return status, NewContainerError(containerID.String(), status.Status,
+				"Container already published", "The container was already published and cannot be published again")
+		case ContainerStatusError:
+			details := "Container processing failed with error status"
+			if status.ErrorMessage != "" {
+				details = status.ErrorMessage
+			}
+			return status, NewContainerError(containerID.String(), status.Status, "Container processing failed", details)
+		case ContainerStatusExpired:
+			return status, NewContainerError(containerID.String(), status.Status,
+				"Container expired", "The container expired before it could be published")
+		}
+	}
+
+	last := ""
+	if status != nil {
+		last = status.Status
+	}
+	return status, NewContainerError(containerID.String(), last, "Timed out waiting for container",
+		fmt.Sprintf("Container was not ready after %d status checks", poll.MaxAttempts))
+}
+
+// PublishContainer waits for an existing container to finish processing and
+// publishes it. Use it to finish a publish that was interrupted after the
+// container was created, for example one found in the container journal.
+// The publish counts against the post quota, as the container's reply
+// target is not known. A container that turns out to be published already
+// is journaled as published and reported with ErrPublishedIDUnknown.
+func (c *Client) PublishContainer(ctx context.Context, containerID ContainerID) (*Post, error) {
+	if !containerID.Valid() {
+		return nil, NewValidationError(400, ErrEmptyContainerID, "Cannot publish without container ID", "container_id")
+	}
+
+	if err := c.EnsureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkQuota(QuotaPost); err != nil {
+		return nil, err
+	}
+
+	rec := &ContainerRecord{ContainerID: containerID.String(), UserID: c.getUserID()}
+	if _, err := c.WaitForContainer(ctx, containerID); err != nil {
+		if alreadyPublished(err) {
+			// An earlier publish went through before its result was recorded
+			c.markPublished(rec, "")
+			c.recordQuota(QuotaPost, "")
+			return nil, fmt.Errorf("container was already published: %w", ErrPublishedIDUnknown)
+		}
+		if IsContainerError(err) {
+			c.journal(rec, PublishStateFailed, "", err)
+		}
+		return nil, fmt.Errorf("container not ready for publishing: %w", err)
+	}
+
+	post, err := c.publishContainer(ctx, containerID.String())
+	if err != nil {
+		if alreadyPublished(err) {
+			c.markPublished(rec, "")
+			c.recordQuota(QuotaPost, "")
+			return nil, fmt.Errorf("container: %w", ErrPublishedIDUnknown)
+		}
+		return nil, fmt.Errorf("failed to publish container: %w", err)
+	}
+	c.markPublished(rec, post.ID)
+	c.recordQuota(QuotaPost, post.ID)
+
+	return post, nil
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryJournal struct {
+	mu      sync.Mutex
+	records []ContainerRecord
+}
+
+func (m *memoryJournal) Record(rec ContainerRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, rec)
+	return nil
+}
+
+func (m *memoryJournal) states() []PublishState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var states []PublishState
+	for _, r := range m.records {
+		states = append(states, r.State)
+	}
+	return states
+}
+
+// newPublishTestClient is createTestClient with a token that won't trigger a refresh.
+func newPublishTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+	client, server := createTestClient(t, handler)
+	if err := client.SetTokenInfo(&TokenInfo{
+		AccessToken: "test-access-token",
+		TokenType:   "Bearer",
+		ExpiresAt:   time.Now().Add(24 * time.Hour),
+		UserID:      "12345",
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to set token info: %v", err)
+	}
+	return client, server
+}
+
+func fastPoll(maxAttempts int) *ContainerPollConfig {
+	return &ContainerPollConfig{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     2 * time.Millisecond,
+		Multiplier:      2,
+		MaxAttempts:     maxAttempts,
+	}
+}
+
+func TestPublishFlow_RecordsStates(t *testing.T) {
+	polls := 0
+	client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/12345/threads":
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "c1"})
+		case "/c1":
+			polls++
+			status := ContainerStatusInProgress
+			if polls > 2 {
+				status = ContainerStatusFinished
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "c1", "status": status})
+		case "/12345/threads_publish":
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "p1"})
+		case "/p1":
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "p1"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	journal := &memoryJournal{}
+	client.config.ContainerJournal = journal
+	client.config.ContainerPoll = fastPoll(5)
+
+	post, err := client.CreateVideoPost(context.Background(), &VideoPostContent{VideoURL: "https://example.com/v.mp4"})
+	if err != nil {
+		t.Fatalf("CreateVideoPost failed: %v", err)
+	}
+	if post.ID != "p1" || polls != 3 {
+		t.Fatalf("expected post p1 after 3 polls, got %s after %d", post.ID, polls)
+	}
+
+	want := []PublishState{PublishStateCreated, PublishStateReady, PublishStatePublished}
+	got := journal.states()
+	if len(got) != len(want) {
+		t.Fatalf("expected states %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected states %v, got %v", want, got)
+		}
+	}
+	last := journal.records[len(journal.records)-1]
+	if last.ContainerID != "c1" || last.PostID != "p1" || last.Kind != MediaTypeVideo || last.UserID != "12345" {
+		t.Errorf("unexpected final record: %+v", last)
+	}
+}
+
+func TestPublishContainer_AlreadyPublished(t *testing.T) {
+	published := false
+	client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/c1":
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "c1", "status": ContainerStatusPublished})
+		case "/12345/threads_publish":
+			published = true
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "p1"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	journal := &memoryJournal{}
+	ledger := &memoryLedger{}
+	client.config.ContainerJournal = journal
+	client.config.QuotaLedger = ledger
+	client.config.ContainerPoll = fastPoll(3)
+
+	_, err := client.PublishContainer(context.Background(), ContainerID("c1"))
+	if !errors.Is(err, ErrPublishedIDUnknown) {
+		t.Fatalf("expected ErrPublishedIDUnknown, got %v", err)
+	}
+	if published {
+		t.Error("expected an already published container not to be published again")
+	}
+	if got := journal.states(); len(got) != 1 || got[0] != PublishStatePublished {
+		t.Errorf("expected the container to be journaled as published, got %v", got)
+	}
+	if len(ledger.recorded) != 1 || ledger.recorded[0] != QuotaPost {
+		t.Errorf("expected the publish to be recorded against the quota, got %v", ledger.recorded)
+	}
+}
+
+func TestWaitForContainer_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		wantStatus string
+	}{
+		{"error", ContainerStatusError, ContainerStatusError},
+		{"expired", ContainerStatusExpired, ContainerStatusExpired},
+		{"timeout", ContainerStatusInProgress, ContainerStatusInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]string{"id": "c1", "status": tt.status, "error_message": "bad media"})
+			})
+			defer server.Close()
+			client.config.ContainerPoll = fastPoll(3)
+
+			_, err := client.WaitForContainer(context.Background(), ContainerID("c1"))
+			var containerErr *ContainerError
+			if !errors.As(err, &containerErr) {
+				t.Fatalf("expected ContainerError, got %v", err)
+			}
+			if containerErr.Status != tt.wantStatus || containerErr.ContainerID != "c1" {
+				t.Errorf("unexpected error: %+v", containerErr)
+			}
+		})
+	}
+}
+
+func TestWaitForContainer_HonoursCancellation(t *testing.T) {
+	client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "c1", "status": ContainerStatusInProgress})
+	})
+	defer server.Close()
+	client.config.ContainerPoll = &ContainerPollConfig{
+		InitialInterval: time.Hour,
+		MaxInterval:     time.Hour,
+		Multiplier:      1,
+		MaxAttempts:     10,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.WaitForContainer(ctx, ContainerID("c1"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("WaitForContainer did not return promptly after cancellation")
+	}
+}
+
+func TestContainerPollConfig_Validate(t *testing.T) {
+	config := NewConfig()
+	config.ClientID = "id"
+	config.ClientSecret = "secret"
+	config.RedirectURI = "https://example.com/callback"
+	if err := config.Validate(); err != nil {
+		t.Fatalf("default config should be valid: %v", err)
+	}
+
+	config.ContainerPoll = &ContainerPollConfig{InitialInterval: time.Second, MaxInterval: time.Millisecond, Multiplier: 2, MaxAttempts: 1}
+	if err := config.Validate(); err == nil {
+		t.Error("expected error for MaxInterval < InitialInterval")
+	}
+}
+
+type memoryLedger struct {
+	refuse   map[QuotaAction]bool
+	recorded []QuotaAction
+}
+
+func (m *memoryLedger) Check(_ string, action QuotaAction) error {
+	if m.refuse[action] {
+		return NewQuotaError(action, 1, 1, time.Now().Add(time.Hour))
+	}
+	return nil
+}
+
+func (m *memoryLedger) Record(_ string, action QuotaAction, _ string) error {
+	m.recorded = append(m.recorded, action)
+	return nil
+}
+
+func TestPublishFlow_Quota(t *testing.T) {
+	created := 0
+	client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/12345/threads":
+			created++
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "c1"})
+		case "/c1":
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "c1", "status": ContainerStatusFinished})
+		case "/12345/threads_publish":
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "p1"})
+		case "/p1":
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "p1"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	ledger := &memoryLedger{refuse: map[QuotaAction]bool{QuotaReply: true}}
+	client.config.QuotaLedger = ledger
+	client.config.ContainerPoll = fastPoll(5)
+
+	if _, err := client.CreateTextPost(context.Background(), &TextPostContent{Text: "hello"}); err != nil {
+		t.Fatalf("CreateTextPost failed: %v", err)
+	}
+	if len(ledger.recorded) != 1 || ledger.recorded[0] != QuotaPost {
+		t.Errorf("expected one recorded post, got %v", ledger.recorded)
+	}
+
+	_, err := client.CreateTextPost(context.Background(), &TextPostContent{Text: "reply", ReplyTo: "p0"})
+	if !IsQuotaError(err) {
+		t.Fatalf("expected QuotaError for a reply, got %v", err)
+	}
+	if created != 1 || len(ledger.recorded) != 1 {
+		t.Errorf("refused reply must not create a container: created=%d recorded=%v", created, ledger.recorded)
+	}
+}
+
+func TestCreateTextPost_AutoPublishOutcomeUnknown(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		closed  bool
+		unknown bool
+	}{
+		{name: "server error after receiving the post", status: http.StatusGatewayTimeout, unknown: true},
+		{name: "rejected post", status: http.StatusBadRequest},
+		{name: "server unreachable", closed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newPublishTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"error":{"message":"failed","code":1}}`))
+			})
+			if tt.closed {
+				server.Close()
+			}
+
+			_, err := client.CreateTextPost(context.Background(), &TextPostContent{Text: "hello", AutoPublishText: true})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, ErrPublishOutcomeUnknown); got != tt.unknown {
+				t.Errorf("errors.Is(err, ErrPublishOutcomeUnknown) = %v, want %v (err: %v)", got, tt.unknown, err)
+			}
+		})
+	}
+}
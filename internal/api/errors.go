@@ -6,6 +6,19 @@ import (
 	"time"
 )
 
+// ErrPublishedIDUnknown is returned when a container turns out to have been
+// published already, for example by a request whose response was lost or by
+// a process that died before recording the result. The post exists but its
+// ID is not known, so callers should treat the publish as done rather than
+// publish the content again.
+var ErrPublishedIDUnknown = errors.New("post was published but its ID is unknown")
+
+// ErrPublishOutcomeUnknown is returned when a request that publishes
+// immediately, such as a text post created with auto_publish_text, fails
+// after it may have reached Threads: it timed out, broke off, or ended with
+// a 5xx. The post may exist, so callers must not retry it automatically.
+var ErrPublishOutcomeUnknown = errors.New("post may have been published")
+
 // BaseError represents a base error type for all Threads API errors.
 // For error handling patterns, see: https://developers.facebook.com/docs/threads/troubleshooting
 type BaseError struct {
@@ -97,6 +110,14 @@ func NewValidationError(code int, message, details, field string) *ValidationErr
 type NetworkError struct {
 	*BaseError
 	Temporary bool `json:"temporary"`
+
+	// err is the transport error this one was made from, if any.
+	err error
+}
+
+// Unwrap returns the transport error behind e, if any.
+func (e *NetworkError) Unwrap() error {
+	return e.err
 }
 
 // NewNetworkError creates a new network error with temporary status.
@@ -120,6 +141,10 @@ func NewNetworkError(code int, message, details string, temporary bool) *Network
 type APIError struct {
 	*BaseError
 	RequestID string `json:"request_id,omitempty"`
+
+	// StatusCode is the HTTP status of the response, when the error came
+	// from one. Code may instead hold the API's own error code.
+	StatusCode int `json:"status_code,omitempty"`
 }
 
 // NewAPIError creates a new API error with optional request ID.
@@ -137,6 +162,56 @@ func NewAPIError(code int, message, details, requestID string) *APIError {
 	}
 }
 
+// ContainerError reports a media container that cannot be published because
+// processing failed, the container expired, or polling gave up waiting.
+// Status holds the last container status seen (ERROR, EXPIRED, IN_PROGRESS).
+type ContainerError struct {
+	*BaseError
+	ContainerID string `json:"container_id"`
+	Status      string `json:"status"`
+}
+
+// NewContainerError creates a new container error for the given container and status.
+func NewContainerError(containerID, status, message, details string) *ContainerError {
+	return &ContainerError{
+		BaseError: &BaseError{
+			Code:    400,
+			Message: message,
+			Type:    "container_error",
+			Details: details,
+		},
+		ContainerID: containerID,
+		Status:      status,
+	}
+}
+
+// QuotaError reports an action refused before it was sent because the
+// account has used its publishing quota for the rolling window. RetryAt is
+// the earliest time the action is expected to fit within the quota again.
+type QuotaError struct {
+	*BaseError
+	Action  QuotaAction `json:"action"`
+	Used    int         `json:"used"`
+	Limit   int         `json:"limit"`
+	RetryAt time.Time   `json:"retry_at"`
+}
+
+// NewQuotaError creates a new quota error for action.
+func NewQuotaError(action QuotaAction, used, limit int, retryAt time.Time) *QuotaError {
+	return &QuotaError{
+		BaseError: &BaseError{
+			Code:    429,
+			Message: fmt.Sprintf("%s quota exceeded", action),
+			Type:    "quota_error",
+			Details: fmt.Sprintf("%d of %d %ss used in the rolling window", used, limit, action),
+		},
+		Action:  action,
+		Used:    used,
+		Limit:   limit,
+		RetryAt: retryAt,
+	}
+}
+
 // IsAuthenticationError checks if an error is an authentication error.
 // This is useful for implementing retry logic or handling authentication failures.
 // Returns true if the error is of type *AuthenticationError.
@@ -181,3 +256,19 @@ func IsAPIError(err error) bool {
 	ok := errors.As(err, &APIError)
 	return ok
 }
+
+// IsContainerError checks if an error is a container processing error.
+// Returns true if the error is of type *ContainerError.
+func IsContainerError(err error) bool {
+	var containerError *ContainerError
+	ok := errors.As(err, &containerError)
+	return ok
+}
+
+// IsQuotaError checks if an error is a local publishing quota error.
+// Returns true if the error is of type *QuotaError.
+func IsQuotaError(err error) bool {
+	var quotaError *QuotaError
+	ok := errors.As(err, &quotaError)
+	return ok
+}
@@ -9,8 +9,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,6 +20,12 @@ type HTTPClient struct {
 	logger      Logger
 	retryConfig *RetryConfig
 	rateLimiter *RateLimiter
+	throttle    *Throttle
+	cache       *CacheConfig
+	telemetry   Telemetry
+	timeout     time.Duration
+	transport   http.RoundTripper
+	middlewares []Middleware
 	baseURL     string
 	userAgent   string
 }
@@ -32,6 +38,14 @@ type RequestOptions struct {
 	Body        interface{}
 	Headers     map[string]string
 	Context     context.Context
+
+	// Cache marks a GET request whose response may be cached
+	Cache CacheEndpoint
+
+	// Idempotent marks a request that is safe to repeat regardless of its
+	// method, such as a batch of GETs sent as a POST, so it is retried like
+	// a GET
+	Idempotent bool
 }
 
 // Response wraps HTTP response with additional metadata
@@ -42,6 +56,7 @@ type Response struct {
 	RateLimit  *RateLimitInfo
 	Duration   time.Duration
 	StatusCode int
+	Cached     bool // served from the response cache
 }
 
 // RateLimitInfo contains rate limiting information from response headers
@@ -54,10 +69,6 @@ type RateLimitInfo struct {
 
 // NewHTTPClient creates a new HTTP client with the provided configuration
 func NewHTTPClient(config *Config, rateLimiter *RateLimiter) *HTTPClient {
-	httpClient := &http.Client{
-		Timeout: config.HTTPTimeout,
-	}
-
 	baseURL := config.BaseURL
 	if baseURL == "" {
 		baseURL = "https://graph.threads.net"
@@ -68,82 +79,124 @@ func NewHTTPClient(config *Config, rateLimiter *RateLimiter) *HTTPClient {
 		userAgent = DefaultUserAgent
 	}
 
-	return &HTTPClient{
-		client:      httpClient,
+	transport := config.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	h := &HTTPClient{
+		client:      &http.Client{},
 		logger:      config.Logger,
 		retryConfig: config.RetryConfig,
 		rateLimiter: rateLimiter,
+		throttle:    NewThrottle(config.Throttle),
+		cache:       config.Cache,
+		telemetry:   config.Telemetry,
+		timeout:     config.HTTPTimeout,
+		transport:   transport,
+		middlewares: config.Middlewares,
 		baseURL:     baseURL,
 		userAgent:   userAgent,
 	}
+	h.client.Transport = h.chain(transport)
+	return h
+}
+
+// chain wraps base in the client's middlewares. Retries are outermost, so
+// every attempt is traced, throttled, timed out and passed through the
+// configured middlewares on its own; logging is innermost, so it records
+// what is actually sent.
+func (h *HTTPClient) chain(base http.RoundTripper) http.RoundTripper {
+	middlewares := []Middleware{
+		RetryMiddleware(h.retryConfig, h.logger),
+		h.telemetryMiddleware(),
+		h.throttleMiddleware(),
+		timeoutMiddleware(h.timeout),
+		RateLimitMiddleware(h.rateLimiter),
+	}
+	middlewares = append(middlewares, h.middlewares...)
+	middlewares = append(middlewares, LoggingMiddleware(h.logger))
+	return Chain(base, middlewares...)
 }
 
 // Do executes an HTTP request with retry logic and error handling
-func (h *HTTPClient) Do(opts *RequestOptions, accessToken string) (*Response, error) {
+func (h *HTTPClient) Do(opts *RequestOptions, accessToken string) (_ *Response, err error) {
 	if opts.Context == nil {
 		opts.Context = context.Background()
 	}
 
-	// Only wait for rate limiter if we've been explicitly rate limited by the API
-	if h.rateLimiter != nil && h.rateLimiter.ShouldWait() {
-		if err := h.rateLimiter.Wait(opts.Context); err != nil {
-			return nil, fmt.Errorf("rate limiter wait failed: %w", err)
+	tel := telemetryOrNoop(h.telemetry)
+	endpoint := Attr("threads.endpoint", endpointName(opts.Path))
+	method := Attr("http.request.method", opts.Method)
+	ctx, span := tel.StartSpan(opts.Context, "threads.api.request", endpoint, method)
+	start := time.Now()
+	var attempts atomic.Int32
+	var last *Response
+	defer func() {
+		status := Attr("http.response.status_code", 0)
+		if last != nil {
+			status.Value = last.StatusCode
+			span.SetAttributes(
+				Attr("threads.request_id", last.RequestID),
+				Attr("threads.cached", last.Cached),
+			)
 		}
-	}
-
-	var lastErr error
-	maxRetries := h.retryConfig.MaxRetries
-	delay := h.retryConfig.InitialDelay
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// Wait before retry
-			select {
-			case <-opts.Context.Done():
-				return nil, opts.Context.Err()
-			case <-time.After(delay):
-			}
-
-			// Exponential backoff
-			delay = time.Duration(float64(delay) * h.retryConfig.BackoffFactor)
-			if delay > h.retryConfig.MaxDelay {
-				delay = h.retryConfig.MaxDelay
-			}
+		retries := max(int(attempts.Load())-1, 0)
+		if retries > 0 {
+			tel.Count(ctx, MetricRetries, int64(retries), endpoint, method)
 		}
+		span.SetAttributes(status, Attr("threads.retries", retries))
+		endSpan(span, err)
 
-		resp, err := h.executeRequest(opts, accessToken)
-		if err != nil {
-			lastErr = err
+		tel.Count(ctx, MetricRequests, 1, endpoint, method, status)
+		tel.Observe(ctx, MetricRequestDuration, float64(time.Since(start).Milliseconds()), endpoint, method, status)
+	}()
+	opts.Context = withAttemptCounter(ctx, &attempts)
 
-			// Check if error is retry-able
-			if !h.isRetryableError(err) {
-				return nil, err
-			}
-
-			h.logRetry(attempt, maxRetries, err)
-			continue
+	// Only wait for rate limiter if we've been explicitly rate limited by the API
+	if h.rateLimiter != nil && h.rateLimiter.ShouldWait() {
+		if err := h.waitForRateLimit(opts.Context); err != nil {
+			return nil, fmt.Errorf("rate limiter wait failed: %w", err)
 		}
+	}
 
-		// Update rate limiter with response headers
-		if h.rateLimiter != nil && resp.RateLimit != nil {
-			h.rateLimiter.UpdateFromHeaders(resp.RateLimit)
-		}
+	// Serve fresh cached responses without touching the API
+	if last = h.freshCachedResponse(opts); last != nil {
+		h.logCacheHit(opts)
+		return last, nil
+	}
+	cached := h.cacheLookup(opts)
 
-		// Check if we should retry based on status code
-		if h.shouldRetryStatus(resp.StatusCode) {
-			lastErr = h.createErrorFromResponse(resp)
-			h.logRetry(attempt, maxRetries, lastErr)
-			continue
+	// Retries, throttling and rate limit tracking happen in the transport
+	last, err = h.executeRequest(opts, accessToken, cached)
+	if err != nil {
+		if retries := attempts.Load() - 1; retries > 0 && isRetryableError(err) {
+			return nil, fmt.Errorf("request failed after %d retries: %w", retries, err)
 		}
-
-		return resp, nil
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("request failed after %d retries: %w", maxRetries, lastErr)
+	h.invalidateCache(opts)
+	return last, nil
+}
+
+// waitForRateLimit waits until the rate limit reported by the API resets.
+func (h *HTTPClient) waitForRateLimit(ctx context.Context) (err error) {
+	tel := telemetryOrNoop(h.telemetry)
+	ctx, span := tel.StartSpan(ctx, "threads.rate_limit.wait")
+	start := time.Now()
+	defer func() {
+		endSpan(span, err)
+		tel.Observe(ctx, MetricRateLimitWait, float64(time.Since(start).Milliseconds()), Attr("threads.limiter", "api"))
+	}()
+
+	return h.rateLimiter.Wait(ctx)
 }
 
-// executeRequest performs a single HTTP request
-func (h *HTTPClient) executeRequest(opts *RequestOptions, accessToken string) (*Response, error) {
+// executeRequest sends a request through the middleware chain. When cached holds a stale
+// response with validators, the request is made conditional and a 304 Not
+// Modified is answered from the cache.
+func (h *HTTPClient) executeRequest(opts *RequestOptions, accessToken string, cached *CachedResponse) (*Response, error) {
 	startTime := time.Now()
 
 	// Build URL
@@ -179,7 +232,11 @@ func (h *HTTPClient) executeRequest(opts *RequestOptions, accessToken string) (*
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(opts.Context, opts.Method, fullURL, bodyReader)
+	ctx := opts.Context
+	if opts.Idempotent {
+		ctx = context.WithValue(ctx, idempotentKey{}, true)
+	}
+	req, err := http.NewRequestWithContext(ctx, opts.Method, fullURL, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -198,13 +255,20 @@ func (h *HTTPClient) executeRequest(opts *RequestOptions, accessToken string) (*
 		req.Header.Set(key, value)
 	}
 
-	// Log request
-	h.logRequest(req, opts.Body)
+	// Revalidate stale cache entries instead of downloading them again
+	if cached != nil {
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.Header.Get("Last-Modified"); modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
 
 	// Execute request
 	httpResp, err := h.client.Do(req)
 	if err != nil {
-		return nil, h.wrapNetworkError(err)
+		return nil, wrapNetworkError(err)
 	}
 	defer func(Body io.ReadCloser) {
 		errClose := Body.Close()
@@ -226,54 +290,26 @@ func (h *HTTPClient) executeRequest(opts *RequestOptions, accessToken string) (*
 		RequestID:  httpResp.Header.Get("X-Fb-Request-Id"),
 		StatusCode: httpResp.StatusCode,
 		Duration:   time.Since(startTime),
-		RateLimit:  h.parseRateLimitHeaders(httpResp.Header),
+		RateLimit:  ParseRateLimitHeaders(httpResp.Header),
 	}
 
-	// Log response
-	h.logResponse(resp)
-
 	// Check for HTTP errors
 	if httpResp.StatusCode >= 400 {
 		return resp, h.createErrorFromResponse(resp)
 	}
 
-	return resp, nil
-}
-
-// parseRateLimitHeaders extracts rate limit information from response headers
-func (h *HTTPClient) parseRateLimitHeaders(headers http.Header) *RateLimitInfo {
-	rateLimitInfo := &RateLimitInfo{}
-
-	if limitStr := headers.Get("X-RateLimit-Limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil {
-			rateLimitInfo.Limit = limit
-		}
-	}
-
-	if remainingStr := headers.Get("X-RateLimit-Remaining"); remainingStr != "" {
-		if remaining, err := strconv.Atoi(remainingStr); err == nil {
-			rateLimitInfo.Remaining = remaining
-		}
-	}
-
-	if resetStr := headers.Get("X-RateLimit-Reset"); resetStr != "" {
-		if resetTime, err := strconv.ParseInt(resetStr, 10, 64); err == nil {
-			rateLimitInfo.Reset = time.Unix(resetTime, 0)
-		}
-	}
-
-	if retryAfterStr := headers.Get("Retry-After"); retryAfterStr != "" {
-		if retryAfter, err := strconv.Atoi(retryAfterStr); err == nil {
-			rateLimitInfo.RetryAfter = time.Duration(retryAfter) * time.Second
-		}
+	if httpResp.StatusCode == http.StatusNotModified && cached != nil {
+		h.storeResponse(opts, resp, cached)
+		notModified := cachedResponse(cached)
+		notModified.Duration = resp.Duration
+		notModified.RateLimit = resp.RateLimit
+		return notModified, nil
 	}
-
-	// Return nil if no rate limit headers found
-	if rateLimitInfo.Limit == 0 && rateLimitInfo.Remaining == 0 && rateLimitInfo.Reset.IsZero() {
-		return nil
+	if httpResp.StatusCode >= 200 && httpResp.StatusCode < 300 {
+		h.storeResponse(opts, resp, nil)
 	}
 
-	return rateLimitInfo
+	return resp, nil
 }
 
 // createErrorFromResponse creates appropriate error types based on HTTP response
@@ -311,54 +347,45 @@ func (h *HTTPClient) createErrorFromResponse(resp *Response) error {
 	case 403:
 		return NewAuthenticationError(errorCode, message, details)
 	case 429:
+		// RateLimitMiddleware has already marked the rate limiter
 		retryAfter := time.Duration(0)
-		resetTime := time.Time{}
-		if resp.RateLimit != nil {
-			if resp.RateLimit.RetryAfter > 0 {
-				retryAfter = resp.RateLimit.RetryAfter
-			}
-			if !resp.RateLimit.Reset.IsZero() {
-				resetTime = resp.RateLimit.Reset
-			}
-		}
-
-		// Mark the rate limiter as rate limited by the API
-		if h.rateLimiter != nil {
-			if resetTime.IsZero() {
-				// If no reset time provided, estimate based on retry after
-				resetTime = time.Now().Add(retryAfter)
-			}
-			h.rateLimiter.MarkRateLimited(resetTime)
+		if resp.RateLimit != nil && resp.RateLimit.RetryAfter > 0 {
+			retryAfter = resp.RateLimit.RetryAfter
 		}
 
 		return NewRateLimitError(errorCode, message, details, retryAfter)
 	case 400, 422:
 		return NewValidationError(errorCode, message, details, "")
-	case 500, 502, 503, 504:
-		return NewAPIError(errorCode, message, details, resp.RequestID)
 	default:
-		return NewAPIError(errorCode, message, details, resp.RequestID)
+		apiErr := NewAPIError(errorCode, message, details, resp.RequestID)
+		apiErr.StatusCode = resp.StatusCode
+		return apiErr
 	}
 }
 
-// wrapNetworkError wraps network errors with appropriate error types
-func (h *HTTPClient) wrapNetworkError(err error) error {
+// wrapNetworkError wraps network errors with appropriate error types. The
+// result unwraps to err, so callers can still tell how the request failed.
+func wrapNetworkError(err error) *NetworkError {
+	netErr := NewNetworkError(0, "Network error", err.Error(), false)
+	netErr.err = err
+
 	// Check for timeout errors
 	if timeoutErr, ok := err.(interface{ Timeout() bool }); ok && timeoutErr.Timeout() {
-		return NewNetworkError(0, "Request timeout", err.Error(), true)
+		netErr.Message, netErr.Temporary = "Request timeout", true
+		return netErr
 	}
 
 	// Check for temporary errors
 	if tempErr, ok := err.(interface{ Temporary() bool }); ok && tempErr.Temporary() {
-		return NewNetworkError(0, "Temporary network error", err.Error(), true)
+		netErr.Message, netErr.Temporary = "Temporary network error", true
 	}
 
-	// Default to permanent network error
-	return NewNetworkError(0, "Network error", err.Error(), false)
+	// Otherwise it is a permanent network error
+	return netErr
 }
 
 // isRetryableError determines if an error should trigger a retry
-func (h *HTTPClient) isRetryableError(err error) bool {
+func isRetryableError(err error) bool {
 	// Rate limit errors are retry-able
 	if IsRateLimitError(err) {
 		return true
@@ -379,84 +406,20 @@ func (h *HTTPClient) isRetryableError(err error) bool {
 	return false
 }
 
-// shouldRetryStatus determines if a status code should trigger a retry
-func (h *HTTPClient) shouldRetryStatus(statusCode int) bool {
-	switch statusCode {
-	case 429: // Too Many Requests
-		return true
-	case 500, 502, 503, 504: // Server errors
-		return true
-	default:
-		return false
-	}
-}
-
-// logRequest logs the outgoing HTTP request
-func (h *HTTPClient) logRequest(req *http.Request, body interface{}) {
-	if h.logger == nil {
-		return
-	}
-
-	fields := []interface{}{
-		"method", req.Method,
-		"url", req.URL.String(),
-		"headers", h.sanitizeHeaders(req.Header),
-	}
-
-	if body != nil {
-		// Don't log sensitive data
-		if req.Header.Get("Content-Type") == "application/json" {
-			fields = append(fields, "body_type", "json")
-		} else {
-			fields = append(fields, "body_type", fmt.Sprintf("%T", body))
-		}
-	}
-
-	h.logger.Debug("HTTP request", fields...)
-}
-
-// logResponse logs the HTTP response
-func (h *HTTPClient) logResponse(resp *Response) {
+// logCacheHit logs a response served from the cache
+func (h *HTTPClient) logCacheHit(opts *RequestOptions) {
 	if h.logger == nil {
 		return
 	}
 
-	fields := []interface{}{
-		"status_code", resp.StatusCode,
-		"duration_ms", resp.Duration.Milliseconds(),
-		"request_id", resp.RequestID,
-	}
-
-	if resp.RateLimit != nil {
-		fields = append(fields,
-			"rate_limit_remaining", resp.RateLimit.Remaining,
-			"rate_limit_limit", resp.RateLimit.Limit,
-		)
-	}
-
-	if resp.StatusCode >= 400 {
-		fields = append(fields, "response_body", string(resp.Body))
-		h.logger.Error("HTTP response error", fields...)
-	} else {
-		h.logger.Debug("HTTP response", fields...)
-	}
-}
-
-// logRetry logs retry attempts
-func (h *HTTPClient) logRetry(attempt, maxRetries int, err error) {
-	if h.logger == nil {
-		return
-	}
-
-	h.logger.Warn("HTTP request retry",
-		"attempt", attempt+1,
-		"max_retries", maxRetries+1,
-		"error", err.Error(),
+	h.logger.Debug("Response cache hit",
+		"path", opts.Path,
+		"endpoint", string(opts.Cache),
 	)
 }
 
 // sanitizeHeaders removes sensitive headers from logging
-func (h *HTTPClient) sanitizeHeaders(headers http.Header) map[string]string {
+func sanitizeHeaders(headers http.Header) map[string]string {
 	sanitized := make(map[string]string)
 	for key, values := range headers {
 		if strings.ToLower(key) == "authorization" {
@@ -469,36 +432,52 @@ func (h *HTTPClient) sanitizeHeaders(headers http.Header) map[string]string {
 }
 
 // GET performs a GET request
-func (h *HTTPClient) GET(path string, queryParams url.Values, accessToken string) (*Response, error) {
+func (h *HTTPClient) GET(ctx context.Context, path string, queryParams url.Values, accessToken string) (*Response, error) {
+	return h.Do(&RequestOptions{
+		Method:      "GET",
+		Path:        path,
+		QueryParams: queryParams,
+		Context:     ctx,
+	}, accessToken)
+}
+
+// GETCached performs a GET request whose response may be served from, and
+// stored in, the response cache under endpoint's TTL
+func (h *HTTPClient) GETCached(ctx context.Context, endpoint CacheEndpoint, path string, queryParams url.Values, accessToken string) (*Response, error) {
 	return h.Do(&RequestOptions{
 		Method:      "GET",
 		Path:        path,
 		QueryParams: queryParams,
+		Cache:       endpoint,
+		Context:     ctx,
 	}, accessToken)
 }
 
 // POST performs a POST request
-func (h *HTTPClient) POST(path string, body interface{}, accessToken string) (*Response, error) {
+func (h *HTTPClient) POST(ctx context.Context, path string, body interface{}, accessToken string) (*Response, error) {
 	return h.Do(&RequestOptions{
-		Method: "POST",
-		Path:   path,
-		Body:   body,
+		Method:  "POST",
+		Path:    path,
+		Body:    body,
+		Context: ctx,
 	}, accessToken)
 }
 
 // PUT performs a PUT request
-func (h *HTTPClient) PUT(path string, body interface{}, accessToken string) (*Response, error) {
+func (h *HTTPClient) PUT(ctx context.Context, path string, body interface{}, accessToken string) (*Response, error) {
 	return h.Do(&RequestOptions{
-		Method: "PUT",
-		Path:   path,
-		Body:   body,
+		Method:  "PUT",
+		Path:    path,
+		Body:    body,
+		Context: ctx,
 	}, accessToken)
 }
 
 // DELETE performs a DELETE request
-func (h *HTTPClient) DELETE(path string, accessToken string) (*Response, error) {
+func (h *HTTPClient) DELETE(ctx context.Context, path string, accessToken string) (*Response, error) {
 	return h.Do(&RequestOptions{
-		Method: "DELETE",
-		Path:   path,
+		Method:  "DELETE",
+		Path:    path,
+		Context: ctx,
 	}, accessToken)
 }
@@ -107,30 +107,16 @@ func (c *Client) GetPostInsights(ctx context.Context, postID PostID, metrics []s
 		return nil, NewValidationError(400, ErrEmptyPostID, "postID cannot be empty", "postID")
 	}
 
-	// Validate metrics
-	validMetrics := make([]string, 0, len(metrics))
-	for _, metric := range metrics {
-		if err := c.validatePostInsightMetric(metric); err != nil {
-			return nil, err
-		}
-		validMetrics = append(validMetrics, metric)
-	}
-
-	// If no metrics specified, use default metrics
-	if len(validMetrics) == 0 {
-		validMetrics = []string{
-			string(PostInsightViews),
-			string(PostInsightLikes),
-			string(PostInsightReplies),
-			string(PostInsightReposts),
-		}
+	validMetrics, err := c.postInsightMetrics(metrics)
+	if err != nil {
+		return nil, err
 	}
 
 	params := url.Values{}
 	params.Set("metric", strings.Join(validMetrics, ","))
 
 	path := fmt.Sprintf("/%s/insights", postID.String())
-	response, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	response, err := c.httpClient.GETCached(ctx, CacheInsights, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post insights: %w", err)
 	}
@@ -144,6 +130,29 @@ func (c *Client) GetPostInsights(ctx context.Context, postID PostID, metrics []s
 	return &insightsResponse, nil
 }
 
+// postInsightMetrics validates metrics for a post insights request, and
+// returns the default metrics if there are none.
+func (c *Client) postInsightMetrics(metrics []string) ([]string, error) {
+	validMetrics := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		if err := c.validatePostInsightMetric(metric); err != nil {
+			return nil, err
+		}
+		validMetrics = append(validMetrics, metric)
+	}
+
+	// If no metrics specified, use default metrics
+	if len(validMetrics) == 0 {
+		validMetrics = []string{
+			string(PostInsightViews),
+			string(PostInsightLikes),
+			string(PostInsightReplies),
+			string(PostInsightReposts),
+		}
+	}
+	return validMetrics, nil
+}
+
 // GetPostInsightsWithOptions retrieves insights for a specific post with advanced options
 func (c *Client) GetPostInsightsWithOptions(ctx context.Context, postID PostID, opts *PostInsightsOptions) (*InsightsResponse, error) {
 	if !postID.Valid() {
@@ -200,7 +209,7 @@ func (c *Client) GetPostInsightsWithOptions(ctx context.Context, postID PostID,
 	}
 
 	path := fmt.Sprintf("/%s/insights", postID.String())
-	response, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	response, err := c.httpClient.GETCached(ctx, CacheInsights, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post insights: %w", err)
 	}
@@ -254,7 +263,7 @@ func (c *Client) GetAccountInsights(ctx context.Context, userID UserID, metrics
 	}
 
 	path := fmt.Sprintf("/%s/threads_insights", userID.String())
-	response, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	response, err := c.httpClient.GETCached(ctx, CacheInsights, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account insights: %w", err)
 	}
@@ -374,7 +383,7 @@ func (c *Client) GetAccountInsightsWithOptions(ctx context.Context, userID UserI
 	}
 
 	path := fmt.Sprintf("/%s/threads_insights", userID.String())
-	response, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	response, err := c.httpClient.GETCached(ctx, CacheInsights, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account insights: %w", err)
 	}
@@ -20,10 +20,10 @@ type ClientInterface interface {
 // Authenticator handles OAuth 2.0 authentication and token management
 type Authenticator interface {
 	// GetAuthURL generates an authorization URL for the OAuth 2.0 flow
-	GetAuthURL(scopes []string) string
+	GetAuthURL(scopes []string, opts ...AuthOption) string
 
 	// ExchangeCodeForToken exchanges an authorization code for an access token
-	ExchangeCodeForToken(ctx context.Context, code string) error
+	ExchangeCodeForToken(ctx context.Context, code string, opts ...AuthOption) error
 
 	// GetLongLivedToken converts a short-lived token to a long-lived token
 	GetLongLivedToken(ctx context.Context) error
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Middleware wraps the transport that sends the client's requests. It can
+// inspect or modify each request before calling next, and each response
+// after it returns.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts an ordinary function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps base in middlewares. The first middleware is the outermost,
+// so it sees each request first and each response last. A nil base uses
+// http.DefaultTransport.
+func Chain(base http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			base = middlewares[i](base)
+		}
+	}
+	return base
+}
+
+// LoggingMiddleware logs every request and response sent through it, with
+// the Authorization header and token parameters redacted. Error responses are logged with their
+// body. A nil logger disables logging.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if logger == nil {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			fields := []any{
+				"method", req.Method,
+				"url", redactURL(req.URL),
+				"headers", sanitizeHeaders(req.Header),
+			}
+			if req.Body != nil && req.Body != http.NoBody {
+				fields = append(fields, "body_type", bodyType(req.Header.Get("Content-Type")))
+			}
+			logger.Debug("HTTP request", fields...)
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				logger.Debug("HTTP request failed", "url", redactURL(req.URL), "error", err.Error())
+				return nil, err
+			}
+
+			fields = []any{
+				"status_code", resp.StatusCode,
+				"duration_ms", time.Since(start).Milliseconds(),
+				"request_id", resp.Header.Get("X-Fb-Request-Id"),
+			}
+			if info := ParseRateLimitHeaders(resp.Header); info != nil {
+				fields = append(fields,
+					"rate_limit_remaining", info.Remaining,
+					"rate_limit_limit", info.Limit,
+				)
+			}
+
+			if resp.StatusCode < 400 {
+				logger.Debug("HTTP response", fields...)
+				return resp, nil
+			}
+
+			// Error bodies are small; read and restore them so they can be logged
+			body, errRead := io.ReadAll(resp.Body)
+			resp.Body.Close() //nolint:errcheck,gosec // Replaced below
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			if errRead != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", errRead)
+			}
+			fields = append(fields, "response_body", string(body))
+			logger.Error("HTTP response error", fields...)
+			return resp, nil
+		})
+	}
+}
+
+// RetryMiddleware resends failed requests as policy allows, waiting
+// between attempts for the delay it chooses or the server's Retry-After.
+// The last response or error is returned once the policy gives up.
+// Requests whose body cannot be replayed are sent once.
+func RetryMiddleware(policy *RetryConfig, logger Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if policy == nil {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			for attempt := 1; ; attempt++ {
+				resp, err := next.RoundTrip(req)
+				if !policy.ShouldRetry(req, resp, err, attempt) {
+					return resp, err
+				}
+
+				retryReq, errBody := rewindRequest(req)
+				if errBody != nil {
+					return resp, err
+				}
+
+				var wait time.Duration
+				if resp != nil {
+					wait = retryAfter(resp.Header)
+				}
+				delay := policy.Delay(attempt, wait)
+
+				if logger != nil {
+					var reason string
+					if err != nil {
+						reason = err.Error()
+					} else {
+						reason = fmt.Sprintf("HTTP %d", resp.StatusCode)
+					}
+					logger.Warn("HTTP request retry",
+						"attempt", attempt,
+						"max_retries", policy.MaxRetries+1,
+						"delay", delay.String(),
+						"error", reason,
+					)
+				}
+				if resp != nil {
+					io.Copy(io.Discard, resp.Body) //nolint:errcheck,gosec // Draining so the connection can be reused
+					resp.Body.Close()              //nolint:errcheck,gosec // The response is discarded
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
+				req = retryReq
+			}
+		})
+	}
+}
+
+// RateLimitMiddleware feeds the rate limit headers of every response to
+// limiter, and marks it rate limited when the API answers 429 Too Many
+// Requests so later requests wait for the reset. A nil limiter disables it.
+func RateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if limiter == nil {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+
+			info := ParseRateLimitHeaders(resp.Header)
+			limiter.UpdateFromHeaders(info)
+
+			if resp.StatusCode == http.StatusTooManyRequests {
+				var resetTime time.Time
+				if info != nil {
+					resetTime = info.Reset
+				}
+				if resetTime.IsZero() {
+					// If no reset time provided, estimate based on retry after
+					resetTime = time.Now().Add(retryAfter(resp.Header))
+				}
+				limiter.MarkRateLimited(resetTime)
+			}
+			return resp, nil
+		})
+	}
+}
+
+// throttleMiddleware spaces out requests per endpoint class before they are
+// sent, so bulk jobs stay below the limits. The throttle is looked up per
+// request, so it can be replaced after the client is built.
+func (h *HTTPClient) throttleMiddleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if h.throttle == nil {
+				return next.RoundTrip(req)
+			}
+
+			class := classifyRequest(req.Method, req.URL.Path)
+			tel := telemetryOrNoop(h.telemetry)
+			ctx, span := tel.StartSpan(req.Context(), "threads.throttle.wait", Attr("threads.class", string(class)))
+			waited, err := h.throttle.Wait(ctx, class)
+			span.SetAttributes(Attr("threads.wait_ms", waited.Milliseconds()))
+			endSpan(span, err)
+			if err != nil {
+				return nil, fmt.Errorf("throttle wait failed: %w", err)
+			}
+			if waited > 0 {
+				tel.Observe(ctx, MetricRateLimitWait, float64(waited.Milliseconds()),
+					Attr("threads.limiter", "throttle"), Attr("threads.class", string(class)))
+				if h.logger != nil {
+					h.logger.Debug("Request throttled",
+						"class", string(class),
+						"wait_duration", waited.String(),
+					)
+				}
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// timeoutMiddleware bounds each attempt, including reading the response
+// body, by timeout. Retry backoff and throttle waits are not counted.
+func timeoutMiddleware(timeout time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if timeout <= 0 {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			resp, err := next.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		})
+	}
+}
+
+// cancelOnClose releases a request's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// ParseRateLimitHeaders extracts rate limit information from response
+// headers. It returns nil when the response carries no rate limit headers.
+func ParseRateLimitHeaders(headers http.Header) *RateLimitInfo {
+	rateLimitInfo := &RateLimitInfo{}
+
+	if limitStr := headers.Get("X-RateLimit-Limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil {
+			rateLimitInfo.Limit = limit
+		}
+	}
+
+	if remainingStr := headers.Get("X-RateLimit-Remaining"); remainingStr != "" {
+		if remaining, err := strconv.Atoi(remainingStr); err == nil {
+			rateLimitInfo.Remaining = remaining
+		}
+	}
+
+	if resetStr := headers.Get("X-RateLimit-Reset"); resetStr != "" {
+		if resetTime, err := strconv.ParseInt(resetStr, 10, 64); err == nil {
+			rateLimitInfo.Reset = time.Unix(resetTime, 0)
+		}
+	}
+
+	if retryAfterStr := headers.Get("Retry-After"); retryAfterStr != "" {
+		if retryAfter, err := strconv.Atoi(retryAfterStr); err == nil {
+			rateLimitInfo.RetryAfter = time.Duration(retryAfter) * time.Second
+		}
+	}
+
+	// Return nil if no rate limit headers found
+	if rateLimitInfo.Limit == 0 && rateLimitInfo.Remaining == 0 && rateLimitInfo.Reset.IsZero() {
+		return nil
+	}
+
+	return rateLimitInfo
+}
+
+// rewindRequest returns a copy of req with a fresh body, so it can be sent
+// again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body cannot be replayed")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
+	return retryReq, nil
+}
+
+// redactURL renders u for logging with tokens and secrets in its query
+// replaced.
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	redactedURL := *u
+	redactedURL.RawQuery = scrubValues(u.Query()).Encode()
+	return redactedURL.String()
+}
+
+// bodyType describes a request body for logging without its content.
+func bodyType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "application/json"):
+		return "json"
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		return "form"
+	case contentType == "":
+		return "unknown"
+	default:
+		return contentType
+	}
+}
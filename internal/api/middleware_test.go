@@ -0,0 +1,271 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingLogger keeps the messages and fields it is given.
+type recordingLogger struct {
+	entries []string
+}
+
+func (l *recordingLogger) record(msg string, fields ...any) {
+	l.entries = append(l.entries, msg+" "+fmt.Sprint(fields...))
+}
+
+func (l *recordingLogger) Debug(msg string, fields ...any) { l.record(msg, fields...) }
+func (l *recordingLogger) Info(msg string, fields ...any)  { l.record(msg, fields...) }
+func (l *recordingLogger) Warn(msg string, fields ...any)  { l.record(msg, fields...) }
+func (l *recordingLogger) Error(msg string, fields ...any) { l.record(msg, fields...) }
+
+func newMiddlewareTestClient(t *testing.T, handler http.HandlerFunc, configure func(*Config)) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := NewConfig()
+	config.ClientID = "test-client-id"
+	config.ClientSecret = "test-client-secret"
+	config.RedirectURI = "https://example.com/callback"
+	config.BaseURL = server.URL
+	config.RetryConfig = &RetryConfig{
+		MaxRetries:    2,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      time.Millisecond,
+		BackoffFactor: 2,
+	}
+	if configure != nil {
+		configure(config)
+	}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := client.SetTokenInfo(&TokenInfo{
+		AccessToken: "test-access-token",
+		TokenType:   "Bearer",
+		ExpiresAt:   time.Now().Add(30 * 24 * time.Hour),
+		UserID:      "12345",
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to set token info: %v", err)
+	}
+	return client
+}
+
+func TestChain_Order(t *testing.T) {
+	var calls []string
+	named := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls = append(calls, "base")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: http.Header{}}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	if _, err := Chain(base, named("outer"), nil, named("inner")).RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	if got := strings.Join(calls, ","); got != "outer,inner,base" {
+		t.Errorf("expected outer,inner,base, got %s", got)
+	}
+}
+
+func TestConfigMiddlewares_RunPerAttempt(t *testing.T) {
+	var served atomic.Int32
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace") != "on" {
+			t.Errorf("expected the middleware header, got %q", r.Header.Get("X-Trace"))
+		}
+		if served.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"12345","username":"testuser"}`))
+	}, func(cfg *Config) {
+		var attempts atomic.Int32
+		cfg.Middlewares = []Middleware{func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				attempts.Add(1)
+				req = req.Clone(req.Context())
+				req.Header.Set("X-Trace", "on")
+				return next.RoundTrip(req)
+			})
+		}}
+		t.Cleanup(func() {
+			if attempts.Load() != 2 {
+				t.Errorf("expected the middleware to see 2 attempts, got %d", attempts.Load())
+			}
+		})
+	})
+
+	user, err := client.GetMe(context.Background())
+	if err != nil {
+		t.Fatalf("GetMe failed: %v", err)
+	}
+	if user.Username != "testuser" {
+		t.Errorf("unexpected user: %+v", user)
+	}
+}
+
+func TestConfigTransport(t *testing.T) {
+	var used atomic.Bool
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"12345","username":"testuser"}`))
+	}, func(cfg *Config) {
+		cfg.Transport = RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			used.Store(true)
+			return http.DefaultTransport.RoundTrip(req)
+		})
+	})
+
+	if _, err := client.GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe failed: %v", err)
+	}
+	if !used.Load() {
+		t.Error("expected requests to go through the configured transport")
+	}
+}
+
+func TestRetryMiddleware_ReplaysBody(t *testing.T) {
+	var bodies []string
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		status := http.StatusOK
+		if len(bodies) < 3 {
+			status = http.StatusTooManyRequests
+		}
+		return &http.Response{StatusCode: status, Body: http.NoBody, Header: http.Header{}}, nil
+	})
+	retry := RetryMiddleware(&RetryConfig{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffFactor: 2}, nil)
+
+	form := url.Values{"text": {"hello"}}
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/threads", strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := retry(base).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(bodies) != 3 {
+		t.Fatalf("expected success on the third attempt, got %d after %d", resp.StatusCode, len(bodies))
+	}
+	for _, body := range bodies {
+		if body != "text=hello" {
+			t.Errorf("expected every attempt to send the body, got %q", body)
+		}
+	}
+}
+
+func TestRetryMiddleware_StopsOnClientErrors(t *testing.T) {
+	var attempts int
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return &http.Response{StatusCode: http.StatusBadRequest, Body: http.NoBody, Header: http.Header{}}, nil
+	})
+	retry := RetryMiddleware(&RetryConfig{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffFactor: 2}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/me", nil)
+	if _, err := retry(base).RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected a 400 not to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestRetryMiddleware_LogsTransportErrors(t *testing.T) {
+	var attempts int
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}
+	})
+	logger := &recordingLogger{}
+	retry := RetryMiddleware(&RetryConfig{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffFactor: 2}, logger)
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/me", nil)
+	if _, err := retry(base).RoundTrip(req); err == nil {
+		t.Fatal("expected the transport error to be returned")
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if len(logger.entries) != 2 {
+		t.Fatalf("expected a log entry per retry, got %v", logger.entries)
+	}
+	for _, entry := range logger.entries {
+		if !strings.Contains(entry, "dial tcp") {
+			t.Errorf("expected the retry log to name the transport error, got %q", entry)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := NewRateLimiter(&RateLimiterConfig{InitialLimit: 100, BackoffMultiplier: 2, MaxBackoff: time.Minute, QueueSize: 1})
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("X-RateLimit-Limit", "200")
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Body: http.NoBody, Header: header}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/me", nil)
+	if _, err := RateLimitMiddleware(limiter)(base).RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+
+	status := limiter.GetStatus()
+	if status.Limit != 200 || status.Remaining != 0 || !status.ResetTime.Equal(reset) {
+		t.Errorf("expected the headers to update the limiter, got %+v", status)
+	}
+	if !limiter.IsRateLimited() {
+		t.Error("expected a 429 to mark the limiter rate limited")
+	}
+}
+
+func TestLoggingMiddleware_RedactsAuthorization(t *testing.T) {
+	logger := &recordingLogger{}
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"Invalid parameter","code":100}}`))
+	}, func(cfg *Config) {
+		cfg.Logger = logger
+	})
+
+	if _, err := client.GetMe(context.Background()); err == nil {
+		t.Fatal("expected the 400 to fail")
+	}
+
+	logged := strings.Join(logger.entries, "\n")
+	if strings.Contains(logged, "test-access-token") {
+		t.Errorf("expected the token to be redacted, got:\n%s", logged)
+	}
+	if !strings.Contains(logged, "Authorization:[REDACTED]") || !strings.Contains(logged, "Invalid parameter") {
+		t.Errorf("expected the request and error body to be logged, got:\n%s", logged)
+	}
+}
@@ -30,24 +30,8 @@ func (c *Client) CreateTextPost(ctx context.Context, content *TextPostContent) (
 		return c.createAndPublishTextPostDirectly(ctx, content)
 	}
 
-	// Standard container creation and publishing flow
-	containerID, err := c.createTextContainer(ctx, content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create text container: %w", err)
-	}
-
-	// Wait for container to be ready
-	if errWait := c.waitForContainerReady(ctx, ContainerID(containerID), DefaultContainerPollMaxAttempts, DefaultContainerPollInterval); errWait != nil {
-		return nil, fmt.Errorf("container not ready for publishing: %w", errWait)
-	}
-
-	// Publish the container
-	post, errPublish := c.publishContainer(ctx, containerID)
-	if errPublish != nil {
-		return nil, fmt.Errorf("failed to publish text post: %w", errPublish)
-	}
-
-	return post, nil
+	// Create, wait for, and publish the container
+	return c.publishFlow(ctx, MediaTypeText, "text", textContainerParams(content), nil)
 }
 
 // CreateImagePost creates a new image post on Threads
@@ -61,29 +45,18 @@ func (c *Client) CreateImagePost(ctx context.Context, content *ImagePostContent)
 		return nil, NewValidationError(400, "Image URL is required", "Post must have an image URL", "image_url")
 	}
 
-	// Ensure we have a valid token
-	if err := c.EnsureValidToken(ctx); err != nil {
+	// Inspect the media before a container is created
+	if err := c.ValidateMediaURL(ctx, content.ImageURL, "image"); err != nil {
 		return nil, err
 	}
 
-	// Create container first
-	containerID, err := c.createImageContainer(ctx, content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create image container: %w", err)
-	}
-
-	// Wait for container to be ready
-	if errWait := c.waitForContainerReady(ctx, ContainerID(containerID), DefaultContainerPollMaxAttempts, DefaultContainerPollInterval); errWait != nil {
-		return nil, fmt.Errorf("container not ready for publishing: %w", errWait)
-	}
-
-	// Publish the container
-	post, errPublish := c.publishContainer(ctx, containerID)
-	if errPublish != nil {
-		return nil, fmt.Errorf("failed to publish image post: %w", errPublish)
+	// Ensure we have a valid token
+	if err := c.EnsureValidToken(ctx); err != nil {
+		return nil, err
 	}
 
-	return post, nil
+	// Create, wait for, and publish the container
+	return c.publishFlow(ctx, MediaTypeImage, "image", imageContainerParams(content), nil)
 }
 
 // CreateVideoPost creates a new video post on Threads
@@ -97,29 +70,18 @@ func (c *Client) CreateVideoPost(ctx context.Context, content *VideoPostContent)
 		return nil, NewValidationError(400, "Video URL is required", "Post must have a video URL", "video_url")
 	}
 
-	// Ensure we have a valid token
-	if err := c.EnsureValidToken(ctx); err != nil {
+	// Inspect the media before a container is created
+	if err := c.ValidateMediaURL(ctx, content.VideoURL, "video"); err != nil {
 		return nil, err
 	}
 
-	// Create container first
-	containerID, err := c.createVideoContainer(ctx, content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create video container: %w", err)
-	}
-
-	// Wait for container to be ready
-	if errWait := c.waitForContainerReady(ctx, ContainerID(containerID), DefaultContainerPollMaxAttempts, DefaultContainerPollInterval); errWait != nil {
-		return nil, fmt.Errorf("container not ready for publishing: %w", errWait)
-	}
-
-	// Publish the container
-	post, errPublish := c.publishContainer(ctx, containerID)
-	if errPublish != nil {
-		return nil, fmt.Errorf("failed to publish video post: %w", errPublish)
+	// Ensure we have a valid token
+	if err := c.EnsureValidToken(ctx); err != nil {
+		return nil, err
 	}
 
-	return post, nil
+	// Create, wait for, and publish the container
+	return c.publishFlow(ctx, MediaTypeVideo, "video", videoContainerParams(content), nil)
 }
 
 // CreateCarouselPost creates a new carousel post on Threads
@@ -138,24 +100,8 @@ func (c *Client) CreateCarouselPost(ctx context.Context, content *CarouselPostCo
 		return nil, err
 	}
 
-	// Create container first
-	containerID, err := c.createCarouselContainer(ctx, content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create carousel container: %w", err)
-	}
-
-	// Wait for container to be ready
-	if errWait := c.waitForContainerReady(ctx, ContainerID(containerID), DefaultContainerPollMaxAttempts, DefaultContainerPollInterval); errWait != nil {
-		return nil, fmt.Errorf("container not ready for publishing: %w", errWait)
-	}
-
-	// Publish the container
-	post, errPublish := c.publishContainer(ctx, containerID)
-	if errPublish != nil {
-		return nil, fmt.Errorf("failed to publish carousel post: %w", errPublish)
-	}
-
-	return post, nil
+	// Create, wait for, and publish the container
+	return c.publishFlow(ctx, MediaTypeCarousel, "carousel", carouselContainerParams(content), content.Children)
 }
 
 // CreateQuotePost creates a new quote post on Threads
@@ -213,7 +159,11 @@ func (c *Client) RepostPost(ctx context.Context, postID PostID) (*Post, error) {
 
 	// Use the direct repost endpoint
 	path := fmt.Sprintf("/%s/repost", postID.String())
-	resp, err := c.httpClient.POST(path, nil, c.getAccessTokenSafe())
+	resp, err := c.httpClient.Do(&RequestOptions{
+		Method:  "POST",
+		Path:    path,
+		Context: ctx,
+	}, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create repost: %w", err)
 	}
@@ -253,7 +203,7 @@ func (c *Client) UnrepostPost(ctx context.Context, repostID PostID) error {
 
 	// Use the unrepost endpoint
 	path := fmt.Sprintf("/%s/unrepost", repostID.String())
-	resp, err := c.httpClient.DELETE(path, c.getAccessTokenSafe())
+	resp, err := c.httpClient.DELETE(ctx, path, c.getAccessTokenSafe())
 	if err != nil {
 		return fmt.Errorf("failed to unrepost: %w", err)
 	}
@@ -289,9 +239,8 @@ func (c *Client) CreateMediaContainer(ctx context.Context, mediaType, mediaURL,
 		return "", NewValidationError(400, "Media URL is required", "Must provide a valid media URL", "media_url")
 	}
 
-	// Validate media URL
-	validator := NewValidator()
-	if err := validator.ValidateMediaURL(mediaURL, strings.ToLower(mediaType)); err != nil {
+	// Validate and inspect the media URL
+	if err := c.ValidateMediaURL(ctx, mediaURL, strings.ToLower(mediaType)); err != nil {
 		return "", err
 	}
 
@@ -321,11 +270,17 @@ func (c *Client) CreateMediaContainer(ctx context.Context, mediaType, mediaURL,
 		return "", err
 	}
 
+	c.journal(&ContainerRecord{
+		ContainerID: containerID,
+		UserID:      c.getUserID(),
+		Kind:        MediaTypeCarouselItem,
+	}, PublishStateCreated, "", nil)
+
 	return ConvertToContainerID(containerID), nil
 }
 
-// createTextContainer creates a container for text content
-func (c *Client) createTextContainer(ctx context.Context, content *TextPostContent) (string, error) {
+// textContainerParams builds the container parameters for text content
+func textContainerParams(content *TextPostContent) url.Values {
 	builder := NewContainerBuilder().
 		SetMediaType(MediaTypeText).
 		SetText(content.Text).
@@ -346,11 +301,11 @@ func (c *Client) createTextContainer(ctx context.Context, content *TextPostConte
 		builder.SetQuotePostID(content.QuotedPostID)
 	}
 
-	return c.createContainer(ctx, builder.Build())
+	return builder.Build()
 }
 
-// createImageContainer creates a container for image content
-func (c *Client) createImageContainer(ctx context.Context, content *ImagePostContent) (string, error) {
+// imageContainerParams builds the container parameters for image content
+func imageContainerParams(content *ImagePostContent) url.Values {
 	builder := NewContainerBuilder().
 		SetMediaType(MediaTypeImage).
 		SetImageURL(content.ImageURL).
@@ -369,11 +324,11 @@ func (c *Client) createImageContainer(ctx context.Context, content *ImagePostCon
 		builder.SetQuotePostID(content.QuotedPostID)
 	}
 
-	return c.createContainer(ctx, builder.Build())
+	return builder.Build()
 }
 
-// createVideoContainer creates a container for video content
-func (c *Client) createVideoContainer(ctx context.Context, content *VideoPostContent) (string, error) {
+// videoContainerParams builds the container parameters for video content
+func videoContainerParams(content *VideoPostContent) url.Values {
 	builder := NewContainerBuilder().
 		SetMediaType(MediaTypeVideo).
 		SetVideoURL(content.VideoURL).
@@ -392,16 +347,11 @@ func (c *Client) createVideoContainer(ctx context.Context, content *VideoPostCon
 		builder.SetQuotePostID(content.QuotedPostID)
 	}
 
-	containerID, err := c.createContainer(ctx, builder.Build())
-	if err != nil {
-		return "", err
-	}
-
-	return containerID, nil
+	return builder.Build()
 }
 
-// createCarouselContainer creates a container for carousel content
-func (c *Client) createCarouselContainer(ctx context.Context, content *CarouselPostContent) (string, error) {
+// carouselContainerParams builds the container parameters for carousel content
+func carouselContainerParams(content *CarouselPostContent) url.Values {
 	builder := NewContainerBuilder().
 		SetMediaType(MediaTypeCarousel).
 		SetText(content.Text).
@@ -419,7 +369,7 @@ func (c *Client) createCarouselContainer(ctx context.Context, content *CarouselP
 		builder.SetQuotePostID(content.QuotedPostID)
 	}
 
-	return c.createContainer(ctx, builder.Build())
+	return builder.Build()
 }
 
 // createAndPublishTextPostDirectly creates and publishes a text post directly when auto_publish_text is true
@@ -446,17 +396,32 @@ func (c *Client) createAndPublishTextPostDirectly(ctx context.Context, content *
 		return nil, NewAuthenticationError(401, "User ID not available", "Cannot determine user ID from token")
 	}
 
+	action := quotaActionFor(builder.Build())
+	if err := c.checkQuota(action); err != nil {
+		return nil, err
+	}
+
 	// Make API call to create and publish post directly
 	path := fmt.Sprintf("/%s/threads", userID)
-	resp, err := c.httpClient.POST(path, builder.Build(), c.getAccessTokenSafe())
+	resp, err := c.httpClient.Do(&RequestOptions{
+		Method:  "POST",
+		Path:    path,
+		Body:    builder.Build(),
+		Context: ctx,
+	}, c.getAccessTokenSafe())
+	if err == nil && resp.StatusCode != 200 {
+		err = c.handleAPIError(resp)
+	}
 	if err != nil {
+		if outcomeUnknown(err) && !neverSent(err) {
+			// The post may exist even though the request failed, so it
+			// counts against the quota and must not be sent again
+			c.recordQuota(action, "")
+			return nil, fmt.Errorf("auto-published text post: %w: %w", ErrPublishOutcomeUnknown, err)
+		}
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		return nil, c.handleAPIError(resp)
-	}
-
 	// Parse response - when auto_publish_text is true, the API returns the post ID directly
 	var post Post
 	if err := safeJSONUnmarshal(resp.Body, &post, "direct publish response", resp.RequestID); err != nil {
@@ -467,13 +432,14 @@ func (c *Client) createAndPublishTextPostDirectly(ctx context.Context, content *
 	if post.ID == "" {
 		return nil, NewAPIError(resp.StatusCode, "Post ID not returned", "API response missing post ID", resp.RequestID)
 	}
+	c.recordQuota(action, post.ID)
 
 	// Fetch the created post details
 	return c.GetPost(ctx, ConvertToPostID(post.ID))
 }
 
 // createContainer is a helper method to create containers with given parameters
-func (c *Client) createContainer(_ context.Context, params url.Values) (string, error) {
+func (c *Client) createContainer(ctx context.Context, params url.Values) (string, error) {
 	// Get user ID from token info
 	userID := c.getUserID()
 	if userID == "" {
@@ -482,7 +448,12 @@ func (c *Client) createContainer(_ context.Context, params url.Values) (string,
 
 	// Make API call to create container
 	path := fmt.Sprintf("/%s/threads", userID)
-	resp, err := c.httpClient.POST(path, params, c.getAccessTokenSafe())
+	resp, err := c.httpClient.Do(&RequestOptions{
+		Method:  "POST",
+		Path:    path,
+		Body:    params,
+		Context: ctx,
+	}, c.getAccessTokenSafe())
 	if err != nil {
 		return "", err
 	}
@@ -524,11 +495,26 @@ func (c *Client) publishContainer(ctx context.Context, containerID string) (*Pos
 		"creation_id": {containerID},
 	}
 
-	// Make API call to publish container
+	// Make API call to publish container. The transport does not retry
+	// threads_publish when its outcome is unknown, since that could publish
+	// the post twice; it is retried here once the container is confirmed
+	// to be unpublished.
 	path := fmt.Sprintf("/%s/threads_publish", userID)
-	resp, err := c.httpClient.POST(path, params, c.getAccessTokenSafe())
-	if err != nil {
-		return nil, err
+	var resp *Response
+	for attempt := 1; ; attempt++ {
+		var err error
+		resp, err = c.httpClient.Do(&RequestOptions{
+			Method:  "POST",
+			Path:    path,
+			Body:    params,
+			Context: ctx,
+		}, c.getAccessTokenSafe())
+		if err == nil {
+			break
+		}
+		if errRetry := c.checkPublishRetry(ctx, containerID, attempt, err); errRetry != nil {
+			return nil, errRetry
+		}
 	}
 
 	if resp.StatusCode != 200 {
@@ -552,6 +538,49 @@ func (c *Client) publishContainer(ctx context.Context, containerID string) (*Pos
 	return c.GetPost(ctx, ConvertToPostID(publishResp.ID))
 }
 
+// checkPublishRetry decides whether a publish that failed with err may be
+// sent again, and waits out the retry delay if so. It returns nil to retry
+// and otherwise the error to report: err itself, or a *ContainerError with
+// status PUBLISHED when the failed request went through after all.
+func (c *Client) checkPublishRetry(ctx context.Context, containerID string, attempt int, err error) error {
+	policy := c.httpClient.retryConfig
+	if policy == nil || attempt > policy.MaxRetries || !outcomeUnknown(err) {
+		return err
+	}
+
+	status, errStatus := c.GetContainerStatus(ctx, ContainerID(containerID))
+	if errStatus != nil {
+		return err
+	}
+	switch status.Status {
+	case ContainerStatusPublished:
+		return NewContainerError(containerID, status.Status, "Container already published",
+			fmt.Sprintf("The publish request failed (%v) but the container was published", err))
+	case ContainerStatusFinished:
+	default:
+		return err
+	}
+
+	delay := policy.Delay(attempt, 0)
+	if c.config != nil && c.config.Logger != nil {
+		c.config.Logger.Warn("Retrying publish of unpublished container",
+			"container_id", containerID,
+			"attempt", attempt,
+			"delay", delay.String(),
+			"error", err.Error(),
+		)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // GetContainerStatus retrieves the status of a media container
 // This is useful for checking if a video or image container has finished processing
 // before attempting to publish it. Returns container status information including:
@@ -575,7 +604,12 @@ func (c *Client) GetContainerStatus(ctx context.Context, containerID ContainerID
 
 	// Make API call to get container status
 	path := fmt.Sprintf("/%s", containerID.String())
-	resp, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	resp, err := c.httpClient.Do(&RequestOptions{
+		Method:      "GET",
+		Path:        path,
+		QueryParams: params,
+		Context:     ctx,
+	}, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get container status: %w", err)
 	}
@@ -601,39 +635,3 @@ func (c *Client) GetContainerStatus(ctx context.Context, containerID ContainerID
 
 	return &status, nil
 }
-
-// waitForContainerReady polls the container status until it's ready to be published
-// Returns an error if the container fails or times out
-//
-//nolint:unparam // maxAttempts always receives DefaultContainerPollMaxAttempts but keeping for API flexibility
-func (c *Client) waitForContainerReady(ctx context.Context, containerID ContainerID, maxAttempts int, pollInterval time.Duration) error {
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		status, err := c.GetContainerStatus(ctx, containerID)
-		if err != nil {
-			return fmt.Errorf("failed to check container status: %w", err)
-		}
-
-		switch status.Status {
-		case ContainerStatusFinished:
-			// Container is ready to be published
-			return nil
-		case ContainerStatusError:
-			if status.ErrorMessage != "" {
-				return fmt.Errorf("container processing failed: %s", status.ErrorMessage)
-			}
-			return fmt.Errorf("container processing failed with error status")
-		case ContainerStatusExpired:
-			return fmt.Errorf("container expired before it could be published")
-		case ContainerStatusInProgress, ContainerStatusPublished:
-			// Still processing or already published, wait and retry
-			time.Sleep(pollInterval)
-			continue
-		default:
-			// Unknown status, wait and retry
-			time.Sleep(pollInterval)
-			continue
-		}
-	}
-
-	return fmt.Errorf("timeout waiting for container to be ready after %d attempts", maxAttempts)
-}
@@ -22,9 +22,13 @@ func (c *Client) DeletePost(ctx context.Context, postID PostID) error {
 		return err
 	}
 
+	if err := c.checkQuota(QuotaDelete); err != nil {
+		return err
+	}
+
 	// Make API call to delete post
 	path := fmt.Sprintf("/%s", postID.String())
-	resp, err := c.httpClient.DELETE(path, c.getAccessTokenSafe())
+	resp, err := c.httpClient.DELETE(ctx, path, c.getAccessTokenSafe())
 	if err != nil {
 		return err
 	}
@@ -56,6 +60,8 @@ func (c *Client) DeletePost(ctx context.Context, postID PostID) error {
 		}
 	}
 
+	c.recordQuota(QuotaDelete, postID.String())
+
 	// Log successful deletion if logger is available
 	if c.config.Logger != nil {
 		c.config.Logger.Info("Successfully deleted post", "post_id", postID.String())
@@ -39,12 +39,19 @@ func (c *Client) CreateReply(ctx context.Context, content *PostContent) (*Post,
 		params.Set("text", content.Text)
 	}
 
+	if err := c.checkQuota(QuotaReply); err != nil {
+		return nil, err
+	}
+
 	// Create container first
 	containerID, err := c.createContainer(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create reply container: %w", err)
 	}
 
+	rec := &ContainerRecord{ContainerID: containerID, UserID: c.getUserID(), Kind: mediaType}
+	c.journal(rec, PublishStateCreated, "", nil)
+
 	// Wait recommended 10 seconds before publishing reply
 	if c.config.Logger != nil {
 		c.config.Logger.Info("Reply container created, waiting before publishing", "container_id", containerID)
@@ -60,8 +67,15 @@ func (c *Client) CreateReply(ctx context.Context, content *PostContent) (*Post,
 	// Publish the container
 	post, err := c.publishContainer(ctx, containerID)
 	if err != nil {
+		if alreadyPublished(err) {
+			c.markPublished(rec, "")
+			c.recordQuota(QuotaReply, "")
+			return nil, fmt.Errorf("reply: %w", ErrPublishedIDUnknown)
+		}
 		return nil, fmt.Errorf("failed to publish reply: %w", err)
 	}
+	c.markPublished(rec, post.ID)
+	c.recordQuota(QuotaReply, post.ID)
 
 	return post, nil
 }
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -254,3 +255,16 @@ func (c *Client) ValidateCountryCodes(codes []string) error {
 	validator := NewValidator()
 	return validator.ValidateCountryCodes(codes)
 }
+
+// ValidateMediaURL checks the format of mediaURL and then inspects the media
+// with the configured MediaChecker, if any. It runs with the request's ctx,
+// as inspecting media may fetch it.
+func (c *Client) ValidateMediaURL(ctx context.Context, mediaURL, mediaType string) error {
+	if err := NewValidator().ValidateMediaURL(mediaURL, mediaType); err != nil {
+		return err
+	}
+	if c.config == nil || c.config.MediaChecker == nil {
+		return nil
+	}
+	return c.config.MediaChecker.CheckMedia(ctx, mediaURL, mediaType)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/url"
+)
+
+// QuotaAction is an operation counted against one of the account's rolling
+// publishing quotas (see GetPublishingLimits).
+type QuotaAction string
+
+// Quota actions tracked by a QuotaLedger.
+const (
+	QuotaPost   QuotaAction = "post"
+	QuotaReply  QuotaAction = "reply"
+	QuotaDelete QuotaAction = "delete"
+)
+
+// QuotaLedger tracks publishing quota usage across client instances, so a
+// publish that would exceed the account's quota fails before any container
+// is created. Check is called before a publish or delete and should return
+// a *QuotaError when the action would exceed quota; Record is called after
+// the action succeeded.
+type QuotaLedger interface {
+	Check(userID string, action QuotaAction) error
+	Record(userID string, action QuotaAction, id string) error
+}
+
+// quotaActionFor returns the quota a container with params counts against.
+func quotaActionFor(params url.Values) QuotaAction {
+	if params.Get("reply_to_id") != "" {
+		return QuotaReply
+	}
+	return QuotaPost
+}
+
+// checkQuota asks the quota ledger, if any, whether action is allowed.
+func (c *Client) checkQuota(action QuotaAction) error {
+	if c.config == nil || c.config.QuotaLedger == nil {
+		return nil
+	}
+	return c.config.QuotaLedger.Check(c.getUserID(), action)
+}
+
+// recordQuota records a completed action. Ledger failures are logged but
+// never fail the action itself, which has already happened.
+func (c *Client) recordQuota(action QuotaAction, id string) {
+	if c.config == nil || c.config.QuotaLedger == nil {
+		return
+	}
+	if err := c.config.QuotaLedger.Record(c.getUserID(), action, id); err != nil && c.config.Logger != nil {
+		c.config.Logger.Warn("Failed to record quota usage", "action", string(action), "id", id, "error", err)
+	}
+}
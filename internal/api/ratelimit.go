@@ -180,6 +180,9 @@ type RateLimitStatus struct {
 	Remaining int           `json:"remaining"`
 	ResetTime time.Time     `json:"reset_time"`
 	ResetIn   time.Duration `json:"reset_in"`
+
+	// Throttle holds client-side throttling metrics per endpoint class.
+	Throttle map[EndpointClass]ThrottleStats `json:"throttle,omitempty"`
 }
 
 // IsNearLimit returns true if we're close to hitting the rate limit
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryConfig is the retry policy for failed requests.
+//
+// Requests that are safe to repeat (GET, HEAD, OPTIONS, PUT and DELETE, or
+// any sent with RequestOptions.Idempotent) are retried after temporary
+// network errors, 429 Too Many Requests and 5xx server errors. Other requests, such as the POSTs that create and publish
+// posts, may already have taken effect when they time out or fail with a
+// 5xx, so they are only retried when the server cannot have acted on them:
+// after a 429, or a temporary error while connecting.
+//
+// Delays grow exponentially with full jitter, and a Retry-After sent by the
+// server takes precedence over the computed delay.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retry attempts (default: 3).
+	// Set to 0 to disable retries. Higher values provide more resilience
+	// but may increase latency for failing requests.
+	MaxRetries int
+
+	// InitialDelay is the backoff before the first retry attempt (default: 1 second).
+	// This delay is multiplied by BackoffFactor for subsequent retries.
+	InitialDelay time.Duration
+
+	// MaxDelay is the maximum delay between retry attempts (default: 30 seconds).
+	// This caps exponential backoff. A 429 whose Retry-After exceeds it is
+	// returned to the caller instead of being waited out.
+	MaxDelay time.Duration
+
+	// BackoffFactor is the multiplier for exponential backoff (default: 2.0).
+	// The backoff before retry n is min(InitialDelay * BackoffFactor^(n-1), MaxDelay),
+	// and the actual delay is drawn uniformly between zero and the backoff.
+	BackoffFactor float64
+
+	// random returns a number in [0, 1) for jitter. Tests replace it.
+	random func() float64
+}
+
+// ShouldRetry reports whether a request that ended with resp or err may be
+// sent again. attempt is the number of attempts made so far, counting from 1.
+func (r *RetryConfig) ShouldRetry(req *http.Request, resp *http.Response, err error, attempt int) bool {
+	if attempt > r.MaxRetries || req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		temporary := wrapNetworkError(err).Temporary
+		if isIdempotent(req) {
+			return temporary
+		}
+		return temporary && neverSent(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// The request was rejected, not processed, so any method may retry
+		return retryAfter(resp.Header) <= r.MaxDelay
+	case shouldRetryStatus(resp.StatusCode):
+		return isIdempotent(req)
+	default:
+		return false
+	}
+}
+
+// Delay returns how long to wait before retry number attempt, counting from
+// 1. A positive retryAfter from the server is used as is. Otherwise the
+// delay is drawn uniformly from zero up to the exponential backoff ("full
+// jitter"), so clients that failed together do not retry in lockstep.
+func (r *RetryConfig) Delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	backoff := float64(r.InitialDelay) * math.Pow(r.BackoffFactor, float64(attempt-1))
+	if backoff > float64(r.MaxDelay) {
+		backoff = float64(r.MaxDelay)
+	}
+
+	random := r.random
+	if random == nil {
+		random = rand.Float64
+	}
+	return time.Duration(random() * backoff)
+}
+
+// idempotentKey marks the context of a request sent with
+// RequestOptions.Idempotent.
+type idempotentKey struct{}
+
+// isIdempotent reports whether sending req twice has the same effect as
+// sending it once.
+func isIdempotent(req *http.Request) bool {
+	if marked, _ := req.Context().Value(idempotentKey{}).(bool); marked {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// neverSent reports whether err shows the request failed before reaching
+// the server: while resolving its host or opening the connection.
+func neverSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// shouldRetryStatus determines if a status code should trigger a retry
+func shouldRetryStatus(statusCode int) bool {
+	switch statusCode {
+	case 429: // Too Many Requests
+		return true
+	case 500, 502, 503, 504: // Server errors
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait the server asked for in a Retry-After
+// header, or 0 if there is none.
+func retryAfter(headers http.Header) time.Duration {
+	if info := ParseRateLimitHeaders(headers); info != nil {
+		return info.RetryAfter
+	}
+	seconds, err := strconv.Atoi(headers.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// outcomeUnknown reports whether a failed request may nevertheless have
+// been carried out: it timed out or broke off, or the server failed with a
+// 5xx after receiving it.
+func outcomeUnknown(err error) bool {
+	var netErr *NetworkError
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// timeoutError is a network error that reports a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryConfig_ShouldRetry(t *testing.T) {
+	policy := &RetryConfig{MaxRetries: 2, InitialDelay: time.Second, MaxDelay: 10 * time.Second, BackoffFactor: 2}
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}
+
+	response := func(status int, retryAfter string) *http.Response {
+		header := http.Header{}
+		if retryAfter != "" {
+			header.Set("Retry-After", retryAfter)
+		}
+		return &http.Response{StatusCode: status, Header: header, Body: http.NoBody}
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		resp    *http.Response
+		err     error
+		attempt int
+		want    bool
+	}{
+		{"GET after 502", http.MethodGet, response(http.StatusBadGateway, ""), nil, 1, true},
+		{"DELETE after 503", http.MethodDelete, response(http.StatusServiceUnavailable, ""), nil, 1, true},
+		{"POST after 502", http.MethodPost, response(http.StatusBadGateway, ""), nil, 1, false},
+		{"POST after 429", http.MethodPost, response(http.StatusTooManyRequests, ""), nil, 1, true},
+		{"Retry-After within MaxDelay", http.MethodGet, response(http.StatusTooManyRequests, "5"), nil, 1, true},
+		{"Retry-After beyond MaxDelay", http.MethodGet, response(http.StatusTooManyRequests, "60"), nil, 1, false},
+		{"GET after 400", http.MethodGet, response(http.StatusBadRequest, ""), nil, 1, false},
+		{"retries used up", http.MethodGet, response(http.StatusBadGateway, ""), nil, 3, false},
+		{"GET after read timeout", http.MethodGet, nil, readErr, 1, true},
+		{"POST after read timeout", http.MethodPost, nil, readErr, 1, false},
+		{"POST after dial timeout", http.MethodPost, nil, dialErr, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "https://example.com/12345/threads_publish", nil)
+			if got := policy.ShouldRetry(req, tt.resp, tt.err, tt.attempt); got != tt.want {
+				t.Errorf("ShouldRetry = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryConfig_Delay(t *testing.T) {
+	policy := &RetryConfig{
+		MaxRetries:    5,
+		InitialDelay:  time.Second,
+		MaxDelay:      5 * time.Second,
+		BackoffFactor: 2,
+		random:        func() float64 { return 0.5 },
+	}
+
+	tests := []struct {
+		attempt    int
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{1, 0, 500 * time.Millisecond},
+		{2, 0, time.Second},
+		{3, 0, 2 * time.Second},
+		{4, 0, 2500 * time.Millisecond}, // capped at MaxDelay before jitter
+		{1, 3 * time.Second, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.Delay(tt.attempt, tt.retryAfter); got != tt.want {
+			t.Errorf("Delay(%d, %v) = %v, want %v", tt.attempt, tt.retryAfter, got, tt.want)
+		}
+	}
+
+	policy.random = nil
+	for range 100 {
+		if got := policy.Delay(3, 0); got < 0 || got >= 4*time.Second {
+			t.Fatalf("expected jittered delay in [0, 4s), got %v", got)
+		}
+	}
+}
+
+func TestPublishContainer_RetriesUnpublishedContainer(t *testing.T) {
+	var publishes atomic.Int32
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/c1":
+			_, _ = w.Write([]byte(`{"id":"c1","status":"FINISHED"}`))
+		case "/12345/threads_publish":
+			if publishes.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"p1"}`))
+		case "/p1":
+			_, _ = w.Write([]byte(`{"id":"p1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}, func(cfg *Config) {
+		cfg.ContainerPoll = fastPoll(5)
+	})
+
+	post, err := client.PublishContainer(context.Background(), ContainerID("c1"))
+	if err != nil {
+		t.Fatalf("PublishContainer failed: %v", err)
+	}
+	if post.ID != "p1" || publishes.Load() != 2 {
+		t.Errorf("expected post p1 after 2 publish requests, got %s after %d", post.ID, publishes.Load())
+	}
+}
+
+func TestPublishContainer_DoesNotRepublish(t *testing.T) {
+	var publishes atomic.Int32
+	var published atomic.Bool
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/c1":
+			status := ContainerStatusFinished
+			if published.Load() {
+				status = ContainerStatusPublished
+			}
+			_, _ = w.Write([]byte(`{"id":"c1","status":"` + status + `"}`))
+		case "/12345/threads_publish":
+			// The post is created, but the response is lost
+			publishes.Add(1)
+			published.Store(true)
+			w.WriteHeader(http.StatusGatewayTimeout)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}, func(cfg *Config) {
+		cfg.ContainerPoll = fastPoll(5)
+	})
+	journal := &memoryJournal{}
+	client.config.ContainerJournal = journal
+
+	_, err := client.PublishContainer(context.Background(), ContainerID("c1"))
+	if !errors.Is(err, ErrPublishedIDUnknown) {
+		t.Fatalf("expected ErrPublishedIDUnknown, got %v", err)
+	}
+	if publishes.Load() != 1 {
+		t.Errorf("expected a single publish request, got %d", publishes.Load())
+	}
+	if states := journal.states(); len(states) != 1 || states[0] != PublishStatePublished {
+		t.Errorf("expected the container to be journaled as published, got %v", states)
+	}
+}
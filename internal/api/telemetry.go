@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Metrics recorded through Config.Telemetry. Durations are in milliseconds.
+const (
+	// MetricRequests counts API requests by endpoint, method and status code.
+	MetricRequests = "threads.api.requests"
+	// MetricRequestDuration is the latency of API requests, retries included.
+	MetricRequestDuration = "threads.api.request.duration"
+	// MetricRetries counts requests sent again after a failed attempt.
+	MetricRetries = "threads.api.retries"
+	// MetricRateLimitWait is time spent waiting for a rate limit, either one
+	// reported by the API or the client-side throttle.
+	MetricRateLimitWait = "threads.api.rate_limit.wait"
+	// MetricContainerWait is time spent waiting for containers to process.
+	MetricContainerWait = "threads.container.wait"
+)
+
+// Telemetry receives traces and metrics for the client's work (optional
+// hook, see Config.Telemetry). Implementations must be safe for concurrent
+// use.
+type Telemetry interface {
+	// StartSpan starts a span as a child of the span in ctx, if any, and
+	// returns a context carrying the new span.
+	StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+
+	// Count adds delta to the counter called name.
+	Count(ctx context.Context, name string, delta int64, attrs ...Attribute)
+
+	// Observe records value in the histogram called name.
+	Observe(ctx context.Context, name string, value float64, attrs ...Attribute)
+}
+
+// Span is a timed operation started by Telemetry.StartSpan.
+type Span interface {
+	// SetAttributes adds or replaces attributes on the span.
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+
+	// End finishes the span.
+	End()
+}
+
+// TraceContextSpan is implemented by spans whose trace context can be sent
+// to the server. The client adds it to every request as W3C Trace Context
+// traceparent and tracestate headers.
+type TraceContextSpan interface {
+	Span
+
+	// TraceParent returns the span's traceparent header value.
+	TraceParent() string
+
+	// TraceState returns the tracestate header value, or "" for none.
+	TraceState() string
+}
+
+// Attribute is a key and value attached to a span or measurement. Values
+// are strings, integers, floats or booleans.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr returns an Attribute.
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// noopTelemetry discards everything; it is used when no hook is configured.
+type noopTelemetry struct{}
+
+func (noopTelemetry) StartSpan(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopTelemetry) Count(context.Context, string, int64, ...Attribute) {}
+
+func (noopTelemetry) Observe(context.Context, string, float64, ...Attribute) {}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// telemetryOrNoop returns t, or a Telemetry that discards everything when t
+// is nil.
+func telemetryOrNoop(t Telemetry) Telemetry {
+	if t == nil {
+		return noopTelemetry{}
+	}
+	return t
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// attemptsKey carries the attempt counter of a request through the
+// middleware chain.
+type attemptsKey struct{}
+
+// withAttemptCounter returns a context whose requests count their attempts
+// in n.
+func withAttemptCounter(ctx context.Context, n *atomic.Int32) context.Context {
+	return context.WithValue(ctx, attemptsKey{}, n)
+}
+
+// telemetryMiddleware wraps every attempt of a request in its own span, so
+// retries and the time between them are visible in traces.
+func (h *HTTPClient) telemetryMiddleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempt := int32(1)
+			if n, ok := req.Context().Value(attemptsKey{}).(*atomic.Int32); ok {
+				attempt = n.Add(1)
+			}
+
+			ctx, span := telemetryOrNoop(h.telemetry).StartSpan(req.Context(), "HTTP "+req.Method,
+				Attr("http.request.method", req.Method),
+				Attr("threads.endpoint", endpointName(req.URL.Path)),
+				Attr("threads.attempt", int(attempt)),
+			)
+			req = req.WithContext(ctx)
+			injectTraceContext(req, span)
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				endSpan(span, err)
+				return nil, err
+			}
+
+			span.SetAttributes(
+				Attr("http.response.status_code", resp.StatusCode),
+				Attr("threads.request_id", resp.Header.Get("X-Fb-Request-Id")),
+			)
+			span.End()
+			return resp, nil
+		})
+	}
+}
+
+// injectTraceContext sets the W3C Trace Context headers of req from span,
+// if it carries a trace context. The headers are copied first, as req
+// shares them with the request the caller built.
+func injectTraceContext(req *http.Request, span Span) {
+	tc, ok := span.(TraceContextSpan)
+	if !ok {
+		return
+	}
+	header := req.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("traceparent", tc.TraceParent())
+	if state := tc.TraceState(); state != "" {
+		header.Set("tracestate", state)
+	} else {
+		header.Del("tracestate")
+	}
+	req.Header = header
+}
+
+// endpointName turns a request path into a low-cardinality endpoint name by
+// replacing object IDs, so "/v1.0/1789/insights" becomes "/{id}/insights".
+func endpointName(path string) string {
+	path = strings.TrimPrefix(path, "/v1.0")
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment != "" && strings.Trim(segment, "0123456789_") == "" {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
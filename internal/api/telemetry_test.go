@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingTelemetry keeps ended spans and metric totals.
+type recordingTelemetry struct {
+	mu      sync.Mutex
+	spans   []*recordedSpan
+	metrics map[string]float64
+}
+
+type recordedSpan struct {
+	tel   *recordingTelemetry
+	name  string
+	attrs map[string]any
+	err   error
+}
+
+func (r *recordingTelemetry) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &recordedSpan{tel: r, name: name, attrs: map[string]any{}}
+	s.SetAttributes(attrs...)
+	return ctx, s
+}
+
+func (r *recordingTelemetry) Count(_ context.Context, name string, delta int64, _ ...Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[name] += float64(delta)
+}
+
+func (r *recordingTelemetry) Observe(_ context.Context, name string, _ float64, _ ...Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[name+".count"]++
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	s.tel.mu.Lock()
+	defer s.tel.mu.Unlock()
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+
+func (s *recordedSpan) End() {
+	s.tel.mu.Lock()
+	defer s.tel.mu.Unlock()
+	s.tel.spans = append(s.tel.spans, s)
+}
+
+func (r *recordingTelemetry) named(name string) []*recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var spans []*recordedSpan
+	for _, s := range r.spans {
+		if s.name == name {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+func TestTelemetry_RequestsAndRetries(t *testing.T) {
+	tel := &recordingTelemetry{metrics: map[string]float64{}}
+	var served atomic.Int32
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Fb-Request-Id", "req-1")
+		if served.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"12345","username":"testuser"}`))
+	}, func(cfg *Config) {
+		cfg.Telemetry = tel
+	})
+
+	if _, err := client.GetUser(context.Background(), UserID("1789")); err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+
+	requests := tel.named("threads.api.request")
+	if len(requests) != 1 {
+		t.Fatalf("expected one request span, got %d", len(requests))
+	}
+	attrs := requests[0].attrs
+	if attrs["threads.endpoint"] != "/{id}" || attrs["http.response.status_code"] != 200 ||
+		attrs["threads.retries"] != 1 || attrs["threads.request_id"] != "req-1" {
+		t.Errorf("unexpected request span attributes: %v", attrs)
+	}
+
+	attempts := tel.named("HTTP GET")
+	if len(attempts) != 2 || attempts[0].attrs["http.response.status_code"] != 503 || attempts[1].attrs["threads.attempt"] != 2 {
+		t.Errorf("expected a span per attempt, got %d", len(attempts))
+	}
+
+	if tel.metrics[MetricRequests] != 1 || tel.metrics[MetricRetries] != 1 || tel.metrics[MetricRequestDuration+".count"] != 1 {
+		t.Errorf("unexpected metrics: %v", tel.metrics)
+	}
+}
+
+func TestTelemetry_ContainerWait(t *testing.T) {
+	tel := &recordingTelemetry{metrics: map[string]float64{}}
+	var polls atomic.Int32
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		status := "IN_PROGRESS"
+		if polls.Add(1) > 1 {
+			status = "FINISHED"
+		}
+		_, _ = w.Write([]byte(`{"id":"c1","status":"` + status + `"}`))
+	}, func(cfg *Config) {
+		cfg.Telemetry = tel
+		cfg.ContainerPoll = fastPoll(5)
+	})
+
+	if _, err := client.WaitForContainer(context.Background(), ContainerID("c1")); err != nil {
+		t.Fatalf("WaitForContainer failed: %v", err)
+	}
+
+	waits := tel.named("threads.container.wait")
+	if len(waits) != 1 || waits[0].attrs["threads.container_status"] != "FINISHED" {
+		t.Fatalf("expected a container wait span, got %+v", waits)
+	}
+	if n := len(tel.named("threads.api.request")); n != 2 {
+		t.Errorf("expected a request span per status check, got %d", n)
+	}
+	if tel.metrics[MetricContainerWait+".count"] != 1 {
+		t.Errorf("expected a container wait measurement, got %v", tel.metrics)
+	}
+}
+
+func TestTelemetry_RateLimitWait(t *testing.T) {
+	tel := &recordingTelemetry{metrics: map[string]float64{}}
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"12345","username":"testuser"}`))
+	}, func(cfg *Config) {
+		cfg.Telemetry = tel
+	})
+	client.rateLimiter.MarkRateLimited(time.Now().Add(10 * time.Millisecond))
+
+	if _, err := client.GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe failed: %v", err)
+	}
+	if len(tel.named("threads.rate_limit.wait")) != 1 || tel.metrics[MetricRateLimitWait+".count"] != 1 {
+		t.Errorf("expected the rate limit wait to be recorded, got %v", tel.metrics)
+	}
+}
+
+// traceContextSpan is a recordedSpan with a fixed W3C trace context.
+type traceContextSpan struct {
+	*recordedSpan
+}
+
+func (traceContextSpan) TraceParent() string {
+	return "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+}
+
+func (traceContextSpan) TraceState() string { return "vendor=value" }
+
+type traceContextTelemetry struct {
+	*recordingTelemetry
+}
+
+func (t traceContextTelemetry) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	ctx, s := t.recordingTelemetry.StartSpan(ctx, name, attrs...)
+	return ctx, traceContextSpan{s.(*recordedSpan)}
+}
+
+func TestTelemetry_PropagatesTraceContext(t *testing.T) {
+	var traceParent, traceState string
+	client := newMiddlewareTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		traceParent, traceState = r.Header.Get("traceparent"), r.Header.Get("tracestate")
+		_, _ = w.Write([]byte(`{"id":"12345"}`))
+	}, func(cfg *Config) {
+		cfg.Telemetry = traceContextTelemetry{&recordingTelemetry{metrics: map[string]float64{}}}
+	})
+
+	if _, err := client.GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe failed: %v", err)
+	}
+	if traceParent != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" || traceState != "vendor=value" {
+		t.Errorf("expected the trace context headers, got %q and %q", traceParent, traceState)
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	tests := map[string]string{
+		"/me":                             "/me",
+		"/v1.0/me/threads":                "/me/threads",
+		"/17890123/insights":              "/{id}/insights",
+		"/v1.0/1234/subscriptions":        "/{id}/subscriptions",
+		"/12345/threads_publishing_limit": "/{id}/threads_publishing_limit",
+		"/keyword_search":                 "/keyword_search",
+		"/17890123_17890124/manage_reply": "/{id}/manage_reply",
+		"/refresh_access_token":           "/refresh_access_token",
+	}
+	for path, want := range tests {
+		if got := endpointName(path); got != want {
+			t.Errorf("endpointName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
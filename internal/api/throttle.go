@@ -0,0 +1,327 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups endpoints that share a throttle bucket.
+type EndpointClass string
+
+// Endpoint classes used for client-side throttling.
+const (
+	EndpointRead     EndpointClass = "read"
+	EndpointPublish  EndpointClass = "publish"
+	EndpointInsights EndpointClass = "insights"
+	EndpointSearch   EndpointClass = "search"
+)
+
+// Priority selects the lane a request waits in when its bucket is empty.
+// Requests in a higher priority lane are always let through first; requests
+// in the same lane are served in arrival order.
+type Priority int
+
+const (
+	// PriorityInteractive is for requests a user is waiting on (the default).
+	PriorityInteractive Priority = iota
+	// PriorityBackground is for bulk and scheduled jobs.
+	PriorityBackground
+
+	numPriorities = 2
+)
+
+// String returns the lane name.
+func (p Priority) String() string {
+	if p == PriorityBackground {
+		return "background"
+	}
+	return "interactive"
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose requests wait in the given lane.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the lane set with WithPriority, if any.
+func PriorityFromContext(ctx context.Context) (Priority, bool) {
+	if ctx == nil {
+		return PriorityInteractive, false
+	}
+	p, ok := ctx.Value(priorityKey{}).(Priority)
+	return p, ok
+}
+
+// ThrottleConfig configures proactive client-side throttling, so bulk jobs
+// stay below the API's limits instead of backing off after a 429.
+type ThrottleConfig struct {
+	// Rates is the sustained requests per second allowed for each endpoint
+	// class. Classes that are missing or not positive are not throttled.
+	Rates map[EndpointClass]float64
+
+	// Burst is how many requests of a class may be sent back to back after
+	// an idle period (default: 1).
+	Burst int
+
+	// Priority is the lane for requests whose context carries none
+	// (default: PriorityInteractive).
+	Priority Priority
+
+	// StatePath, when set, is a file through which every process with the
+	// same path shares the buckets, under a lock file. Concurrent CLI
+	// invocations are then throttled together, and background requests
+	// yield to interactive ones waiting in other processes. Without it the
+	// buckets and lanes only cover the requests of one process.
+	StatePath string
+}
+
+// ThrottleStats reports how one endpoint class has been throttled.
+type ThrottleStats struct {
+	Rate      float64       `json:"rate"`
+	Burst     int           `json:"burst"`
+	Requests  int64         `json:"requests"`
+	Delayed   int64         `json:"delayed"`
+	Waiting   int           `json:"waiting"`
+	TotalWait time.Duration `json:"total_wait"`
+	MaxWait   time.Duration `json:"max_wait"`
+}
+
+// AverageWait returns the mean wait over all requests of the class.
+func (s ThrottleStats) AverageWait() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Requests)
+}
+
+// Throttle is a set of token buckets, one per endpoint class, with priority
+// lanes for requests waiting on an empty bucket. A nil *Throttle lets every
+// request through immediately.
+type Throttle struct {
+	mu        sync.Mutex
+	buckets   map[EndpointClass]*bucket
+	priority  Priority
+	statePath string
+	now       func() time.Time
+}
+
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	lanes  [numPriorities][]*waiter
+	wake   chan struct{}
+	stats  ThrottleStats
+}
+
+type waiter struct {
+	id       string
+	priority Priority
+}
+
+// NewThrottle returns a throttle for cfg, or nil when cfg throttles nothing.
+func NewThrottle(cfg *ThrottleConfig) *Throttle {
+	if cfg == nil {
+		return nil
+	}
+
+	burst := cfg.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	t := &Throttle{
+		buckets:   make(map[EndpointClass]*bucket),
+		priority:  cfg.Priority,
+		statePath: cfg.StatePath,
+		now:       time.Now,
+	}
+	now := t.now()
+	for class, rate := range cfg.Rates {
+		if rate <= 0 {
+			continue
+		}
+		t.buckets[class] = &bucket{
+			rate:   rate,
+			burst:  float64(burst),
+			tokens: float64(burst),
+			last:   now,
+			wake:   make(chan struct{}),
+			stats:  ThrottleStats{Rate: rate, Burst: burst},
+		}
+	}
+	if len(t.buckets) == 0 {
+		return nil
+	}
+	return t
+}
+
+// Wait blocks until a request of class may be sent, and returns how long it
+// waited. The lane is taken from ctx, falling back to the configured
+// default.
+func (t *Throttle) Wait(ctx context.Context, class EndpointClass) (time.Duration, error) {
+	if t == nil {
+		return 0, nil
+	}
+	b, ok := t.buckets[class]
+	if !ok {
+		return 0, nil
+	}
+
+	priority, ok := PriorityFromContext(ctx)
+	if !ok {
+		priority = t.priority
+	}
+	if priority < 0 || priority >= numPriorities {
+		priority = PriorityBackground
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	start := t.now()
+	w := &waiter{id: newWaiterID(), priority: priority}
+	if !b.queuedAhead(priority) && t.take(class, b, w, start) {
+		b.record(0)
+		return 0, nil
+	}
+
+	b.lanes[priority] = append(b.lanes[priority], w)
+	for {
+		now := t.now()
+		if b.head() == w && t.take(class, b, w, now) {
+			b.remove(w)
+			b.broadcast()
+			waited := now.Sub(start)
+			b.record(waited)
+			return waited, nil
+		}
+		b.refill(now)
+
+		delay := time.Duration(math.Ceil((1 - b.tokens) / b.rate * float64(time.Second)))
+		if b.tokens >= 1 {
+			// A token is free but another process's interactive request
+			// gets it first
+			delay = throttleYieldPoll
+		}
+		wake := b.wake
+
+		t.mu.Unlock()
+		timer := time.NewTimer(delay)
+		var err error
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-timer.C:
+		case <-wake:
+		}
+		timer.Stop()
+		t.mu.Lock()
+
+		if err != nil {
+			b.remove(w)
+			b.broadcast()
+			t.release(class, w)
+			return t.now().Sub(start), err
+		}
+	}
+}
+
+// Stats returns per-class throttling metrics, or nil for a nil throttle.
+func (t *Throttle) Stats() map[EndpointClass]ThrottleStats {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make(map[EndpointClass]ThrottleStats, len(t.buckets))
+	for class, b := range t.buckets {
+		s := b.stats
+		for _, lane := range b.lanes {
+			s.Waiting += len(lane)
+		}
+		stats[class] = s
+	}
+	return stats
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// queuedAhead reports whether anyone waits in the lane for priority or a
+// higher one.
+func (b *bucket) queuedAhead(priority Priority) bool {
+	for p := Priority(0); p <= priority; p++ {
+		if len(b.lanes[p]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// head returns the waiter that gets the next token.
+func (b *bucket) head() *waiter {
+	for _, lane := range b.lanes {
+		if len(lane) > 0 {
+			return lane[0]
+		}
+	}
+	return nil
+}
+
+func (b *bucket) remove(w *waiter) {
+	lane := b.lanes[w.priority]
+	for i, other := range lane {
+		if other == w {
+			b.lanes[w.priority] = append(lane[:i:i], lane[i+1:]...)
+			return
+		}
+	}
+}
+
+// broadcast wakes every waiter so the new head can claim a token.
+func (b *bucket) broadcast() {
+	close(b.wake)
+	b.wake = make(chan struct{})
+}
+
+func (b *bucket) record(waited time.Duration) {
+	b.stats.Requests++
+	if waited > 0 {
+		b.stats.Delayed++
+		b.stats.TotalWait += waited
+		if waited > b.stats.MaxWait {
+			b.stats.MaxWait = waited
+		}
+	}
+}
+
+// classifyRequest returns the throttle bucket a request belongs to.
+func classifyRequest(method, path string) EndpointClass {
+	switch {
+	case strings.Contains(path, "insights"):
+		return EndpointInsights
+	case strings.Contains(path, "search"):
+		return EndpointSearch
+	case strings.HasSuffix(path, "/"):
+		// Batch requests are POSTed to the root but only pack reads
+		return EndpointRead
+	case method != "" && method != http.MethodGet:
+		return EndpointPublish
+	default:
+		return EndpointRead
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+// interactiveLeaseMargin is added to an interactive waiter's expected wait
+// when it is listed in the shared state, so the entry outlives the wait
+// but lapses soon after a process dies while waiting.
+const interactiveLeaseMargin = 2 * time.Second
+
+// throttleYieldPoll is how often a background request rechecks the shared
+// state while it yields to an interactive request in another process.
+const throttleYieldPoll = 50 * time.Millisecond
+
+// waiterSeq numbers waiters so their IDs are unique within the process.
+var waiterSeq atomic.Int64
+
+// throttleState is the bucket state shared through ThrottleConfig.StatePath.
+type throttleState struct {
+	Classes map[EndpointClass]*sharedBucket `json:"classes"`
+}
+
+type sharedBucket struct {
+	Tokens float64   `json:"tokens"`
+	Last   time.Time `json:"last"`
+
+	// Interactive lists interactive requests waiting in any process, by
+	// waiter ID, with when each entry lapses unless renewed. Background
+	// requests do not take a token while another process has one listed.
+	Interactive map[string]time.Time `json:"interactive,omitempty"`
+}
+
+// newWaiterID returns an ID that is unique across processes.
+func newWaiterID() string {
+	return fmt.Sprintf("%d-%d", os.Getpid(), waiterSeq.Add(1))
+}
+
+// take claims a token from b for w at now, and reports whether it got one.
+// With a state file the token comes from the shared bucket. If the state
+// file cannot be used, the process falls back to its own bucket rather than
+// failing the request.
+func (t *Throttle) take(class EndpointClass, b *bucket, w *waiter, now time.Time) bool {
+	if t.statePath != "" {
+		ok, err := t.takeShared(class, b, w, now)
+		if err == nil {
+			return ok
+		}
+	}
+
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// takeShared is take for a shared bucket. It loads the bucket into b,
+// takes a token if one is free and w need not yield, and saves the bucket,
+// all under the state file's lock. An interactive waiter that gets no token
+// is listed in the state so background requests elsewhere yield to it.
+func (t *Throttle) takeShared(class EndpointClass, b *bucket, w *waiter, now time.Time) (bool, error) {
+	var ok bool
+	err := t.updateState(func(state *throttleState) {
+		sb := state.Classes[class]
+		if sb == nil {
+			sb = &sharedBucket{Tokens: b.burst, Last: now}
+			state.Classes[class] = sb
+		}
+		for id, expires := range sb.Interactive {
+			if !expires.After(now) {
+				delete(sb.Interactive, id)
+			}
+		}
+
+		b.tokens, b.last = sb.Tokens, sb.Last
+		b.refill(now)
+		ok = b.tokens >= 1 && (w.priority == PriorityInteractive || !sb.othersWaiting(w.id))
+		switch {
+		case ok:
+			b.tokens--
+			delete(sb.Interactive, w.id)
+		case w.priority == PriorityInteractive:
+			if sb.Interactive == nil {
+				sb.Interactive = map[string]time.Time{}
+			}
+			wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+			sb.Interactive[w.id] = now.Add(wait + interactiveLeaseMargin)
+		}
+		sb.Tokens, sb.Last = b.tokens, b.last
+	})
+	return ok, err
+}
+
+// release removes w from the shared state when it stops waiting without a
+// token. Failures are ignored; the entry lapses on its own.
+func (t *Throttle) release(class EndpointClass, w *waiter) {
+	if t.statePath == "" || w.priority != PriorityInteractive {
+		return
+	}
+	t.updateState(func(state *throttleState) { //nolint:errcheck,gosec // Best-effort cleanup
+		if sb := state.Classes[class]; sb != nil {
+			delete(sb.Interactive, w.id)
+		}
+	})
+}
+
+// updateState applies fn to the shared state and saves it, under the state
+// file's lock so concurrent processes never lose each other's tokens.
+func (t *Throttle) updateState(fn func(state *throttleState)) error {
+	return fsutil.WithLock(t.statePath+".lock", func() error {
+		state := &throttleState{}
+		data, err := os.ReadFile(t.statePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return fmt.Errorf("failed to read throttle state: %w", err)
+		default:
+			// A corrupt file is replaced; the buckets refill quickly
+			json.Unmarshal(data, state) //nolint:errcheck,gosec // Starting over is safe
+		}
+		if state.Classes == nil {
+			state.Classes = map[EndpointClass]*sharedBucket{}
+		}
+
+		fn(state)
+		return fsutil.WriteJSON(t.statePath, state)
+	})
+}
+
+// othersWaiting reports whether an interactive request other than id is
+// waiting.
+func (sb *sharedBucket) othersWaiting(id string) bool {
+	for other := range sb.Interactive {
+		if other != id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottle_SpacesRequests(t *testing.T) {
+	throttle := NewThrottle(&ThrottleConfig{Rates: map[EndpointClass]float64{EndpointRead: 20}})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := throttle.Wait(context.Background(), EndpointRead); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected 3 requests at 20/s to take ~100ms, took %v", elapsed)
+	}
+
+	// Unconfigured classes are not throttled.
+	if waited, err := throttle.Wait(context.Background(), EndpointPublish); err != nil || waited != 0 {
+		t.Errorf("expected no wait for publish, got %v, %v", waited, err)
+	}
+
+	stats := throttle.Stats()[EndpointRead]
+	if stats.Requests != 3 || stats.Delayed != 2 || stats.TotalWait <= 0 || stats.MaxWait > stats.TotalWait {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if _, ok := throttle.Stats()[EndpointPublish]; ok {
+		t.Error("expected no stats for an unthrottled class")
+	}
+}
+
+func TestThrottle_InteractiveJumpsAhead(t *testing.T) {
+	throttle := NewThrottle(&ThrottleConfig{
+		Rates:    map[EndpointClass]float64{EndpointPublish: 10},
+		Priority: PriorityBackground,
+	})
+	if _, err := throttle.Wait(context.Background(), EndpointPublish); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan Priority, 3)
+	wait := func(ctx context.Context, p Priority) {
+		if _, err := throttle.Wait(ctx, EndpointPublish); err != nil {
+			t.Errorf("Wait failed: %v", err)
+		}
+		order <- p
+	}
+
+	// Two background requests queue first; the interactive one arrives later
+	// but is served before both.
+	go wait(context.Background(), PriorityBackground)
+	go wait(context.Background(), PriorityBackground)
+	time.Sleep(20 * time.Millisecond)
+	go wait(WithPriority(context.Background(), PriorityInteractive), PriorityInteractive)
+
+	if first := <-order; first != PriorityInteractive {
+		t.Errorf("expected the interactive request first, got %s", first)
+	}
+	<-order
+	<-order
+}
+
+func TestThrottle_Cancel(t *testing.T) {
+	throttle := NewThrottle(&ThrottleConfig{Rates: map[EndpointClass]float64{EndpointSearch: 0.1}})
+	if _, err := throttle.Wait(context.Background(), EndpointSearch); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := throttle.Wait(ctx, EndpointSearch); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if waiting := throttle.Stats()[EndpointSearch].Waiting; waiting != 0 {
+		t.Errorf("expected cancelled waiter to leave the queue, %d waiting", waiting)
+	}
+}
+
+// TestThrottleHelperProcess takes tokens from a shared throttle when run as
+// a separate process by TestThrottle_SharedAcrossProcesses, printing when
+// it got each one.
+func TestThrottleHelperProcess(t *testing.T) {
+	path := os.Getenv("THREADS_TEST_THROTTLE_STATE")
+	if path == "" {
+		t.Skip("only runs as a helper process")
+	}
+	throttle := NewThrottle(&ThrottleConfig{
+		Rates:     map[EndpointClass]float64{EndpointPublish: 10},
+		StatePath: path,
+	})
+	for i := 0; i < 3; i++ {
+		if _, err := throttle.Wait(context.Background(), EndpointPublish); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+		fmt.Printf("token %d\n", time.Now().UnixNano())
+	}
+}
+
+func TestThrottle_SharedAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "throttle.json")
+
+	var wg sync.WaitGroup
+	outputs := make([][]byte, 2)
+	for i := range outputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestThrottleHelperProcess$")
+			cmd.Env = append(os.Environ(), "THREADS_TEST_THROTTLE_STATE="+path)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Errorf("helper process failed: %v\n%s", err, out)
+			}
+			outputs[i] = out
+		}()
+	}
+	wg.Wait()
+
+	var tokens []int64
+	for _, out := range outputs {
+		for _, line := range strings.Split(string(out), "\n") {
+			if at, ok := strings.CutPrefix(line, "token "); ok {
+				n, err := strconv.ParseInt(at, 10, 64)
+				if err != nil {
+					t.Fatalf("bad helper output %q", line)
+				}
+				tokens = append(tokens, n)
+			}
+		}
+	}
+	if len(tokens) != 6 {
+		t.Fatalf("expected 6 tokens from 2 processes, got %d:\n%s\n%s", len(tokens), outputs[0], outputs[1])
+	}
+
+	// 10/s with a burst of 1 spaces all six ~100ms apart, whichever process
+	// took them
+	slices.Sort(tokens)
+	for i := 1; i < len(tokens); i++ {
+		if gap := time.Duration(tokens[i] - tokens[i-1]); gap < 80*time.Millisecond {
+			t.Errorf("tokens %d and %d were only %v apart", i, i+1, gap)
+		}
+	}
+}
+
+func TestThrottle_BackgroundYieldsToOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "throttle.json")
+	// Two throttles sharing a state file stand in for two processes. The
+	// interactive one's slower rate makes it wake after the background one,
+	// which would take the token first if it did not yield.
+	background := NewThrottle(&ThrottleConfig{
+		Rates:     map[EndpointClass]float64{EndpointPublish: 5},
+		Priority:  PriorityBackground,
+		StatePath: path,
+	})
+	interactive := NewThrottle(&ThrottleConfig{
+		Rates:     map[EndpointClass]float64{EndpointPublish: 2},
+		StatePath: path,
+	})
+	if _, err := background.Wait(context.Background(), EndpointPublish); err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		priority Priority
+		waited   time.Duration
+	}
+	results := make(chan result, 2)
+	wait := func(throttle *Throttle, p Priority) {
+		waited, err := throttle.Wait(context.Background(), EndpointPublish)
+		if err != nil {
+			t.Errorf("Wait failed: %v", err)
+		}
+		results <- result{p, waited}
+	}
+
+	// The background request queues first but yields to the interactive
+	// request from the other throttle, which waits on the same bucket.
+	go wait(background, PriorityBackground)
+	time.Sleep(20 * time.Millisecond)
+	go wait(interactive, PriorityInteractive)
+
+	first := <-results
+	if first.priority != PriorityInteractive {
+		t.Errorf("expected the interactive request first, got %s", first.priority)
+	}
+	if first.waited < 100*time.Millisecond {
+		t.Errorf("expected the interactive request to wait for the shared bucket, waited %v", first.waited)
+	}
+	if second := <-results; second.priority != PriorityBackground {
+		t.Errorf("expected the background request to follow, got %s", second.priority)
+	}
+}
+
+func TestThrottle_Disabled(t *testing.T) {
+	if NewThrottle(nil) != nil || NewThrottle(&ThrottleConfig{Rates: map[EndpointClass]float64{EndpointRead: 0}}) != nil {
+		t.Fatal("expected nil throttle when nothing is throttled")
+	}
+	var throttle *Throttle
+	if waited, err := throttle.Wait(context.Background(), EndpointRead); err != nil || waited != 0 {
+		t.Errorf("nil throttle should not wait, got %v, %v", waited, err)
+	}
+	if throttle.Stats() != nil {
+		t.Error("nil throttle should have no stats")
+	}
+}
+
+func TestHTTPClient_Throttles(t *testing.T) {
+	client, server := createTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"12345","username":"testuser"}`))
+	})
+	defer server.Close()
+	client.httpClient.throttle = NewThrottle(&ThrottleConfig{Rates: map[EndpointClass]float64{EndpointRead: 50}})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.httpClient.GET(context.Background(), "/me", nil, "token"); err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+	}
+
+	stats, ok := client.GetRateLimitStatus().Throttle[EndpointRead]
+	if !ok || stats.Requests != 2 || stats.Delayed != 1 {
+		t.Errorf("expected throttle metrics in rate limit status, got %+v", stats)
+	}
+}
+
+func TestClassifyRequest(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         EndpointClass
+	}{
+		{"GET", "/me/threads", EndpointRead},
+		{"GET", "/123/threads_insights", EndpointInsights},
+		{"GET", "/p1/insights", EndpointInsights},
+		{"GET", "/me/threads_keyword_search", EndpointSearch},
+		{"GET", "/location_search", EndpointSearch},
+		{"POST", "/me/threads", EndpointPublish},
+		{"POST", "/me/threads_publish", EndpointPublish},
+		{"DELETE", "/p1", EndpointPublish},
+		{"POST", "/", EndpointRead},
+		{"", "/me", EndpointRead},
+	}
+	for _, tt := range tests {
+		if got := classifyRequest(tt.method, tt.path); got != tt.want {
+			t.Errorf("classifyRequest(%s, %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
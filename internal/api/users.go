@@ -25,7 +25,7 @@ func (c *Client) GetUser(ctx context.Context, userID UserID) (*User, error) {
 
 	// Make API call to get user
 	path := fmt.Sprintf("/%s", userID.String())
-	resp, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	resp, err := c.httpClient.GETCached(ctx, CacheUser, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +129,7 @@ func (c *Client) GetUserFields(ctx context.Context, userID UserID, fields []stri
 
 	// Make API call to get user
 	path := fmt.Sprintf("/%s", userID.String())
-	resp, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	resp, err := c.httpClient.GETCached(ctx, CacheUser, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, err
 	}
@@ -196,7 +196,7 @@ func (c *Client) LookupPublicProfile(ctx context.Context, username string) (*Pub
 
 	// Make API call to lookup public profile
 	path := "/profile_lookup"
-	resp, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	resp, err := c.httpClient.GET(ctx, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, err
 	}
@@ -263,7 +263,7 @@ func (c *Client) GetPublicProfilePosts(ctx context.Context, username string, opt
 
 	// Make API call to get public profile posts
 	path := "/profile_posts"
-	resp, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	resp, err := c.httpClient.GET(ctx, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, err
 	}
@@ -327,7 +327,7 @@ func (c *Client) GetUserReplies(ctx context.Context, userID UserID, opts *PostsO
 
 	// Make API call to get user replies
 	path := fmt.Sprintf("/%s/replies", userID.String())
-	resp, err := c.httpClient.GET(path, params, c.getAccessTokenSafe())
+	resp, err := c.httpClient.GET(ctx, path, params, c.getAccessTokenSafe())
 	if err != nil {
 		return nil, err
 	}
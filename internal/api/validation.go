@@ -1,11 +1,19 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
 )
 
+// MediaChecker inspects media before it is sent to Threads. CheckMedia
+// returns a *ValidationError naming the failing field when the media
+// violates Threads' limits. mediaType is "image" or "video".
+type MediaChecker interface {
+	CheckMedia(ctx context.Context, mediaURL, mediaType string) error
+}
+
 // Validator provides common validation methods
 type Validator struct{}
 
@@ -106,7 +106,7 @@ func (c *Client) SubscribeWebhook(ctx context.Context, opts *WebhookSubscribeOpt
 	c.mu.RUnlock()
 
 	// POST to /{app-id}/subscriptions
-	resp, err := c.httpClient.POST(
+	resp, err := c.httpClient.POST(ctx,
 		fmt.Sprintf("/v1.0/%s/subscriptions", appID),
 		formData,
 		token,
@@ -155,7 +155,7 @@ func (c *Client) ListWebhookSubscriptions(ctx context.Context) (*WebhookSubscrip
 	params.Set("access_token", token)
 
 	// GET /{app-id}/subscriptions
-	resp, err := c.httpClient.GET(
+	resp, err := c.httpClient.GET(ctx,
 		fmt.Sprintf("/v1.0/%s/subscriptions", appID),
 		params,
 		token,
@@ -0,0 +1,24 @@
+// Package apitesting starts the apitest fake Threads API for Go tests.
+//
+// It is kept apart from apitest, which the dev command links into the CLI,
+// so the release binary does not import the testing package.
+package apitesting
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/apitest"
+)
+
+// Start serves a new apitest.Server on a local port for the duration of the
+// test and sets its URL.
+func Start(t testing.TB) *apitest.Server {
+	t.Helper()
+
+	s := apitest.New()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	s.URL = ts.URL
+	return s
+}
@@ -0,0 +1,164 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+)
+
+// adminPrefix is where a running server accepts control requests, so
+// faults can be injected into a fake started with `threads dev fake-server`.
+const adminPrefix = "/_fake/"
+
+// Fault is a failure returned for matching requests instead of the normal
+// response.
+type Fault struct {
+	// Method matches the request method; empty matches any method.
+	Method string `json:"method,omitempty"`
+	// Path is a path.Match pattern such as "/*/threads_publish".
+	Path string `json:"path"`
+	// Status is the HTTP status to return.
+	Status int `json:"status"`
+	// Code and Message fill the Graph API error body. Defaults depend on
+	// Status.
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	// RetryAfter, in seconds, is sent with rate-limit responses.
+	RetryAfter int `json:"retry_after,omitempty"`
+	// Times is how many requests fail; zero fails until the fault is cleared.
+	Times int `json:"times,omitempty"`
+}
+
+// Inject adds a fault. Faults are checked in the order they were added.
+func (s *Server) Inject(f Fault) error {
+	if _, err := path.Match(f.Path, ""); err != nil {
+		return err
+	}
+	if f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+	return nil
+}
+
+// Fail makes the next times requests matching method and pattern fail with
+// status. A times of zero fails until ClearFaults.
+func (s *Server) Fail(method, pattern string, status, times int) {
+	if err := s.Inject(Fault{Method: method, Path: pattern, Status: status, Times: times}); err != nil {
+		panic("apitest: " + err.Error())
+	}
+}
+
+// RateLimit makes the next times requests matching method and pattern fail
+// with 429 Too Many Requests and a Retry-After of retryAfter.
+func (s *Server) RateLimit(method, pattern string, retryAfter time.Duration, times int) {
+	fault := Fault{
+		Method:     method,
+		Path:       pattern,
+		Status:     http.StatusTooManyRequests,
+		RetryAfter: int(retryAfter / time.Second),
+		Times:      times,
+	}
+	if err := s.Inject(fault); err != nil {
+		panic("apitest: " + err.Error())
+	}
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// matchFaultLocked returns the first fault matching the request and uses up
+// one of its failures.
+func (s *Server) matchFaultLocked(method, requestPath string) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if ok, _ := path.Match(f.Path, requestPath); !ok {
+			continue
+		}
+
+		matched := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return &matched
+	}
+	return nil
+}
+
+func (f *Fault) write(w http.ResponseWriter, now time.Time) {
+	code, message := f.Code, f.Message
+	switch {
+	case f.Status == http.StatusTooManyRequests:
+		if code == 0 {
+			code = 4
+		}
+		if message == "" {
+			message = "Application request limit reached"
+		}
+		w.Header().Set("X-RateLimit-Limit", "200")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Duration(f.RetryAfter)*time.Second).Unix(), 10))
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(f.RetryAfter))
+		}
+	case f.Status == http.StatusUnauthorized:
+		if code == 0 {
+			code = 190
+		}
+	case f.Status >= 500:
+		if code == 0 {
+			code = 2
+		}
+	}
+	if code == 0 {
+		code = 100
+	}
+	if message == "" {
+		message = http.StatusText(f.Status)
+	}
+	writeError(w, f.Status, code, message)
+}
+
+// serveAdmin handles control requests:
+//
+//	POST   /_fake/faults  inject the JSON-encoded Fault in the body
+//	DELETE /_fake/faults  clear all faults
+//	POST   /_fake/reset   restore the seed data
+func (s *Server) serveAdmin(w http.ResponseWriter, r *http.Request, action string) {
+	switch {
+	case action == "faults" && r.Method == http.MethodPost:
+		var f Fault
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			writeError(w, http.StatusBadRequest, 100, "Invalid fault: "+err.Error())
+			return
+		}
+		if err := s.Inject(f); err != nil {
+			writeError(w, http.StatusBadRequest, 100, "Invalid fault path: "+err.Error())
+			return
+		}
+	case action == "faults" && r.Method == http.MethodDelete:
+		s.ClearFaults()
+	case action == "reset" && r.Method == http.MethodPost:
+		s.Reset()
+	default:
+		writeError(w, http.StatusNotFound, 100, "Unknown fake server action")
+		return
+	}
+	writeJSON(w, map[string]any{"success": true})
+}
@@ -0,0 +1,1147 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampFormat is how the Graph API formats timestamps.
+const timestampFormat = "2006-01-02T15:04:05+0000"
+
+// Limits enforced when creating containers.
+const (
+	maxTextLength       = 500
+	minCarouselChildren = 2
+	maxCarouselChildren = 20
+	containerLifetime   = 24 * time.Hour
+	defaultPageSize     = 25
+)
+
+// maxBatchSize is the most requests a batch request may pack.
+const maxBatchSize = 50
+
+// Quotas reported by threads_publishing_limit.
+const (
+	postQuota           = 250
+	replyQuota          = 1000
+	deleteQuota         = 100
+	locationSearchQuota = 500
+	quotaDuration       = 24 * time.Hour
+)
+
+var postMetrics = []string{"views", "likes", "replies", "reposts", "quotes", "shares", "link_clicks", "profile_clicks"}
+
+var accountMetrics = []string{"views", "likes", "replies", "reposts", "quotes", "clicks", "followers_count", "follower_demographics"}
+
+var scopes = []string{
+	"threads_basic",
+	"threads_content_publish",
+	"threads_manage_replies",
+	"threads_manage_insights",
+	"threads_read_replies",
+	"threads_manage_mentions",
+	"threads_keyword_search",
+	"threads_delete",
+	"threads_location_tagging",
+	"threads_profile_discovery",
+}
+
+// route dispatches a request to its handler. The caller holds s.mu.
+func (s *Server) route(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch strings.Join(segments, "/") {
+	case "oauth/access_token":
+		s.handleExchangeCode(w, r)
+		return
+	case "access_token":
+		s.handleLongLivedToken(w, r)
+		return
+	case "refresh_access_token":
+		s.handleRefreshToken(w, r)
+		return
+	case "debug_token":
+		s.handleDebugToken(w, r)
+		return
+	}
+
+	caller, ok := s.authenticateLocked(w, r)
+	if !ok {
+		return
+	}
+
+	if len(segments) == 1 && segments[0] == "" && r.Method == http.MethodPost {
+		s.handleBatch(w, r)
+		return
+	}
+
+	switch len(segments) {
+	case 1:
+		switch segments[0] {
+		case "keyword_search":
+			s.handleKeywordSearch(w, r)
+		case "location_search":
+			s.handleLocationSearch(w, r, caller)
+		case "profile_lookup":
+			s.handleProfileLookup(w, r)
+		case "profile_posts":
+			s.handleProfilePosts(w, r)
+		case "me":
+			s.handleObject(w, r, caller, caller.userID)
+		default:
+			s.handleObject(w, r, caller, segments[0])
+		}
+	case 2:
+		s.handleEdge(w, r, caller, segments[0], segments[1])
+	default:
+		unsupported(w, r)
+	}
+}
+
+// handleBatch answers a batch request by routing each of the requests it
+// packs with the caller's credentials. Only GET requests may be batched.
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var items []struct {
+		Method      string `json:"method"`
+		RelativeURL string `json:"relative_url"`
+	}
+	if err := json.Unmarshal([]byte(r.Form.Get("batch")), &items); err != nil || len(items) == 0 {
+		writeError(w, http.StatusBadRequest, 100, "The parameter batch must be a JSON array of requests")
+		return
+	}
+	if len(items) > maxBatchSize {
+		writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("A batch cannot contain more than %d requests", maxBatchSize))
+		return
+	}
+
+	answers := make([]map[string]any, len(items))
+	for i, item := range items {
+		rec := &batchRecorder{header: http.Header{}, code: http.StatusOK}
+		inner, err := http.NewRequest(item.Method, "/"+strings.TrimPrefix(item.RelativeURL, "/"), nil)
+		if err != nil || inner.Method != http.MethodGet {
+			writeError(rec, http.StatusBadRequest, 100, "Only GET requests can be batched")
+		} else {
+			inner.Header.Set("Authorization", r.Header.Get("Authorization"))
+			_ = inner.ParseForm()
+			if accessToken := r.Form.Get("access_token"); accessToken != "" {
+				inner.Form.Set("access_token", accessToken)
+			}
+			path := strings.TrimPrefix(inner.URL.Path, "/v1.0")
+			s.route(rec, inner, strings.Split(strings.Trim(path, "/"), "/"))
+		}
+		answers[i] = map[string]any{"code": rec.code, "body": rec.body.String()}
+	}
+	writeJSON(w, answers)
+}
+
+// batchRecorder captures the response to one request in a batch. It stands
+// in for httptest.ResponseRecorder, which would link the testing package
+// into the CLI through the dev command.
+type batchRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (r *batchRecorder) Header() http.Header         { return r.header }
+func (r *batchRecorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *batchRecorder) WriteHeader(code int)        { r.code = code }
+
+func (s *Server) handleEdge(w http.ResponseWriter, r *http.Request, caller *token, id, edge string) {
+	if id == "me" {
+		id = caller.userID
+	}
+
+	switch {
+	case edge == "threads" && r.Method == http.MethodPost:
+		s.handleCreateContainer(w, r, caller, id)
+	case edge == "threads" && r.Method == http.MethodGet:
+		s.listUserPosts(w, r, id, func(p *post) bool { return p.replyTo == "" && !p.ghost })
+	case edge == "threads_publish" && r.Method == http.MethodPost:
+		s.handlePublish(w, r, caller, id)
+	case edge == "threads_publishing_limit" && r.Method == http.MethodGet:
+		s.handlePublishingLimit(w, r, caller, id)
+	case edge == "threads_insights" && r.Method == http.MethodGet:
+		s.handleAccountInsights(w, r, caller, id)
+	case edge == "mentions" && r.Method == http.MethodGet:
+		s.handleMentions(w, r, id)
+	case edge == "ghost_posts" && r.Method == http.MethodGet:
+		s.listUserPosts(w, r, id, func(p *post) bool { return p.ghost })
+	case edge == "replies" && r.Method == http.MethodGet:
+		if _, ok := s.users[id]; ok {
+			s.listUserPosts(w, r, id, func(p *post) bool { return p.replyTo != "" })
+			return
+		}
+		s.handleReplies(w, r, id, func(p *post) bool { return p.replyTo == id })
+	case edge == "conversation" && r.Method == http.MethodGet:
+		s.handleReplies(w, r, id, func(p *post) bool { return p.rootID == id })
+	case edge == "insights" && r.Method == http.MethodGet:
+		s.handlePostInsights(w, r, id)
+	case edge == "manage_reply" && r.Method == http.MethodPost:
+		s.handleManageReply(w, r, caller, id)
+	case edge == "repost" && r.Method == http.MethodPost:
+		s.handleRepost(w, caller, id)
+	case edge == "unrepost" && (r.Method == http.MethodPost || r.Method == http.MethodDelete):
+		s.handleUnrepost(w, caller, id)
+	case edge == "subscriptions":
+		s.handleSubscriptions(w, r, id)
+	default:
+		unsupported(w, r)
+	}
+}
+
+// handleObject serves GET and DELETE on a node: a user, post, container or
+// location.
+func (s *Server) handleObject(w http.ResponseWriter, r *http.Request, caller *token, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		var fields map[string]any
+		if u, ok := s.users[id]; ok {
+			fields = s.userFields(u)
+		} else if p, ok := s.posts[id]; ok {
+			fields = s.postFields(p)
+		} else if c, ok := s.containers[id]; ok {
+			fields = s.containerFields(c)
+		} else if l, ok := s.locations[id]; ok {
+			fields = locationFields(l)
+		} else {
+			notFound(w, id)
+			return
+		}
+		writeJSON(w, selectFields(fields, r.Form.Get("fields")))
+	case http.MethodDelete:
+		p, ok := s.posts[id]
+		if !ok {
+			notFound(w, id)
+			return
+		}
+		if p.ownerID != caller.userID {
+			writeError(w, http.StatusForbidden, 10, "Application does not have permission to delete this post")
+			return
+		}
+		delete(s.posts, id)
+		s.recordUsageLocked(caller.userID, "delete")
+		writeJSON(w, map[string]any{"success": true})
+	default:
+		unsupported(w, r)
+	}
+}
+
+func (s *Server) handleCreateContainer(w http.ResponseWriter, r *http.Request, caller *token, userID string) {
+	if userID != caller.userID {
+		writeError(w, http.StatusForbidden, 10, "Cannot create posts for another user")
+		return
+	}
+
+	form := r.Form
+	mediaType := strings.ToUpper(form.Get("media_type"))
+	text := form.Get("text")
+	switch {
+	case mediaType == "":
+		writeError(w, http.StatusBadRequest, 100, "The parameter media_type is required")
+		return
+	case len([]rune(text)) > maxTextLength:
+		writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("Text exceeds the maximum length of %d characters", maxTextLength))
+		return
+	}
+
+	switch mediaType {
+	case "TEXT":
+		if text == "" && form.Get("poll_attachment") == "" && form.Get("link_attachment") == "" {
+			writeError(w, http.StatusBadRequest, 100, "The parameter text is required for text posts")
+			return
+		}
+	case "IMAGE":
+		if form.Get("image_url") == "" {
+			writeError(w, http.StatusBadRequest, 100, "The parameter image_url is required for image posts")
+			return
+		}
+	case "VIDEO":
+		if form.Get("video_url") == "" {
+			writeError(w, http.StatusBadRequest, 100, "The parameter video_url is required for video posts")
+			return
+		}
+	case "CAROUSEL":
+		children := carouselChildren(form)
+		if len(children) < minCarouselChildren || len(children) > maxCarouselChildren {
+			writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("Carousels need between %d and %d children", minCarouselChildren, maxCarouselChildren))
+			return
+		}
+		for _, childID := range children {
+			child, ok := s.containers[childID]
+			if !ok || child.ownerID != caller.userID || child.params.Get("is_carousel_item") != "true" {
+				writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("Invalid carousel item %s", childID))
+				return
+			}
+		}
+	default:
+		writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("Unsupported media_type %s", mediaType))
+		return
+	}
+
+	for _, key := range []string{"reply_to_id", "quote_post_id"} {
+		if id := form.Get(key); id != "" {
+			if _, ok := s.posts[id]; !ok {
+				writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("The parameter %s refers to an unknown post", key))
+				return
+			}
+		}
+	}
+	if id := form.Get("location_id"); id != "" {
+		if _, ok := s.locations[id]; !ok {
+			writeError(w, http.StatusBadRequest, 100, "The parameter location_id refers to an unknown location")
+			return
+		}
+	}
+
+	c := &container{
+		id:        s.newIDLocked(),
+		ownerID:   caller.userID,
+		params:    cloneValues(form),
+		status:    "FINISHED",
+		createdAt: s.now(),
+	}
+	if (mediaType == "IMAGE" || mediaType == "VIDEO") && s.ProcessingPolls > 0 {
+		c.status = "IN_PROGRESS"
+	}
+
+	if mediaType == "TEXT" && form.Get("auto_publish_text") == "true" {
+		writeJSON(w, map[string]any{"id": s.publishLocked(c)})
+		return
+	}
+	s.containers[c.id] = c
+	writeJSON(w, map[string]any{"id": c.id})
+}
+
+func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request, caller *token, userID string) {
+	if userID != caller.userID {
+		writeError(w, http.StatusForbidden, 10, "Cannot publish posts for another user")
+		return
+	}
+
+	c, ok := s.containers[r.Form.Get("creation_id")]
+	if !ok || c.ownerID != caller.userID {
+		writeError(w, http.StatusBadRequest, 100, "The parameter creation_id refers to an unknown container")
+		return
+	}
+	s.expireLocked(c)
+
+	switch {
+	case c.params.Get("is_carousel_item") == "true":
+		writeError(w, http.StatusBadRequest, 100, "Carousel items cannot be published on their own")
+	case c.status == "IN_PROGRESS":
+		writeError(w, http.StatusBadRequest, 9007, "The media is not ready for publishing, please wait for a moment")
+	case c.status != "FINISHED":
+		writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("Container %s cannot be published (status %s)", c.id, c.status))
+	default:
+		writeJSON(w, map[string]any{"id": s.publishLocked(c)})
+	}
+}
+
+// publishLocked turns a container into a post and returns the post ID.
+func (s *Server) publishLocked(c *container) string {
+	mediaTypes := map[string]string{
+		"TEXT":     "TEXT_POST",
+		"IMAGE":    "IMAGE",
+		"VIDEO":    "VIDEO",
+		"CAROUSEL": "CAROUSEL_ALBUM",
+	}
+
+	params := c.params
+	p := &post{
+		ownerID:    c.ownerID,
+		text:       params.Get("text"),
+		mediaType:  mediaTypes[strings.ToUpper(params.Get("media_type"))],
+		mediaURL:   params.Get("image_url") + params.Get("video_url"),
+		altText:    params.Get("alt_text"),
+		linkURL:    params.Get("link_attachment"),
+		topicTag:   params.Get("topic_tag"),
+		locationID: params.Get("location_id"),
+		quotedID:   params.Get("quote_post_id"),
+		children:   carouselChildren(params),
+		ghost:      params.Get("is_ghost_post") == "true",
+	}
+	if parentID := params.Get("reply_to_id"); parentID != "" {
+		s.setReplyLocked(p, parentID)
+	}
+
+	id := s.addPostLocked(p)
+	c.status = "PUBLISHED"
+	c.postID = id
+	if p.replyTo != "" {
+		s.recordUsageLocked(c.ownerID, "reply")
+	} else {
+		s.recordUsageLocked(c.ownerID, "post")
+	}
+	return id
+}
+
+// expireLocked expires containers that were not published in time.
+func (s *Server) expireLocked(c *container) {
+	if c.status != "PUBLISHED" && s.now().Sub(c.createdAt) > containerLifetime {
+		c.status = "EXPIRED"
+		c.errorMessage = "Container expired before it was published"
+	}
+}
+
+func (s *Server) containerFields(c *container) map[string]any {
+	s.expireLocked(c)
+	if c.status == "IN_PROGRESS" {
+		if c.polls >= s.ProcessingPolls {
+			c.status = "FINISHED"
+		} else {
+			c.polls++
+		}
+	}
+
+	fields := map[string]any{"id": c.id, "status": c.status}
+	if c.errorMessage != "" {
+		fields["error_message"] = c.errorMessage
+	}
+	return fields
+}
+
+func (s *Server) handlePublishingLimit(w http.ResponseWriter, r *http.Request, caller *token, userID string) {
+	if userID != caller.userID {
+		writeError(w, http.StatusForbidden, 10, "Cannot read another user's publishing limit")
+		return
+	}
+
+	config := func(total int) map[string]any {
+		return map[string]any{"quota_total": total, "quota_duration": int(quotaDuration / time.Second)}
+	}
+	limits := map[string]any{
+		"quota_usage":                 s.usageLocked(userID, "post"),
+		"config":                      config(postQuota),
+		"reply_quota_usage":           s.usageLocked(userID, "reply"),
+		"reply_config":                config(replyQuota),
+		"delete_quota_usage":          s.usageLocked(userID, "delete"),
+		"delete_config":               config(deleteQuota),
+		"location_search_quota_usage": s.usageLocked(userID, "location_search"),
+		"location_search_config":      config(locationSearchQuota),
+	}
+	writeJSON(w, map[string]any{"data": []any{selectFields(limits, r.Form.Get("fields"))}})
+}
+
+func (s *Server) listUserPosts(w http.ResponseWriter, r *http.Request, userID string, keep func(*post) bool) {
+	if _, ok := s.users[userID]; !ok {
+		notFound(w, userID)
+		return
+	}
+	posts := s.sortedPostsLocked(func(p *post) bool { return p.ownerID == userID && keep(p) })
+	s.writePosts(w, r, filterTime(posts, r.Form), true)
+}
+
+func (s *Server) handleMentions(w http.ResponseWriter, r *http.Request, userID string) {
+	u, ok := s.users[userID]
+	if !ok {
+		notFound(w, userID)
+		return
+	}
+	mention := "@" + strings.ToLower(u.username)
+	posts := s.sortedPostsLocked(func(p *post) bool {
+		return p.ownerID != userID && strings.Contains(strings.ToLower(p.text), mention)
+	})
+	s.writePosts(w, r, posts, true)
+}
+
+func (s *Server) handleReplies(w http.ResponseWriter, r *http.Request, postID string, keep func(*post) bool) {
+	if _, ok := s.posts[postID]; !ok {
+		notFound(w, postID)
+		return
+	}
+	replies := s.sortedPostsLocked(keep)
+	s.writePosts(w, r, replies, r.Form.Get("reverse") != "false")
+}
+
+func (s *Server) handleManageReply(w http.ResponseWriter, r *http.Request, caller *token, replyID string) {
+	reply, ok := s.posts[replyID]
+	if !ok || reply.replyTo == "" {
+		notFound(w, replyID)
+		return
+	}
+	if root, ok := s.posts[reply.rootID]; !ok || root.ownerID != caller.userID {
+		writeError(w, http.StatusForbidden, 10, "Only the author of the conversation can hide replies")
+		return
+	}
+
+	hide, err := strconv.ParseBool(r.Form.Get("hide"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 100, "The parameter hide must be true or false")
+		return
+	}
+	reply.hidden = hide
+	writeJSON(w, map[string]any{"success": true})
+}
+
+func (s *Server) handleRepost(w http.ResponseWriter, caller *token, postID string) {
+	if _, ok := s.posts[postID]; !ok {
+		notFound(w, postID)
+		return
+	}
+	id := s.addPostLocked(&post{ownerID: caller.userID, mediaType: "REPOST_FACADE", repostedID: postID})
+	writeJSON(w, map[string]any{"id": id})
+}
+
+// handleUnrepost removes a repost, given either its ID or the ID of the
+// reposted post.
+func (s *Server) handleUnrepost(w http.ResponseWriter, caller *token, id string) {
+	for _, p := range s.posts {
+		if p.ownerID == caller.userID && p.repostedID != "" && (p.id == id || p.repostedID == id) {
+			delete(s.posts, p.id)
+			writeJSON(w, map[string]any{"success": true})
+			return
+		}
+	}
+	notFound(w, id)
+}
+
+func (s *Server) handlePostInsights(w http.ResponseWriter, r *http.Request, postID string) {
+	p, ok := s.posts[postID]
+	if !ok {
+		notFound(w, postID)
+		return
+	}
+	metrics, ok := requestedMetrics(w, r.Form.Get("metric"), postMetrics)
+	if !ok {
+		return
+	}
+
+	data := make([]any, 0, len(metrics))
+	for _, metric := range metrics {
+		value := p.metrics[metric]
+		switch metric {
+		case "replies":
+			value += s.countLocked(func(q *post) bool { return q.replyTo == postID })
+		case "reposts":
+			value += s.countLocked(func(q *post) bool { return q.repostedID == postID })
+		case "quotes":
+			value += s.countLocked(func(q *post) bool { return q.quotedID == postID })
+		}
+		data = append(data, insight(postID, metric, "lifetime", value, s.now()))
+	}
+	writeJSON(w, map[string]any{"data": data})
+}
+
+func (s *Server) handleAccountInsights(w http.ResponseWriter, r *http.Request, caller *token, userID string) {
+	u, ok := s.users[userID]
+	if !ok {
+		notFound(w, userID)
+		return
+	}
+	if userID != caller.userID {
+		writeError(w, http.StatusForbidden, 10, "Cannot read another user's insights")
+		return
+	}
+	metrics, ok := requestedMetrics(w, r.Form.Get("metric"), accountMetrics)
+	if !ok {
+		return
+	}
+	period := r.Form.Get("period")
+	if period == "" {
+		period = "lifetime"
+	}
+
+	data := make([]any, 0, len(metrics))
+	for _, metric := range metrics {
+		var value int
+		switch metric {
+		case "followers_count":
+			value = u.followers
+		case "follower_demographics":
+		default:
+			for _, p := range s.posts {
+				if p.ownerID != userID {
+					continue
+				}
+				value += p.metrics[metric]
+				switch metric {
+				case "replies":
+					value += s.countLocked(func(q *post) bool { return q.replyTo == p.id })
+				case "reposts":
+					value += s.countLocked(func(q *post) bool { return q.repostedID == p.id })
+				}
+			}
+		}
+		data = append(data, insight(userID, metric, period, value, s.now()))
+	}
+	writeJSON(w, map[string]any{"data": data})
+}
+
+func (s *Server) handleKeywordSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(r.Form.Get("q")))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, 100, "The parameter q is required")
+		return
+	}
+	byTag := strings.EqualFold(r.Form.Get("search_mode"), "TAG")
+	mediaType := strings.ToUpper(r.Form.Get("media_type"))
+	if mediaType == "TEXT" {
+		mediaType = "TEXT_POST"
+	}
+
+	posts := s.sortedPostsLocked(func(p *post) bool {
+		if p.ghost || p.repostedID != "" || (mediaType != "" && p.mediaType != mediaType) {
+			return false
+		}
+		if byTag {
+			return strings.EqualFold(strings.TrimPrefix(p.topicTag, "#"), strings.TrimPrefix(query, "#"))
+		}
+		return strings.Contains(strings.ToLower(p.text), query)
+	})
+	s.writePosts(w, r, filterTime(posts, r.Form), true)
+}
+
+func (s *Server) handleLocationSearch(w http.ResponseWriter, r *http.Request, caller *token) {
+	query := strings.ToLower(strings.TrimSpace(r.Form.Get("q")))
+	lat, latErr := strconv.ParseFloat(r.Form.Get("latitude"), 64)
+	lng, lngErr := strconv.ParseFloat(r.Form.Get("longitude"), 64)
+	if query == "" && (latErr != nil || lngErr != nil) {
+		writeError(w, http.StatusBadRequest, 100, "Provide q, or latitude and longitude")
+		return
+	}
+	s.recordUsageLocked(caller.userID, "location_search")
+
+	data := []any{}
+	for _, l := range s.sortedLocationsLocked() {
+		if query != "" && !strings.Contains(strings.ToLower(l.name+" "+l.city), query) {
+			continue
+		}
+		if latErr == nil && lngErr == nil && (math.Abs(l.latitude-lat) > 1 || math.Abs(l.longitude-lng) > 1) {
+			continue
+		}
+		data = append(data, selectFields(locationFields(l), r.Form.Get("fields")))
+	}
+	writeJSON(w, map[string]any{"data": data})
+}
+
+func (s *Server) handleProfileLookup(w http.ResponseWriter, r *http.Request) {
+	username := r.Form.Get("username")
+	u := s.userByNameLocked(username)
+	if u == nil {
+		notFound(w, username)
+		return
+	}
+
+	profile := map[string]any{
+		"username":            u.username,
+		"name":                u.name,
+		"profile_picture_url": u.profilePictureURL,
+		"biography":           u.biography,
+		"is_verified":         u.verified,
+		"follower_count":      u.followers,
+	}
+	totals := map[string]int{}
+	for _, p := range s.posts {
+		if p.ownerID != u.id {
+			continue
+		}
+		for _, metric := range []string{"likes", "quotes", "replies", "reposts", "views"} {
+			totals[metric] += p.metrics[metric]
+		}
+	}
+	for metric, total := range totals {
+		profile[metric+"_count"] = total
+	}
+	writeJSON(w, selectFields(profile, r.Form.Get("fields")))
+}
+
+func (s *Server) handleProfilePosts(w http.ResponseWriter, r *http.Request) {
+	username := r.Form.Get("username")
+	u := s.userByNameLocked(username)
+	if u == nil {
+		notFound(w, username)
+		return
+	}
+	s.listUserPosts(w, r, u.id, func(p *post) bool { return p.replyTo == "" && !p.ghost })
+}
+
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *http.Request, appID string) {
+	subs := s.subscriptions[appID]
+	switch r.Method {
+	case http.MethodGet:
+		data := []any{}
+		for _, object := range sortedKeys(subs) {
+			sub := subs[object]
+			fields := []any{}
+			for _, name := range sub.fields {
+				fields = append(fields, map[string]any{"name": name, "version": "v1.0"})
+			}
+			data = append(data, map[string]any{
+				"object":       sub.object,
+				"callback_url": sub.callbackURL,
+				"fields":       fields,
+				"active":       true,
+				"created_time": sub.createdAt.UTC().Format(timestampFormat),
+			})
+		}
+		writeJSON(w, map[string]any{"data": data})
+	case http.MethodPost:
+		object, callbackURL := r.Form.Get("object"), r.Form.Get("callback_url")
+		if object == "" || !strings.HasPrefix(callbackURL, "https://") {
+			writeError(w, http.StatusBadRequest, 100, "The parameters object and an https callback_url are required")
+			return
+		}
+		if subs == nil {
+			subs = map[string]*subscription{}
+			s.subscriptions[appID] = subs
+		}
+		subs[object] = &subscription{
+			object:      object,
+			callbackURL: callbackURL,
+			fields:      splitList(r.Form["fields"]),
+			createdAt:   s.now(),
+		}
+		writeJSON(w, map[string]any{"success": true})
+	case http.MethodDelete:
+		object := r.Form.Get("object")
+		if _, ok := subs[object]; !ok && object != "" {
+			notFound(w, object)
+			return
+		}
+		if object == "" {
+			delete(s.subscriptions, appID)
+		} else {
+			delete(subs, object)
+		}
+		writeJSON(w, map[string]any{"success": true})
+	default:
+		unsupported(w, r)
+	}
+}
+
+func (s *Server) handleExchangeCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		unsupported(w, r)
+		return
+	}
+	for _, key := range []string{"client_id", "client_secret", "redirect_uri", "code"} {
+		if r.Form.Get(key) == "" {
+			writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("The parameter %s is required", key))
+			return
+		}
+	}
+	if r.Form.Get("grant_type") != "authorization_code" {
+		writeError(w, http.StatusBadRequest, 100, "Unsupported grant_type")
+		return
+	}
+
+	accessToken := s.issueTokenLocked("fake-short-", DefaultUserID, shortLivedTokenTTL)
+	userID, _ := strconv.ParseInt(DefaultUserID, 10, 64)
+	writeJSON(w, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "bearer",
+		"expires_in":   int(shortLivedTokenTTL / time.Second),
+		"user_id":      userID,
+	})
+}
+
+func (s *Server) handleLongLivedToken(w http.ResponseWriter, r *http.Request) {
+	if r.Form.Get("grant_type") != "th_exchange_token" || r.Form.Get("client_secret") == "" {
+		writeError(w, http.StatusBadRequest, 100, "The parameters grant_type=th_exchange_token and client_secret are required")
+		return
+	}
+	s.writeNewToken(w, r, "fake-long-")
+}
+
+func (s *Server) handleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	if r.Form.Get("grant_type") != "th_refresh_token" {
+		writeError(w, http.StatusBadRequest, 100, "The parameter grant_type=th_refresh_token is required")
+		return
+	}
+	s.writeNewToken(w, r, "fake-refreshed-")
+}
+
+// writeNewToken issues a long-lived token in place of the presented one.
+func (s *Server) writeNewToken(w http.ResponseWriter, r *http.Request, prefix string) {
+	caller, ok := s.authenticateLocked(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, map[string]any{
+		"access_token": s.issueTokenLocked(prefix, caller.userID, longLivedTokenTTL),
+		"token_type":   "bearer",
+		"expires_in":   int(longLivedTokenTTL / time.Second),
+	})
+}
+
+func (s *Server) handleDebugToken(w http.ResponseWriter, r *http.Request) {
+	data := map[string]any{"is_valid": false, "scopes": []string{}}
+	if t, ok := s.tokens[r.Form.Get("input_token")]; ok {
+		data = map[string]any{
+			"type":                   "USER",
+			"application":            "Threads Fake",
+			"is_valid":               s.now().Before(t.expiresAt),
+			"user_id":                t.userID,
+			"issued_at":              t.issuedAt.Unix(),
+			"expires_at":             t.expiresAt.Unix(),
+			"data_access_expires_at": t.expiresAt.Unix(),
+			"scopes":                 scopes,
+		}
+	}
+	writeJSON(w, map[string]any{"data": data})
+}
+
+// authenticateLocked returns the token presented in the Authorization
+// header or access_token parameter, or writes an OAuth error.
+func (s *Server) authenticateLocked(w http.ResponseWriter, r *http.Request) (*token, bool) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if accessToken == "" {
+		accessToken = r.Form.Get("access_token")
+	}
+
+	t, ok := s.tokens[accessToken]
+	switch {
+	case accessToken == "":
+		writeError(w, http.StatusBadRequest, 2500, "An active access token must be used to query information about the current user.")
+		return nil, false
+	case !ok:
+		writeError(w, http.StatusUnauthorized, 190, "Invalid OAuth access token - Cannot parse access token")
+		return nil, false
+	case !s.now().Before(t.expiresAt):
+		writeError(w, http.StatusUnauthorized, 190, "Error validating access token: Session has expired")
+		return nil, false
+	}
+	return t, true
+}
+
+func (s *Server) issueTokenLocked(prefix, userID string, ttl time.Duration) string {
+	accessToken := prefix + s.newIDLocked()
+	s.tokens[accessToken] = &token{userID: userID, issuedAt: s.now(), expiresAt: s.now().Add(ttl)}
+	return accessToken
+}
+
+// recordUsageLocked counts an action against the user's publishing quota.
+func (s *Server) recordUsageLocked(userID, action string) {
+	key := userID + "/" + action
+	s.usage[key] = append(s.usage[key], s.now())
+}
+
+// usageLocked counts the user's actions in the current quota window.
+func (s *Server) usageLocked(userID, action string) int {
+	count := 0
+	for _, at := range s.usage[userID+"/"+action] {
+		if s.now().Sub(at) < quotaDuration {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) countLocked(match func(*post) bool) int {
+	count := 0
+	for _, p := range s.posts {
+		if match(p) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) sortedLocationsLocked() []*location {
+	locations := make([]*location, 0, len(s.locations))
+	for _, id := range sortedKeys(s.locations) {
+		locations = append(locations, s.locations[id])
+	}
+	return locations
+}
+
+// writePosts writes one page of posts, newest first unless newestFirst is
+// false, with cursors for the neighbouring pages.
+func (s *Server) writePosts(w http.ResponseWriter, r *http.Request, posts []*post, newestFirst bool) {
+	if newestFirst {
+		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+			posts[i], posts[j] = posts[j], posts[i]
+		}
+	}
+
+	limit, err := strconv.Atoi(r.Form.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	start, end := 0, len(posts)
+	if after := decodeCursor(r.Form.Get("after")); after != "" {
+		start = indexOf(posts, after) + 1
+	} else if before := decodeCursor(r.Form.Get("before")); before != "" {
+		if end = indexOf(posts, before); end < 0 {
+			end = 0
+		}
+		start = max(end-limit, 0)
+	}
+	end = min(end, start+limit)
+
+	data := make([]any, 0, end-start)
+	for _, p := range posts[start:end] {
+		data = append(data, selectFields(s.postFields(p), r.Form.Get("fields")))
+	}
+
+	paging := map[string]any{}
+	if start < end {
+		cursors := map[string]any{
+			"before": encodeCursor(posts[start].id),
+			"after":  encodeCursor(posts[end-1].id),
+		}
+		paging["cursors"] = cursors
+		if end < len(posts) {
+			paging["next"] = fmt.Sprintf("%s?after=%s", r.URL.Path, cursors["after"])
+		}
+		if start > 0 {
+			paging["previous"] = fmt.Sprintf("%s?before=%s", r.URL.Path, cursors["before"])
+		}
+	}
+	writeJSON(w, map[string]any{"data": data, "paging": paging})
+}
+
+func (s *Server) postFields(p *post) map[string]any {
+	owner := s.users[p.ownerID]
+	shortcode := "F" + p.id
+	fields := map[string]any{
+		"id":                 p.id,
+		"media_product_type": "THREADS",
+		"media_type":         p.mediaType,
+		"permalink":          fmt.Sprintf("https://www.threads.net/@%s/post/%s", owner.username, shortcode),
+		"owner":              map[string]any{"id": p.ownerID},
+		"username":           owner.username,
+		"timestamp":          p.timestamp.Format(timestampFormat),
+		"shortcode":          shortcode,
+		"is_quote_post":      p.quotedID != "",
+		"has_replies":        s.countLocked(func(q *post) bool { return q.replyTo == p.id }) > 0,
+		"reply_audience":     "EVERYONE",
+		"is_reply":           p.replyTo != "",
+	}
+	optional := map[string]string{
+		"text":                p.text,
+		"media_url":           p.mediaURL,
+		"alt_text":            p.altText,
+		"link_attachment_url": p.linkURL,
+		"topic_tag":           p.topicTag,
+	}
+	for key, value := range optional {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	refs := map[string]string{
+		"quoted_post":   p.quotedID,
+		"reposted_post": p.repostedID,
+		"replied_to":    p.replyTo,
+		"root_post":     p.rootID,
+	}
+	for key, id := range refs {
+		if id != "" {
+			fields[key] = map[string]any{"id": id}
+		}
+	}
+	if len(p.children) > 0 {
+		children := make([]any, len(p.children))
+		for i, id := range p.children {
+			children[i] = map[string]any{"id": id}
+		}
+		fields["children"] = map[string]any{"data": children}
+	}
+	if p.replyTo != "" {
+		fields["hide_status"] = "NOT_HUSHED"
+		if p.hidden {
+			fields["hide_status"] = "HIDDEN"
+		}
+	}
+	if p.ghost {
+		fields["ghost_post_status"] = "active"
+		fields["ghost_post_expiration_timestamp"] = p.timestamp.Add(24 * time.Hour).Format(timestampFormat)
+	}
+	return fields
+}
+
+func (s *Server) userFields(u *user) map[string]any {
+	return map[string]any{
+		"id":                          u.id,
+		"username":                    u.username,
+		"name":                        u.name,
+		"threads_profile_picture_url": u.profilePictureURL,
+		"threads_biography":           u.biography,
+		"is_verified":                 u.verified,
+	}
+}
+
+func locationFields(l *location) map[string]any {
+	return map[string]any{
+		"id":          l.id,
+		"name":        l.name,
+		"address":     l.address,
+		"city":        l.city,
+		"country":     l.country,
+		"latitude":    l.latitude,
+		"longitude":   l.longitude,
+		"postal_code": l.postalCode,
+	}
+}
+
+func insight(id, metric, period string, value int, now time.Time) map[string]any {
+	title := strings.ToUpper(metric[:1]) + strings.ReplaceAll(metric[1:], "_", " ")
+	return map[string]any{
+		"id":          fmt.Sprintf("%s/insights/%s/%s", id, metric, period),
+		"name":        metric,
+		"period":      period,
+		"title":       title,
+		"description": fmt.Sprintf("The number of %s.", strings.ReplaceAll(metric, "_", " ")),
+		"values":      []any{map[string]any{"value": value, "end_time": now.UTC().Format(timestampFormat)}},
+		"total_value": map[string]any{"value": value},
+	}
+}
+
+// requestedMetrics validates the comma-separated metric parameter.
+func requestedMetrics(w http.ResponseWriter, param string, valid []string) ([]string, bool) {
+	metrics := splitList([]string{param})
+	if len(metrics) == 0 {
+		writeError(w, http.StatusBadRequest, 100, "The parameter metric is required")
+		return nil, false
+	}
+	for _, metric := range metrics {
+		if !contains(valid, metric) {
+			writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("metric must be one of the following values: %s", strings.Join(valid, ", ")))
+			return nil, false
+		}
+	}
+	return metrics, true
+}
+
+// selectFields keeps only the requested fields, plus the id. Nested field
+// selections such as "children{id}" select the whole field.
+func selectFields(fields map[string]any, param string) map[string]any {
+	if param == "" {
+		return fields
+	}
+	selected := map[string]any{}
+	if id, ok := fields["id"]; ok {
+		selected["id"] = id
+	}
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if i := strings.Index(name, "{"); i >= 0 {
+			name = name[:i]
+		}
+		if value, ok := fields[name]; ok {
+			selected[name] = value
+		}
+	}
+	return selected
+}
+
+// filterTime applies the since and until parameters.
+func filterTime(posts []*post, form url.Values) []*post {
+	since, _ := strconv.ParseInt(form.Get("since"), 10, 64)
+	until, _ := strconv.ParseInt(form.Get("until"), 10, 64)
+	if since == 0 && until == 0 {
+		return posts
+	}
+
+	var filtered []*post
+	for _, p := range posts {
+		ts := p.timestamp.Unix()
+		if (since == 0 || ts >= since) && (until == 0 || ts <= until) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+func carouselChildren(form url.Values) []string {
+	return splitList(form["children"])
+}
+
+// splitList flattens repeated and comma-separated values.
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := url.Values{}
+	for key, vals := range values {
+		if key != "access_token" {
+			clone[key] = append([]string(nil), vals...)
+		}
+	}
+	return clone
+}
+
+func indexOf(posts []*post, id string) int {
+	for i, p := range posts {
+		if p.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func encodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodeCursor(cursor string) string {
+	id, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ""
+	}
+	return string(id)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the Graph API's format.
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"message":    message,
+			"type":       "OAuthException",
+			"code":       code,
+			"fbtrace_id": w.Header().Get("X-Fb-Request-Id"),
+		},
+	})
+}
+
+func notFound(w http.ResponseWriter, id string) {
+	writeError(w, http.StatusNotFound, 100, fmt.Sprintf("Object with ID '%s' does not exist, cannot be loaded due to missing permissions, or does not support this operation", id))
+}
+
+func unsupported(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusBadRequest, 100, fmt.Sprintf("Unsupported %s request", strings.ToLower(r.Method)))
+}
@@ -0,0 +1,399 @@
+// Package apitest provides an in-memory fake of the Threads Graph API.
+//
+// The fake implements the endpoints the api package calls, keeps posts,
+// containers, replies, locations, tokens and webhook subscriptions in memory,
+// and can inject errors or rate-limit responses on demand. Point a client's
+// BaseURL at it to run commands end to end without network access.
+package apitest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Seed values for the account every new server starts with.
+const (
+	DefaultUserID      = "12345"
+	DefaultUsername    = "testuser"
+	DefaultAccessToken = "fake-access-token"
+)
+
+// Token lifetimes reported by the token endpoints.
+const (
+	shortLivedTokenTTL = time.Hour
+	longLivedTokenTTL  = 60 * 24 * time.Hour
+)
+
+// Server is an in-memory Threads API. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of a server started with Start.
+	URL string
+
+	// ProcessingPolls is how many status checks a new media container
+	// reports IN_PROGRESS before it is FINISHED.
+	ProcessingPolls int
+
+	mu            sync.Mutex
+	now           func() time.Time
+	nextID        int64
+	users         map[string]*user
+	posts         map[string]*post
+	containers    map[string]*container
+	locations     map[string]*location
+	tokens        map[string]*token
+	subscriptions map[string]map[string]*subscription
+	usage         map[string][]time.Time
+	faults        []*Fault
+	requests      []Request
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Form   url.Values
+}
+
+// Post is a snapshot of a post held by the server.
+type Post struct {
+	ID        string
+	Username  string
+	Text      string
+	MediaType string
+	ReplyTo   string
+	Hidden    bool
+	Timestamp time.Time
+}
+
+type user struct {
+	id                string
+	username          string
+	name              string
+	biography         string
+	profilePictureURL string
+	verified          bool
+	followers         int
+}
+
+type post struct {
+	id         string
+	ownerID    string
+	text       string
+	mediaType  string
+	mediaURL   string
+	altText    string
+	linkURL    string
+	topicTag   string
+	locationID string
+	replyTo    string
+	rootID     string
+	quotedID   string
+	repostedID string
+	children   []string
+	hidden     bool
+	ghost      bool
+	timestamp  time.Time
+	metrics    map[string]int
+}
+
+type container struct {
+	id           string
+	ownerID      string
+	params       url.Values
+	status       string
+	errorMessage string
+	polls        int
+	postID       string
+	createdAt    time.Time
+}
+
+type location struct {
+	id         string
+	name       string
+	address    string
+	city       string
+	country    string
+	postalCode string
+	latitude   float64
+	longitude  float64
+}
+
+type token struct {
+	userID    string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+type subscription struct {
+	object      string
+	callbackURL string
+	fields      []string
+	createdAt   time.Time
+}
+
+// New returns a server seeded with the DefaultUsername account, whose
+// DefaultAccessToken is accepted, and a sample location.
+func New() *Server {
+	s := &Server{now: time.Now}
+	s.Reset()
+	return s
+}
+
+// Reset discards all state and faults and restores the seed data.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID = 1000000
+	s.users = map[string]*user{}
+	s.posts = map[string]*post{}
+	s.containers = map[string]*container{}
+	s.locations = map[string]*location{}
+	s.tokens = map[string]*token{}
+	s.subscriptions = map[string]map[string]*subscription{}
+	s.usage = map[string][]time.Time{}
+	s.faults = nil
+	s.requests = nil
+
+	s.users[DefaultUserID] = &user{
+		id:        DefaultUserID,
+		username:  DefaultUsername,
+		name:      "Test User",
+		biography: "Fake account served by apitest",
+		followers: 42,
+	}
+	s.tokens[DefaultAccessToken] = &token{
+		userID:    DefaultUserID,
+		issuedAt:  s.now(),
+		expiresAt: s.now().Add(longLivedTokenTTL),
+	}
+	s.addLocationLocked(&location{
+		name:       "Golden Gate Park",
+		address:    "501 Stanyan St",
+		city:       "San Francisco",
+		country:    "US",
+		postalCode: "94117",
+		latitude:   37.7694,
+		longitude:  -122.4862,
+	})
+}
+
+// AddToken makes the server accept accessToken for the default account.
+func (s *Server) AddToken(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[accessToken] = &token{
+		userID:    DefaultUserID,
+		issuedAt:  s.now(),
+		expiresAt: s.now().Add(longLivedTokenTTL),
+	}
+}
+
+// ExpireToken makes accessToken report as expired.
+func (s *Server) ExpireToken(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.tokens[accessToken]; ok {
+		t.expiresAt = s.now().Add(-time.Second)
+	}
+}
+
+// AddUser adds an account and returns its ID. Posts by other accounts can
+// be searched, replied to and looked up by username.
+func (s *Server) AddUser(username string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := &user{id: s.newIDLocked(), username: username, name: username}
+	s.users[u.id] = u
+	return u.id
+}
+
+// AddPost publishes a text post as username, which must have been added,
+// and returns its ID.
+func (s *Server) AddPost(username, text string) string {
+	return s.AddReply(username, "", text)
+}
+
+// AddReply publishes a reply to parentID as username and returns its ID.
+func (s *Server) AddReply(username, parentID, text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owner := s.userByNameLocked(username)
+	if owner == nil {
+		panic(fmt.Sprintf("apitest: unknown user %q", username))
+	}
+	p := &post{ownerID: owner.id, text: text, mediaType: "TEXT_POST"}
+	if parentID != "" {
+		s.setReplyLocked(p, parentID)
+	}
+	return s.addPostLocked(p)
+}
+
+// AddLocation adds a searchable location and returns its ID.
+func (s *Server) AddLocation(name, city, country string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addLocationLocked(&location{name: name, city: city, country: country})
+}
+
+// SetInsight sets a metric reported for a post, such as "views" or "likes".
+// Replies, reposts and quotes are counted from the server's posts.
+func (s *Server) SetInsight(postID, metric string, value int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.posts[postID]; ok {
+		p.metrics[metric] = value
+	}
+}
+
+// Post returns a snapshot of the post with id.
+func (s *Server) Post(id string) (Post, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.posts[id]
+	if !ok {
+		return Post{}, false
+	}
+	return s.snapshotLocked(p), true
+}
+
+// Posts returns every post, oldest first.
+func (s *Server) Posts() []Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	posts := s.sortedPostsLocked(func(*post) bool { return true })
+	snapshots := make([]Post, len(posts))
+	for i, p := range posts {
+		snapshots[i] = s.snapshotLocked(p)
+	}
+	return snapshots
+}
+
+// Requests returns the requests received so far, including injected
+// failures.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Fb-Request-Id", fmt.Sprintf("fake-%d", s.now().UnixNano()))
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, 100, "Invalid request body")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1.0")
+	if strings.HasPrefix(path, adminPrefix) {
+		s.serveAdmin(w, r, strings.TrimPrefix(path, adminPrefix))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.Query(),
+		Form:   r.PostForm,
+	})
+	if fault := s.matchFaultLocked(r.Method, path); fault != nil {
+		fault.write(w, s.now())
+		return
+	}
+
+	s.route(w, r, strings.Split(strings.Trim(path, "/"), "/"))
+}
+
+func (s *Server) newIDLocked() string {
+	s.nextID++
+	return strconv.FormatInt(s.nextID, 10)
+}
+
+func (s *Server) addPostLocked(p *post) string {
+	p.id = s.newIDLocked()
+	p.timestamp = s.now().UTC()
+	if p.metrics == nil {
+		p.metrics = map[string]int{}
+	}
+	s.posts[p.id] = p
+	return p.id
+}
+
+func (s *Server) addLocationLocked(l *location) string {
+	l.id = s.newIDLocked()
+	s.locations[l.id] = l
+	return l.id
+}
+
+func (s *Server) setReplyLocked(p *post, parentID string) {
+	p.replyTo = parentID
+	p.rootID = parentID
+	if parent, ok := s.posts[parentID]; ok && parent.rootID != "" {
+		p.rootID = parent.rootID
+	}
+}
+
+func (s *Server) userByNameLocked(username string) *user {
+	username = strings.TrimPrefix(username, "@")
+	for _, u := range s.users {
+		if strings.EqualFold(u.username, username) {
+			return u
+		}
+	}
+	return nil
+}
+
+// sortedPostsLocked returns the posts matching keep, oldest first.
+func (s *Server) sortedPostsLocked(keep func(*post) bool) []*post {
+	var posts []*post
+	for _, p := range s.posts {
+		if keep(p) {
+			posts = append(posts, p)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		if !posts[i].timestamp.Equal(posts[j].timestamp) {
+			return posts[i].timestamp.Before(posts[j].timestamp)
+		}
+		// IDs are sequential, so a shorter ID is older
+		a, b := posts[i].id, posts[j].id
+		return len(a) < len(b) || (len(a) == len(b) && a < b)
+	})
+	return posts
+}
+
+func (s *Server) snapshotLocked(p *post) Post {
+	snapshot := Post{
+		ID:        p.id,
+		Text:      p.text,
+		MediaType: p.mediaType,
+		ReplyTo:   p.replyTo,
+		Hidden:    p.hidden,
+		Timestamp: p.timestamp,
+	}
+	if owner, ok := s.users[p.ownerID]; ok {
+		snapshot.Username = owner.username
+	}
+	return snapshot
+}
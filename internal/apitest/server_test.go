@@ -0,0 +1,370 @@
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+// start serves a new server for the test. Other packages use
+// apitesting.Start, which cannot be imported here without a cycle.
+func start(t *testing.T) *Server {
+	t.Helper()
+
+	s := New()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	s.URL = ts.URL
+	return s
+}
+
+func newClient(t *testing.T, s *Server) *api.Client {
+	t.Helper()
+
+	config := api.NewConfig()
+	config.ClientID = "test-client-id"
+	config.ClientSecret = "test-client-secret"
+	config.RedirectURI = "https://example.com/callback"
+	config.BaseURL = s.URL
+	config.RetryConfig.MaxRetries = 0
+	config.ContainerPoll = &api.ContainerPollConfig{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     2 * time.Millisecond,
+		Multiplier:      2,
+		MaxAttempts:     10,
+	}
+
+	client, err := api.NewClientWithToken(DefaultAccessToken, config)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestServer_PublishFlow(t *testing.T) {
+	s := start(t)
+	s.ProcessingPolls = 2
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	text, err := client.CreateTextPost(ctx, &api.TextPostContent{Text: "hello fake", TopicTag: "golang"})
+	if err != nil {
+		t.Fatalf("CreateTextPost failed: %v", err)
+	}
+	if text.Text != "hello fake" || text.Username != DefaultUsername || text.MediaType != "TEXT_POST" {
+		t.Errorf("unexpected post: %+v", text)
+	}
+
+	image, err := client.CreateImagePost(ctx, &api.ImagePostContent{ImageURL: "https://example.com/a.jpg", AltText: "a cat"})
+	if err != nil {
+		t.Fatalf("CreateImagePost failed: %v", err)
+	}
+	if image.MediaURL != "https://example.com/a.jpg" || image.AltText != "a cat" {
+		t.Errorf("unexpected image post: %+v", image)
+	}
+
+	reply, err := client.CreateTextPost(ctx, &api.TextPostContent{Text: "a reply", ReplyTo: text.ID})
+	if err != nil {
+		t.Fatalf("reply failed: %v", err)
+	}
+	if got, _ := s.Post(reply.ID); got.ReplyTo != text.ID {
+		t.Errorf("expected a reply to %s, got %+v", text.ID, got)
+	}
+
+	posts, err := client.GetUserPosts(ctx, api.ConvertToUserID(DefaultUserID), nil)
+	if err != nil {
+		t.Fatalf("GetUserPosts failed: %v", err)
+	}
+	if len(posts.Data) != 2 || posts.Data[0].ID != image.ID {
+		t.Errorf("expected both top-level posts, newest first, got %+v", posts.Data)
+	}
+
+	limits, err := client.GetPublishingLimits(ctx)
+	if err != nil {
+		t.Fatalf("GetPublishingLimits failed: %v", err)
+	}
+	if limits.QuotaUsage != 2 || limits.ReplyQuotaUsage != 1 || limits.Config.QuotaTotal != postQuota {
+		t.Errorf("unexpected limits: %+v", limits)
+	}
+
+	if err := client.DeletePost(ctx, api.ConvertToPostID(image.ID)); err != nil {
+		t.Fatalf("DeletePost failed: %v", err)
+	}
+	if _, err := client.GetPost(ctx, api.ConvertToPostID(image.ID)); err == nil {
+		t.Error("expected the deleted post to be gone")
+	}
+}
+
+func TestServer_PublishBeforeProcessing(t *testing.T) {
+	s := start(t)
+	s.ProcessingPolls = 5
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	containerID, err := client.CreateMediaContainer(ctx, "IMAGE", "https://example.com/a.jpg", "")
+	if err != nil {
+		t.Fatalf("CreateMediaContainer failed: %v", err)
+	}
+	status, err := client.GetContainerStatus(ctx, containerID)
+	if err != nil || status.Status != "IN_PROGRESS" {
+		t.Fatalf("expected IN_PROGRESS, got %+v, %v", status, err)
+	}
+
+	resp, err := http.Post(s.URL+"/12345/threads_publish?access_token="+DefaultAccessToken+"&creation_id="+containerID.String(), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected publishing an unprocessed container to fail, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_RepliesAndSearch(t *testing.T) {
+	s := start(t)
+	s.AddUser("alice")
+	root := s.AddPost(DefaultUsername, "Release notes for the fake server")
+	first := s.AddReply("alice", root, "nice work @testuser")
+	nested := s.AddReply(DefaultUsername, first, "thanks")
+	s.AddPost("alice", "unrelated")
+	s.SetInsight(root, "views", 120)
+
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	replies, err := client.GetReplies(ctx, api.ConvertToPostID(root), nil)
+	if err != nil || len(replies.Data) != 1 || replies.Data[0].ID != first {
+		t.Fatalf("expected one direct reply, got %+v, %v", replies, err)
+	}
+	conversation, err := client.GetConversation(ctx, api.ConvertToPostID(root), nil)
+	if err != nil || len(conversation.Data) != 2 {
+		t.Fatalf("expected the whole conversation, got %+v, %v", conversation, err)
+	}
+	if conversation.Data[0].ID != nested {
+		t.Errorf("expected reverse chronological order, got %s first", conversation.Data[0].ID)
+	}
+
+	if err := client.HideReply(ctx, api.ConvertToPostID(first)); err != nil {
+		t.Fatalf("HideReply failed: %v", err)
+	}
+	if got, _ := s.Post(first); !got.Hidden {
+		t.Error("expected the reply to be hidden")
+	}
+
+	mentions, err := client.GetUserMentions(ctx, api.ConvertToUserID(DefaultUserID), nil)
+	if err != nil || len(mentions.Data) != 1 || mentions.Data[0].ID != first {
+		t.Errorf("expected one mention, got %+v, %v", mentions, err)
+	}
+
+	results, err := client.KeywordSearch(ctx, "release", nil)
+	if err != nil || len(results.Data) != 1 || results.Data[0].ID != root {
+		t.Errorf("expected one search result, got %+v, %v", results, err)
+	}
+
+	insights, err := client.GetPostInsights(ctx, api.ConvertToPostID(root), []string{"views", "replies"})
+	if err != nil {
+		t.Fatalf("GetPostInsights failed: %v", err)
+	}
+	values := map[string]int{}
+	for _, insight := range insights.Data {
+		values[insight.Name] = insight.Values[0].Value
+	}
+	if values["views"] != 120 || values["replies"] != 1 {
+		t.Errorf("unexpected insights: %v", values)
+	}
+
+	profile, err := client.LookupPublicProfile(ctx, "@alice")
+	if err != nil || profile.Username != "alice" {
+		t.Errorf("expected alice's profile, got %+v, %v", profile, err)
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	s := start(t)
+	for i := 0; i < 5; i++ {
+		s.AddPost(DefaultUsername, "post")
+	}
+	client := newClient(t, s)
+
+	var seen []string
+	opts := &api.PaginationOptions{Limit: 2}
+	for page := 0; page < 5; page++ {
+		resp, err := client.GetUserPosts(context.Background(), api.ConvertToUserID(DefaultUserID), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range resp.Data {
+			seen = append(seen, p.ID)
+		}
+		if resp.Paging.Cursors == nil || len(resp.Data) < 2 {
+			break
+		}
+		opts.After = resp.Paging.Cursors.After
+	}
+	if len(seen) != 5 {
+		t.Errorf("expected to page through 5 posts, got %v", seen)
+	}
+}
+
+func TestServer_Locations(t *testing.T) {
+	s := start(t)
+	id := s.AddLocation("Ferry Building", "San Francisco", "US")
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	found, err := client.SearchLocations(ctx, "ferry", nil, nil)
+	if err != nil || len(found.Data) != 1 || found.Data[0].ID != id {
+		t.Fatalf("expected one location, got %+v, %v", found, err)
+	}
+	location, err := client.GetLocation(ctx, api.LocationID(id))
+	if err != nil || location.City != "San Francisco" {
+		t.Errorf("unexpected location: %+v, %v", location, err)
+	}
+}
+
+func TestServer_Tokens(t *testing.T) {
+	s := start(t)
+
+	config := api.NewConfig()
+	config.ClientID = "test-client-id"
+	config.ClientSecret = "test-client-secret"
+	config.RedirectURI = "https://example.com/callback"
+	config.BaseURL = s.URL
+	config.RetryConfig.MaxRetries = 0
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := client.ExchangeCodeForToken(ctx, "auth-code"); err != nil {
+		t.Fatalf("ExchangeCodeForToken failed: %v", err)
+	}
+	short := client.GetAccessToken()
+	if err := client.GetLongLivedToken(ctx); err != nil {
+		t.Fatalf("GetLongLivedToken failed: %v", err)
+	}
+	long := client.GetAccessToken()
+	if err := client.RefreshToken(ctx); err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if refreshed := client.GetAccessToken(); refreshed == long || long == short {
+		t.Errorf("expected new tokens, got %q, %q, %q", short, long, refreshed)
+	}
+
+	debug, err := client.DebugToken(ctx, client.GetAccessToken())
+	if err != nil || !debug.Data.IsValid || debug.Data.UserID != DefaultUserID {
+		t.Errorf("expected a valid token, got %+v, %v", debug, err)
+	}
+
+	s.ExpireToken(DefaultAccessToken)
+	if _, err := api.NewClientWithToken(DefaultAccessToken, config); err == nil {
+		t.Error("expected an expired token to be rejected")
+	}
+}
+
+func TestServer_WebhookSubscriptions(t *testing.T) {
+	s := start(t)
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	if _, err := client.SubscribeWebhook(ctx, &api.WebhookSubscribeOptions{
+		CallbackURL: "https://example.com/hook",
+		Fields:      []api.WebhookEventType{"mentions", "publishes"},
+	}); err != nil {
+		t.Fatalf("SubscribeWebhook failed: %v", err)
+	}
+
+	subs, err := client.ListWebhookSubscriptions(ctx)
+	if err != nil || len(subs.Data) != 1 || len(subs.Data[0].Fields) != 2 {
+		t.Fatalf("expected one subscription with two fields, got %+v, %v", subs, err)
+	}
+
+	if err := client.DeleteWebhookSubscription(ctx, "user"); err != nil {
+		t.Fatalf("DeleteWebhookSubscription failed: %v", err)
+	}
+	if subs, _ := client.ListWebhookSubscriptions(ctx); len(subs.Data) != 0 {
+		t.Errorf("expected no subscriptions, got %+v", subs.Data)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	s := start(t)
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	s.Fail(http.MethodPost, "/*/threads_publish", http.StatusServiceUnavailable, 1)
+	if _, err := client.CreateTextPost(ctx, &api.TextPostContent{Text: "first"}); err == nil {
+		t.Fatal("expected the injected failure")
+	}
+	if _, err := client.CreateTextPost(ctx, &api.TextPostContent{Text: "second"}); err != nil {
+		t.Fatalf("expected the fault to be used up, got %v", err)
+	}
+
+	s.RateLimit("", "/12345", 30*time.Second, 0)
+	_, err := client.GetMe(ctx)
+	var rateLimited *api.RateLimitError
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	if rateLimited.RetryAfter != 30*time.Second {
+		t.Errorf("expected Retry-After to be reported, got %v", rateLimited.RetryAfter)
+	}
+	s.ClearFaults()
+
+	// Faults can be injected into a running server over HTTP. A new client
+	// is used because the last one now backs off until the limit resets.
+	client = newClient(t, s)
+	resp, err := http.Post(s.URL+"/_fake/faults", "application/json", bytes.NewBufferString(`{"path":"/keyword_search","status":500,"times":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the fault to be accepted, got %d", resp.StatusCode)
+	}
+	if _, err := client.KeywordSearch(ctx, "anything", nil); err == nil {
+		t.Error("expected the fault injected over HTTP")
+	}
+}
+
+func TestServer_Batch(t *testing.T) {
+	s := start(t)
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	first := s.AddPost(DefaultUsername, "first")
+	second := s.AddPost(DefaultUsername, "second")
+	s.SetInsight(second, "views", 42)
+
+	posts, err := client.GetPostsBatch(ctx, []api.PostID{api.PostID(first), "404404", api.PostID(second)})
+	if err != nil {
+		t.Fatalf("GetPostsBatch failed: %v", err)
+	}
+	if posts[0].Post == nil || posts[0].Post.Text != "first" || posts[2].Post == nil || posts[2].Post.Text != "second" {
+		t.Errorf("unexpected posts: %+v", posts)
+	}
+	if posts[1].Err == nil {
+		t.Error("expected the unknown post to fail")
+	}
+
+	insights, err := client.GetPostInsightsBatch(ctx, []api.PostID{api.PostID(first), api.PostID(second)}, []string{"views"})
+	if err != nil {
+		t.Fatalf("GetPostInsightsBatch failed: %v", err)
+	}
+	if got := insights[1].Insights; got == nil || len(got.Data) != 1 || got.Data[0].Values[0].Value != 42 {
+		t.Errorf("unexpected insights: %+v", insights[1])
+	}
+
+	for _, req := range s.Requests() {
+		if req.Path != "/debug_token" && (req.Method != http.MethodPost || req.Path != "/") {
+			t.Errorf("expected only batch requests, got %s %s", req.Method, req.Path)
+		}
+	}
+}
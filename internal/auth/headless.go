@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AuthURL returns the authorization URL carrying this server's CSRF state,
+// for the user to open in a browser on any device.
+func (s *OAuthServer) AuthURL() string {
+	return s.buildAuthURL()
+}
+
+// CompleteHeadless finishes the OAuth flow without the local callback
+// listener. The input is the full redirect URL the browser was sent to, its
+// query string, or the bare authorization code. Redirect URLs must carry
+// this server's state; a bare code has none to check.
+func (s *OAuthServer) CompleteHeadless(ctx context.Context, input string) (*OAuthResult, error) {
+	code, err := s.parseRedirect(input)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.authCode = code
+	s.mu.Unlock()
+
+	return s.exchangeCodeForToken(ctx, code)
+}
+
+// parseRedirect returns the authorization code from pasted input.
+func (s *OAuthServer) parseRedirect(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errMissingCode
+	}
+
+	if !strings.ContainsAny(input, "?=") {
+		// Threads appends #_ to the code in its redirect
+		return strings.TrimSuffix(input, "#_"), nil
+	}
+
+	raw := input
+	if _, query, ok := strings.Cut(raw, "?"); ok {
+		raw = query
+	}
+	raw, _, _ = strings.Cut(raw, "#")
+	query, err := url.ParseQuery(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	return s.codeFromQuery(query)
+}
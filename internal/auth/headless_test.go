@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/apitest"
+	"github.com/salmonumbrella/threads-cli/internal/apitest/apitesting"
+)
+
+func TestParseRedirect(t *testing.T) {
+	server := NewOAuthServer("client-id", "secret", "http://127.0.0.1:8585/callback", []string{"basic"})
+	state := server.csrfToken
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"redirect URL", "http://127.0.0.1:8585/callback?code=abc123&state=" + state + "#_\n", "abc123", ""},
+		{"query string", "code=abc123&state=" + state, "abc123", ""},
+		{"bare code", "  abc123#_ ", "abc123", ""},
+		{"wrong state", "http://127.0.0.1:8585/callback?code=abc123&state=other", "", "CSRF validation failed"},
+		{"missing state", "http://127.0.0.1:8585/callback?code=abc123", "", "CSRF validation failed"},
+		{"denied", "http://127.0.0.1:8585/callback?state=" + state + "&error=access_denied&error_description=User+denied", "", "access_denied - User denied"},
+		{"no code", "http://127.0.0.1:8585/callback?state=" + state, "", "missing authorization code"},
+		{"empty", "\n", "", "missing authorization code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.parseRedirect(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %q, %v", tt.wantErr, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthURL_CarriesState(t *testing.T) {
+	server := NewOAuthServer("client-id", "secret", "http://127.0.0.1:8585/callback", []string{"basic"})
+
+	u, err := url.Parse(server.AuthURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("state") != server.csrfToken {
+		t.Errorf("expected the CSRF token as state, got %q", u.Query().Get("state"))
+	}
+}
+
+func TestCompleteHeadless_ExchangesCode(t *testing.T) {
+	fake := apitesting.Start(t)
+	server := NewOAuthServer("client-id", "secret", "http://127.0.0.1:8585/callback", []string{"threads_basic"})
+	server.SetBaseURL(fake.URL)
+
+	result, err := server.CompleteHeadless(context.Background(), "http://127.0.0.1:8585/callback?code=abc123&state="+server.csrfToken+"#_")
+	if err != nil {
+		t.Fatalf("CompleteHeadless failed: %v", err)
+	}
+	if !strings.HasPrefix(result.AccessToken, "fake-long-") {
+		t.Errorf("expected the long-lived token, got %q", result.AccessToken)
+	}
+	if result.Username != apitest.DefaultUsername || result.UserID == "" || len(result.Scopes) == 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if server.authCode != "abc123" {
+		t.Errorf("expected authCode=abc123, got %q", server.authCode)
+	}
+
+	if _, err := server.CompleteHeadless(context.Background(), "http://127.0.0.1:8585/callback?code=abc123&state=forged"); err == nil {
+		t.Error("expected a forged state to be rejected")
+	}
+}
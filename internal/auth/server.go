@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/subtle"
+	"crypto/tls"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
@@ -20,12 +22,18 @@ import (
 	"github.com/salmonumbrella/threads-cli/internal/api"
 )
 
+var (
+	errStateMismatch = errors.New("CSRF validation failed")
+	errMissingCode   = errors.New("missing authorization code")
+)
+
 // OAuthResult contains the result of OAuth authentication
 type OAuthResult struct {
 	AccessToken string
 	UserID      string
 	Username    string
 	ExpiresAt   time.Time
+	Scopes      []string // Granted scopes, nil when they could not be read
 }
 
 // OAuthServer handles the browser-based OAuth flow
@@ -34,6 +42,10 @@ type OAuthServer struct {
 	clientSecret string
 	redirectURI  string
 	scopes       []string
+	baseURL      string
+	pkce         *api.PKCE
+	tlsCertFile  string
+	tlsKeyFile   string
 	result       chan *OAuthResult
 	errChan      chan error
 	shutdown     chan struct{}
@@ -61,6 +73,36 @@ func NewOAuthServer(clientID, clientSecret, redirectURI string, scopes []string)
 	}
 }
 
+// SetBaseURL overrides the Threads API base URL used for the token exchange.
+// An empty URL keeps the default.
+func (s *OAuthServer) SetBaseURL(baseURL string) {
+	s.baseURL = baseURL
+}
+
+// EnablePKCE protects the flow with a PKCE code challenge. Call it before
+// building the authorization URL.
+func (s *OAuthServer) EnablePKCE() error {
+	pkce, err := api.NewPKCE()
+	if err != nil {
+		return err
+	}
+	s.pkce = pkce
+	return nil
+}
+
+// SetTLSCertificate sets the certificate and key files served by an https
+// callback. Without them a self-signed certificate is generated.
+func (s *OAuthServer) SetTLSCertificate(certFile, keyFile string) {
+	s.tlsCertFile = certFile
+	s.tlsKeyFile = keyFile
+}
+
+// RedirectURI returns the redirect URI of the flow, with the actual port once
+// Start has bound a dynamic one.
+func (s *OAuthServer) RedirectURI() string {
+	return s.redirectURI
+}
+
 // Start starts the OAuth server and opens the browser
 func (s *OAuthServer) Start(ctx context.Context) (*OAuthResult, error) {
 	// Parse redirect URI to get port
@@ -77,7 +119,16 @@ func (s *OAuthServer) Start(ctx context.Context) (*OAuthResult, error) {
 	defer listener.Close() //nolint:errcheck // Best-effort cleanup
 
 	port := listener.Addr().(*net.TCPAddr).Port
-	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
+	scheme := "http"
+	if u.Scheme == "https" {
+		tlsConfig, errTLS := s.tlsConfig(u.Hostname())
+		if errTLS != nil {
+			return nil, errTLS
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+		scheme = "https"
+	}
+	baseURL := fmt.Sprintf("%s://127.0.0.1:%d", scheme, port)
 
 	// Update redirect URI with actual port if using dynamic port
 	if u.Port() == "0" || u.Port() == "" {
@@ -143,6 +194,10 @@ func (s *OAuthServer) buildAuthURL() string {
 		"response_type": {"code"},
 		"state":         {s.csrfToken},
 	}
+	if s.pkce != nil {
+		params.Set("code_challenge", s.pkce.Challenge)
+		params.Set("code_challenge_method", "S256")
+	}
 	return fmt.Sprintf("https://www.api.net/oauth/authorize?%s", params.Encode())
 }
 
@@ -157,27 +212,17 @@ func (s *OAuthServer) handleRoot(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *OAuthServer) handleCallback(w http.ResponseWriter, r *http.Request) {
-	// Verify state/CSRF token
-	state := r.URL.Query().Get("state")
-	if subtle.ConstantTimeCompare([]byte(state), []byte(s.csrfToken)) != 1 {
-		http.Error(w, "Invalid state parameter", http.StatusForbidden)
-		s.errChan <- fmt.Errorf("CSRF validation failed")
-		return
-	}
-
-	// Check for error
-	if errCode := r.URL.Query().Get("error"); errCode != "" {
-		errDesc := r.URL.Query().Get("error_description")
-		http.Error(w, fmt.Sprintf("Authorization failed: %s", errDesc), http.StatusBadRequest)
-		s.errChan <- fmt.Errorf("authorization denied: %s - %s", errCode, errDesc)
-		return
-	}
-
-	// Get authorization code
-	code := r.URL.Query().Get("code")
-	if code == "" {
-		http.Error(w, "Missing authorization code", http.StatusBadRequest)
-		s.errChan <- fmt.Errorf("missing authorization code")
+	code, err := s.codeFromQuery(r.URL.Query())
+	if err != nil {
+		switch {
+		case errors.Is(err, errStateMismatch):
+			http.Error(w, "Invalid state parameter", http.StatusForbidden)
+		case errors.Is(err, errMissingCode):
+			http.Error(w, "Missing authorization code", http.StatusBadRequest)
+		default:
+			http.Error(w, fmt.Sprintf("Authorization failed: %s", r.URL.Query().Get("error_description")), http.StatusBadRequest)
+		}
+		s.errChan <- err
 		return
 	}
 
@@ -187,7 +232,7 @@ func (s *OAuthServer) handleCallback(w http.ResponseWriter, r *http.Request) {
 
 	// Exchange code for token
 	go func() {
-		result, err := s.exchangeCodeForToken(code)
+		result, err := s.exchangeCodeForToken(context.Background(), code)
 		if err != nil {
 			s.errChan <- err
 			return
@@ -199,12 +244,33 @@ func (s *OAuthServer) handleCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/success", http.StatusTemporaryRedirect)
 }
 
-func (s *OAuthServer) exchangeCodeForToken(code string) (*OAuthResult, error) {
+// codeFromQuery validates the CSRF state of an authorization redirect's query
+// and returns its authorization code.
+func (s *OAuthServer) codeFromQuery(query url.Values) (string, error) {
+	// Verify state/CSRF token
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(s.csrfToken)) != 1 {
+		return "", errStateMismatch
+	}
+
+	// Check for error
+	if errCode := query.Get("error"); errCode != "" {
+		return "", fmt.Errorf("authorization denied: %s - %s", errCode, query.Get("error_description"))
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", errMissingCode
+	}
+	return code, nil
+}
+
+func (s *OAuthServer) exchangeCodeForToken(ctx context.Context, code string) (*OAuthResult, error) {
 	config := &api.Config{
 		ClientID:     s.clientID,
 		ClientSecret: s.clientSecret,
 		RedirectURI:  s.redirectURI,
 		Scopes:       s.scopes,
+		BaseURL:      s.baseURL,
 	}
 
 	client, err := api.NewClient(config)
@@ -212,11 +278,16 @@ func (s *OAuthServer) exchangeCodeForToken(code string) (*OAuthResult, error) {
 		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
+	var opts []api.AuthOption
+	if s.pkce != nil {
+		opts = append(opts, api.WithPKCE(s.pkce))
+	}
+
 	// Exchange code for token
-	if errExchange := client.ExchangeCodeForToken(ctx, code); errExchange != nil {
+	if errExchange := client.ExchangeCodeForToken(ctx, code, opts...); errExchange != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", errExchange)
 	}
 
@@ -232,6 +303,14 @@ func (s *OAuthServer) exchangeCodeForToken(code string) (*OAuthResult, error) {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
 
+	// The user may have declined some of the requested scopes
+	var scopes []string
+	if debugInfo, errDebug := client.DebugToken(ctx, ""); errDebug != nil {
+		slog.Warn("failed to read granted scopes", "error", errDebug)
+	} else {
+		scopes = debugInfo.Data.Scopes
+	}
+
 	tokenInfo := client.GetTokenInfo()
 
 	return &OAuthResult{
@@ -239,6 +318,7 @@ func (s *OAuthServer) exchangeCodeForToken(code string) (*OAuthResult, error) {
 		UserID:      tokenInfo.UserID,
 		Username:    user.Username,
 		ExpiresAt:   tokenInfo.ExpiresAt,
+		Scopes:      scopes,
 	}, nil
 }
 
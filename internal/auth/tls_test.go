@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/apitest/apitesting"
+)
+
+func TestSelfSignedCertificate_VerifiesForHost(t *testing.T) {
+	cert, err := selfSignedCertificate("auth.example.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	for _, name := range []string{"auth.example.test", "localhost", "127.0.0.1"} {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: name, Roots: roots}); err != nil {
+			t.Errorf("expected the certificate to be valid for %s: %v", name, err)
+		}
+	}
+	if time.Until(leaf.NotAfter) > selfSignedValidity {
+		t.Errorf("expected a short-lived certificate, valid until %v", leaf.NotAfter)
+	}
+}
+
+func TestTLSConfig_UserCertificate(t *testing.T) {
+	cert, err := selfSignedCertificate("localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewOAuthServer("client-id", "secret", "https://localhost:8443/callback", []string{"basic"})
+	server.SetTLSCertificate(certFile, keyFile)
+	cfg, err := server.tlsConfig("localhost")
+	if err != nil {
+		t.Fatalf("tlsConfig failed: %v", err)
+	}
+	if string(cfg.Certificates[0].Certificate[0]) != string(cert.Certificate[0]) {
+		t.Error("expected the user-supplied certificate to be served")
+	}
+
+	server.SetTLSCertificate(certFile, "")
+	if _, err := server.tlsConfig("localhost"); err == nil {
+		t.Error("expected a certificate without a key to be rejected")
+	}
+}
+
+func TestStart_HTTPSCallbackWithPKCE(t *testing.T) {
+	fake := apitesting.Start(t)
+
+	// Reserve a free port so the redirect URI is known up front
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	_ = l.Close()
+	redirectURI := fmt.Sprintf("https://127.0.0.1:%d/callback", port)
+
+	server := NewOAuthServer("client-id", "secret", redirectURI, []string{"threads_basic"})
+	server.SetBaseURL(fake.URL)
+	if err := server.EnablePKCE(); err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := url.Parse(server.AuthURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authURL.Query().Get("code_challenge") != server.pkce.Challenge || authURL.Query().Get("code_challenge_method") != "S256" {
+		t.Errorf("expected the PKCE challenge in the authorization URL, got %q", authURL.RawQuery)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	type outcome struct {
+		result *OAuthResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := server.Start(ctx)
+		done <- outcome{result, err}
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, //nolint:gosec // Self-signed test certificate
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		resp, err = client.Get(redirectURI + "?code=abc123&state=" + server.csrfToken)
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 || resp.TLS.PeerCertificates[0].VerifyHostname("127.0.0.1") != nil {
+		t.Error("expected the callback to be served over TLS for 127.0.0.1")
+	}
+
+	got := <-done
+	if got.err != nil {
+		t.Fatalf("Start failed: %v", got.err)
+	}
+	if !strings.HasPrefix(got.result.AccessToken, "fake-long-") {
+		t.Errorf("expected the long-lived token, got %q", got.result.AccessToken)
+	}
+	if server.RedirectURI() != redirectURI {
+		t.Errorf("expected redirect URI %s, got %s", redirectURI, server.RedirectURI())
+	}
+}
@@ -0,0 +1,172 @@
+// Package cache stores API responses on disk so repeated reads of the same
+// post, profile or insights can be answered without a request.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+const dirName = "http"
+
+// Stats summarizes the entries stored for an account.
+type Stats struct {
+	Dir     string    `json:"dir"`
+	Entries int       `json:"entries"`
+	Fresh   int       `json:"fresh"`
+	Expired int       `json:"expired"`
+	Bytes   int64     `json:"bytes"`
+	Oldest  time.Time `json:"oldest,omitempty"`
+	Newest  time.Time `json:"newest,omitempty"`
+}
+
+// Store is a directory of cached responses for one account. Entries are
+// files named after a hash of the request path and query, so an account's
+// entries for a path can be dropped without reading them.
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// DefaultDir returns the default cache location under the cache directory.
+func DefaultDir() string {
+	return filepath.Join(config.CacheDir(), dirName)
+}
+
+// New returns the store for account inside dir.
+func New(dir, account string) *Store {
+	return &Store{dir: filepath.Join(dir, hash(account)), now: time.Now}
+}
+
+// Dir returns the directory holding the account's entries.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Get returns the entry for path and query, or nil when there is none.
+// Unreadable entries are treated as misses.
+func (s *Store) Get(path, query string) (*api.CachedResponse, error) {
+	data, err := os.ReadFile(s.file(path, query))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var entry api.CachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, nil //nolint:nilerr // A corrupt entry is a miss; it is overwritten on the next store
+	}
+	return &entry, nil
+}
+
+// Set stores entry for path and query.
+func (s *Store) Set(path, query string, entry *api.CachedResponse) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.write(s.file(path, query), data)
+}
+
+// Invalidate removes every entry for path, whatever its query.
+func (s *Store) Invalidate(path string) error {
+	matches, err := filepath.Glob(filepath.Join(s.dir, hash(path)+"-*.json"))
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// Stats reports the number, freshness and size of the account's entries.
+func (s *Store) Stats() (Stats, error) {
+	stats := Stats{Dir: s.dir}
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return stats, nil
+		}
+		return stats, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	now := s.now()
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			continue
+		}
+		var entry api.CachedResponse
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+
+		stats.Entries++
+		stats.Bytes += int64(len(data))
+		if now.Before(entry.ExpiresAt) {
+			stats.Fresh++
+		} else {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || entry.StoredAt.Before(stats.Oldest) {
+			stats.Oldest = entry.StoredAt
+		}
+		if entry.StoredAt.After(stats.Newest) {
+			stats.Newest = entry.StoredAt
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes every entry of the account.
+func (s *Store) Clear() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// ClearAll removes the entries of every account stored in dir.
+func ClearAll(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) file(path, query string) string {
+	return filepath.Join(s.dir, hash(path)+"-"+hash(query)+".json")
+}
+
+// write stores an entry atomically so concurrent runs never read a partial
+// file.
+func (s *Store) write(path string, data []byte) error {
+	if err := fsutil.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+func entry(body string, stored time.Time, ttl time.Duration) *api.CachedResponse {
+	return &api.CachedResponse{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": {`"v1"`}},
+		Body:       []byte(body),
+		StoredAt:   stored,
+		ExpiresAt:  stored.Add(ttl),
+	}
+}
+
+func TestStore_GetSet(t *testing.T) {
+	dir := t.TempDir()
+	s := New(dir, "alice")
+
+	got, err := s.Get("/p1", "fields=id")
+	if err != nil || got != nil {
+		t.Fatalf("expected a miss, got %+v, %v", got, err)
+	}
+
+	now := time.Now()
+	if err := s.Set("/p1", "fields=id", entry(`{"id":"p1"}`, now, time.Minute)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	got, err = s.Get("/p1", "fields=id")
+	if err != nil || got == nil {
+		t.Fatalf("expected a hit, got %+v, %v", got, err)
+	}
+	if string(got.Body) != `{"id":"p1"}` || got.Header.Get("ETag") != `"v1"` {
+		t.Errorf("unexpected entry: %+v", got)
+	}
+
+	// Queries and accounts are kept apart.
+	if got, _ := s.Get("/p1", "fields=id,text"); got != nil {
+		t.Error("expected a miss for another query")
+	}
+	if got, _ := New(dir, "bob").Get("/p1", "fields=id"); got != nil {
+		t.Error("expected a miss for another account")
+	}
+}
+
+func TestStore_CorruptEntryIsMiss(t *testing.T) {
+	s := New(t.TempDir(), "alice")
+	if err := s.Set("/p1", "", entry("{}", time.Now(), time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(s.file("/p1", ""), []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Get("/p1", ""); err != nil || got != nil {
+		t.Errorf("expected a corrupt entry to be a miss, got %+v, %v", got, err)
+	}
+}
+
+func TestStore_Invalidate(t *testing.T) {
+	s := New(t.TempDir(), "alice")
+	now := time.Now()
+	for _, key := range [][2]string{{"/p1", "a=1"}, {"/p1", "a=2"}, {"/p2", "a=1"}} {
+		if err := s.Set(key[0], key[1], entry("{}", now, time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Invalidate("/p1"); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if got, _ := s.Get("/p1", "a=1"); got != nil {
+		t.Error("expected /p1 entries to be removed")
+	}
+	if got, _ := s.Get("/p2", "a=1"); got == nil {
+		t.Error("expected /p2 to be kept")
+	}
+
+	// Invalidating a path with no entries is not an error.
+	if err := New(t.TempDir(), "bob").Invalidate("/p1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStore_StatsAndClear(t *testing.T) {
+	dir := t.TempDir()
+	s := New(dir, "alice")
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	stats, err := s.Stats()
+	if err != nil || stats.Entries != 0 {
+		t.Fatalf("expected an empty cache, got %+v, %v", stats, err)
+	}
+
+	if err := s.Set("/p1", "", entry("{}", now.Add(-time.Hour), 2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("/p2", "", entry("{}", now.Add(-2*time.Hour), time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(dir, "bob").Set("/p3", "", entry("{}", now, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err = s.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Entries != 2 || stats.Fresh != 1 || stats.Expired != 1 || stats.Bytes == 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if !stats.Oldest.Equal(now.Add(-2*time.Hour)) || !stats.Newest.Equal(now.Add(-time.Hour)) {
+		t.Errorf("unexpected oldest/newest: %v, %v", stats.Oldest, stats.Newest)
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if stats, _ := s.Stats(); stats.Entries != 0 {
+		t.Errorf("expected the account's cache to be empty, got %+v", stats)
+	}
+	if got, _ := New(dir, "bob").Get("/p3", ""); got == nil {
+		t.Error("expected other accounts to be kept")
+	}
+
+	if err := ClearAll(dir); err != nil {
+		t.Fatalf("ClearAll failed: %v", err)
+	}
+	if got, _ := New(dir, "bob").Get("/p3", ""); got != nil {
+		t.Error("expected every account to be cleared")
+	}
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -38,6 +41,8 @@ func NewAuthCmd(f *Factory) *cobra.Command {
 	cmd.AddCommand(newAuthStatusCmd(f))
 	cmd.AddCommand(newAuthListCmd(f))
 	cmd.AddCommand(newAuthRemoveCmd(f))
+	cmd.AddCommand(newAuthMigrateCmd(f))
+	cmd.AddCommand(newAuthUpgradeCmd(f))
 
 	return cmd
 }
@@ -48,6 +53,25 @@ type authLoginOptions struct {
 	ClientSecret string
 	RedirectURI  string
 	Scopes       []string
+	oauthFlowOptions
+}
+
+// oauthFlowOptions selects how an OAuth authorization is completed.
+type oauthFlowOptions struct {
+	Headless bool
+	PrintURL bool
+	PKCE     bool
+	TLSCert  string
+	TLSKey   string
+}
+
+// bindOAuthFlowFlags registers the flags of oauthFlowOptions on cmd.
+func bindOAuthFlowFlags(cmd *cobra.Command, opts *oauthFlowOptions) {
+	cmd.Flags().BoolVar(&opts.Headless, "headless", false, "Print the authorization URL and read the redirect URL from stdin instead of opening a browser")
+	cmd.Flags().BoolVar(&opts.PrintURL, "print-url", false, "Like --headless, but print only the URL and read the redirect without prompting")
+	cmd.Flags().BoolVar(&opts.PKCE, "pkce", false, "Protect the flow with a PKCE code challenge")
+	cmd.Flags().StringVar(&opts.TLSCert, "tls-cert", "", "Certificate file for an https callback (default: self-signed)")
+	cmd.Flags().StringVar(&opts.TLSKey, "tls-key", "", "Private key file for --tls-cert")
 }
 
 func newAuthLoginCmd(f *Factory) *cobra.Command {
@@ -62,8 +86,29 @@ func newAuthLoginCmd(f *Factory) *cobra.Command {
 		Short:   "Authenticate with Threads via browser",
 		Long: `Opens a browser to authenticate with Threads using OAuth 2.0.
 
-After authentication, your credentials are securely stored in the system keychain.
-Tokens are automatically converted to long-lived tokens (60 days).`,
+After authentication, your credentials are stored in the configured credential
+store (the system keychain by default).
+Tokens are automatically converted to long-lived tokens (60 days).
+
+On machines without a browser (over SSH, in containers), use --headless: the
+authorization URL is printed instead, and after approving it on any device you
+paste the URL the browser was redirected to (or just its code) back into the
+terminal. The redirect page does not need to load. --print-url does the same
+with only the URL on stdout and no prompts, for scripts that forward the URL
+and write the redirect to stdin.
+
+An https redirect URI serves the callback over TLS, with --tls-cert and
+--tls-key or a self-signed certificate the browser asks you to accept once.
+The redirect URI is stored with the account and reused when you log in to it
+again without --redirect-uri. --pkce adds a PKCE code challenge to the flow.`,
+		Example: `  # Log in from a remote shell
+  threads auth login --headless
+
+  # Scripted: the first line of stdout is the URL, the redirect is read from stdin
+  threads auth login --print-url
+
+  # HTTPS callback with PKCE
+  threads auth login --redirect-uri https://localhost:8443/callback --pkce`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runAuthLogin(cmd, f, opts)
 		},
@@ -74,6 +119,7 @@ Tokens are automatically converted to long-lived tokens (60 days).`,
 	cmd.Flags().StringVar(&opts.ClientSecret, "client-secret", "", "Meta App Client Secret (or THREADS_CLIENT_SECRET)")
 	cmd.Flags().StringVar(&opts.RedirectURI, "redirect-uri", "", "OAuth Redirect URI (or THREADS_REDIRECT_URI)")
 	cmd.Flags().StringSliceVar(&opts.Scopes, "scopes", opts.Scopes, "OAuth scopes to request")
+	bindOAuthFlowFlags(cmd, &opts.oauthFlowOptions)
 
 	return cmd
 }
@@ -99,22 +145,25 @@ func runAuthLogin(cmd *cobra.Command, f *Factory, opts *authLoginOptions) error
 		}
 	}
 
-	if redirectURI == "" {
-		redirectURI = "http://127.0.0.1:8585/callback"
-	}
-
 	store, err := f.Store()
 	if err != nil {
 		return FormatError(err)
 	}
 
+	if redirectURI == "" {
+		// Log in again with the redirect URI the account was set up with
+		if existing, errGet := store.Get(opts.Name); errGet == nil && existing != nil {
+			redirectURI = existing.RedirectURI
+		}
+	}
+	if redirectURI == "" {
+		redirectURI = "http://127.0.0.1:8585/callback"
+	}
+
 	ctx := cmd.Context()
 	p := f.UI(ctx)
-	p.Info("Starting authentication flow...")
-	p.Info("Opening browser for Threads authorization...")
 
-	server := auth.NewOAuthServer(clientID, clientSecret, redirectURI, opts.Scopes)
-	result, err := server.Start(ctx)
+	result, redirectURI, err := f.runOAuthFlow(ctx, clientID, clientSecret, redirectURI, opts.Scopes, &opts.oauthFlowOptions)
 	if err != nil {
 		return WrapError("authentication failed", err)
 	}
@@ -129,6 +178,7 @@ func runAuthLogin(cmd *cobra.Command, f *Factory, opts *authLoginOptions) error
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
 		RedirectURI:  redirectURI,
+		Scopes:       result.Scopes,
 	}
 
 	if err := store.Set(opts.Name, creds); err != nil {
@@ -146,6 +196,7 @@ func runAuthLogin(cmd *cobra.Command, f *Factory, opts *authLoginOptions) error
 			"is_expired":        time.Now().After(result.ExpiresAt),
 			"days_until_expiry": time.Until(result.ExpiresAt).Hours() / 24,
 			"scopes":            opts.Scopes,
+			"granted_scopes":    result.Scopes,
 		})
 	}
 
@@ -157,6 +208,60 @@ func runAuthLogin(cmd *cobra.Command, f *Factory, opts *authLoginOptions) error
 	return nil
 }
 
+// runOAuthFlow authorizes scopes for the app in the browser or headless, as
+// opts select, and returns the result and the redirect URI that was used.
+func (f *Factory) runOAuthFlow(ctx context.Context, clientID, clientSecret, redirectURI string, scopes []string, opts *oauthFlowOptions) (*auth.OAuthResult, string, error) {
+	server := auth.NewOAuthServer(clientID, clientSecret, redirectURI, scopes)
+	server.SetBaseURL(os.Getenv("THREADS_BASE_URL"))
+	server.SetTLSCertificate(opts.TLSCert, opts.TLSKey)
+	if opts.PKCE {
+		if err := server.EnablePKCE(); err != nil {
+			return nil, "", fmt.Errorf("failed to set up PKCE: %w", err)
+		}
+	}
+
+	var result *auth.OAuthResult
+	var err error
+	if opts.Headless || opts.PrintURL {
+		result, err = loginHeadless(ctx, server, redirectURI, opts.PrintURL)
+	} else {
+		p := f.UI(ctx)
+		p.Info("Starting authentication flow...")
+		p.Info("Opening browser for Threads authorization...")
+		if strings.HasPrefix(redirectURI, "https://") && opts.TLSCert == "" {
+			p.Info("The callback uses a self-signed certificate; accept the browser warning to finish")
+		}
+		result, err = server.Start(ctx)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return result, server.RedirectURI(), nil
+}
+
+// loginHeadless prints the authorization URL and completes the flow with the
+// redirect URL or code read from stdin. Prompts go to stderr unless quiet,
+// which leaves the URL as the only output before the result.
+func loginHeadless(ctx context.Context, server *auth.OAuthServer, redirectURI string, quiet bool) (*auth.OAuthResult, error) {
+	io := iocontext.GetIO(ctx)
+	if quiet {
+		fmt.Fprintln(io.Out, server.AuthURL()) //nolint:errcheck // Best-effort output
+	} else {
+		fmt.Fprintf(io.ErrOut, "Open this URL in a browser on any device and approve access:\n\n  %s\n\n", server.AuthURL()) //nolint:errcheck // Best-effort output
+		fmt.Fprintf(io.ErrOut, "The browser is then sent to %s, which may fail to load.\n", redirectURI)                     //nolint:errcheck // Best-effort output
+		fmt.Fprint(io.ErrOut, "Paste the full URL from the address bar (or just the code): ")                                //nolint:errcheck // Best-effort output
+	}
+
+	scanner := bufio.NewScanner(io.In)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read the redirect URL: %w", err)
+		}
+		return nil, fmt.Errorf("no redirect URL or code on stdin")
+	}
+	return server.CompleteHeadless(ctx, scanner.Text())
+}
+
 type authTokenOptions struct {
 	Name         string
 	ClientID     string
@@ -216,6 +321,7 @@ func runAuthToken(cmd *cobra.Command, f *Factory, opts *authTokenOptions, args [
 	cfg := &api.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
+		BaseURL:      os.Getenv("THREADS_BASE_URL"),
 		Debug:        f.Debug,
 	}
 	if f.Debug {
@@ -260,6 +366,7 @@ func runAuthToken(cmd *cobra.Command, f *Factory, opts *authTokenOptions, args [
 		CreatedAt:    time.Now(),
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
+		Scopes:       debugInfo.Data.Scopes,
 	}
 
 	if err := store.Set(opts.Name, creds); err != nil {
@@ -276,6 +383,7 @@ func runAuthToken(cmd *cobra.Command, f *Factory, opts *authTokenOptions, args [
 			"expires_at":        expiresAt,
 			"is_expired":        time.Now().After(expiresAt),
 			"days_until_expiry": time.Until(expiresAt).Hours() / 24,
+			"scopes":            debugInfo.Data.Scopes,
 		})
 	}
 
@@ -333,33 +441,12 @@ func runAuthRefresh(cmd *cobra.Command, f *Factory) error {
 		}
 	}
 
-	cfg := &api.Config{
-		ClientID:     creds.ClientID,
-		ClientSecret: creds.ClientSecret,
-		Debug:        f.Debug,
-	}
-	if f.Debug {
-		cfg.Logger = f.logger()
-	}
-
-	client, err := f.NewClient(creds.AccessToken, cfg)
-	if err != nil {
-		return WrapError("failed to create client", err)
-	}
-
 	ctx := cmd.Context()
-	if err := client.RefreshToken(ctx); err != nil {
+	creds, err = f.tokenRefresher(store).Refresh(ctx, account)
+	if err != nil {
 		return WrapError("failed to refresh token", err)
 	}
 
-	tokenInfo := client.GetTokenInfo()
-	creds.AccessToken = tokenInfo.AccessToken
-	creds.ExpiresAt = tokenInfo.ExpiresAt
-
-	if err := store.Set(account, *creds); err != nil {
-		return WrapError("failed to update stored credentials", err)
-	}
-
 	io := iocontext.GetIO(ctx)
 	if outfmt.IsJSON(ctx) {
 		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
@@ -439,6 +526,7 @@ func runAuthStatus(cmd *cobra.Command, f *Factory) error {
 			"expires_at":        creds.ExpiresAt,
 			"is_expired":        creds.IsExpired(),
 			"days_until_expiry": creds.DaysUntilExpiry(),
+			"scopes":            creds.Scopes,
 		})
 	}
 
@@ -462,6 +550,9 @@ func runAuthStatus(cmd *cobra.Command, f *Factory) error {
 		days := creds.DaysUntilExpiry()
 		fmt.Fprintf(io.Out, "Expires:  %s (%s)\n", creds.ExpiresAt.Format("2006-01-02 15:04"), ui.FormatDuration(days)) //nolint:errcheck // Best-effort output
 	}
+	if creds.Scopes != nil {
+		fmt.Fprintf(io.Out, "Scopes:   %s\n", strings.Join(creds.Scopes, ", ")) //nolint:errcheck // Best-effort output
+	}
 
 	return nil
 }
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/apitest"
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+func TestAuthLogin_PrintURL(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	t.Setenv("THREADS_BASE_URL", server.URL)
+	store := &mockCredentialsStore{}
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	// A bare code skips the state check, so it can be written before the URL is known
+	streams.In = bytes.NewBufferString("abc123#_\n")
+	out, err := runRoot(t, f, streams, "auth", "login", "--print-url", "--client-id", "app", "--client-secret", "app-secret", "-n", "remote", "-o", "json")
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	authURL, result, _ := strings.Cut(out, "\n")
+	u, err := url.Parse(authURL)
+	if err != nil || u.Query().Get("client_id") != "app" || len(u.Query().Get("state")) != 64 {
+		t.Errorf("expected the authorization URL on the first line, got %q", authURL)
+	}
+	if !strings.Contains(result, `"username": "`+apitest.DefaultUsername+`"`) {
+		t.Errorf("expected the login result after the URL, got %q", result)
+	}
+	if streams.ErrOut.(*bytes.Buffer).Len() != 0 {
+		t.Errorf("expected no prompts, got %q", streams.ErrOut)
+	}
+
+	if store.creds == nil || !strings.HasPrefix(store.creds.AccessToken, "fake-long-") || store.creds.Name != "remote" || store.creds.ClientSecret != "app-secret" {
+		t.Errorf("expected long-lived credentials to be stored, got %+v", store.creds)
+	}
+}
+
+func TestAuthLogin_HeadlessRejectsForgedState(t *testing.T) {
+	_, f, streams := newFakeServerFactory(t)
+	store := &mockCredentialsStore{}
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	streams.In = bytes.NewBufferString("http://127.0.0.1:8585/callback?code=abc123&state=forged\n")
+	_, err := runRoot(t, f, streams, "auth", "login", "--headless", "--client-id", "app", "--client-secret", "app-secret")
+	if err == nil || !strings.Contains(err.Error(), "CSRF validation failed") {
+		t.Fatalf("expected a CSRF error, got %v", err)
+	}
+	if !strings.Contains(streams.ErrOut.(*bytes.Buffer).String(), "https://www.api.net/oauth/authorize?") {
+		t.Errorf("expected the authorization URL on stderr, got %q", streams.ErrOut)
+	}
+	if store.creds != nil {
+		t.Error("expected nothing to be stored")
+	}
+
+	streams.In = &bytes.Buffer{}
+	if _, err := runRoot(t, f, streams, "auth", "login", "--headless", "--client-id", "app", "--client-secret", "app-secret"); err == nil || !strings.Contains(err.Error(), "no redirect URL") {
+		t.Errorf("expected an error for empty stdin, got %v", err)
+	}
+}
+
+func TestAuthLogin_ReusesStoredRedirectURI(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	t.Setenv("THREADS_BASE_URL", server.URL)
+	t.Setenv("THREADS_REDIRECT_URI", "")
+	store := &mockCredentialsStore{creds: testCredentials()}
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	streams.In = bytes.NewBufferString("abc123\n")
+	out, err := runRoot(t, f, streams, "auth", "login", "--print-url", "--pkce", "--client-id", "app", "--client-secret", "app-secret", "-n", "test-user")
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	authURL, _, _ := strings.Cut(out, "\n")
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Query().Get("redirect_uri"); got != "https://example.com/callback" {
+		t.Errorf("expected the account's redirect URI, got %q", got)
+	}
+	if u.Query().Get("code_challenge") == "" || u.Query().Get("code_challenge_method") != "S256" {
+		t.Errorf("expected a PKCE challenge, got %q", u.RawQuery)
+	}
+	if store.creds.RedirectURI != "https://example.com/callback" {
+		t.Errorf("expected the redirect URI to be stored again, got %q", store.creds.RedirectURI)
+	}
+}
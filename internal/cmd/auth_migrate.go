@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+type authMigrateOptions struct {
+	To   string
+	Keep bool
+}
+
+func newAuthMigrateCmd(f *Factory) *cobra.Command {
+	opts := &authMigrateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "migrate --to <store>",
+		Short: "Move stored accounts to another credential store",
+		Long: `Copy every stored account from the current credential store to another one,
+switch the config to the new store, and remove the accounts from the old one.
+
+Tokens and client secrets are moved as they are, so no new login is needed.
+Stores: ` + strings.Join(secrets.Backends, ", ") + `.`,
+		Example: `  # Move to an encrypted file on a headless server
+  THREADS_KEYRING_PASSWORD=... threads auth migrate --to file
+
+  # Move back to the system keychain, keeping the file as a backup
+  threads auth migrate --to keyring --keep`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuthMigrate(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.To, "to", "", "Credential store to move accounts to: "+strings.Join(secrets.Backends, "|"))
+	cmd.Flags().BoolVar(&opts.Keep, "keep", false, "Keep the accounts in the old store")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func runAuthMigrate(cmd *cobra.Command, f *Factory, opts *authMigrateOptions) error {
+	var settings *config.SecretsConfig
+	if f.Config != nil {
+		settings = f.Config.Secrets
+	}
+	from := secrets.BackendName(settings)
+	to := strings.ToLower(strings.TrimSpace(opts.To))
+
+	if !slices.Contains(secrets.Backends, to) {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Unknown credential store: %s", opts.To),
+			Suggestion: "Use one of: " + strings.Join(secrets.Backends, ", "),
+		}
+	}
+	if to == from {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Accounts are already stored in the %s store", to),
+			Suggestion: "Pass the store to move to with --to",
+		}
+	}
+
+	source, err := f.Store()
+	if err != nil {
+		return FormatError(err)
+	}
+	dest, err := secrets.OpenBackend(to, settings)
+	if err != nil {
+		return WrapError(fmt.Sprintf("failed to open the %s store", to), err)
+	}
+
+	names, err := source.List()
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Copy everything before switching, so a failure leaves the old store in use
+	for _, name := range names {
+		creds, err := source.Get(name)
+		if err != nil {
+			return WrapError(fmt.Sprintf("failed to read account %q", name), err)
+		}
+		if err := dest.Set(name, *creds); err != nil {
+			return WrapError(fmt.Sprintf("failed to copy account %q to the %s store", name, to), err)
+		}
+	}
+
+	fileCfg, err := config.LoadFile(config.ConfigPath())
+	if err != nil {
+		return WrapError("failed to load config", err)
+	}
+	if fileCfg.Secrets == nil {
+		fileCfg.Secrets = &config.SecretsConfig{}
+	}
+	fileCfg.Secrets.Backend = to
+	if err := config.Save(fileCfg); err != nil {
+		return WrapError("failed to save config", err)
+	}
+
+	ctx := cmd.Context()
+	p := f.UI(ctx)
+
+	var notRemoved []string
+	if !opts.Keep {
+		for _, name := range names {
+			if err := source.Delete(name); err != nil {
+				notRemoved = append(notRemoved, name)
+			}
+		}
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(map[string]any{
+			"from":        from,
+			"to":          to,
+			"accounts":    names,
+			"kept":        opts.Keep,
+			"not_removed": notRemoved,
+		})
+	}
+
+	p.Success("Moved %d account(s) from the %s store to the %s store", len(names), from, to)
+	for _, name := range names {
+		fmt.Fprintf(io.Out, "  %s\n", name) //nolint:errcheck // Best-effort output
+	}
+	if len(notRemoved) > 0 {
+		p.Warning("Could not remove from the %s store: %s", from, strings.Join(notRemoved, ", "))
+	}
+	if env := os.Getenv("THREADS_SECRETS_BACKEND"); env != "" && !strings.EqualFold(env, to) {
+		p.Warning("THREADS_SECRETS_BACKEND=%s overrides the config; unset it to use the %s store", env, to)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+func TestAuthMigrate_ToFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("THREADS_CONFIG", filepath.Join(dir, "config.json"))
+	t.Setenv("THREADS_SECRETS_BACKEND", "")
+	t.Setenv(secrets.PasswordEnv, "migrate-passphrase")
+
+	f, streams := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+	if _, err := runRoot(t, f, streams, "auth", "migrate", "--to", "file", "-o", "json"); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	store, err := secrets.OpenBackend(secrets.BackendFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := store.Get("test-user")
+	if err != nil {
+		t.Fatalf("expected the account in the file store: %v", err)
+	}
+	want := testCredentials()
+	if creds.AccessToken != want.AccessToken || creds.ClientSecret != want.ClientSecret || creds.UserID != want.UserID {
+		t.Errorf("unexpected migrated credentials %+v", creds)
+	}
+
+	saved, err := config.LoadFile(config.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secrets.BackendName(saved.Secrets) != secrets.BackendFile {
+		t.Errorf("expected the config to switch to the file store, got %+v", saved.Secrets)
+	}
+}
+
+func TestAuthMigrate_Validation(t *testing.T) {
+	f := newTestFactory(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--to", "keyring"}, "already stored"},
+		{[]string{"--to", "floppy"}, "Unknown credential store"},
+	}
+	for _, tt := range tests {
+		cmd := newAuthMigrateCmd(f)
+		cmd.SetContext(outfmt.WithFormat(t.Context(), "text"))
+		cmd.SetArgs(tt.args)
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
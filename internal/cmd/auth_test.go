@@ -21,6 +21,8 @@ func TestAuthCmd_Structure(t *testing.T) {
 		"status":  true,
 		"list":    true,
 		"remove":  true,
+		"migrate": true,
+		"upgrade": true,
 	}
 
 	for _, sub := range cmd.Commands() {
@@ -49,6 +51,8 @@ func TestAuthLoginCmd_Flags(t *testing.T) {
 		{"client-secret", ""},
 		{"redirect-uri", ""},
 		{"scopes", ""},
+		{"headless", ""},
+		{"print-url", ""},
 	}
 
 	for _, flag := range flags {
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+type authUpgradeOptions struct {
+	Scopes []string
+	oauthFlowOptions
+}
+
+func newAuthUpgradeCmd(f *Factory) *cobra.Command {
+	opts := &authUpgradeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "upgrade --scopes <scope,...>",
+		Short: "Grant additional OAuth scopes to an account",
+		Long: `Re-run the OAuth flow for an account, asking only for the scopes it has not
+granted yet. The account keeps its app credentials and redirect URI, and its
+token is replaced by one carrying the new permissions.
+
+Commands that need a scope the account lacks fail before calling the API and
+name the scope to upgrade with. 'threads auth status' lists the granted scopes.`,
+		Example: `  # Allow deleting posts and keyword search
+  threads auth upgrade --scopes threads_delete,threads_keyword_search
+
+  # Upgrade another account from a remote shell
+  threads auth upgrade -a work --scopes threads_manage_insights --headless`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuthUpgrade(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.Scopes, "scopes", nil, "OAuth scopes to add")
+	bindOAuthFlowFlags(cmd, &opts.oauthFlowOptions)
+	_ = cmd.MarkFlagRequired("scopes")
+
+	return cmd
+}
+
+func runAuthUpgrade(cmd *cobra.Command, f *Factory, opts *authUpgradeOptions) error {
+	account, err := f.resolveAccount()
+	if err != nil {
+		return err
+	}
+	store, err := f.Store()
+	if err != nil {
+		return FormatError(err)
+	}
+	creds, err := store.Get(account)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if creds.ClientID == "" || creds.ClientSecret == "" {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Account %q has no app credentials to request scopes with", account),
+			Suggestion: fmt.Sprintf("Run 'threads auth login --name %s --scopes ...' with your app's client ID and secret instead", account),
+		}
+	}
+
+	var requested []string
+	for _, scope := range opts.Scopes {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			requested = append(requested, scope)
+		}
+	}
+
+	ctx := cmd.Context()
+	io := iocontext.GetIO(ctx)
+	p := f.UI(ctx)
+
+	additional := additionalScopes(creds, requested)
+	if len(additional) == 0 {
+		if outfmt.IsJSON(ctx) {
+			out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+			return out.Output(map[string]any{
+				"account": account,
+				"added":   []string{},
+				"missing": []string{},
+				"scopes":  creds.Scopes,
+			})
+		}
+		p.Success("Account %q already has %s", account, strings.Join(requested, ", "))
+		return nil
+	}
+
+	redirectURI := creds.RedirectURI
+	if redirectURI == "" {
+		redirectURI = "http://127.0.0.1:8585/callback"
+	}
+
+	result, redirectURI, err := f.runOAuthFlow(ctx, creds.ClientID, creds.ClientSecret, redirectURI, additional, &opts.oauthFlowOptions)
+	if err != nil {
+		return WrapError("authentication failed", err)
+	}
+
+	// Permissions are granted per user, so a different login must not
+	// replace this account's token
+	if creds.UserID != "" && result.UserID != creds.UserID {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Authorized as @%s, not @%s of account %q", result.Username, creds.Username, account),
+			Suggestion: fmt.Sprintf("Log in to Threads as @%s in the browser and run the upgrade again", creds.Username),
+		}
+	}
+
+	updated := *creds
+	updated.AccessToken = result.AccessToken
+	updated.ExpiresAt = result.ExpiresAt
+	updated.RedirectURI = redirectURI
+	updated.Scopes = result.Scopes
+	verified := result.Scopes != nil
+	if !verified && creds.Scopes != nil {
+		// The granted scopes could not be read. Assume the requested ones
+		// were granted rather than storing unknown scopes, which would turn
+		// scope checks off for the account.
+		updated.Scopes = slices.Concat(creds.Scopes, additional)
+	}
+	if err := store.Set(account, updated); err != nil {
+		return WrapError("failed to store credentials", err)
+	}
+
+	// The user may decline scopes on the consent screen
+	added, missing, unverified := []string{}, []string{}, []string{}
+	if verified {
+		missing = updated.MissingScopes(additional)
+		for _, scope := range additional {
+			if !slices.Contains(missing, scope) {
+				added = append(added, scope)
+			}
+		}
+	} else {
+		unverified = additional
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if missing == nil {
+			missing = []string{}
+		}
+		return out.Output(map[string]any{
+			"account":    account,
+			"added":      added,
+			"missing":    missing,
+			"unverified": unverified,
+			"scopes":     updated.Scopes,
+			"expires_at": updated.ExpiresAt,
+		})
+	}
+
+	if len(added) > 0 {
+		p.Success("Granted %s to account %q", strings.Join(added, ", "), account)
+	}
+	if len(missing) > 0 {
+		p.Warning("Not granted: %s", strings.Join(missing, ", "))
+	}
+	if len(unverified) > 0 {
+		p.Warning("Could not read the granted scopes; assuming %s were granted", strings.Join(unverified, ", "))
+	}
+	fmt.Fprintf(io.Out, "  Expires:  %s (%.0f days)\n", updated.ExpiresAt.Format("2006-01-02"), time.Until(updated.ExpiresAt).Hours()/24) //nolint:errcheck // Best-effort output
+
+	return nil
+}
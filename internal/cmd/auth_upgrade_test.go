@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+func TestAuthUpgrade_RequestsOnlyAdditionalScopes(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	t.Setenv("THREADS_BASE_URL", server.URL)
+	store := &mockCredentialsStore{creds: testCredentials()}
+	store.creds.Scopes = []string{scopeBasic, scopeContentPublish}
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	streams.In = bytes.NewBufferString("abc123\n")
+	out, err := runRoot(t, f, streams, "auth", "upgrade", "--scopes", "threads_basic,threads_delete", "--print-url", "-o", "json")
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+
+	authURL, result, _ := strings.Cut(out, "\n")
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Query().Get("scope"); got != scopeDelete {
+		t.Errorf("expected only the additional scope to be requested, got %q", got)
+	}
+	if u.Query().Get("client_id") != "test-client-id" || u.Query().Get("redirect_uri") != "https://example.com/callback" {
+		t.Errorf("expected the account's app and redirect URI, got %q", u.RawQuery)
+	}
+	if !strings.Contains(result, `"added": [`+"\n"+`    "threads_delete"`) {
+		t.Errorf("expected threads_delete to be reported as added, got %s", result)
+	}
+
+	if !strings.HasPrefix(store.creds.AccessToken, "fake-long-") || !slices.Contains(store.creds.Scopes, scopeDelete) {
+		t.Errorf("expected the upgraded token and scopes to be stored, got %+v", store.creds)
+	}
+	if store.creds.ClientSecret != "test-client-secret" {
+		t.Error("expected the app credentials to be kept")
+	}
+}
+
+func TestAuthUpgrade_KeepsScopesWhenGrantsUnreadable(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	t.Setenv("THREADS_BASE_URL", server.URL)
+	server.Fail("GET", "/debug_token", http.StatusBadRequest, 0)
+	store := &mockCredentialsStore{creds: testCredentials()}
+	store.creds.Scopes = []string{scopeBasic, scopeContentPublish}
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	streams.In = bytes.NewBufferString("abc123\n")
+	out, err := runRoot(t, f, streams, "auth", "upgrade", "--scopes", "threads_delete", "--print-url", "-o", "json")
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+
+	_, result, _ := strings.Cut(out, "\n")
+	if !strings.Contains(result, `"added": []`) || !strings.Contains(result, `"unverified": [`+"\n"+`    "threads_delete"`) {
+		t.Errorf("expected threads_delete to be reported as unverified, got %s", result)
+	}
+	want := []string{scopeBasic, scopeContentPublish, scopeDelete}
+	if !slices.Equal(store.creds.Scopes, want) {
+		t.Errorf("expected scopes %v to be stored, got %v", want, store.creds.Scopes)
+	}
+}
+
+func TestAuthUpgrade_AlreadyGranted(t *testing.T) {
+	_, f, streams := newFakeServerFactory(t)
+	store := &mockCredentialsStore{creds: testCredentials()}
+	store.creds.Scopes = []string{scopeBasic, scopeDelete}
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	out, err := runRoot(t, f, streams, "auth", "upgrade", "--scopes", "threads_delete", "--print-url", "-o", "json")
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if strings.Contains(out, "oauth/authorize") || !strings.Contains(out, `"added": []`) {
+		t.Errorf("expected no OAuth flow, got %s", out)
+	}
+	if store.creds.AccessToken != testCredentials().AccessToken {
+		t.Error("expected the token to be kept")
+	}
+}
+
+func TestAuthUpgrade_RejectsOtherUser(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	t.Setenv("THREADS_BASE_URL", server.URL)
+	store := &mockCredentialsStore{creds: testCredentials()}
+	store.creds.UserID = "99999"
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	streams.In = bytes.NewBufferString("abc123\n")
+	_, err := runRoot(t, f, streams, "auth", "upgrade", "--scopes", "threads_delete", "--print-url")
+	var ufe *UserFriendlyError
+	if !errors.As(err, &ufe) || !strings.Contains(ufe.Message, "Authorized as @") {
+		t.Fatalf("expected a different-user error, got %v", err)
+	}
+	if store.creds.AccessToken != testCredentials().AccessToken {
+		t.Error("expected the token to be kept")
+	}
+}
+
+func TestClient_MissingScopeFailsFast(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	store := &mockCredentialsStore{creds: testCredentials()}
+	store.creds.Scopes = []string{scopeBasic, scopeContentPublish}
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	_, err := runRoot(t, f, streams, "posts", "delete", "1234567890", "-y")
+	var ufe *UserFriendlyError
+	if !errors.As(err, &ufe) {
+		t.Fatalf("expected a UserFriendlyError, got %v", err)
+	}
+	if !strings.Contains(ufe.Message, scopeDelete) || !strings.Contains(ufe.Suggestion, "threads auth upgrade --account test-user --scopes threads_delete") {
+		t.Errorf("expected the missing scope and upgrade command, got %q / %q", ufe.Message, ufe.Suggestion)
+	}
+	if len(server.Requests()) != 0 {
+		t.Errorf("expected no API calls, got %d", len(server.Requests()))
+	}
+
+	// Granted scopes pass the check
+	if _, err := runRoot(t, f, streams, "me"); err != nil {
+		t.Errorf("me failed: %v", err)
+	}
+}
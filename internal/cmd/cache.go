@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/cache"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+// cacheEndpoints lists cacheable endpoints in display order.
+var cacheEndpoints = []api.CacheEndpoint{api.CachePost, api.CacheUser, api.CacheInsights, api.CacheLocation}
+
+// NewCacheCmd builds the cache command group.
+func NewCacheCmd(f *Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clear the response cache",
+		Long: `Inspect or clear the on-disk cache of API responses.
+
+Posts, profiles, insights and locations are cached per account under the
+cache directory, so repeated reads are answered without a request. Stale
+entries are revalidated with the API and reused when unchanged.
+
+Use --refresh on any command to revalidate cached responses, or --no-cache
+(or THREADS_NO_CACHE=1) to bypass the cache entirely. Cache lifetimes can be
+set per endpoint in the config file:
+
+  "cache": {"ttl": {"post": "1m", "insights": "15m", "location": "0"}}`,
+	}
+
+	cmd.AddCommand(newCacheStatsCmd(f))
+	cmd.AddCommand(newCacheClearCmd(f))
+
+	return cmd
+}
+
+func newCacheStatsCmd(f *Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show cached entries for the active account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			account, err := f.resolveAccount()
+			if err != nil {
+				return err
+			}
+			cfg, err := f.cacheConfig()
+			if err != nil {
+				return err
+			}
+
+			stats, err := cache.New(cache.DefaultDir(), account).Stats()
+			if err != nil {
+				return WrapError("failed to read cache", err)
+			}
+
+			enabled := cfg != nil
+			ttls := make(map[api.CacheEndpoint]string, len(cacheEndpoints))
+			for _, endpoint := range cacheEndpoints {
+				ttls[endpoint] = cacheTTLString(cfg, endpoint)
+			}
+
+			io := iocontext.GetIO(ctx)
+			if outfmt.IsJSON(ctx) {
+				out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+				return out.Output(map[string]any{
+					"account": account,
+					"enabled": enabled,
+					"stats":   stats,
+					"ttl":     ttls,
+				})
+			}
+
+			status := "enabled"
+			if !enabled {
+				status = "disabled"
+			}
+			//nolint:errcheck // Best-effort output
+			fmt.Fprintf(io.Out, "Cache (%s): %s\n", status, stats.Dir)
+			//nolint:errcheck // Best-effort output
+			fmt.Fprintf(io.Out, "Entries: %d (%d fresh, %d expired), %s\n", stats.Entries, stats.Fresh, stats.Expired, formatBytes(stats.Bytes))
+			if stats.Entries > 0 {
+				//nolint:errcheck // Best-effort output
+				fmt.Fprintf(io.Out, "Stored: %s to %s\n", stats.Oldest.Local().Format(time.DateTime), stats.Newest.Local().Format(time.DateTime))
+			}
+			for _, endpoint := range cacheEndpoints {
+				fmt.Fprintf(io.Out, "TTL %s: %s\n", endpoint, ttls[endpoint]) //nolint:errcheck // Best-effort output
+			}
+			return nil
+		},
+	}
+}
+
+func newCacheClearCmd(f *Factory) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove cached responses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var cleared string
+			if all {
+				if err := cache.ClearAll(cache.DefaultDir()); err != nil {
+					return WrapError("failed to clear cache", err)
+				}
+				cleared = "all accounts"
+			} else {
+				account, err := f.resolveAccount()
+				if err != nil {
+					return err
+				}
+				if err := cache.New(cache.DefaultDir(), account).Clear(); err != nil {
+					return WrapError("failed to clear cache", err)
+				}
+				cleared = account
+			}
+
+			io := iocontext.GetIO(ctx)
+			if outfmt.IsJSON(ctx) {
+				out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+				return out.Output(map[string]any{
+					"cleared": cleared,
+				})
+			}
+			f.UI(ctx).Success("Cleared cached responses for %s", cleared)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Clear the cache of every account")
+	return cmd
+}
+
+// cacheTTLString describes how long responses of endpoint are cached.
+func cacheTTLString(cfg *api.CacheConfig, endpoint api.CacheEndpoint) string {
+	if cfg == nil {
+		return "off"
+	}
+	ttl, ok := cfg.TTLs[endpoint]
+	if !ok {
+		ttl = api.DefaultCacheTTLs[endpoint]
+	}
+	if ttl <= 0 {
+		return "off"
+	}
+	return ttl.String()
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/cache"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+func TestCacheCmd_StatsAndClear(t *testing.T) {
+	setTestDataDir(t)
+
+	f, streams := newIntegrationTestFactory(t, "http://localhost")
+	account, err := f.resolveAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := cache.New(cache.DefaultDir(), account)
+	now := time.Now()
+	if err := store.Set("/p1", "", &api.CachedResponse{StatusCode: 200, Body: []byte("{}"), StoredAt: now, ExpiresAt: now.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := outfmt.WithFormat(iocontext.WithIO(context.Background(), streams), "json")
+	cmd := NewCacheCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"stats"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+
+	var result struct {
+		Enabled bool              `json:"enabled"`
+		Stats   cache.Stats       `json:"stats"`
+		TTL     map[string]string `json:"ttl"`
+	}
+	if err := json.Unmarshal(streams.Out.(*bytes.Buffer).Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !result.Enabled || result.Stats.Entries != 1 || result.Stats.Fresh != 1 {
+		t.Errorf("unexpected stats: %+v", result)
+	}
+	if result.TTL["user"] != "1h0m0s" {
+		t.Errorf("expected the default user TTL, got %v", result.TTL)
+	}
+
+	cmd = NewCacheCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"clear"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("clear failed: %v", err)
+	}
+	if got, _ := store.Get("/p1", ""); got != nil {
+		t.Error("expected the cache to be cleared")
+	}
+}
+
+func TestFactory_CacheConfig(t *testing.T) {
+	setTestDataDir(t)
+	f, _ := newIntegrationTestFactory(t, "http://localhost")
+
+	cfg, err := f.cacheConfig()
+	if err != nil || cfg == nil || cfg.Store == nil || cfg.Mode != api.CacheDefault {
+		t.Fatalf("expected the cache to be enabled by default, got %+v, %v", cfg, err)
+	}
+
+	f.CacheMode = api.CacheOff
+	if cfg, _ := f.cacheConfig(); cfg != nil {
+		t.Error("expected --no-cache to disable the cache")
+	}
+
+	f.CacheMode = api.CacheRefresh
+	f.Config.Cache = &config.CacheConfig{TTL: map[string]string{"Post": "1m", "location": "0"}}
+	cfg, err = f.cacheConfig()
+	if err != nil {
+		t.Fatalf("cacheConfig failed: %v", err)
+	}
+	if cfg.Mode != api.CacheRefresh || cfg.TTLs[api.CachePost] != time.Minute || cfg.TTLs[api.CacheLocation] >= 0 {
+		t.Errorf("unexpected cache config: %+v", cfg)
+	}
+
+	for _, ttl := range []map[string]string{{"post": "soon"}, {"feed": "1m"}} {
+		f.Config.Cache = &config.CacheConfig{TTL: ttl}
+		if _, err := f.cacheConfig(); err == nil {
+			t.Errorf("expected an error for %v", ttl)
+		}
+	}
+
+	f.Config.Cache = &config.CacheConfig{Disabled: true}
+	f.CacheMode = api.CacheDefault
+	if cfg, _ := f.cacheConfig(); cfg != nil {
+		t.Error("expected the config to disable the cache")
+	}
+}
@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/containers"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/ui"
+)
+
+// NewContainersCmd builds the containers command group.
+func NewContainersCmd(f *Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "containers",
+		Aliases: []string{"container"},
+		Short:   "Inspect and finish interrupted publishes",
+		Long: `Inspect media containers created by this CLI and finish publishes that
+were interrupted after the container was created.
+
+Every post is published in three steps: create a container, wait for it
+to finish processing, then publish it. Each step is recorded in a local
+journal, so if the CLI is interrupted (for example while a video is still
+processing) the container can be published later instead of re-uploaded.
+Unpublished containers expire after 24 hours.`,
+	}
+
+	cmd.AddCommand(newContainersListCmd(f))
+	cmd.AddCommand(requireScopes(newContainersStatusCmd(f), scopeBasic))
+	cmd.AddCommand(requireScopes(newContainersPublishCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(requireScopes(newContainersResumeCmd(f), scopeBasic, scopeContentPublish))
+
+	return cmd
+}
+
+func newContainersListCmd(f *Factory) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List journaled containers",
+		Long: `List containers recorded in the local journal, newest first.
+
+By default only unpublished containers are shown. Use --all to include
+published containers and carousel items.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runContainersList(cmd, f, all)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Include published containers and carousel items")
+	return cmd
+}
+
+func runContainersList(cmd *cobra.Command, f *Factory, all bool) error {
+	ctx := cmd.Context()
+
+	journal := containers.NewJournal(containers.DefaultPath())
+	records, err := journal.List()
+	if err != nil {
+		return WrapError("failed to read container journal", err)
+	}
+
+	filtered := []api.ContainerRecord{}
+	for _, rec := range records {
+		if !all && (rec.State == api.PublishStatePublished || rec.Kind == api.MediaTypeCarouselItem) {
+			continue
+		}
+		filtered = append(filtered, rec)
+	}
+
+	io := iocontext.GetIO(ctx)
+	out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+	if outfmt.IsJSONL(ctx) {
+		return out.Output(filtered)
+	}
+	if outfmt.IsJSON(ctx) {
+		return out.Output(itemsEnvelope(filtered, nil, ""))
+	}
+
+	if len(filtered) == 0 {
+		f.UI(ctx).Info("No unpublished containers")
+		return nil
+	}
+
+	now := time.Now()
+	out.Header("CONTAINER", "KIND", "STATE", "CREATED", "DETAILS")
+	for _, rec := range filtered {
+		state := string(rec.State)
+		if (rec.State == api.PublishStateCreated || rec.State == api.PublishStateReady) && !containers.IsResumable(&rec, now) {
+			state = "expired"
+		}
+		details := rec.Error
+		if rec.PostID != "" {
+			details = "post " + rec.PostID
+		}
+		out.Row(rec.ContainerID, rec.Kind, state, ui.FormatRelativeTime(rec.CreatedAt), details)
+	}
+	out.Flush()
+	return nil
+}
+
+func newContainersStatusCmd(f *Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [container-id]",
+		Short: "Show a container's processing status",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runContainersStatus(cmd, f, args[0])
+		},
+	}
+}
+
+func runContainersStatus(cmd *cobra.Command, f *Factory, containerID string) error {
+	ctx := cmd.Context()
+
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	status, err := client.GetContainerStatus(ctx, api.ContainerID(containerID))
+	if err != nil {
+		return WrapError("failed to get container status", err)
+	}
+
+	journal := containers.NewJournal(containers.DefaultPath())
+	rec, err := journal.Get(containerID)
+	if err != nil && !errors.Is(err, containers.ErrNotFound) {
+		return WrapError("failed to read container journal", err)
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(map[string]any{
+			"container_id":  status.ID,
+			"status":        status.Status,
+			"error_message": status.ErrorMessage,
+			"journal":       rec,
+		})
+	}
+
+	fmt.Fprintf(io.Out, "Container: %s\n", status.ID)     //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "Status:    %s\n", status.Status) //nolint:errcheck // Best-effort output
+	if status.ErrorMessage != "" {
+		fmt.Fprintf(io.Out, "Error:     %s\n", status.ErrorMessage) //nolint:errcheck // Best-effort output
+	}
+	if rec != nil {
+		fmt.Fprintf(io.Out, "Kind:      %s\n", rec.Kind)                                            //nolint:errcheck // Best-effort output
+		fmt.Fprintf(io.Out, "Journal:   %s\n", rec.State)                                           //nolint:errcheck // Best-effort output
+		fmt.Fprintf(io.Out, "Created:   %s\n", rec.CreatedAt.Local().Format("2006-01-02 15:04:05")) //nolint:errcheck // Best-effort output
+		if rec.PostID != "" {
+			fmt.Fprintf(io.Out, "Post:      %s\n", rec.PostID) //nolint:errcheck // Best-effort output
+		}
+	}
+	return nil
+}
+
+func newContainersPublishCmd(f *Factory) *cobra.Command {
+	var emit string
+	var timeoutSecs int
+
+	cmd := &cobra.Command{
+		Use:   "publish [container-id]",
+		Short: "Publish an existing container",
+		Long: `Wait for an existing container to finish processing, then publish it.
+
+Use this to finish a publish that was interrupted after its container was
+created. Find candidates with 'threads containers list'.`,
+		Example: `  threads containers publish 17890012345678901
+  threads containers publish 17890012345678901 --emit url`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runContainersPublish(cmd, f, args[0], emit, timeoutSecs)
+		},
+	}
+
+	cmd.Flags().StringVar(&emit, "emit", "", "Emit: json|id|url (useful for chaining; suppresses extra text output)")
+	cmd.Flags().IntVar(&timeoutSecs, "timeout", 300, "Timeout in seconds to wait for container processing")
+	return cmd
+}
+
+func runContainersPublish(cmd *cobra.Command, f *Factory, containerID, emit string, timeoutSecs int) error {
+	ctx := cmd.Context()
+
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	post, err := publishContainerWithTimeout(ctx, client, containerID, timeoutSecs)
+	io := iocontext.GetIO(ctx)
+	if errors.Is(err, api.ErrPublishedIDUnknown) {
+		if outfmt.IsJSON(ctx) {
+			out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+			return out.Output(containerResumeResult{ContainerID: containerID, Published: true})
+		}
+		f.UI(ctx).Success("Container %s was already published", containerID)
+		return nil
+	}
+	if err != nil {
+		return WrapError("failed to publish container", err)
+	}
+
+	if cmd.Flags().Changed("emit") {
+		mode, errEmit := parseEmitMode(emit)
+		if errEmit != nil {
+			return errEmit
+		}
+		return emitResult(ctx, io, mode, post.ID, post.Permalink, post)
+	}
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(post)
+	}
+
+	f.UI(ctx).Success("Container published!")
+	fmt.Fprintf(io.Out, "  ID:        %s\n", post.ID)        //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "  Permalink: %s\n", post.Permalink) //nolint:errcheck // Best-effort output
+	return nil
+}
+
+// containerResumeResult is the outcome of resuming one container.
+type containerResumeResult struct {
+	ContainerID string `json:"container_id"`
+	Kind        string `json:"kind"`
+	Published   bool   `json:"published"`
+	PostID      string `json:"post_id,omitempty"`
+	Permalink   string `json:"permalink,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+func newContainersResumeCmd(f *Factory) *cobra.Command {
+	var timeoutSecs int
+
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Publish every unfinished container for the active account",
+		Long: `Publish every journaled container for the active account that was created
+but never published and has not expired, oldest first.
+
+Carousel items are not published on their own; they are published as part
+of their carousel container.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runContainersResume(cmd, f, timeoutSecs)
+		},
+	}
+
+	cmd.Flags().IntVar(&timeoutSecs, "timeout", 300, "Timeout in seconds to wait for each container")
+	return cmd
+}
+
+func runContainersResume(cmd *cobra.Command, f *Factory, timeoutSecs int) error {
+	ctx := cmd.Context()
+
+	creds, err := f.ActiveCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	journal := containers.NewJournal(containers.DefaultPath())
+	pending, err := journal.Resumable(creds.UserID)
+	if err != nil {
+		return WrapError("failed to read container journal", err)
+	}
+
+	io := iocontext.GetIO(ctx)
+	if len(pending) == 0 {
+		if outfmt.IsJSON(ctx) {
+			out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+			return out.Output(itemsEnvelope([]containerResumeResult{}, nil, ""))
+		}
+		f.UI(ctx).Info("Nothing to resume")
+		return nil
+	}
+
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	results := make([]containerResumeResult, 0, len(pending))
+	failed := 0
+	for _, rec := range pending {
+		result := containerResumeResult{ContainerID: rec.ContainerID, Kind: rec.Kind}
+		post, errPublish := publishContainerWithTimeout(ctx, client, rec.ContainerID, timeoutSecs)
+		switch {
+		case errors.Is(errPublish, api.ErrPublishedIDUnknown):
+			// Published by an earlier run that died before journaling it
+			result.Published = true
+		case errPublish != nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			result.Error = FormatError(errPublish).Error()
+			failed++
+		default:
+			result.Published = true
+			result.PostID = post.ID
+			result.Permalink = post.Permalink
+		}
+		results = append(results, result)
+	}
+
+	if outfmt.IsJSONL(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if errOut := out.Output(results); errOut != nil {
+			return errOut
+		}
+	} else if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if errOut := out.Output(itemsEnvelope(results, nil, "")); errOut != nil {
+			return errOut
+		}
+	} else {
+		p := f.UI(ctx)
+		for _, r := range results {
+			if r.Error != "" {
+				p.Error("%s (%s): %s", r.ContainerID, r.Kind, r.Error)
+				continue
+			}
+			if r.PostID == "" {
+				p.Success("%s (%s) was already published", r.ContainerID, r.Kind)
+				continue
+			}
+			p.Success("%s (%s) published as %s", r.ContainerID, r.Kind, r.PostID)
+		}
+	}
+
+	if failed > 0 {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("%d of %d containers could not be published", failed, len(results)),
+			Suggestion: "Run 'threads containers status <container-id>' for details",
+		}
+	}
+	return nil
+}
+
+// publishContainerWithTimeout waits for and publishes a container, giving up
+// after timeoutSecs.
+func publishContainerWithTimeout(ctx context.Context, client *api.Client, containerID string, timeoutSecs int) (*api.Post, error) {
+	publishCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSecs)*time.Second)
+	defer cancel()
+
+	post, err := client.PublishContainer(publishCtx, api.ContainerID(containerID))
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return nil, &UserFriendlyError{
+			Message:    "Timeout waiting for media processing",
+			Suggestion: "The container may still finish processing. Try again later, or increase the timeout with --timeout",
+			Cause:      err,
+		}
+	}
+	return post, err
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/containers"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+func TestContainersCmd_Structure(t *testing.T) {
+	f := newTestFactory(t)
+	cmd := NewContainersCmd(f)
+
+	if cmd.Use != "containers" {
+		t.Errorf("expected Use=containers, got %s", cmd.Use)
+	}
+
+	expected := map[string]bool{"list": false, "status": false, "publish": false, "resume": false}
+	for _, sub := range cmd.Commands() {
+		if _, ok := expected[sub.Name()]; !ok {
+			t.Errorf("unexpected subcommand: %s", sub.Name())
+		}
+		expected[sub.Name()] = true
+	}
+	for name, found := range expected {
+		if !found {
+			t.Errorf("missing subcommand: %s", name)
+		}
+	}
+}
+
+func TestContainersResume(t *testing.T) {
+	setTestDataDir(t)
+
+	journal := containers.NewJournal(containers.DefaultPath())
+	now := time.Now()
+	for _, rec := range []api.ContainerRecord{
+		{ContainerID: "c1", UserID: "12345", Kind: api.MediaTypeVideo, State: api.PublishStateCreated, CreatedAt: now.Add(-time.Hour)},
+		{ContainerID: "c2", UserID: "99999", Kind: api.MediaTypeText, State: api.PublishStateCreated, CreatedAt: now},
+	} {
+		if err := journal.Record(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var published []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/refresh_access_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "refreshed-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		case "/c1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1", "status": "FINISHED"})
+		case "/12345/threads_publish":
+			_ = r.ParseForm()
+			published = append(published, r.Form.Get("creation_id"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "p1"})
+		case "/p1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":        "p1",
+				"permalink": "https://www.threads.net/t/p1",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+				"username":  "testuser",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	f, io := newIntegrationTestFactory(t, server.URL)
+	f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+		cfg.ContainerJournal = journal
+	})
+	ctx := iocontext.WithIO(context.Background(), io)
+	ctx = outfmt.WithFormat(ctx, "json")
+
+	cmd := newContainersResumeCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("containers resume failed: %v", err)
+	}
+
+	if len(published) != 1 || published[0] != "c1" {
+		t.Fatalf("expected only the active account's container to be published, got %v", published)
+	}
+
+	var envelope struct {
+		Items []containerResumeResult `json:"items"`
+	}
+	if err := json.Unmarshal(io.Out.(*bytes.Buffer).Bytes(), &envelope); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(envelope.Items) != 1 || envelope.Items[0].PostID != "p1" {
+		t.Errorf("unexpected resume results: %+v", envelope.Items)
+	}
+
+	rec, err := journal.Get("c1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if rec.State != api.PublishStatePublished || rec.PostID != "p1" || rec.Kind != api.MediaTypeVideo {
+		t.Errorf("expected journal to record the publish, got %+v", rec)
+	}
+}
+
+func TestContainersResume_AlreadyPublished(t *testing.T) {
+	setTestDataDir(t)
+
+	journal := containers.NewJournal(containers.DefaultPath())
+	if err := journal.Record(api.ContainerRecord{ContainerID: "c1", UserID: "12345", Kind: api.MediaTypeText, State: api.PublishStateReady}); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/refresh_access_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "refreshed-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		case "/c1":
+			// Published by a run that died before journaling it
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1", "status": "PUBLISHED"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	f, io := newIntegrationTestFactory(t, server.URL)
+	f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+		cfg.ContainerJournal = journal
+	})
+	ctx := iocontext.WithIO(context.Background(), io)
+	ctx = outfmt.WithFormat(ctx, "json")
+
+	cmd := newContainersResumeCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("containers resume failed: %v", err)
+	}
+
+	var envelope struct {
+		Items []containerResumeResult `json:"items"`
+	}
+	if err := json.Unmarshal(io.Out.(*bytes.Buffer).Bytes(), &envelope); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(envelope.Items) != 1 || !envelope.Items[0].Published || envelope.Items[0].Error != "" {
+		t.Errorf("expected the container to be reported as published, got %+v", envelope.Items)
+	}
+
+	rec, err := journal.Get("c1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if rec.State != api.PublishStatePublished {
+		t.Errorf("expected journal to record the container as published, got %s", rec.State)
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/apitest"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+type devFakeServerOptions struct {
+	Addr            string
+	ProcessingPolls int
+}
+
+// NewDevCmd builds the dev command group.
+func NewDevCmd(f *Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Tools for developing against the Threads API",
+	}
+
+	cmd.AddCommand(newDevFakeServerCmd(f))
+
+	return cmd
+}
+
+func newDevFakeServerCmd(f *Factory) *cobra.Command {
+	opts := &devFakeServerOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "fake-server",
+		Short: "Run an in-memory fake of the Threads API",
+		Long: `Run a local, in-memory stand-in for graph.threads.net.
+
+The fake implements publishing (containers, status and publish), posts,
+replies and reply moderation, insights, keyword search, locations,
+publishing limits, the token endpoints and webhook subscriptions. State
+lives in memory and is lost when the server stops.
+
+It accepts the printed access token for the seeded account. Point the CLI
+at it with THREADS_BASE_URL.
+
+Errors and rate limits can be injected while it runs:
+
+  POST   /_fake/faults   {"method": "POST", "path": "/*/threads_publish",
+                          "status": 429, "retry_after": 30, "times": 1}
+  DELETE /_fake/faults   clear injected faults
+  POST   /_fake/reset    discard all state
+
+Go tests can start the same server with internal/apitest/apitesting.`,
+		Example: `  # Serve on a fixed port
+  threads dev fake-server --addr 127.0.0.1:8765
+
+  # Make the next publish fail with a rate limit
+  curl -X POST http://127.0.0.1:8765/_fake/faults \
+    -d '{"path": "/*/threads_publish", "status": 429, "retry_after": 30, "times": 1}'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDevFakeServer(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Addr, "addr", "127.0.0.1:8765", "Address to listen on")
+	cmd.Flags().IntVar(&opts.ProcessingPolls, "processing-polls", 0, "Status checks an image or video container reports IN_PROGRESS before it is FINISHED")
+
+	return cmd
+}
+
+func runDevFakeServer(cmd *cobra.Command, f *Factory, opts *devFakeServerOptions) error {
+	ctx := cmd.Context()
+	io := iocontext.GetIO(ctx)
+
+	if opts.ProcessingPolls < 0 {
+		return &UserFriendlyError{
+			Message:    "--processing-polls cannot be negative",
+			Suggestion: "Pass 0 to finish containers immediately",
+		}
+	}
+
+	server := apitest.New()
+	server.ProcessingPolls = opts.ProcessingPolls
+
+	listener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return WrapError(fmt.Sprintf("failed to listen on %s", opts.Addr), err)
+	}
+	baseURL := "http://" + listener.Addr().String()
+
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if err := out.Output(map[string]any{
+			"base_url":     baseURL,
+			"access_token": apitest.DefaultAccessToken,
+			"user_id":      apitest.DefaultUserID,
+			"username":     apitest.DefaultUsername,
+		}); err != nil {
+			listener.Close() //nolint:errcheck,gosec // Already returning the output error
+			return err
+		}
+	} else {
+		f.UI(ctx).Success("Fake Threads API listening on %s", baseURL)
+		fmt.Fprintf(io.Out, "  Account:       @%s (%s)\n", apitest.DefaultUsername, apitest.DefaultUserID) //nolint:errcheck // Best-effort output
+		fmt.Fprintf(io.Out, "  Access token:  %s\n", apitest.DefaultAccessToken)                           //nolint:errcheck // Best-effort output
+		fmt.Fprintf(io.Out, "  Use it with:   THREADS_BASE_URL=%s threads ...\n", baseURL)                 //nolint:errcheck // Best-effort output
+	}
+
+	return serveHTTP(ctx, listener, server)
+}
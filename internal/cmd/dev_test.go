@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/apitest"
+	"github.com/salmonumbrella/threads-cli/internal/apitest/apitesting"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+// newFakeServerFactory returns a factory whose client talks to an in-memory
+// fake API that accepts the test credentials.
+func newFakeServerFactory(t *testing.T) (*apitest.Server, *Factory, *iocontext.IO) {
+	t.Helper()
+	setTestDataDir(t)
+
+	server := apitesting.Start(t)
+	server.AddToken(testCredentials().AccessToken)
+
+	f, streams := newIntegrationTestFactory(t, server.URL)
+	f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+		cfg.RetryConfig.MaxRetries = 0
+	})
+	return server, f, streams
+}
+
+// runRoot executes the root command with args and returns its stdout.
+func runRoot(t *testing.T, f *Factory, streams *iocontext.IO, args ...string) (string, error) {
+	t.Helper()
+
+	out := streams.Out.(*bytes.Buffer)
+	out.Reset()
+	streams.ErrOut.(*bytes.Buffer).Reset()
+
+	cmd := NewRootCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), streams))
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestFakeServer_EndToEnd(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	server.AddUser("alice")
+
+	out, err := runRoot(t, f, streams, "posts", "create", "--text", "hello from the fake server", "--emit", "id")
+	if err != nil {
+		t.Fatalf("posts create failed: %v", err)
+	}
+	postID := strings.TrimSpace(out)
+	if got, ok := server.Post(postID); !ok || got.Text != "hello from the fake server" {
+		t.Fatalf("expected the post on the server, got %+v", got)
+	}
+
+	out, err = runRoot(t, f, streams, "me", "-o", "json")
+	if err != nil || !strings.Contains(out, `"testuser"`) {
+		t.Errorf("me failed: %v: %s", err, out)
+	}
+
+	out, err = runRoot(t, f, streams, "search", "fake server", "-o", "json")
+	if err != nil || !strings.Contains(out, postID) {
+		t.Errorf("search failed: %v: %s", err, out)
+	}
+
+	replyID := server.AddReply("alice", postID, "nice")
+	out, err = runRoot(t, f, streams, "replies", "list", postID, "-o", "json")
+	if err != nil || !strings.Contains(out, replyID) {
+		t.Errorf("replies list failed: %v: %s", err, out)
+	}
+	if _, err := runRoot(t, f, streams, "replies", "hide", replyID); err != nil {
+		t.Errorf("replies hide failed: %v", err)
+	}
+	if got, _ := server.Post(replyID); !got.Hidden {
+		t.Error("expected the reply to be hidden")
+	}
+
+	server.SetInsight(postID, "views", 7)
+	out, err = runRoot(t, f, streams, "insights", "post", postID, "-o", "json")
+	if err != nil || !strings.Contains(out, `"views"`) {
+		t.Errorf("insights post failed: %v: %s", err, out)
+	}
+
+	out, err = runRoot(t, f, streams, "locations", "search", "park", "-o", "json")
+	if err != nil || !strings.Contains(out, "Golden Gate Park") {
+		t.Errorf("locations search failed: %v: %s", err, out)
+	}
+
+	if _, err := runRoot(t, f, streams, "posts", "delete", postID, "--yes"); err != nil {
+		t.Fatalf("posts delete failed: %v", err)
+	}
+	if _, ok := server.Post(postID); ok {
+		t.Error("expected the post to be deleted")
+	}
+}
+
+func TestFakeServer_InjectedFaults(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+
+	server.Fail(http.MethodPost, "/*/threads", http.StatusBadRequest, 1)
+	if _, err := runRoot(t, f, streams, "posts", "create", "--text", "first"); err == nil {
+		t.Fatal("expected the injected failure")
+	}
+	if _, err := runRoot(t, f, streams, "posts", "create", "--text", "second"); err != nil {
+		t.Fatalf("expected the fault to be used up, got %v", err)
+	}
+
+	server.RateLimit("", "/12345/threads_publishing_limit", time.Minute, 1)
+	if _, err := runRoot(t, f, streams, "ratelimit", "publishing"); err == nil {
+		t.Error("expected the rate limit response to fail the command")
+	}
+}
+
+func TestDevFakeServerCmd(t *testing.T) {
+	f, streams := newIntegrationTestFactory(t, "http://localhost")
+
+	// A cancelled context stops the server as soon as it has started.
+	ctx, cancel := context.WithCancel(outfmt.WithFormat(iocontext.WithIO(context.Background(), streams), "json"))
+	cancel()
+
+	cmd := NewDevCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"fake-server", "--addr", "127.0.0.1:0"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("fake-server failed: %v", err)
+	}
+
+	var result map[string]string
+	if err := json.Unmarshal(streams.Out.(*bytes.Buffer).Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !strings.HasPrefix(result["base_url"], "http://127.0.0.1:") || result["access_token"] != apitest.DefaultAccessToken {
+		t.Errorf("unexpected output: %v", result)
+	}
+
+	cmd = NewDevCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetErr(streams.ErrOut)
+	cmd.SetArgs([]string{"fake-server", "--processing-polls", "-1"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected an error for negative --processing-polls")
+	}
+}
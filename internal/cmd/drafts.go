@@ -0,0 +1,672 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/drafts"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/ui"
+)
+
+// draftValidator runs the client-side PostValidator checks. The validation
+// methods do not touch client state, so a zero Client works offline.
+var draftValidator api.PostValidator = &api.Client{}
+
+// NewDraftsCmd builds the drafts command group.
+func NewDraftsCmd(f *Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "drafts",
+		Aliases: []string{"draft"},
+		Short:   "Prepare, review, and publish draft posts",
+		Long: `Prepare posts offline, review them, and publish when ready.
+
+Drafts accept the same content flags as 'threads posts create' and
+'threads posts carousel', are validated when saved, and are published
+through the same code path as those commands.`,
+	}
+
+	cmd.AddCommand(newDraftsNewCmd(f))
+	cmd.AddCommand(newDraftsEditCmd(f))
+	cmd.AddCommand(newDraftsListCmd(f))
+	cmd.AddCommand(newDraftsShowCmd(f))
+	cmd.AddCommand(requireScopes(newDraftsPublishCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(newDraftsRmCmd(f))
+
+	return cmd
+}
+
+type draftInputOptions struct {
+	Draft    drafts.Draft
+	TextFile string
+	Editor   bool
+}
+
+// bindDraftFlags registers the content flags shared by 'drafts new' and
+// 'drafts edit'. Flag names match 'posts create' and 'posts carousel'.
+func bindDraftFlags(cmd *cobra.Command, opts *draftInputOptions) {
+	d := &opts.Draft
+	cmd.Flags().StringVarP(&d.Text, "text", "t", "", "Post text content")
+	cmd.Flags().StringVar(&opts.TextFile, "text-file", "", "Read post text content from a file (or '-' for stdin)")
+	cmd.Flags().BoolVar(&opts.Editor, "editor", false, "Write the post text in $EDITOR")
+	cmd.Flags().StringVar(&d.ImageURL, "image", "", "Image URL or local file for image posts")
+	cmd.Flags().StringVar(&d.VideoURL, "video", "", "Video URL or local file for video posts")
+	cmd.Flags().StringVar(&d.AltText, "alt-text", "", "Alt text for media accessibility")
+	cmd.Flags().StringSliceVar(&d.Items, "items", nil, "Carousel media URLs or local files (comma-separated)")
+	cmd.Flags().StringArrayVar(&d.ItemAltTexts, "item-alt-text", nil, "Alt text for each carousel item (repeatable, in order)")
+	cmd.Flags().StringVar(&d.ReplyTo, "reply-to", "", "Post ID to reply to")
+	cmd.Flags().StringVar(&d.Poll, "poll", "", "Create a poll with comma-separated options (2-4 options)")
+	cmd.Flags().BoolVar(&d.Ghost, "ghost", false, "Create a ghost post (text-only, expires 24 hours after publishing)")
+	cmd.Flags().StringVar(&d.Topic, "topic", "", "Add a topic tag to the post")
+	cmd.Flags().StringVar(&d.Location, "location", "", "Attach a location ID to the post")
+	cmd.Flags().StringVar(&d.ReplyControl, "reply-control", "", "Control who can reply: everyone, accounts_you_follow, mentioned_only")
+	cmd.Flags().StringVar(&d.GIF, "gif", "", "Attach a GIF using a Tenor GIF ID (text-only posts)")
+	cmd.Flags().BoolVar(&d.SpoilerMedia, "spoiler-media", false, "Mark the media as a spoiler")
+	cmd.Flags().StringArrayVar(&d.Spoilers, "spoiler", nil, "Mark a text range as a spoiler as OFFSET:LENGTH (repeatable)")
+}
+
+// applyDraftFlags copies every flag the user set from src into dst.
+func applyDraftFlags(cmd *cobra.Command, src, dst *drafts.Draft) {
+	changed := cmd.Flags().Changed
+	if changed("text") {
+		dst.Text = src.Text
+	}
+	if changed("image") {
+		dst.ImageURL = src.ImageURL
+	}
+	if changed("video") {
+		dst.VideoURL = src.VideoURL
+	}
+	if changed("alt-text") {
+		dst.AltText = src.AltText
+	}
+	if changed("items") {
+		dst.Items = src.Items
+	}
+	if changed("item-alt-text") {
+		dst.ItemAltTexts = src.ItemAltTexts
+	}
+	if changed("reply-to") {
+		dst.ReplyTo = src.ReplyTo
+	}
+	if changed("poll") {
+		dst.Poll = src.Poll
+	}
+	if changed("ghost") {
+		dst.Ghost = src.Ghost
+	}
+	if changed("topic") {
+		dst.Topic = src.Topic
+	}
+	if changed("location") {
+		dst.Location = src.Location
+	}
+	if changed("reply-control") {
+		dst.ReplyControl = src.ReplyControl
+	}
+	if changed("gif") {
+		dst.GIF = src.GIF
+	}
+	if changed("spoiler-media") {
+		dst.SpoilerMedia = src.SpoilerMedia
+	}
+	if changed("spoiler") {
+		dst.Spoilers = src.Spoilers
+	}
+}
+
+// resolveDraftText applies --text-file and --editor on top of d.Text.
+func resolveDraftText(ctx context.Context, cmd *cobra.Command, opts *draftInputOptions, d *drafts.Draft) error {
+	if strings.TrimSpace(opts.TextFile) != "" {
+		if cmd.Flags().Changed("text") || opts.Editor {
+			return &UserFriendlyError{
+				Message:    "Cannot combine --text-file with --text or --editor",
+				Suggestion: "Choose one source for the post text",
+			}
+		}
+		txt, err := readTextFileOrStdin(ctx, opts.TextFile)
+		if err != nil {
+			return err
+		}
+		d.Text = txt
+	}
+
+	if opts.Editor {
+		txt, err := editTextInEditor(ctx, d.Text)
+		if err != nil {
+			return err
+		}
+		d.Text = txt
+	}
+	return nil
+}
+
+func newDraftsNewCmd(f *Factory) *cobra.Command {
+	opts := &draftInputOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "new",
+		Aliases: []string{"create", "add"},
+		Short:   "Create a draft",
+		Example: `  # Draft a text post
+  threads drafts new --text "Thinking out loud..."
+
+  # Write the text in your editor
+  threads drafts new --editor --topic golang
+
+  # Draft a carousel
+  threads drafts new --items url1,url2 --text "Album" --item-alt-text "First" --item-alt-text "Second"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDraftsNew(cmd, f, opts)
+		},
+	}
+
+	bindDraftFlags(cmd, opts)
+	return cmd
+}
+
+func runDraftsNew(cmd *cobra.Command, f *Factory, opts *draftInputOptions) error {
+	ctx := cmd.Context()
+
+	d := opts.Draft
+	if err := resolveDraftText(ctx, cmd, opts, &d); err != nil {
+		return err
+	}
+	// Drafts can be written before logging in; the account is only a default.
+	if account, errAccount := f.resolveAccount(); errAccount == nil {
+		d.Account = account
+	}
+
+	if err := validateDraft(&d); err != nil {
+		return err
+	}
+	if err := absDraftMedia(&d); err != nil {
+		return err
+	}
+
+	store := drafts.NewStore(drafts.DefaultDir())
+	if err := store.Save(&d); err != nil {
+		return WrapError("failed to save draft", err)
+	}
+
+	return outputDraftSaved(ctx, f, &d, "Draft saved")
+}
+
+func newDraftsEditCmd(f *Factory) *cobra.Command {
+	opts := &draftInputOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "edit [draft-id]",
+		Short: "Edit a draft",
+		Long: `Edit a draft. Only the flags you pass are changed; pass an empty value
+(e.g. --image "") to clear a field. Use --editor to edit the text in $EDITOR.`,
+		Example: `  threads drafts edit a1b2c3d4 --text "Better wording"
+  threads drafts edit a1b2c3d4 --editor
+  threads drafts edit a1b2c3d4 --poll ""`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDraftsEdit(cmd, f, opts, args[0])
+		},
+	}
+
+	bindDraftFlags(cmd, opts)
+	return cmd
+}
+
+func runDraftsEdit(cmd *cobra.Command, f *Factory, opts *draftInputOptions, id string) error {
+	ctx := cmd.Context()
+
+	store := drafts.NewStore(drafts.DefaultDir())
+	d, err := getDraft(store, id)
+	if err != nil {
+		return err
+	}
+
+	applyDraftFlags(cmd, &opts.Draft, d)
+	if err := resolveDraftText(ctx, cmd, opts, d); err != nil {
+		return err
+	}
+
+	if err := validateDraft(d); err != nil {
+		return err
+	}
+	if err := absDraftMedia(d); err != nil {
+		return err
+	}
+
+	if err := store.Save(d); err != nil {
+		return WrapError("failed to save draft", err)
+	}
+
+	return outputDraftSaved(ctx, f, d, "Draft updated")
+}
+
+func outputDraftSaved(ctx context.Context, f *Factory, d *drafts.Draft, msg string) error {
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(d)
+	}
+
+	f.UI(ctx).Success("%s", msg)
+	fmt.Fprintf(io.Out, "  ID:   %s\n", d.ID)       //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "  Type: %s\n", d.Kind())   //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "  Text: %s\n", preview(d)) //nolint:errcheck // Best-effort output
+	return nil
+}
+
+func newDraftsListCmd(f *Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List drafts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDraftsList(cmd, f)
+		},
+	}
+}
+
+func runDraftsList(cmd *cobra.Command, f *Factory) error {
+	ctx := cmd.Context()
+
+	store := drafts.NewStore(drafts.DefaultDir())
+	list, err := store.List()
+	if err != nil {
+		return WrapError("failed to list drafts", err)
+	}
+
+	io := iocontext.GetIO(ctx)
+	out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+	if outfmt.IsJSONL(ctx) {
+		return out.Output(list)
+	}
+	if outfmt.IsJSON(ctx) {
+		return out.Output(itemsEnvelope(list, nil, ""))
+	}
+
+	if len(list) == 0 {
+		f.UI(ctx).Info("No drafts")
+		return nil
+	}
+
+	out.Header("ID", "TYPE", "UPDATED", "TEXT")
+	for _, d := range list {
+		out.Row(d.ID, d.Kind(), ui.FormatRelativeTime(d.UpdatedAt), preview(&d))
+	}
+	out.Flush()
+	return nil
+}
+
+func newDraftsShowCmd(f *Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:     "show [draft-id]",
+		Aliases: []string{"get", "preview"},
+		Short:   "Preview a draft",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDraftsShow(cmd, f, args[0])
+		},
+	}
+}
+
+func runDraftsShow(cmd *cobra.Command, f *Factory, id string) error {
+	ctx := cmd.Context()
+
+	store := drafts.NewStore(drafts.DefaultDir())
+	d, err := getDraft(store, id)
+	if err != nil {
+		return err
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(d)
+	}
+
+	w := io.Out
+	fmt.Fprintf(w, "ID:       %s\n", d.ID)                                              //nolint:errcheck // Best-effort output
+	fmt.Fprintf(w, "Type:     %s\n", d.Kind())                                          //nolint:errcheck // Best-effort output
+	fmt.Fprintf(w, "Updated:  %s\n", d.UpdatedAt.Local().Format("2006-01-02 15:04:05")) //nolint:errcheck // Best-effort output
+	if d.Account != "" {
+		fmt.Fprintf(w, "Account:  %s\n", d.Account) //nolint:errcheck // Best-effort output
+	}
+
+	fields := []struct{ label, value string }{
+		{"Image", d.ImageURL},
+		{"Video", d.VideoURL},
+		{"Alt text", d.AltText},
+		{"Reply to", d.ReplyTo},
+		{"Poll", d.Poll},
+		{"Topic", d.Topic},
+		{"Location", d.Location},
+		{"Replies", d.ReplyControl},
+		{"GIF", d.GIF},
+		{"Spoilers", strings.Join(d.Spoilers, ", ")},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			fmt.Fprintf(w, "%-9s %s\n", field.label+":", field.value) //nolint:errcheck // Best-effort output
+		}
+	}
+	if d.Ghost {
+		fmt.Fprintln(w, "Ghost:    yes") //nolint:errcheck // Best-effort output
+	}
+	if d.SpoilerMedia {
+		fmt.Fprintln(w, "Spoiler:  media") //nolint:errcheck // Best-effort output
+	}
+	for i, item := range d.Items {
+		alt := ""
+		if i < len(d.ItemAltTexts) && d.ItemAltTexts[i] != "" {
+			alt = fmt.Sprintf(" (alt: %s)", d.ItemAltTexts[i])
+		}
+		fmt.Fprintf(w, "Item %d:   %s%s\n", i+1, item, alt) //nolint:errcheck // Best-effort output
+	}
+
+	fmt.Fprintf(w, "\n%s\n\n", d.Text)                                                      //nolint:errcheck // Best-effort output
+	fmt.Fprintf(w, "%d/%d characters\n", utf8.RuneCountInString(d.Text), api.MaxTextLength) //nolint:errcheck // Best-effort output
+
+	if err := validateDraft(d); err != nil {
+		f.UI(ctx).Warning("Not ready to publish: %s", FormatError(err).Error())
+	} else {
+		f.UI(ctx).Success("Ready to publish")
+	}
+	return nil
+}
+
+type draftsPublishOptions struct {
+	Emit        string
+	Keep        bool
+	TimeoutSecs int
+}
+
+func newDraftsPublishCmd(f *Factory) *cobra.Command {
+	opts := &draftsPublishOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "publish [draft-id]",
+		Short: "Publish a draft",
+		Long: `Publish a draft exactly as 'threads posts create' (or 'threads posts carousel')
+would. The draft is removed after publishing unless --keep is set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDraftsPublish(cmd, f, opts, args[0])
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Emit, "emit", "", "Emit: json|id|url (useful for chaining; suppresses extra text output)")
+	cmd.Flags().BoolVar(&opts.Keep, "keep", false, "Keep the draft after publishing")
+	cmd.Flags().IntVar(&opts.TimeoutSecs, "timeout", 300, "Timeout in seconds for carousel media processing")
+	return cmd
+}
+
+func runDraftsPublish(cmd *cobra.Command, f *Factory, opts *draftsPublishOptions, id string) error {
+	ctx := cmd.Context()
+
+	store := drafts.NewStore(drafts.DefaultDir())
+	d, err := getDraft(store, id)
+	if err != nil {
+		return err
+	}
+	if d.PostID != "" || d.PublishedAt != nil {
+		msg := fmt.Sprintf("Draft %s was already published", d.ID)
+		if d.PostID != "" {
+			msg += " as post " + d.PostID
+		}
+		return &UserFriendlyError{
+			Message:    msg,
+			Suggestion: "Create a new draft, or remove this one with 'threads drafts rm'",
+		}
+	}
+
+	// Publish as the account the draft was written for unless one was chosen explicitly.
+	if f.Account == "" && d.Account != "" {
+		f.Account = d.Account
+	}
+
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	post, err := publishDraft(ctx, client, d, opts.TimeoutSecs, newLocalMedia(f))
+	idUnknown := errors.Is(err, api.ErrPublishedIDUnknown)
+	if idUnknown {
+		// The post went out but its ID was lost with the response
+		post, err = &api.Post{}, nil
+	}
+	if err != nil {
+		return WrapError("failed to publish draft", err)
+	}
+
+	if opts.Keep {
+		now := post.Timestamp.Time
+		if idUnknown {
+			now = time.Now()
+		}
+		d.PostID = post.ID
+		d.PublishedAt = &now
+		if errSave := store.Save(d); errSave != nil {
+			return WrapError("post published but failed to update draft", errSave)
+		}
+	} else if errDelete := store.Delete(d.ID); errDelete != nil {
+		return WrapError("post published but failed to remove draft", errDelete)
+	}
+
+	io := iocontext.GetIO(ctx)
+	if cmd.Flags().Changed("emit") {
+		mode, errEmit := parseEmitMode(opts.Emit)
+		if errEmit != nil {
+			return errEmit
+		}
+		return emitResult(ctx, io, mode, post.ID, post.Permalink, post)
+	}
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(post)
+	}
+
+	if idUnknown {
+		f.UI(ctx).Success("Draft published, but its post ID could not be retrieved")
+		return nil
+	}
+	f.UI(ctx).Success("Draft published!")
+	fmt.Fprintf(io.Out, "  ID:        %s\n", post.ID)        //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "  Permalink: %s\n", post.Permalink) //nolint:errcheck // Best-effort output
+	return nil
+}
+
+func newDraftsRmCmd(f *Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm [draft-id]",
+		Aliases: []string{"delete", "remove"},
+		Short:   "Delete a draft",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDraftsRm(cmd, f, args[0])
+		},
+	}
+}
+
+func runDraftsRm(cmd *cobra.Command, f *Factory, id string) error {
+	ctx := cmd.Context()
+
+	store := drafts.NewStore(drafts.DefaultDir())
+	d, err := getDraft(store, id)
+	if err != nil {
+		return err
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) && !outfmt.GetYes(ctx) {
+		return &UserFriendlyError{
+			Message:    "Refusing to prompt for confirmation in JSON output mode",
+			Suggestion: "Re-run with --yes (or --no-prompt) to confirm deletion",
+		}
+	}
+	if !f.Confirm(ctx, fmt.Sprintf("Delete draft %s (%q)?", d.ID, preview(d))) {
+		fmt.Fprintln(io.Out, "Cancelled.") //nolint:errcheck // Best-effort output
+		return nil
+	}
+
+	if err := store.Delete(d.ID); err != nil {
+		return WrapError("failed to delete draft", err)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(map[string]any{
+			"ok":       true,
+			"draft_id": d.ID,
+			"deleted":  true,
+			"action":   "delete_draft",
+		})
+	}
+
+	f.UI(ctx).Success("Draft deleted")
+	return nil
+}
+
+func getDraft(store *drafts.Store, id string) (*drafts.Draft, error) {
+	d, err := store.Get(id)
+	if errors.Is(err, drafts.ErrNotFound) {
+		return nil, &UserFriendlyError{
+			Message:    fmt.Sprintf("Draft not found: %s", id),
+			Suggestion: "Run 'threads drafts list' to see draft IDs",
+		}
+	}
+	if err != nil {
+		return nil, WrapError("failed to read draft", err)
+	}
+	return d, nil
+}
+
+// draftCreateOptions maps a single-media draft onto 'posts create' options.
+func draftCreateOptions(d *drafts.Draft) *postsCreateOptions {
+	return &postsCreateOptions{
+		Text:         d.Text,
+		ImageURL:     d.ImageURL,
+		VideoURL:     d.VideoURL,
+		AltText:      d.AltText,
+		ReplyTo:      d.ReplyTo,
+		Poll:         d.Poll,
+		Ghost:        d.Ghost,
+		Topic:        d.Topic,
+		Location:     d.Location,
+		ReplyControl: d.ReplyControl,
+		GIF:          d.GIF,
+		SpoilerMedia: d.SpoilerMedia,
+		Spoilers:     d.Spoilers,
+	}
+}
+
+// draftCarouselOptions maps a carousel draft onto 'posts carousel' options.
+func draftCarouselOptions(d *drafts.Draft) *postsCarouselOptions {
+	return &postsCarouselOptions{
+		Items:        d.Items,
+		Text:         d.Text,
+		AltTexts:     d.ItemAltTexts,
+		ReplyTo:      d.ReplyTo,
+		Topic:        d.Topic,
+		Location:     d.Location,
+		ReplyControl: d.ReplyControl,
+		SpoilerMedia: d.SpoilerMedia,
+		Spoilers:     d.Spoilers,
+	}
+}
+
+// validateDraft builds the draft's post content with the same rules as the
+// create commands and runs the PostValidator checks on it.
+func validateDraft(d *drafts.Draft) error {
+	if d.IsCarousel() {
+		if err := rejectSingleMediaOptions(draftCreateOptions(d)); err != nil {
+			return err
+		}
+		content, items, err := buildCarouselContent(draftCarouselOptions(d))
+		if err != nil {
+			return err
+		}
+		validator := api.NewValidator()
+		for i, item := range items {
+			if errURL := validator.ValidateMediaURL(validationMediaURL(item.URL), strings.ToLower(item.MediaType)); errURL != nil {
+				return WrapError(fmt.Sprintf("invalid carousel item %d", i+1), errURL)
+			}
+		}
+		// Containers don't exist until publish; validate the count with the URLs.
+		placeholder := *content
+		placeholder.Children = d.Items
+		return FormatError(draftValidator.ValidateCarouselPostContent(&placeholder))
+	}
+
+	content, err := buildPostContent(draftCreateOptions(d))
+	if err != nil {
+		return err
+	}
+	switch c := content.(type) {
+	case *api.ImagePostContent:
+		placeholder := *c
+		placeholder.ImageURL = validationMediaURL(c.ImageURL)
+		return FormatError(draftValidator.ValidateImagePostContent(&placeholder))
+	case *api.VideoPostContent:
+		placeholder := *c
+		placeholder.VideoURL = validationMediaURL(c.VideoURL)
+		return FormatError(draftValidator.ValidateVideoPostContent(&placeholder))
+	case *api.TextPostContent:
+		return FormatError(draftValidator.ValidateTextPostContent(c))
+	default:
+		return fmt.Errorf("unsupported post content type %T", content)
+	}
+}
+
+// absDraftMedia stores local media as absolute paths so the draft can be
+// published from any directory.
+func absDraftMedia(d *drafts.Draft) error {
+	var err error
+	if d.ImageURL, err = normalizeMediaRef(d.ImageURL); err != nil {
+		return err
+	}
+	if d.VideoURL, err = normalizeMediaRef(d.VideoURL); err != nil {
+		return err
+	}
+	for i := range d.Items {
+		if d.Items[i], err = normalizeMediaRef(d.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishDraft publishes a draft through the same helpers as 'posts create'
+// and 'posts carousel'.
+func publishDraft(ctx context.Context, client *api.Client, d *drafts.Draft, timeoutSecs int, uploads *localMedia) (*api.Post, error) {
+	if d.IsCarousel() {
+		content, items, err := buildCarouselContent(draftCarouselOptions(d))
+		if err != nil {
+			return nil, err
+		}
+		return publishCarouselContent(ctx, client, content, items, timeoutSecs, uploads)
+	}
+
+	content, err := buildPostContent(draftCreateOptions(d))
+	if err != nil {
+		return nil, err
+	}
+	return publishPostContent(ctx, client, content, uploads)
+}
+
+// preview returns a single-line, truncated version of the draft text.
+func preview(d *drafts.Draft) string {
+	text := strings.ReplaceAll(d.Text, "\n", " ")
+	if utf8.RuneCountInString(text) > 40 {
+		text = string([]rune(text)[:40]) + "..."
+	}
+	return text
+}
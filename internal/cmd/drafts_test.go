@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/drafts"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+func TestDraftsCmd_Structure(t *testing.T) {
+	f := newTestFactory(t)
+	cmd := NewDraftsCmd(f)
+
+	if cmd.Use != "drafts" {
+		t.Errorf("expected Use=drafts, got %s", cmd.Use)
+	}
+
+	expected := map[string]bool{"new": false, "edit": false, "list": false, "show": false, "publish": false, "rm": false}
+	for _, sub := range cmd.Commands() {
+		if _, ok := expected[sub.Name()]; !ok {
+			t.Errorf("unexpected subcommand: %s", sub.Name())
+		}
+		expected[sub.Name()] = true
+	}
+	for name, found := range expected {
+		if !found {
+			t.Errorf("missing subcommand: %s", name)
+		}
+	}
+}
+
+func TestValidateDraft(t *testing.T) {
+	tests := []struct {
+		name    string
+		draft   drafts.Draft
+		wantErr bool
+	}{
+		{"text", drafts.Draft{Text: "hello"}, false},
+		{"text with poll", drafts.Draft{Text: "pick", Poll: "A,B"}, false},
+		{"image", drafts.Draft{ImageURL: "https://example.com/a.jpg", SpoilerMedia: true}, false},
+		{"carousel", drafts.Draft{Items: []string{"https://example.com/a.jpg", "https://example.com/b.mp4"}}, false},
+		{"empty", drafts.Draft{}, true},
+		{"too long", drafts.Draft{Text: strings.Repeat("a", 501)}, true},
+		{"image and video", drafts.Draft{ImageURL: "https://example.com/a.jpg", VideoURL: "https://example.com/b.mp4"}, true},
+		{"carousel too small", drafts.Draft{Items: []string{"https://example.com/a.jpg"}}, true},
+		{"carousel with image", drafts.Draft{Items: []string{"https://example.com/a.jpg", "https://example.com/b.jpg"}, ImageURL: "https://example.com/c.jpg"}, true},
+		{"bad reply control", drafts.Draft{Text: "hi", ReplyControl: "nobody"}, true},
+		{"bad spoiler", drafts.Draft{Text: "hi", Spoilers: []string{"oops"}}, true},
+		{"spoiler media without media", drafts.Draft{Text: "hi", SpoilerMedia: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDraft(&tt.draft)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateDraft() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDraftsLifecycle(t *testing.T) {
+	setTestDataDir(t)
+
+	var published map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/refresh_access_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "refreshed-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		case "/12345/threads":
+			_ = r.ParseForm()
+			published = map[string]string{"text": r.Form.Get("text"), "topic_tag": r.Form.Get("topic_tag")}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1"})
+		case "/c1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1", "status": "FINISHED"})
+		case "/12345/threads_publish":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "p1"})
+		case "/p1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":        "p1",
+				"permalink": "https://www.threads.net/t/p1",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+				"username":  "testuser",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	f, io := newIntegrationTestFactory(t, server.URL)
+	ctx := iocontext.WithIO(context.Background(), io)
+	ctx = outfmt.WithFormat(ctx, "json")
+	out := io.Out.(*bytes.Buffer)
+
+	run := func(cmd interface {
+		SetContext(context.Context)
+		SetArgs([]string)
+		Execute() error
+	}, args ...string) error {
+		out.Reset()
+		cmd.SetContext(ctx)
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	if err := run(newDraftsNewCmd(f), "--text", "draft one", "--poll", "A"); err == nil {
+		t.Fatal("expected invalid draft to be rejected")
+	}
+
+	if err := run(newDraftsNewCmd(f), "--text", "draft one"); err != nil {
+		t.Fatalf("drafts new failed: %v", err)
+	}
+	var saved drafts.Draft
+	if err := json.Unmarshal(out.Bytes(), &saved); err != nil {
+		t.Fatalf("failed to parse new output: %v", err)
+	}
+	if saved.ID == "" || saved.Account != "test-user" {
+		t.Fatalf("unexpected draft: %+v", saved)
+	}
+
+	if err := run(newDraftsEditCmd(f), saved.ID, "--topic", "golang"); err != nil {
+		t.Fatalf("drafts edit failed: %v", err)
+	}
+	store := drafts.NewStore(drafts.DefaultDir())
+	edited, err := store.Get(saved.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if edited.Text != "draft one" || edited.Topic != "golang" {
+		t.Fatalf("expected edit to keep text and set topic, got %+v", edited)
+	}
+
+	if err := run(newDraftsListCmd(f)); err != nil {
+		t.Fatalf("drafts list failed: %v", err)
+	}
+	if !strings.Contains(out.String(), saved.ID) {
+		t.Errorf("expected list output to contain %s, got %s", saved.ID, out.String())
+	}
+
+	if err := run(newDraftsPublishCmd(f), saved.ID); err != nil {
+		t.Fatalf("drafts publish failed: %v", err)
+	}
+	if published["text"] != "draft one" || published["topic_tag"] != "golang" {
+		t.Errorf("unexpected published content: %+v", published)
+	}
+	if _, err := store.Get(saved.ID); err == nil {
+		t.Error("expected draft to be removed after publishing")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/salmonumbrella/threads-cli/internal/api"
 	"github.com/salmonumbrella/threads-cli/internal/outfmt"
@@ -40,7 +41,7 @@ type errorPayload struct {
 	Suggestion string `json:"suggestion,omitempty"`
 
 	// Kind is a stable, coarse classification that agents can use for branching.
-	Kind string `json:"kind,omitempty"` // auth|rate_limit|validation|network|api|unknown
+	Kind string `json:"kind,omitempty"` // auth|rate_limit|quota|validation|network|api|unknown
 
 	// API details, when available.
 	Code      int    `json:"code,omitempty"`
@@ -49,7 +50,7 @@ type errorPayload struct {
 	RequestID string `json:"request_id,omitempty"`
 	Temporary bool   `json:"temporary,omitempty"`
 
-	// RetryAfterSeconds is set for rate limit errors when present.
+	// RetryAfterSeconds is set for rate limit and quota errors when present.
 	RetryAfterSeconds int64 `json:"retry_after_seconds,omitempty"`
 }
 
@@ -91,6 +92,7 @@ func writeErrorJSONTo(ctx context.Context, w io.Writer, formatted error) error {
 	// Enrich with typed API errors if we can.
 	var authErr *api.AuthenticationError
 	var rateErr *api.RateLimitError
+	var quotaErr *api.QuotaError
 	var valErr *api.ValidationError
 	var netErr *api.NetworkError
 	var apiErr *api.APIError
@@ -105,6 +107,11 @@ func writeErrorJSONTo(ctx context.Context, w io.Writer, formatted error) error {
 		payload.Code = rateErr.Code
 		payload.Type = rateErr.Type
 		payload.RetryAfterSeconds = int64(rateErr.RetryAfter.Seconds())
+	case errors.As(root, &quotaErr):
+		payload.Kind = "quota"
+		payload.Code = quotaErr.Code
+		payload.Type = quotaErr.Type
+		payload.RetryAfterSeconds = int64(time.Until(quotaErr.RetryAt).Seconds())
 	case errors.As(root, &valErr):
 		payload.Kind = "validation"
 		payload.Code = valErr.Code
@@ -145,6 +152,24 @@ func FormatError(err error) error {
 		return ufErr
 	}
 
+	// A publish that went through even though its response was lost
+	if errors.Is(err, api.ErrPublishedIDUnknown) {
+		return &UserFriendlyError{
+			Message:    "The post was published, but its ID could not be retrieved",
+			Suggestion: "Do not publish it again; find it with 'threads posts list'",
+			Cause:      err,
+		}
+	}
+
+	// A publish that failed after it may have reached Threads
+	if errors.Is(err, api.ErrPublishOutcomeUnknown) {
+		return &UserFriendlyError{
+			Message:    "The post may have been published before the request failed",
+			Suggestion: "Check 'threads posts list' before publishing it again",
+			Cause:      err,
+		}
+	}
+
 	// Check for authentication errors
 	var authErr *api.AuthenticationError
 	if errors.As(err, &authErr) {
@@ -157,6 +182,12 @@ func FormatError(err error) error {
 		return formatRateLimitError(rateLimitErr)
 	}
 
+	// Check for local publishing quota errors
+	var quotaErr *api.QuotaError
+	if errors.As(err, &quotaErr) {
+		return formatQuotaError(quotaErr)
+	}
+
 	// Check for validation errors
 	var validationErr *api.ValidationError
 	if errors.As(err, &validationErr) {
@@ -169,6 +200,12 @@ func FormatError(err error) error {
 		return formatNetworkError(networkErr)
 	}
 
+	// Check for container processing errors
+	var containerErr *api.ContainerError
+	if errors.As(err, &containerErr) {
+		return formatContainerError(containerErr)
+	}
+
 	// Check for API errors
 	var apiErr *api.APIError
 	if errors.As(err, &apiErr) {
@@ -231,6 +268,15 @@ func formatRateLimitError(err *api.RateLimitError) *UserFriendlyError {
 	}
 }
 
+func formatQuotaError(err *api.QuotaError) *UserFriendlyError {
+	return &UserFriendlyError{
+		Message: fmt.Sprintf("Quota reached: %d of %d %ss used in the last 24 hours", err.Used, err.Limit, err.Action),
+		Suggestion: fmt.Sprintf("Try again after %s, or use 'threads schedule add' to publish later. Run 'threads ratelimit history' to see recent usage",
+			err.RetryAt.Local().Format("2006-01-02 15:04")),
+		Cause: err,
+	}
+}
+
 func formatValidationError(err *api.ValidationError) *UserFriendlyError {
 	msg := "Invalid input"
 	suggestion := ""
@@ -256,6 +302,11 @@ func formatValidationError(err *api.ValidationError) *UserFriendlyError {
 		suggestion = "Use a supported media format (JPEG, PNG for images; MP4 for videos)"
 	case strings.Contains(lowerMsg, "carousel") && strings.Contains(lowerMsg, "items"):
 		suggestion = "Carousel posts require 2-20 media items"
+	case strings.Contains(lowerMsg, "media requirements"),
+		strings.Contains(lowerMsg, "media url is not reachable"),
+		strings.Contains(lowerMsg, "media could not be inspected"):
+		msg = fmt.Sprintf("%s: %s", err.Message, err.Details)
+		suggestion = "Run 'threads media check' for details, or set THREADS_SKIP_MEDIA_CHECK=1 to skip the pre-flight check"
 	}
 
 	return &UserFriendlyError{
@@ -345,6 +396,35 @@ func formatAPIError(err *api.APIError) *UserFriendlyError {
 	}
 }
 
+func formatContainerError(err *api.ContainerError) *UserFriendlyError {
+	var msg string
+	var suggestion string
+
+	switch err.Status {
+	case api.ContainerStatusError:
+		msg = fmt.Sprintf("Media processing failed: %s", err.Details)
+		suggestion = "Check that the media URL is accessible and the format is supported (JPEG, PNG for images; MP4 for videos)"
+
+	case api.ContainerStatusExpired:
+		msg = "Media container expired before publishing"
+		suggestion = "Re-upload the media and publish immediately after container creation"
+
+	case api.ContainerStatusPublished:
+		msg = fmt.Sprintf("Container %s was already published", err.ContainerID)
+		suggestion = "Run 'threads posts list' to find the published post"
+
+	default:
+		msg = "Timeout waiting for media processing"
+		suggestion = fmt.Sprintf("The container may still finish processing. Check it with 'threads containers status %s' and publish it with 'threads containers publish %s'", err.ContainerID, err.ContainerID)
+	}
+
+	return &UserFriendlyError{
+		Message:    msg,
+		Suggestion: suggestion,
+		Cause:      err,
+	}
+}
+
 func formatGenericError(errMsg string, originalErr error) error {
 	lowerMsg := strings.ToLower(errMsg)
 
@@ -135,6 +135,22 @@ func TestFormatError_RateLimitError(t *testing.T) {
 	}
 }
 
+func TestFormatError_QuotaError(t *testing.T) {
+	err := fmt.Errorf("publish: %w", api.NewQuotaError(api.QuotaDelete, 25, 25, time.Now().Add(time.Hour)))
+	formatted := FormatError(err)
+
+	ufErr, ok := formatted.(*UserFriendlyError)
+	if !ok {
+		t.Fatalf("FormatError() did not return *UserFriendlyError, got %T", formatted)
+	}
+	if !strings.Contains(ufErr.Message, "25 of 25 deletes") {
+		t.Errorf("Message = %q, want usage", ufErr.Message)
+	}
+	if !strings.Contains(ufErr.Suggestion, "threads ratelimit history") {
+		t.Errorf("Suggestion = %q, want history hint", ufErr.Suggestion)
+	}
+}
+
 func TestFormatError_ValidationError(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -4,30 +4,42 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/term"
 
 	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/cache"
 	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/containers"
 	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/media"
 	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/quota"
 	"github.com/salmonumbrella/threads-cli/internal/secrets"
+	"github.com/salmonumbrella/threads-cli/internal/telemetry"
 	"github.com/salmonumbrella/threads-cli/internal/ui"
 )
 
 // Factory provides shared dependencies and helpers for commands.
 type Factory struct {
-	IO         *iocontext.IO
-	Config     *config.Config
-	Store      func() (secrets.Store, error)
-	NewClient  func(accessToken string, cfg *api.Config) (*api.Client, error)
-	Output     outfmt.Format
-	ColorMode  outfmt.ColorMode
-	Debug      bool
-	Account    string
-	debugLog   api.Logger
-	loggerOnce sync.Once
+	IO        *iocontext.IO
+	Config    *config.Config
+	Store     func() (secrets.Store, error)
+	NewClient func(accessToken string, cfg *api.Config) (*api.Client, error)
+	Output    outfmt.Format
+	ColorMode outfmt.ColorMode
+	Debug     bool
+	Account   string
+	CacheMode api.CacheMode
+	// Telemetry records API calls when a trace file or collector is set.
+	Telemetry   *telemetry.Recorder
+	commandSpan api.Span
+	debugLog    api.Logger
+	loggerOnce  sync.Once
 }
 
 // FactoryOptions allows overriding factory dependencies (mainly for tests).
@@ -56,8 +68,15 @@ func NewFactory(ctx context.Context, opts FactoryOptions) (*Factory, error) {
 
 	store := opts.Store
 	if store == nil {
+		// Opening a store may prompt or run a key command, so do it once
+		var once sync.Once
+		var opened secrets.Store
+		var openErr error
 		store = func() (secrets.Store, error) {
-			return secrets.OpenDefault()
+			once.Do(func() {
+				opened, openErr = secrets.Open(cfg.Secrets)
+			})
+			return opened, openErr
 		}
 	}
 
@@ -100,6 +119,10 @@ func (f *Factory) UI(ctx context.Context) *ui.Printer {
 // This is useful for avoiding extra API calls (e.g. GetMe) when we already
 // have stable identifiers like user_id.
 func (f *Factory) ActiveCredentials(_ context.Context) (*secrets.Credentials, error) {
+	if creds, err := replayCredentials(); creds != nil || err != nil {
+		return creds, err
+	}
+
 	account, err := f.resolveAccount()
 	if err != nil {
 		return nil, err
@@ -131,30 +154,198 @@ func (f *Factory) Client(ctx context.Context) (*api.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := checkScopes(ctx, creds); err != nil {
+		return nil, err
+	}
+
+	cassette, err := api.CassetteFromEnv()
+	if err != nil {
+		return nil, &UserFriendlyError{
+			Message:    err.Error(),
+			Suggestion: "Set THREADS_RECORD to capture a session, or THREADS_REPLAY to play one back",
+		}
+	}
+
+	// Cassettes pin the token they were recorded with
+	if cassette == nil {
+		creds = f.refreshAhead(ctx, creds)
+	}
 
 	cfg := &api.Config{
-		ClientID:     creds.ClientID,
-		ClientSecret: creds.ClientSecret,
-		Debug:        f.Debug,
+		ClientID:         creds.ClientID,
+		ClientSecret:     creds.ClientSecret,
+		RedirectURI:      creds.RedirectURI,
+		BaseURL:          os.Getenv("THREADS_BASE_URL"),
+		Debug:            f.Debug,
+		ContainerJournal: containers.NewJournal(containers.DefaultPath()),
+		QuotaLedger:      quota.NewLedger(quota.DefaultPath()),
+		Cassette:         cassette,
+	}
+
+	if f.Config == nil || !f.Config.SkipMediaCheck {
+		cfg.MediaChecker = media.NewChecker(nil)
+	}
+
+	if f.Config != nil && f.Config.Throttle != nil {
+		cfg.Throttle = throttleConfig(f.Config.Throttle)
+		if priority, ok := api.PriorityFromContext(ctx); ok {
+			cfg.Throttle.Priority = priority
+		}
+	}
+
+	if cfg.Cassette != nil && cfg.Cassette.Mode == api.CassetteRecord {
+		cfg.Cassette.Account = &api.CassetteAccount{
+			Name:     creds.Name,
+			UserID:   creds.UserID,
+			Username: creds.Username,
+		}
+	}
+
+	// A cassette must see every request, so cached responses are not used
+	if cfg.Cassette == nil {
+		if cfg.Cache, err = f.cacheConfig(); err != nil {
+			return nil, err
+		}
 	}
 
 	if f.Debug {
 		cfg.Logger = f.logger()
 	}
 
+	if f.Telemetry != nil {
+		cfg.Telemetry = f.Telemetry
+	}
+
 	client, err := f.NewClient(creds.AccessToken, cfg)
 	if err != nil {
 		return nil, WrapError("failed to create API client", err)
 	}
 
+	// The recorded token has a real expiry; keep replays from refreshing it
+	if cfg.Cassette != nil && cfg.Cassette.Mode == api.CassetteReplay {
+		if err := client.SetTokenInfo(&api.TokenInfo{
+			AccessToken: creds.AccessToken,
+			TokenType:   "Bearer",
+			ExpiresAt:   time.Now().Add(24 * time.Hour),
+			UserID:      creds.UserID,
+			CreatedAt:   time.Now(),
+		}); err != nil {
+			return nil, WrapError("failed to create API client", err)
+		}
+	}
+
 	return client, nil
 }
 
+// throttleConfig converts the configured request rates for the API client.
+// The buckets are shared through a file in the data directory, so every CLI
+// process draws from the same rates.
+func throttleConfig(t *config.ThrottleConfig) *api.ThrottleConfig {
+	return &api.ThrottleConfig{
+		StatePath: filepath.Join(config.DataDir(), "throttle.json"),
+		Rates: map[api.EndpointClass]float64{
+			api.EndpointRead:     t.Read,
+			api.EndpointPublish:  t.Publish,
+			api.EndpointInsights: t.Insights,
+			api.EndpointSearch:   t.Search,
+		},
+		Burst: t.Burst,
+	}
+}
+
+// cacheConfig returns the response cache for the active account, or nil
+// when caching is turned off.
+func (f *Factory) cacheConfig() (*api.CacheConfig, error) {
+	var settings *config.CacheConfig
+	if f.Config != nil {
+		settings = f.Config.Cache
+	}
+	if f.CacheMode == api.CacheOff || (settings != nil && settings.Disabled) {
+		return nil, nil
+	}
+
+	account, err := f.resolveAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &api.CacheConfig{
+		Store: cache.New(cache.DefaultDir(), account),
+		Mode:  f.CacheMode,
+	}
+	if settings == nil || len(settings.TTL) == 0 {
+		return cfg, nil
+	}
+
+	cfg.TTLs = make(map[api.CacheEndpoint]time.Duration, len(settings.TTL))
+	for name, value := range settings.TTL {
+		endpoint := api.CacheEndpoint(strings.ToLower(name))
+		if _, ok := api.DefaultCacheTTLs[endpoint]; !ok {
+			return nil, &UserFriendlyError{
+				Message:    fmt.Sprintf("Unknown cache endpoint in config: %s", name),
+				Suggestion: "Valid endpoints are: post, user, insights, location",
+			}
+		}
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return nil, &UserFriendlyError{
+				Message:    fmt.Sprintf("Invalid cache TTL for %s: %s", name, value),
+				Suggestion: "Use a duration such as 30s, 10m or 1h, or 0 to disable caching",
+			}
+		}
+		if ttl == 0 {
+			ttl = -1
+		}
+		cfg.TTLs[endpoint] = ttl
+	}
+	return cfg, nil
+}
+
+// replayCredentials returns placeholder credentials for the account a
+// THREADS_REPLAY cassette was recorded with, so replays need no stored
+// credentials. It returns nil when not replaying.
+func replayCredentials() (*secrets.Credentials, error) {
+	cassette, err := api.CassetteFromEnv()
+	if err != nil || cassette == nil || cassette.Mode != api.CassetteReplay {
+		return nil, nil //nolint:nilerr // Reported when the client is created
+	}
+
+	recorded, err := api.LoadCassette(cassette.Path)
+	if err != nil {
+		return nil, &UserFriendlyError{
+			Message:    fmt.Sprintf("Failed to read cassette %s", cassette.Path),
+			Suggestion: "Record one first by running the command with THREADS_RECORD=" + cassette.Path,
+			Cause:      err,
+		}
+	}
+	if recorded.Account == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	return &secrets.Credentials{
+		Name:         recorded.Account.Name,
+		AccessToken:  "replay",
+		UserID:       recorded.Account.UserID,
+		Username:     recorded.Account.Username,
+		ExpiresAt:    now.Add(24 * time.Hour),
+		CreatedAt:    now,
+		ClientID:     "replay",
+		ClientSecret: "replay",
+	}, nil
+}
+
 func (f *Factory) resolveAccount() (string, error) {
 	if f.Account != "" {
 		return f.Account, nil
 	}
 
+	if creds, err := replayCredentials(); err != nil {
+		return "", err
+	} else if creds != nil {
+		return creds.Name, nil
+	}
+
 	store, err := f.Store()
 	if err != nil {
 		return "", FormatError(err)
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+func TestFactory_RecordAndReplay(t *testing.T) {
+	setTestDataDir(t)
+	path := filepath.Join(t.TempDir(), "me.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/debug_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"is_valid":   true,
+				"user_id":    "12345",
+				"issued_at":  time.Now().Add(-time.Hour).Unix(),
+				"expires_at": time.Now().Add(2 * time.Hour).Unix(),
+			}})
+		case "/12345":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "12345", "username": "testuser"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	run := func(store func() (secrets.Store, error)) string {
+		t.Helper()
+		streams := &iocontext.IO{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}, In: &bytes.Buffer{}}
+		f, err := NewFactory(context.Background(), FactoryOptions{
+			IO:     streams,
+			Config: config.Default(),
+			Store:  store,
+			NewClient: func(accessToken string, cfg *api.Config) (*api.Client, error) {
+				cfg.BaseURL = server.URL
+				cfg.RedirectURI = "https://example.com/callback"
+				return api.NewClientWithToken(accessToken, cfg)
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := NewUsersMeCmd(f)
+		cmd.SetContext(outfmt.WithFormat(iocontext.WithIO(context.Background(), streams), "json"))
+		cmd.SetArgs([]string{})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("me failed: %v (stderr: %s)", err, streams.ErrOut.(*bytes.Buffer).String())
+		}
+		return streams.Out.(*bytes.Buffer).String()
+	}
+
+	t.Setenv("THREADS_RECORD", path)
+	recorded := run(func() (secrets.Store, error) {
+		return &mockCredentialsStore{creds: testCredentials()}, nil
+	})
+
+	// Replay with the API gone and no stored credentials.
+	server.Close()
+	t.Setenv("THREADS_RECORD", "")
+	t.Setenv("THREADS_REPLAY", path)
+	replayed := run(func() (secrets.Store, error) {
+		return nil, errors.New("no keychain")
+	})
+
+	if replayed != recorded {
+		t.Errorf("replayed output differs:\nrecorded: %s\nreplayed: %s", recorded, replayed)
+	}
+}
+
+func TestFactory_ReplayMissingCassette(t *testing.T) {
+	t.Setenv("THREADS_REPLAY", filepath.Join(t.TempDir(), "missing.json"))
+
+	f, _ := newIntegrationTestFactory(t, "http://localhost")
+	_, err := f.ActiveCredentials(context.Background())
+	var friendly *UserFriendlyError
+	if !errors.As(err, &friendly) {
+		t.Fatalf("expected a friendly error, got %v", err)
+	}
+}
+
+func TestFactory_StoreFromConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.Secrets = &config.SecretsConfig{
+		Backend: "vault",
+		Vault:   &config.VaultSecretsConfig{Address: "http://127.0.0.1:8200", Token: "root"},
+	}
+	f, err := NewFactory(context.Background(), FactoryOptions{Config: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := f.Store()
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if _, ok := store.(*secrets.VaultStore); !ok {
+		t.Errorf("expected the configured vault store, got %T", store)
+	}
+	if again, _ := f.Store(); again != store {
+		t.Error("expected the store to be opened once")
+	}
+}
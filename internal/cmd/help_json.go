@@ -18,6 +18,7 @@ type helpJSON struct {
 	Long        string   `json:"long,omitempty"`
 	Example     string   `json:"example,omitempty"`
 	Aliases     []string `json:"aliases"`
+	Scopes      []string `json:"scopes,omitempty"`
 
 	Flags          []helpFlag `json:"flags"`
 	InheritedFlags []helpFlag `json:"inherited_flags"`
@@ -92,6 +93,7 @@ func buildHelpJSON(cmd *cobra.Command) helpJSON {
 		Long:           strings.TrimSpace(cmd.Long),
 		Example:        strings.TrimSpace(cmd.Example),
 		Aliases:        []string{},
+		Scopes:         commandScopes(cmd),
 		Flags:          []helpFlag{},
 		InheritedFlags: []helpFlag{},
 		Subcommands:    []helpSubcommand{},
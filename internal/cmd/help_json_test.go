@@ -59,6 +59,9 @@ func TestHelpJSONCmd_SubcommandPath(t *testing.T) {
 	if payload["command_path"] != "threads posts get" {
 		t.Fatalf("expected command_path=threads posts get, got %v", payload["command_path"])
 	}
+	if scopes, _ := payload["scopes"].([]any); len(scopes) != 1 || scopes[0] != "threads_basic" {
+		t.Errorf("expected scopes [threads_basic], got %v", payload["scopes"])
+	}
 	if payload["use"] == "" {
 		t.Fatalf("expected use to be set, got empty")
 	}
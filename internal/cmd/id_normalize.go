@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
 	"strings"
+	"unicode"
+
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
 )
 
 // normalizeIDArg accepts common agent shorthands:
@@ -158,3 +164,41 @@ func extractUsernameFromURL(raw string) (string, bool) {
 	}
 	return "", false
 }
+
+// readIDsFile reads IDs separated by whitespace or commas from path, or from
+// stdin when path is "-", and normalizes each with normalizeIDArg.
+func readIDsFile(ctx context.Context, path string, expectedKind string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(iocontext.GetIO(ctx).In)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // Reading a user-supplied file path is intentional for CLI automation.
+	}
+	if err != nil {
+		return nil, &UserFriendlyError{
+			Message:    fmt.Sprintf("Failed to read IDs file: %s", path),
+			Suggestion: "Check the path or use --ids-file - to read from stdin",
+			Cause:      err,
+		}
+	}
+
+	fields := strings.FieldsFunc(string(data), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	ids := make([]string, 0, len(fields))
+	for _, field := range fields {
+		id, errID := normalizeIDArg(field, expectedKind)
+		if errID != nil {
+			return nil, errID
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, &UserFriendlyError{
+			Message:    fmt.Sprintf("No %s IDs found in %s", expectedKind, path),
+			Suggestion: "List one ID per line, or separate them with commas",
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/inbox"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+type inboxOptions struct {
+	SinceLast bool
+	All       bool
+	Archived  bool
+	MarkRead  bool
+	Offline   bool
+	Limit     int
+	Posts     int
+}
+
+// NewInboxCmd builds the inbox command.
+func NewInboxCmd(f *Factory) *cobra.Command {
+	opts := &inboxOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "inbox",
+		Short: "Show new mentions and replies",
+		Long: `Show mentions of you and replies to your recent posts that you have not
+read yet.
+
+Each run fetches your latest mentions and the replies on your most recent
+posts, and remembers every item it has seen in a local inbox under the data
+directory. Items stay unread until you run 'threads inbox mark-read', and
+'threads inbox archive' hides them for good.
+
+For cron jobs, --since-last shows only items that were not seen by any
+earlier run, so each new mention or reply is processed exactly once.`,
+		Example: `  # Show unread mentions and replies
+  threads inbox
+
+  # Process only what arrived since the last run
+  threads inbox --since-last -o jsonl | ./handle-items.sh
+
+  # Show and mark everything read in one go
+  threads inbox --mark-read
+
+  # Manage items
+  threads inbox mark-read 18028939643234567
+  threads inbox archive --all`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInbox(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.SinceLast, "since-last", false, "Only show items first seen by this run")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Include read items")
+	cmd.Flags().BoolVar(&opts.Archived, "archived", false, "Show archived items")
+	cmd.Flags().BoolVar(&opts.MarkRead, "mark-read", false, "Mark the shown items as read")
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Show stored items without fetching")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 25, "Mentions, and replies per post, to fetch")
+	cmd.Flags().IntVar(&opts.Posts, "posts", 10, "Number of recent posts to check for replies")
+	cmd.MarkFlagsMutuallyExclusive("since-last", "all", "archived")
+	cmd.MarkFlagsMutuallyExclusive("since-last", "offline")
+
+	cmd.AddCommand(newInboxSetStateCmd(f, "mark-read", inbox.StateRead))
+	cmd.AddCommand(newInboxSetStateCmd(f, "archive", inbox.StateArchived))
+
+	return cmd
+}
+
+func runInbox(cmd *cobra.Command, f *Factory, opts *inboxOptions) error {
+	ctx := cmd.Context()
+	store := inbox.NewStore(inbox.DefaultPath())
+
+	account, err := f.resolveAccount()
+	if err != nil {
+		return err
+	}
+
+	var added []inbox.Item
+	var lastChecked time.Time
+	if opts.Offline {
+		if lastChecked, err = store.LastChecked(account); err != nil {
+			return WrapError("failed to read inbox", err)
+		}
+	} else {
+		client, errClient := f.Client(ctx)
+		if errClient != nil {
+			return errClient
+		}
+		creds, errCreds := f.ActiveCredentials(ctx)
+		if errCreds != nil {
+			return errCreds
+		}
+
+		fetched, errFetch := fetchInboxItems(ctx, client, creds, opts)
+		if errFetch != nil {
+			return errFetch
+		}
+		if added, lastChecked, err = store.Sync(account, fetched); err != nil {
+			return WrapError("failed to update inbox", err)
+		}
+	}
+
+	var items []inbox.Item
+	switch {
+	case opts.SinceLast:
+		items = added
+	case opts.Archived:
+		items, err = store.List(account, inbox.StateArchived)
+	case opts.All:
+		items, err = store.List(account, inbox.StateUnread, inbox.StateRead)
+	default:
+		items, err = store.List(account, inbox.StateUnread)
+	}
+	if err != nil {
+		return WrapError("failed to read inbox", err)
+	}
+	if items == nil {
+		items = []inbox.Item{}
+	}
+
+	if err := printInboxItems(ctx, f, items, len(added), lastChecked, opts); err != nil {
+		return err
+	}
+
+	if opts.MarkRead && len(items) > 0 {
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			if item.State == inbox.StateUnread {
+				ids = append(ids, item.ID)
+			}
+		}
+		if len(ids) > 0 {
+			if _, err := store.SetState(account, ids, inbox.StateRead); err != nil {
+				return WrapError("failed to mark items read", err)
+			}
+		}
+	}
+	return nil
+}
+
+// fetchInboxItems fetches recent mentions and the replies on recent posts,
+// skipping replies written by the account itself.
+func fetchInboxItems(ctx context.Context, client *api.Client, creds *secrets.Credentials, opts *inboxOptions) ([]inbox.Item, error) {
+	userID := api.UserID(creds.UserID)
+
+	mentions, err := client.GetUserMentions(ctx, userID, &api.PaginationOptions{Limit: opts.Limit})
+	if err != nil {
+		return nil, WrapError("failed to get mentions", err)
+	}
+
+	var items []inbox.Item
+	for _, post := range mentions.Data {
+		items = append(items, inboxItem(post, inbox.KindMention, ""))
+	}
+
+	if opts.Posts <= 0 {
+		return items, nil
+	}
+
+	posts, err := client.GetUserPosts(ctx, userID, &api.PaginationOptions{Limit: opts.Posts})
+	if err != nil {
+		return nil, WrapError("failed to get recent posts", err)
+	}
+	for _, post := range posts.Data {
+		if !post.HasReplies {
+			continue
+		}
+		replies, err := client.GetReplies(ctx, api.PostID(post.ID), &api.RepliesOptions{Limit: opts.Limit})
+		if err != nil {
+			return nil, WrapError(fmt.Sprintf("failed to get replies for post %s", post.ID), err)
+		}
+		for _, reply := range replies.Data {
+			if reply.IsReplyOwnedByMe || (creds.Username != "" && reply.Username == creds.Username) {
+				continue
+			}
+			items = append(items, inboxItem(reply, inbox.KindReply, post.ID))
+		}
+	}
+	return items, nil
+}
+
+func inboxItem(post api.Post, kind inbox.Kind, inReplyTo string) inbox.Item {
+	return inbox.Item{
+		ID:        post.ID,
+		Kind:      kind,
+		Username:  post.Username,
+		Text:      post.Text,
+		Permalink: post.Permalink,
+		Timestamp: post.Timestamp.Time,
+		InReplyTo: inReplyTo,
+	}
+}
+
+func printInboxItems(ctx context.Context, f *Factory, items []inbox.Item, added int, lastChecked time.Time, opts *inboxOptions) error {
+	io := iocontext.GetIO(ctx)
+	out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+
+	if outfmt.IsJSONL(ctx) {
+		return out.Output(items)
+	}
+	if outfmt.GetFormat(ctx) == outfmt.JSON {
+		return out.Output(itemsEnvelope(items, nil, ""))
+	}
+
+	if !opts.Offline {
+		since := "first check"
+		if !lastChecked.IsZero() {
+			since = "since " + lastChecked.Local().Format("2006-01-02 15:04")
+		}
+		f.UI(ctx).Info("%d new item(s) %s", added, since)
+	}
+
+	if len(items) == 0 {
+		out.Empty("Inbox is empty")
+		return nil
+	}
+
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		text := item.Text
+		if len(text) > 50 {
+			text = text[:47] + "..."
+		}
+		rows[i] = []string{
+			item.ID,
+			string(item.Kind),
+			"@" + item.Username,
+			text,
+			item.Timestamp.Local().Format("2006-01-02 15:04"),
+			string(item.State),
+		}
+	}
+	return out.Table([]string{"ID", "KIND", "FROM", "TEXT", "TIMESTAMP", "STATE"}, rows, []outfmt.ColumnType{
+		outfmt.ColumnID,
+		outfmt.ColumnPlain,
+		outfmt.ColumnPlain,
+		outfmt.ColumnPlain,
+		outfmt.ColumnDate,
+		outfmt.ColumnStatus,
+	})
+}
+
+func newInboxSetStateCmd(f *Factory, use string, state inbox.State) *cobra.Command {
+	var all bool
+
+	short := "Mark inbox items as read"
+	allHelp := "Mark every unread item as read"
+	from := []inbox.State{inbox.StateUnread}
+	if state == inbox.StateArchived {
+		short = "Archive inbox items so they are never shown again"
+		allHelp = "Archive every unread and read item"
+		from = []inbox.State{inbox.StateUnread, inbox.StateRead}
+	}
+
+	cmd := &cobra.Command{
+		Use:   use + " [id...]",
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if len(args) == 0 && !all {
+				return &UserFriendlyError{
+					Message:    "No inbox items given",
+					Suggestion: "Pass item IDs from 'threads inbox', or use --all",
+				}
+			}
+
+			account, err := f.resolveAccount()
+			if err != nil {
+				return err
+			}
+			store := inbox.NewStore(inbox.DefaultPath())
+
+			var changed int
+			if all {
+				changed, err = store.SetStateWhere(account, state, from...)
+			} else {
+				var updated []inbox.Item
+				updated, err = store.SetState(account, args, state)
+				changed = len(updated)
+			}
+			if errors.Is(err, inbox.ErrNotFound) {
+				return &UserFriendlyError{
+					Message:    err.Error(),
+					Suggestion: "Run 'threads inbox --all' to see item IDs",
+				}
+			}
+			if err != nil {
+				return WrapError("failed to update inbox", err)
+			}
+
+			io := iocontext.GetIO(ctx)
+			if outfmt.IsJSON(ctx) {
+				out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+				return out.Output(map[string]any{
+					"state":   state,
+					"updated": changed,
+				})
+			}
+			f.UI(ctx).Success("%d item(s) marked %s", changed, state)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, allHelp)
+	return cmd
+}
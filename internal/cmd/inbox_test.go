@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+// inboxTestServer serves mentions and replies; mentions can be changed
+// between runs.
+type inboxTestServer struct {
+	mu       sync.Mutex
+	mentions []map[string]any
+}
+
+func (s *inboxTestServer) handler(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	var data []map[string]any
+	switch r.URL.Path {
+	case "/refresh_access_token":
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "refreshed-token", "token_type": "Bearer", "expires_in": 3600})
+		return
+	case "/12345/mentions":
+		data = s.mentions
+	case "/12345/threads":
+		data = []map[string]any{
+			{"id": "p1", "text": "my post", "has_replies": true},
+			{"id": "p2", "text": "quiet post"},
+		}
+	case "/p1/replies":
+		data = []map[string]any{
+			{"id": "r1", "username": "carol", "text": "nice", "timestamp": "2026-01-02T10:00:00+0000"},
+			{"id": "r2", "username": "testuser", "text": "thanks!", "timestamp": "2026-01-02T11:00:00+0000"},
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func runInboxCmd(t *testing.T, f *Factory, args ...string) []map[string]any {
+	t.Helper()
+	out := &bytes.Buffer{}
+	f.IO.Out = out
+	ctx := outfmt.WithFormat(iocontext.WithIO(context.Background(), f.IO), "jsonl")
+
+	cmd := NewInboxCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("inbox %v failed: %v", args, err)
+	}
+
+	var items []map[string]any
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		var item map[string]any
+		if err := dec.Decode(&item); err != nil {
+			t.Fatalf("invalid JSONL output: %v", err)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func itemIDs(items []map[string]any) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i], _ = item["id"].(string)
+	}
+	return ids
+}
+
+func TestInbox_TracksNewItems(t *testing.T) {
+	setTestDataDir(t)
+	srv := &inboxTestServer{mentions: []map[string]any{
+		{"id": "m1", "username": "bob", "text": "hey @testuser", "timestamp": "2026-01-02T09:00:00+0000"},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(srv.handler))
+	defer server.Close()
+
+	f, _ := newIntegrationTestFactory(t, server.URL)
+
+	// First run: the mention and carol's reply are new; our own reply is skipped.
+	if got := itemIDs(runInboxCmd(t, f, "--since-last")); len(got) != 2 || got[0] != "r1" || got[1] != "m1" {
+		t.Fatalf("expected r1 and m1, got %v", got)
+	}
+
+	// Nothing new on the second run, but both remain unread.
+	if got := runInboxCmd(t, f, "--since-last"); len(got) != 0 {
+		t.Fatalf("expected no new items, got %v", itemIDs(got))
+	}
+	if got := runInboxCmd(t, f); len(got) != 2 {
+		t.Fatalf("expected 2 unread items, got %v", itemIDs(got))
+	}
+
+	// A new mention arrives.
+	srv.mu.Lock()
+	srv.mentions = append(srv.mentions, map[string]any{"id": "m2", "username": "dave", "text": "ping", "timestamp": "2026-01-03T09:00:00+0000"})
+	srv.mu.Unlock()
+	if got := itemIDs(runInboxCmd(t, f, "--since-last", "--mark-read")); len(got) != 1 || got[0] != "m2" {
+		t.Fatalf("expected only m2, got %v", got)
+	}
+
+	runInboxCmd(t, f, "mark-read", "m1")
+	runInboxCmd(t, f, "archive", "r1")
+
+	if got := runInboxCmd(t, f, "--offline"); len(got) != 0 {
+		t.Errorf("expected no unread items, got %v", itemIDs(got))
+	}
+	if got := itemIDs(runInboxCmd(t, f, "--offline", "--all")); len(got) != 2 {
+		t.Errorf("expected m1 and m2 as read, got %v", got)
+	}
+	archived := runInboxCmd(t, f, "--offline", "--archived")
+	if len(archived) != 1 || archived[0]["id"] != "r1" || archived[0]["in_reply_to"] != "p1" {
+		t.Errorf("unexpected archived items: %v", archived)
+	}
+}
+
+func TestInbox_MarkReadUnknownID(t *testing.T) {
+	setTestDataDir(t)
+	f, io := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+
+	cmd := NewInboxCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), io))
+	cmd.SetArgs([]string{"mark-read", "missing"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an unknown item")
+	}
+
+	cmd = NewInboxCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), io))
+	cmd.SetArgs([]string{"archive"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error without IDs or --all")
+	}
+}
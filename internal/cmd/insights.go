@@ -19,14 +19,15 @@ func NewInsightsCmd(f *Factory) *cobra.Command {
 		Long:  `Access insights and analytics data for posts and your account.`,
 	}
 
-	cmd.AddCommand(newInsightsPostCmd(f))
-	cmd.AddCommand(newInsightsAccountCmd(f))
+	cmd.AddCommand(requireScopes(newInsightsPostCmd(f), scopeBasic, scopeManageInsights))
+	cmd.AddCommand(requireScopes(newInsightsAccountCmd(f), scopeBasic, scopeManageInsights))
 
 	return cmd
 }
 
 type insightsPostOptions struct {
 	Metrics []string
+	IDsFile string
 }
 
 func newInsightsPostCmd(f *Factory) *cobra.Command {
@@ -49,14 +50,38 @@ Examples:
   threads insights post 12345678901234567
   threads insights post 12345678901234567 --metrics views,likes,replies
   threads insights post 12345678901234567 --metrics link_clicks,profile_clicks
-  threads insights post 12345678901234567 --output json`,
-		Args: cobra.ExactArgs(1),
+  threads insights post 12345678901234567 --output json
+  threads insights post --ids-file ids.txt --metrics views,likes
+
+With --ids-file, post IDs are read one per line (or separated by commas) and
+fetched in batches, and the metrics are shown as one row per post.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.IDsFile != "" {
+				if len(args) > 0 {
+					return &UserFriendlyError{
+						Message:    "Cannot combine a post ID with --ids-file",
+						Suggestion: "Add the post ID to the IDs file instead",
+					}
+				}
+				postIDs, err := readIDsFile(cmd.Context(), opts.IDsFile, "post")
+				if err != nil {
+					return err
+				}
+				return runInsightsPostBatch(cmd, f, opts, postIDs)
+			}
+			if len(args) == 0 {
+				return &UserFriendlyError{
+					Message:    "A post ID is required",
+					Suggestion: "Pass a post ID, or --ids-file to get insights for many posts",
+				}
+			}
 			return runInsightsPost(cmd, f, opts, args[0])
 		},
 	}
 
 	cmd.Flags().StringSliceVar(&opts.Metrics, "metrics", opts.Metrics, "Metrics to retrieve (comma-separated)")
+	cmd.Flags().StringVar(&opts.IDsFile, "ids-file", "", "Read post IDs from a file (- for stdin)")
 	return cmd
 }
 
@@ -91,19 +116,110 @@ func runInsightsPost(cmd *cobra.Command, f *Factory, opts *insightsPostOptions,
 	fmtr.Header("METRIC", "VALUE", "PERIOD")
 
 	for _, insight := range insights.Data {
-		value := 0
-		if len(insight.Values) > 0 {
-			value = insight.Values[0].Value
-		} else if insight.TotalValue != nil {
-			value = insight.TotalValue.Value
-		}
-		fmtr.Row(insight.Name, value, insight.Period)
+		fmtr.Row(insight.Name, insightValue(insight), insight.Period)
 	}
 	fmtr.Flush()
 
 	return nil
 }
 
+// insightsBatchResult is one post of 'insights post --ids-file'.
+type insightsBatchResult struct {
+	ID       string                `json:"id"`
+	Insights *api.InsightsResponse `json:"insights,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+func runInsightsPostBatch(cmd *cobra.Command, f *Factory, opts *insightsPostOptions, postIDs []string) error {
+	ctx := cmd.Context()
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]api.PostID, len(postIDs))
+	for i, id := range postIDs {
+		ids[i] = api.PostID(id)
+	}
+	fetched, err := client.GetPostInsightsBatch(ctx, ids, opts.Metrics)
+	if err != nil {
+		return WrapError("failed to get post insights", err)
+	}
+
+	results := make([]insightsBatchResult, len(fetched))
+	failed := 0
+	for i, r := range fetched {
+		results[i] = insightsBatchResult{ID: r.ID.String(), Insights: r.Insights}
+		if r.Err != nil {
+			results[i].Error = FormatError(r.Err).Error()
+			failed++
+		}
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSONL(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if errOut := out.Output(results); errOut != nil {
+			return errOut
+		}
+	} else if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if errOut := out.Output(itemsEnvelope(results, nil, "")); errOut != nil {
+			return errOut
+		}
+	} else {
+		fmtr := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		header := []string{"POST"}
+		for _, metric := range opts.Metrics {
+			header = append(header, strings.ToUpper(metric))
+		}
+		fmtr.Header(header...)
+
+		for _, r := range results {
+			if r.Error != "" {
+				continue
+			}
+			values := make(map[string]int, len(r.Insights.Data))
+			for _, insight := range r.Insights.Data {
+				values[insight.Name] = insightValue(insight)
+			}
+			row := []any{r.ID}
+			for _, metric := range opts.Metrics {
+				row = append(row, values[metric])
+			}
+			fmtr.Row(row...)
+		}
+		fmtr.Flush()
+
+		p := f.UI(ctx)
+		for _, r := range results {
+			if r.Error != "" {
+				p.Error("%s: %s", r.ID, r.Error)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Insights for %d of %d posts could not be fetched", failed, len(results)),
+			Suggestion: "Check that the failed IDs exist and belong to this account",
+		}
+	}
+	return nil
+}
+
+// insightValue returns the value of a metric, whether the API reported it
+// as a series or a total.
+func insightValue(insight api.Insight) int {
+	if len(insight.Values) > 0 {
+		return insight.Values[0].Value
+	}
+	if insight.TotalValue != nil {
+		return insight.TotalValue.Value
+	}
+	return 0
+}
+
 type insightsAccountOptions struct {
 	Metrics   []string
 	Period    string
@@ -238,13 +354,7 @@ func runInsightsAccount(cmd *cobra.Command, f *Factory, opts *insightsAccountOpt
 	fmtr.Header("METRIC", "VALUE", "PERIOD")
 
 	for _, insight := range insights.Data {
-		value := 0
-		if len(insight.Values) > 0 {
-			value = insight.Values[0].Value
-		} else if insight.TotalValue != nil {
-			value = insight.TotalValue.Value
-		}
-		fmtr.Row(insight.Name, value, insight.Period)
+		fmtr.Row(insight.Name, insightValue(insight), insight.Period)
 	}
 	fmtr.Flush()
 
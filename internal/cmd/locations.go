@@ -16,8 +16,8 @@ func NewLocationsCmd(f *Factory) *cobra.Command {
 		Short:   "Location search and details",
 	}
 
-	cmd.AddCommand(newLocationsSearchCmd(f))
-	cmd.AddCommand(newLocationsGetCmd(f))
+	cmd.AddCommand(requireScopes(newLocationsSearchCmd(f), scopeBasic, scopeLocationTagging))
+	cmd.AddCommand(requireScopes(newLocationsGetCmd(f), scopeBasic, scopeLocationTagging))
 
 	return cmd
 }
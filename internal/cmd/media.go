@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/media"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+// NewMediaCmd builds the media command group.
+func NewMediaCmd(f *Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "media",
+		Short: "Inspect media before posting",
+	}
+
+	cmd.AddCommand(newMediaCheckCmd(f))
+
+	return cmd
+}
+
+// mediaCheckResult is the output of 'media check'.
+type mediaCheckResult struct {
+	*media.Info
+	AspectRatio float64           `json:"aspect_ratio,omitempty"`
+	OK          bool              `json:"ok"`
+	Violations  []media.Violation `json:"violations"`
+}
+
+func newMediaCheckCmd(f *Factory) *cobra.Command {
+	var mediaType string
+
+	cmd := &cobra.Command{
+		Use:   "check [url|file]",
+		Short: "Check an image or video against Threads media limits",
+		Long: `Read the headers of an image or video (a URL or a local file) and check
+it against the limits Threads publishes: format, file size, dimensions and
+aspect ratio, and for video the container, codecs, duration and frame rate.
+
+The same checks run automatically before a post's media container is
+created. Set THREADS_SKIP_MEDIA_CHECK=1 to turn them off.`,
+		Example: `  threads media check ./clip.mp4
+  threads media check https://example.com/photo.jpg --type image`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMediaCheck(cmd, f, args[0], mediaType)
+		},
+	}
+
+	cmd.Flags().StringVar(&mediaType, "type", "", "Check as image or video (default: detected)")
+	return cmd
+}
+
+func runMediaCheck(cmd *cobra.Command, f *Factory, ref, mediaType string) error {
+	ctx := cmd.Context()
+
+	mediaType = strings.ToLower(mediaType)
+	if mediaType != "" && mediaType != "image" && mediaType != "video" {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Invalid --type value: %s", mediaType),
+			Suggestion: "Valid values are: image, video",
+		}
+	}
+
+	info, violations, err := media.NewChecker(nil).Inspect(ctx, ref, mediaType)
+	if err != nil {
+		return WrapError(fmt.Sprintf("failed to inspect %s", ref), err)
+	}
+
+	result := mediaCheckResult{
+		Info:        info,
+		AspectRatio: info.AspectRatio(),
+		OK:          len(violations) == 0,
+		Violations:  violations,
+	}
+	if result.Violations == nil {
+		result.Violations = []media.Violation{}
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if errOut := out.Output(result); errOut != nil {
+			return errOut
+		}
+	} else {
+		printMediaInfo(ctx, f, info, violations)
+	}
+
+	if len(violations) > 0 {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("%s does not meet Threads media requirements", ref),
+			Suggestion: "Re-encode or resize the media, then check it again",
+		}
+	}
+	return nil
+}
+
+func printMediaInfo(ctx context.Context, f *Factory, info *media.Info, violations []media.Violation) {
+	io := iocontext.GetIO(ctx)
+
+	fmt.Fprintf(io.Out, "Source:       %s\n", info.Source)                                       //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "Type:         %s (%s)\n", strings.ToLower(info.MediaType), info.Format) //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "Content type: %s\n", info.ContentType)                                  //nolint:errcheck // Best-effort output
+	if info.Size > 0 {
+		fmt.Fprintf(io.Out, "Size:         %d bytes\n", info.Size) //nolint:errcheck // Best-effort output
+	}
+	if info.Width > 0 {
+		fmt.Fprintf(io.Out, "Dimensions:   %dx%d (aspect %.2f)\n", info.Width, info.Height, info.AspectRatio()) //nolint:errcheck // Best-effort output
+	}
+	if info.Duration > 0 {
+		fmt.Fprintf(io.Out, "Duration:     %.1fs\n", info.Duration) //nolint:errcheck // Best-effort output
+	}
+	if info.FrameRate > 0 {
+		fmt.Fprintf(io.Out, "Frame rate:   %.2f fps\n", info.FrameRate) //nolint:errcheck // Best-effort output
+	}
+	if info.VideoCodec != "" {
+		fmt.Fprintf(io.Out, "Video codec:  %s\n", info.VideoCodec) //nolint:errcheck // Best-effort output
+	}
+	if info.AudioCodec != "" {
+		fmt.Fprintf(io.Out, "Audio:        %s, %d Hz, %d ch\n", info.AudioCodec, info.AudioSampleRate, info.AudioChannels) //nolint:errcheck // Best-effort output
+	}
+	fmt.Fprintln(io.Out) //nolint:errcheck // Best-effort output
+
+	p := f.UI(ctx)
+	if len(violations) == 0 {
+		p.Success("Media meets Threads requirements")
+		return
+	}
+	for _, v := range violations {
+		p.Error("%s: %s", v.Field, v.Message)
+	}
+}
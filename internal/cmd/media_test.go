@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+func TestMediaCheck_JSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantOK        bool
+	}{
+		{"within limits", 640, 480, true},
+		{"too wide", 1100, 100, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			photo := writeTestJPEG(t, tt.width, tt.height)
+
+			f, io := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+			ctx := iocontext.WithIO(context.Background(), io)
+			ctx = outfmt.WithFormat(ctx, "json")
+
+			cmd := newMediaCheckCmd(f)
+			cmd.SetContext(ctx)
+			cmd.SetArgs([]string{photo})
+			err := cmd.Execute()
+			if (err == nil) != tt.wantOK {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+
+			var result struct {
+				Format     string            `json:"format"`
+				Width      int               `json:"width"`
+				OK         bool              `json:"ok"`
+				Violations []json.RawMessage `json:"violations"`
+			}
+			out := io.Out.(*bytes.Buffer)
+			if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+				t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+			}
+			if result.Format != "jpeg" || result.Width != tt.width || result.OK != tt.wantOK {
+				t.Errorf("unexpected result: %+v", result)
+			}
+			if tt.wantOK != (len(result.Violations) == 0) {
+				t.Errorf("unexpected violations: %d", len(result.Violations))
+			}
+		})
+	}
+}
+
+func TestMediaCheck_InvalidType(t *testing.T) {
+	f, io := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+	cmd := newMediaCheckCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), io))
+	cmd.SetArgs([]string{"./photo.jpg", "--type", "gif"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected invalid --type error")
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/media"
+)
+
+// localMedia uploads local files referenced by --image, --video or --items to
+// the account's media host, and removes them again after a successful publish
+// when the host sets delete_after_publish. A nil *localMedia leaves every
+// reference untouched.
+type localMedia struct {
+	f        *Factory
+	host     *config.MediaHost
+	uploader media.Uploader
+	uploaded []*media.Uploaded
+}
+
+func newLocalMedia(f *Factory) *localMedia {
+	return &localMedia{f: f}
+}
+
+// resolve returns ref unchanged when it is a URL, otherwise checks and
+// uploads the file it names and returns the hosted URL. mediaType is "image"
+// or "video".
+func (m *localMedia) resolve(ctx context.Context, ref, mediaType string) (string, error) {
+	if m == nil || !media.IsLocal(ref) {
+		return ref, nil
+	}
+
+	// Check before uploading; once hosted, the URL is checked again by the
+	// API client, which is cheap since only the headers are read.
+	if m.f.Config == nil || !m.f.Config.SkipMediaCheck {
+		if err := media.NewChecker(nil).CheckMedia(ctx, ref, mediaType); err != nil {
+			return "", FormatError(err)
+		}
+	}
+
+	if m.uploader == nil {
+		if err := m.init(); err != nil {
+			return "", err
+		}
+	}
+
+	uploaded, err := media.UploadFile(ctx, m.uploader, ref)
+	if err != nil {
+		return "", WrapError(fmt.Sprintf("failed to upload %s", ref), err)
+	}
+	m.uploaded = append(m.uploaded, uploaded)
+	return uploaded.URL, nil
+}
+
+func (m *localMedia) init() error {
+	account, err := m.f.resolveAccount()
+	if err != nil {
+		return err
+	}
+
+	var host *config.MediaHost
+	if m.f.Config != nil {
+		host = m.f.Config.MediaHostFor(account)
+	}
+	if host == nil {
+		return &UserFriendlyError{
+			Message:    "Local media files need a media host to upload to",
+			Suggestion: fmt.Sprintf("Add a \"media\" section for account %q to %s, or pass a public http(s) URL", account, config.ConfigPath()),
+		}
+	}
+
+	uploader, err := media.NewUploader(host, nil)
+	if err != nil {
+		return &UserFriendlyError{
+			Message:    "Invalid media host configuration",
+			Suggestion: fmt.Sprintf("Check the \"media\" section in %s", config.ConfigPath()),
+			Cause:      err,
+		}
+	}
+	m.host = host
+	m.uploader = uploader
+	return nil
+}
+
+// cleanup deletes this run's uploads if the media host asks for it. By the
+// time a post is published every container has reached FINISHED, so Threads
+// no longer needs the files.
+func (m *localMedia) cleanup(ctx context.Context) {
+	if m == nil || m.host == nil || !m.host.DeleteAfterPublish {
+		return
+	}
+	for _, u := range m.uploaded {
+		if err := m.uploader.Delete(ctx, u.Key); err != nil {
+			m.f.UI(ctx).Warning("Failed to delete uploaded media %s: %v", u.Key, err)
+		}
+	}
+	m.uploaded = nil
+}
+
+// resolveContent returns a copy of content with local media uploaded.
+func (m *localMedia) resolveContent(ctx context.Context, content any) (any, error) {
+	switch c := content.(type) {
+	case *api.ImagePostContent:
+		resolved := *c
+		imageURL, err := m.resolve(ctx, c.ImageURL, "image")
+		resolved.ImageURL = imageURL
+		return &resolved, err
+	case *api.VideoPostContent:
+		resolved := *c
+		videoURL, err := m.resolve(ctx, c.VideoURL, "video")
+		resolved.VideoURL = videoURL
+		return &resolved, err
+	default:
+		return content, nil
+	}
+}
+
+// resolveItems returns a copy of items with local media uploaded.
+func (m *localMedia) resolveItems(ctx context.Context, items []carouselItem) ([]carouselItem, error) {
+	resolved := make([]carouselItem, len(items))
+	for i, item := range items {
+		itemURL, err := m.resolve(ctx, item.URL, strings.ToLower(item.MediaType))
+		if err != nil {
+			return nil, err
+		}
+		item.URL = itemURL
+		resolved[i] = item
+	}
+	return resolved, nil
+}
+
+// normalizeMediaRef checks that a local media file exists and returns its
+// absolute path, so queued and drafted posts still find it when published
+// from another directory. URLs are returned unchanged.
+func normalizeMediaRef(ref string) (string, error) {
+	if !media.IsLocal(ref) {
+		return ref, nil
+	}
+
+	info, err := os.Stat(ref)
+	if err != nil || info.IsDir() {
+		return "", &UserFriendlyError{
+			Message:    fmt.Sprintf("Media file not found: %s", ref),
+			Suggestion: "Pass an http(s) URL, or the path of an existing image or video file",
+		}
+	}
+	return filepath.Abs(ref)
+}
+
+// validationMediaURL stands in for a local file's eventual URL so content can
+// be validated before it is uploaded.
+func validationMediaURL(ref string) string {
+	if !media.IsLocal(ref) {
+		return ref
+	}
+	return "https://localhost/" + filepath.Base(ref)
+}
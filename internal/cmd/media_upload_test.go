@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+func TestPostsCreate_UploadsLocalImage(t *testing.T) {
+	var mu sync.Mutex
+	var uploads, deletes []string
+	var imageURL string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/media/"):
+			key := strings.TrimPrefix(r.URL.Path, "/media/")
+			if r.Method == http.MethodDelete {
+				deletes = append(deletes, key)
+			} else {
+				uploads = append(uploads, key)
+			}
+		case r.URL.Path == "/refresh_access_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "refreshed-token", "token_type": "Bearer", "expires_in": 3600})
+		case r.URL.Path == "/12345/threads":
+			_ = r.ParseForm()
+			imageURL = r.Form.Get("image_url")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1"})
+		case r.URL.Path == "/c1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1", "status": "FINISHED"})
+		case r.URL.Path == "/12345/threads_publish":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "p1"})
+		case r.URL.Path == "/p1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":        "p1",
+				"permalink": "https://www.threads.net/t/p1",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	photo := writeTestJPEG(t, 640, 480)
+
+	f, io := newIntegrationTestFactory(t, server.URL)
+	f.Config.Accounts = map[string]*config.AccountConfig{
+		"test-user": {Media: &config.MediaHost{
+			Type:               "http",
+			UploadURL:          server.URL + "/media/{key}",
+			PublicURL:          "https://cdn.example.com/{key}",
+			DeleteAfterPublish: true,
+		}},
+	}
+	ctx := iocontext.WithIO(context.Background(), io)
+	ctx = outfmt.WithFormat(ctx, "json")
+
+	cmd := newPostsCreateCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--image", photo, "--text", "Local upload"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("posts create failed: %v", err)
+	}
+
+	if len(uploads) != 1 || !strings.HasSuffix(uploads[0], "-photo.jpg") {
+		t.Fatalf("expected one upload, got %v", uploads)
+	}
+	if imageURL != "https://cdn.example.com/"+uploads[0] {
+		t.Errorf("expected the container to use the hosted URL, got %q", imageURL)
+	}
+	if len(deletes) != 1 || deletes[0] != uploads[0] {
+		t.Errorf("expected the upload to be deleted after publishing, got %v", deletes)
+	}
+}
+
+func TestPostsCreate_LocalImageWithoutMediaHost(t *testing.T) {
+	photo := writeTestJPEG(t, 640, 480)
+
+	f, io := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+	ctx := iocontext.WithIO(context.Background(), io)
+
+	cmd := newPostsCreateCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--image", photo})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "media host") {
+		t.Fatalf("expected a missing media host error, got %v", err)
+	}
+}
+
+func TestBuildPostContent_MissingLocalFile(t *testing.T) {
+	_, err := buildPostContent(&postsCreateOptions{ImageURL: "./does-not-exist.jpg"})
+	if err == nil || !strings.Contains(err.Error(), "Media file not found") {
+		t.Fatalf("expected media file not found error, got %v", err)
+	}
+}
+
+func TestPostsCreate_RejectsLocalMediaFailingChecks(t *testing.T) {
+	photo := writeTestJPEG(t, 1100, 100)
+
+	f, io := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+	ctx := iocontext.WithIO(context.Background(), io)
+
+	cmd := newPostsCreateCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--image", photo})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "aspect ratio 1100x100") {
+		t.Fatalf("expected an aspect ratio error before uploading, got %v", err)
+	}
+}
+
+// writeTestJPEG writes a blank JPEG of the given size and returns its path.
+func writeTestJPEG(t *testing.T, width, height int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := jpeg.Encode(file, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
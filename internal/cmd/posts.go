@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,12 +17,6 @@ import (
 	"github.com/salmonumbrella/threads-cli/internal/ui"
 )
 
-const (
-	// containerPollingInterval is the time between status checks when waiting
-	// for media container processing to complete.
-	containerPollingInterval = 2 * time.Second
-)
-
 // NewPostsCmd builds the posts command group.
 func NewPostsCmd(f *Factory) *cobra.Command {
 	cmd := &cobra.Command{
@@ -29,15 +26,16 @@ func NewPostsCmd(f *Factory) *cobra.Command {
 		Long:    `Create, read, list, and delete posts on Threads.`,
 	}
 
-	cmd.AddCommand(newPostsCreateCmd(f))
-	cmd.AddCommand(newPostsGetCmd(f))
-	cmd.AddCommand(newPostsListCmd(f))
-	cmd.AddCommand(newPostsDeleteCmd(f))
-	cmd.AddCommand(newPostsCarouselCmd(f))
-	cmd.AddCommand(newPostsQuoteCmd(f))
-	cmd.AddCommand(newPostsRepostCmd(f))
-	cmd.AddCommand(newPostsUnrepostCmd(f))
-	cmd.AddCommand(newPostsGhostListCmd(f))
+	cmd.AddCommand(requireScopes(newPostsCreateCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(requireScopes(newPostsGetCmd(f), scopeBasic))
+	cmd.AddCommand(requireScopes(newPostsListCmd(f), scopeBasic))
+	cmd.AddCommand(requireScopes(newPostsDeleteCmd(f), scopeBasic, scopeDelete))
+	cmd.AddCommand(requireScopes(newPostsCarouselCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(requireScopes(newPostsThreadCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(requireScopes(newPostsQuoteCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(requireScopes(newPostsRepostCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(requireScopes(newPostsUnrepostCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(requireScopes(newPostsGhostListCmd(f), scopeBasic))
 
 	return cmd
 }
@@ -56,6 +54,8 @@ type postsCreateOptions struct {
 	Location     string
 	ReplyControl string
 	GIF          string
+	SpoilerMedia bool
+	Spoilers     []string
 }
 
 func newPostsCreateCmd(f *Factory) *cobra.Command {
@@ -79,6 +79,9 @@ Examples:
   # Create a video post
   threads posts create --video "https://example.com/video.mp4"
 
+  # Upload a local file to the configured media host
+  threads posts create --text "Fresh off the camera" --image ./photo.jpg
+
   # Create a reply
   threads posts create --text "Great post!" --reply-to 12345678901234567
 
@@ -104,8 +107,8 @@ Examples:
 	cmd.Flags().StringVarP(&opts.Text, "text", "t", "", "Post text content")
 	cmd.Flags().StringVar(&opts.TextFile, "text-file", "", "Read post text content from a file (or '-' for stdin)")
 	cmd.Flags().StringVar(&opts.Emit, "emit", "", "Emit: json|id|url (useful for chaining; suppresses extra text output)")
-	cmd.Flags().StringVar(&opts.ImageURL, "image", "", "Image URL for image posts")
-	cmd.Flags().StringVar(&opts.VideoURL, "video", "", "Video URL for video posts")
+	cmd.Flags().StringVar(&opts.ImageURL, "image", "", "Image URL or local file for image posts")
+	cmd.Flags().StringVar(&opts.VideoURL, "video", "", "Video URL or local file for video posts")
 	cmd.Flags().StringVar(&opts.AltText, "alt-text", "", "Alt text for media accessibility")
 	cmd.Flags().StringVar(&opts.ReplyTo, "reply-to", "", "Post ID to reply to")
 	cmd.Flags().StringVar(&opts.Poll, "poll", "", "Create a poll with comma-separated options (2-4 options, e.g., \"Yes,No\" or \"A,B,C,D\")")
@@ -114,6 +117,8 @@ Examples:
 	cmd.Flags().StringVar(&opts.Location, "location", "", "Attach a location ID to the post (use 'threads locations search' to find IDs)")
 	cmd.Flags().StringVar(&opts.ReplyControl, "reply-control", "", "Control who can reply: everyone, accounts_you_follow, mentioned_only")
 	cmd.Flags().StringVar(&opts.GIF, "gif", "", "Attach a GIF using a Tenor GIF ID (text-only posts)")
+	cmd.Flags().BoolVar(&opts.SpoilerMedia, "spoiler-media", false, "Mark the image or video as a spoiler")
+	cmd.Flags().StringArrayVar(&opts.Spoilers, "spoiler", nil, "Mark a text range as a spoiler as OFFSET:LENGTH (repeatable)")
 
 	return cmd
 }
@@ -135,6 +140,57 @@ func runPostsCreate(cmd *cobra.Command, f *Factory, opts *postsCreateOptions) er
 		opts.Text = txt
 	}
 
+	content, err := buildPostContent(opts)
+	if err != nil {
+		return err
+	}
+
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	post, err := publishPostContent(ctx, client, content, newLocalMedia(f))
+	if err != nil {
+		return WrapError("failed to create post", err)
+	}
+
+	io := iocontext.GetIO(ctx)
+	if cmd.Flags().Changed("emit") {
+		mode, errEmit := parseEmitMode(opts.Emit)
+		if errEmit != nil {
+			return errEmit
+		}
+		return emitResult(ctx, io, mode, post.ID, post.Permalink, post)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(post)
+	}
+
+	p := f.UI(ctx)
+	if opts.Ghost {
+		p.Success("Ghost post created successfully! (expires in 24 hours)")
+	} else {
+		p.Success("Post created successfully!")
+	}
+	fmt.Fprintf(io.Out, "  ID:        %s\n", post.ID)        //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "  Permalink: %s\n", post.Permalink) //nolint:errcheck // Best-effort output
+	if post.Text != "" {
+		text := post.Text
+		if len(text) > 50 {
+			text = text[:50] + "..."
+		}
+		fmt.Fprintf(io.Out, "  Text:      %s\n", text) //nolint:errcheck // Best-effort output
+	}
+
+	return nil
+}
+
+// buildPostContent validates create options and builds the matching
+// TextPostContent, ImagePostContent, or VideoPostContent.
+func buildPostContent(opts *postsCreateOptions) (any, error) {
 	hasImage := opts.ImageURL != ""
 	hasVideo := opts.VideoURL != ""
 	hasText := opts.Text != ""
@@ -142,117 +198,99 @@ func runPostsCreate(cmd *cobra.Command, f *Factory, opts *postsCreateOptions) er
 	hasGIF := opts.GIF != ""
 
 	if !hasText && !hasImage && !hasVideo {
-		return &UserFriendlyError{
+		return nil, &UserFriendlyError{
 			Message:    "No content provided for the post",
 			Suggestion: "Provide at least one of --text, --image, or --video",
 		}
 	}
 
 	if hasImage && hasVideo {
-		return &UserFriendlyError{
+		return nil, &UserFriendlyError{
 			Message:    "Cannot combine image and video in a single post",
 			Suggestion: "Use --image OR --video, not both. For multiple media items, use 'threads posts carousel'",
 		}
 	}
 
 	if opts.Ghost && (hasImage || hasVideo) {
-		return &UserFriendlyError{
+		return nil, &UserFriendlyError{
 			Message:    "Ghost posts can only contain text",
 			Suggestion: "Remove --image or --video flags to create a ghost post",
 		}
 	}
 
 	if hasPoll && (hasImage || hasVideo) {
-		return &UserFriendlyError{
+		return nil, &UserFriendlyError{
 			Message:    "Poll posts can only contain text",
 			Suggestion: "Remove --image or --video flags to create a poll post",
 		}
 	}
 
 	if hasGIF && (hasImage || hasVideo) {
-		return &UserFriendlyError{
+		return nil, &UserFriendlyError{
 			Message:    "GIF posts can only contain text",
 			Suggestion: "Remove --image or --video flags to create a GIF post",
 		}
 	}
 
-	var replyControl api.ReplyControl
-	if opts.ReplyControl != "" {
-		switch opts.ReplyControl {
-		case "everyone":
-			replyControl = api.ReplyControlEveryone
-		case "accounts_you_follow":
-			replyControl = api.ReplyControlAccountsYouFollow
-		case "mentioned_only":
-			replyControl = api.ReplyControlMentioned
-		default:
-			return &UserFriendlyError{
-				Message:    fmt.Sprintf("Invalid reply-control value: %s", opts.ReplyControl),
-				Suggestion: "Valid values are: everyone, accounts_you_follow, mentioned_only",
-			}
+	if opts.SpoilerMedia && !hasImage && !hasVideo {
+		return nil, &UserFriendlyError{
+			Message:    "--spoiler-media requires an image or video",
+			Suggestion: "Use --spoiler OFFSET:LENGTH to hide part of the text instead",
 		}
 	}
 
-	var pollAttachment *api.PollAttachment
-	if hasPoll {
-		options := strings.Split(opts.Poll, ",")
-		for i := range options {
-			options[i] = strings.TrimSpace(options[i])
-		}
-		if len(options) < 2 {
-			return &UserFriendlyError{
-				Message:    "Poll requires at least 2 options",
-				Suggestion: "Provide comma-separated options, e.g., --poll \"Yes,No\"",
-			}
-		}
-		if len(options) > 4 {
-			return &UserFriendlyError{
-				Message:    "Poll supports maximum 4 options",
-				Suggestion: "Reduce the number of options to 4 or fewer",
-			}
-		}
-		pollAttachment = &api.PollAttachment{
-			OptionA: options[0],
-			OptionB: options[1],
-		}
-		if len(options) > 2 {
-			pollAttachment.OptionC = options[2]
-		}
-		if len(options) > 3 {
-			pollAttachment.OptionD = options[3]
-		}
+	replyControl, err := parseReplyControl(opts.ReplyControl)
+	if err != nil {
+		return nil, err
 	}
 
-	client, err := f.Client(ctx)
+	textEntities, err := parseSpoilerRanges(opts.Spoilers)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var post *api.Post
+	imageURL, err := normalizeMediaRef(opts.ImageURL)
+	if err != nil {
+		return nil, err
+	}
+	videoURL, err := normalizeMediaRef(opts.VideoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var pollAttachment *api.PollAttachment
+	if hasPoll {
+		pollAttachment, err = parsePollOptions(opts.Poll)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	switch {
 	case hasImage:
-		content := &api.ImagePostContent{
-			Text:         opts.Text,
-			ImageURL:     opts.ImageURL,
-			AltText:      opts.AltText,
-			ReplyTo:      opts.ReplyTo,
-			ReplyControl: replyControl,
-			TopicTag:     opts.Topic,
-			LocationID:   opts.Location,
-		}
-		post, err = client.CreateImagePost(ctx, content)
+		return &api.ImagePostContent{
+			Text:           opts.Text,
+			ImageURL:       imageURL,
+			AltText:        opts.AltText,
+			ReplyTo:        opts.ReplyTo,
+			ReplyControl:   replyControl,
+			TopicTag:       opts.Topic,
+			LocationID:     opts.Location,
+			TextEntities:   textEntities,
+			IsSpoilerMedia: opts.SpoilerMedia,
+		}, nil
 	case hasVideo:
-		content := &api.VideoPostContent{
-			Text:         opts.Text,
-			VideoURL:     opts.VideoURL,
-			AltText:      opts.AltText,
-			ReplyTo:      opts.ReplyTo,
-			ReplyControl: replyControl,
-			TopicTag:     opts.Topic,
-			LocationID:   opts.Location,
-		}
-		post, err = client.CreateVideoPost(ctx, content)
+		return &api.VideoPostContent{
+			Text:           opts.Text,
+			VideoURL:       videoURL,
+			AltText:        opts.AltText,
+			ReplyTo:        opts.ReplyTo,
+			ReplyControl:   replyControl,
+			TopicTag:       opts.Topic,
+			LocationID:     opts.Location,
+			TextEntities:   textEntities,
+			IsSpoilerMedia: opts.SpoilerMedia,
+		}, nil
 	default:
 		content := &api.TextPostContent{
 			Text:           opts.Text,
@@ -262,6 +300,7 @@ func runPostsCreate(cmd *cobra.Command, f *Factory, opts *postsCreateOptions) er
 			LocationID:     opts.Location,
 			PollAttachment: pollAttachment,
 			IsGhostPost:    opts.Ghost,
+			TextEntities:   textEntities,
 		}
 		if hasGIF {
 			content.GIFAttachment = &api.GIFAttachment{
@@ -269,57 +308,148 @@ func runPostsCreate(cmd *cobra.Command, f *Factory, opts *postsCreateOptions) er
 				Provider: api.GIFProviderTenor,
 			}
 		}
-		post, err = client.CreateTextPost(ctx, content)
+		return content, nil
 	}
+}
 
+// publishPostContent publishes content built by buildPostContent, uploading
+// local media through uploads first. Uploads are also cleaned up when the
+// post went out but its ID was lost (api.ErrPublishedIDUnknown).
+func publishPostContent(ctx context.Context, client *api.Client, content any, uploads *localMedia) (*api.Post, error) {
+	content, err := uploads.resolveContent(ctx, content)
 	if err != nil {
-		return WrapError("failed to create post", err)
+		return nil, err
 	}
 
-	io := iocontext.GetIO(ctx)
-	if cmd.Flags().Changed("emit") {
-		mode, errEmit := parseEmitMode(opts.Emit)
-		if errEmit != nil {
-			return errEmit
+	var post *api.Post
+	switch c := content.(type) {
+	case *api.ImagePostContent:
+		post, err = client.CreateImagePost(ctx, c)
+	case *api.VideoPostContent:
+		post, err = client.CreateVideoPost(ctx, c)
+	case *api.TextPostContent:
+		post, err = client.CreateTextPost(ctx, c)
+	default:
+		return nil, fmt.Errorf("unsupported post content type %T", content)
+	}
+	if err != nil && !errors.Is(err, api.ErrPublishedIDUnknown) {
+		return nil, err
+	}
+	uploads.cleanup(ctx)
+	return post, err
+}
+
+// parseReplyControl maps a --reply-control flag value to the API enum.
+func parseReplyControl(value string) (api.ReplyControl, error) {
+	switch value {
+	case "":
+		return "", nil
+	case "everyone":
+		return api.ReplyControlEveryone, nil
+	case "accounts_you_follow":
+		return api.ReplyControlAccountsYouFollow, nil
+	case "mentioned_only":
+		return api.ReplyControlMentioned, nil
+	default:
+		return "", &UserFriendlyError{
+			Message:    fmt.Sprintf("Invalid reply-control value: %s", value),
+			Suggestion: "Valid values are: everyone, accounts_you_follow, mentioned_only",
 		}
-		return emitResult(ctx, io, mode, post.ID, post.Permalink, post)
 	}
+}
 
-	if outfmt.IsJSON(ctx) {
-		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
-		return out.Output(post)
+// parseSpoilerRanges parses --spoiler OFFSET:LENGTH values into text entities.
+func parseSpoilerRanges(values []string) ([]api.TextEntity, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	entities := make([]api.TextEntity, 0, len(values))
+	for _, value := range values {
+		offsetStr, lengthStr, ok := strings.Cut(value, ":")
+		offset, errOffset := strconv.Atoi(strings.TrimSpace(offsetStr))
+		length, errLength := strconv.Atoi(strings.TrimSpace(lengthStr))
+		if !ok || errOffset != nil || errLength != nil {
+			return nil, &UserFriendlyError{
+				Message:    fmt.Sprintf("Invalid --spoiler value: %s", value),
+				Suggestion: "Use OFFSET:LENGTH, e.g. --spoiler 10:5 hides 5 characters starting at offset 10",
+			}
+		}
+		entities = append(entities, api.TextEntity{
+			EntityType: "SPOILER",
+			Offset:     offset,
+			Length:     length,
+		})
 	}
+	return entities, nil
+}
 
-	p := f.UI(ctx)
-	if opts.Ghost {
-		p.Success("Ghost post created successfully! (expires in 24 hours)")
-	} else {
-		p.Success("Post created successfully!")
+// parsePollOptions parses a comma-separated --poll flag value.
+func parsePollOptions(value string) (*api.PollAttachment, error) {
+	options := strings.Split(value, ",")
+	for i := range options {
+		options[i] = strings.TrimSpace(options[i])
 	}
-	fmt.Fprintf(io.Out, "  ID:        %s\n", post.ID)        //nolint:errcheck // Best-effort output
-	fmt.Fprintf(io.Out, "  Permalink: %s\n", post.Permalink) //nolint:errcheck // Best-effort output
-	if post.Text != "" {
-		text := post.Text
-		if len(text) > 50 {
-			text = text[:50] + "..."
+	if len(options) < 2 {
+		return nil, &UserFriendlyError{
+			Message:    "Poll requires at least 2 options",
+			Suggestion: "Provide comma-separated options, e.g., --poll \"Yes,No\"",
 		}
-		fmt.Fprintf(io.Out, "  Text:      %s\n", text) //nolint:errcheck // Best-effort output
 	}
-
-	return nil
+	if len(options) > 4 {
+		return nil, &UserFriendlyError{
+			Message:    "Poll supports maximum 4 options",
+			Suggestion: "Reduce the number of options to 4 or fewer",
+		}
+	}
+	poll := &api.PollAttachment{
+		OptionA: options[0],
+		OptionB: options[1],
+	}
+	if len(options) > 2 {
+		poll.OptionC = options[2]
+	}
+	if len(options) > 3 {
+		poll.OptionD = options[3]
+	}
+	return poll, nil
 }
 
 func newPostsGetCmd(f *Factory) *cobra.Command {
+	var idsFile string
+
 	cmd := &cobra.Command{
 		Use:     "get [post-id]",
 		Aliases: []string{"show"},
 		Short:   "Get a single post by ID",
-		Long: `Retrieve a single post by its ID.
+		Long: `Retrieve a single post by its ID, or many with --ids-file.
+
+With --ids-file, IDs are read one per line (or separated by commas) and
+fetched in batches. Posts that cannot be fetched are reported individually.
 
 		Example:
-	  threads posts get 12345678901234567`,
-		Args: cobra.ExactArgs(1),
+	  threads posts get 12345678901234567
+	  threads posts get --ids-file ids.txt --output json`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if idsFile != "" {
+				if len(args) > 0 {
+					return &UserFriendlyError{
+						Message:    "Cannot combine a post ID with --ids-file",
+						Suggestion: "Add the post ID to the IDs file instead",
+					}
+				}
+				postIDs, err := readIDsFile(cmd.Context(), idsFile, "post")
+				if err != nil {
+					return err
+				}
+				return runPostsGetBatch(cmd, f, postIDs)
+			}
+			if len(args) == 0 {
+				return &UserFriendlyError{
+					Message:    "A post ID is required",
+					Suggestion: "Pass a post ID, or --ids-file to get many posts",
+				}
+			}
 			postID, err := normalizeIDArg(args[0], "post")
 			if err != nil {
 				return err
@@ -327,6 +457,8 @@ func newPostsGetCmd(f *Factory) *cobra.Command {
 			return runPostsGet(cmd, f, postID)
 		},
 	}
+
+	cmd.Flags().StringVar(&idsFile, "ids-file", "", "Read post IDs from a file (- for stdin)")
 	return cmd
 }
 
@@ -348,26 +480,97 @@ func runPostsGet(cmd *cobra.Command, f *Factory, postID string) error {
 		return out.Output(post)
 	}
 
-	fmt.Fprintf(io.Out, "ID:        %s\n", post.ID)                                      //nolint:errcheck // Best-effort output
-	fmt.Fprintf(io.Out, "Username:  @%s\n", post.Username)                               //nolint:errcheck // Best-effort output
-	fmt.Fprintf(io.Out, "Type:      %s\n", post.MediaType)                               //nolint:errcheck // Best-effort output
-	fmt.Fprintf(io.Out, "Permalink: %s\n", post.Permalink)                               //nolint:errcheck // Best-effort output
-	fmt.Fprintf(io.Out, "Timestamp: %s\n", post.Timestamp.Format("2006-01-02 15:04:05")) //nolint:errcheck // Best-effort output
+	printPost(io.Out, post)
+	return nil
+}
+
+// postBatchResult is one post of 'posts get --ids-file'.
+type postBatchResult struct {
+	ID    string    `json:"id"`
+	Post  *api.Post `json:"post,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+func runPostsGetBatch(cmd *cobra.Command, f *Factory, postIDs []string) error {
+	ctx := cmd.Context()
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]api.PostID, len(postIDs))
+	for i, id := range postIDs {
+		ids[i] = api.PostID(id)
+	}
+	fetched, err := client.GetPostsBatch(ctx, ids)
+	if err != nil {
+		return WrapError("failed to get posts", err)
+	}
+
+	results := make([]postBatchResult, len(fetched))
+	failed := 0
+	for i, r := range fetched {
+		results[i] = postBatchResult{ID: r.ID.String(), Post: r.Post}
+		if r.Err != nil {
+			results[i].Error = FormatError(r.Err).Error()
+			failed++
+		}
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSONL(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if errOut := out.Output(results); errOut != nil {
+			return errOut
+		}
+	} else if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if errOut := out.Output(itemsEnvelope(results, nil, "")); errOut != nil {
+			return errOut
+		}
+	} else {
+		p := f.UI(ctx)
+		for i, r := range results {
+			if r.Error != "" {
+				p.Error("%s: %s", r.ID, r.Error)
+				continue
+			}
+			if i > 0 {
+				fmt.Fprintln(io.Out) //nolint:errcheck // Best-effort output
+			}
+			printPost(io.Out, r.Post)
+		}
+	}
+
+	if failed > 0 {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("%d of %d posts could not be fetched", failed, len(results)),
+			Suggestion: "Check that the failed IDs exist and are visible to this account",
+		}
+	}
+	return nil
+}
+
+// printPost writes the details of a post as text.
+func printPost(w io.Writer, post *api.Post) {
+	fmt.Fprintf(w, "ID:        %s\n", post.ID)                                      //nolint:errcheck // Best-effort output
+	fmt.Fprintf(w, "Username:  @%s\n", post.Username)                               //nolint:errcheck // Best-effort output
+	fmt.Fprintf(w, "Type:      %s\n", post.MediaType)                               //nolint:errcheck // Best-effort output
+	fmt.Fprintf(w, "Permalink: %s\n", post.Permalink)                               //nolint:errcheck // Best-effort output
+	fmt.Fprintf(w, "Timestamp: %s\n", post.Timestamp.Format("2006-01-02 15:04:05")) //nolint:errcheck // Best-effort output
 
 	if post.Text != "" {
-		fmt.Fprintf(io.Out, "Text:      %s\n", post.Text) //nolint:errcheck // Best-effort output
+		fmt.Fprintf(w, "Text:      %s\n", post.Text) //nolint:errcheck // Best-effort output
 	}
 	if post.MediaURL != "" {
-		fmt.Fprintf(io.Out, "Media URL: %s\n", post.MediaURL) //nolint:errcheck // Best-effort output
+		fmt.Fprintf(w, "Media URL: %s\n", post.MediaURL) //nolint:errcheck // Best-effort output
 	}
 	if post.IsReply {
-		fmt.Fprintf(io.Out, "Reply to:  %s\n", post.ReplyTo) //nolint:errcheck // Best-effort output
+		fmt.Fprintf(w, "Reply to:  %s\n", post.ReplyTo) //nolint:errcheck // Best-effort output
 	}
 	if post.IsQuotePost {
-		fmt.Fprintln(io.Out, "Quote:     yes") //nolint:errcheck // Best-effort output
+		fmt.Fprintln(w, "Quote:     yes") //nolint:errcheck // Best-effort output
 	}
-
-	return nil
 }
 
 func newPostsListCmd(f *Factory) *cobra.Command {
@@ -592,11 +795,16 @@ func runPostsDelete(cmd *cobra.Command, f *Factory, postID string) error {
 }
 
 type postsCarouselOptions struct {
-	Items       []string
-	Text        string
-	AltTexts    []string
-	ReplyTo     string
-	TimeoutSecs int
+	Items        []string
+	Text         string
+	AltTexts     []string
+	ReplyTo      string
+	Topic        string
+	Location     string
+	ReplyControl string
+	SpoilerMedia bool
+	Spoilers     []string
+	TimeoutSecs  int
 }
 
 func newPostsCarouselCmd(f *Factory) *cobra.Command {
@@ -611,7 +819,8 @@ func newPostsCarouselCmd(f *Factory) *cobra.Command {
 		Short:   "Create a carousel post with multiple images/videos",
 		Long: `Create a carousel post with 2-20 media items.
 
-Each item should be a URL to an image or video. Alt text can be provided
+Each item should be a URL or local path to an image or video (local files
+are uploaded to the configured media host). Alt text can be provided
 for accessibility using --alt-text (one per item, in order).`,
 		Example: `  # Create carousel with 3 images
   threads posts carousel --items url1,url2,url3
@@ -623,10 +832,15 @@ for accessibility using --alt-text (one per item, in order).`,
 		},
 	}
 
-	cmd.Flags().StringSliceVar(&opts.Items, "items", nil, "Media URLs (comma-separated)")
+	cmd.Flags().StringSliceVar(&opts.Items, "items", nil, "Media URLs or local files (comma-separated)")
 	cmd.Flags().StringVar(&opts.Text, "text", "", "Caption text")
 	cmd.Flags().StringSliceVar(&opts.AltTexts, "alt-text", nil, "Alt text for each item (in order)")
 	cmd.Flags().StringVar(&opts.ReplyTo, "reply-to", "", "Post ID to reply to")
+	cmd.Flags().StringVar(&opts.Topic, "topic", "", "Add a topic tag to the post")
+	cmd.Flags().StringVar(&opts.Location, "location", "", "Attach a location ID to the post")
+	cmd.Flags().StringVar(&opts.ReplyControl, "reply-control", "", "Control who can reply: everyone, accounts_you_follow, mentioned_only")
+	cmd.Flags().BoolVar(&opts.SpoilerMedia, "spoiler-media", false, "Mark all carousel media as spoilers")
+	cmd.Flags().StringArrayVar(&opts.Spoilers, "spoiler", nil, "Mark a text range as a spoiler as OFFSET:LENGTH (repeatable)")
 	cmd.Flags().IntVar(&opts.TimeoutSecs, "timeout", 300, "Timeout in seconds for container processing")
 	cmd.Flags().StringVar(&emit, "emit", "", "Emit: json|id|url (useful for chaining; suppresses extra text output)")
 	//nolint:errcheck,gosec // MarkFlagRequired cannot fail for a flag that exists
@@ -636,17 +850,9 @@ for accessibility using --alt-text (one per item, in order).`,
 }
 
 func runPostsCarousel(cmd *cobra.Command, f *Factory, opts *postsCarouselOptions, emit string) error {
-	if len(opts.Items) < 2 {
-		return &UserFriendlyError{
-			Message:    "Carousel requires at least 2 items",
-			Suggestion: "Add more items with --items or use 'threads posts create' for a single media post",
-		}
-	}
-	if len(opts.Items) > 20 {
-		return &UserFriendlyError{
-			Message:    "Carousel supports maximum 20 items",
-			Suggestion: "Reduce the number of items to 20 or fewer",
-		}
+	content, items, err := buildCarouselContent(opts)
+	if err != nil {
+		return err
 	}
 
 	ctx := cmd.Context()
@@ -655,35 +861,7 @@ func runPostsCarousel(cmd *cobra.Command, f *Factory, opts *postsCarouselOptions
 		return err
 	}
 
-	var containerIDs []string
-	for i, itemURL := range opts.Items {
-		var altText string
-		if i < len(opts.AltTexts) {
-			altText = opts.AltTexts[i]
-		}
-
-		mediaType := detectMediaType(itemURL)
-		containerID, errContainer := client.CreateMediaContainer(ctx, mediaType, itemURL, altText)
-		if errContainer != nil {
-			return WrapError(fmt.Sprintf("failed to create container for item %d", i+1), errContainer)
-		}
-
-		if errWait := waitForContainer(ctx, client, containerID, opts.TimeoutSecs); errWait != nil {
-			return WrapError(fmt.Sprintf("container %d not ready", i+1), errWait)
-		}
-
-		containerIDs = append(containerIDs, string(containerID))
-	}
-
-	content := &api.CarouselPostContent{
-		Text:     opts.Text,
-		Children: containerIDs,
-	}
-	if opts.ReplyTo != "" {
-		content.ReplyTo = opts.ReplyTo
-	}
-
-	post, err := client.CreateCarouselPost(ctx, content)
+	post, err := publishCarouselContent(ctx, client, content, items, opts.TimeoutSecs, newLocalMedia(f))
 	if err != nil {
 		return WrapError("failed to create carousel post", err)
 	}
@@ -711,11 +889,135 @@ func runPostsCarousel(cmd *cobra.Command, f *Factory, opts *postsCarouselOptions
 		}
 		fmt.Fprintf(io.Out, "  Text:      %s\n", text) //nolint:errcheck // Best-effort output
 	}
-	fmt.Fprintf(io.Out, "  Items:     %d\n", len(containerIDs)) //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "  Items:     %d\n", len(items)) //nolint:errcheck // Best-effort output
+
+	return nil
+}
+
+// buildCarouselContent validates carousel options and returns the post
+// content (without children) plus the media items to create containers for.
+func buildCarouselContent(opts *postsCarouselOptions) (*api.CarouselPostContent, []carouselItem, error) {
+	if len(opts.Items) < api.MinCarouselItems {
+		return nil, nil, &UserFriendlyError{
+			Message:    "Carousel requires at least 2 items",
+			Suggestion: "Add more items with --items or use 'threads posts create' for a single media post",
+		}
+	}
+	if len(opts.Items) > api.MaxCarouselItems {
+		return nil, nil, &UserFriendlyError{
+			Message:    "Carousel supports maximum 20 items",
+			Suggestion: "Reduce the number of items to 20 or fewer",
+		}
+	}
 
+	replyControl, err := parseReplyControl(opts.ReplyControl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	textEntities, err := parseSpoilerRanges(opts.Spoilers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs := make([]string, len(opts.Items))
+	for i, item := range opts.Items {
+		if refs[i], err = normalizeMediaRef(item); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	content := &api.CarouselPostContent{
+		Text:           opts.Text,
+		ReplyTo:        opts.ReplyTo,
+		ReplyControl:   replyControl,
+		TopicTag:       opts.Topic,
+		LocationID:     opts.Location,
+		TextEntities:   textEntities,
+		IsSpoilerMedia: opts.SpoilerMedia,
+	}
+	return content, carouselItemsFromURLs(refs, opts.AltTexts), nil
+}
+
+// rejectSingleMediaOptions reports create options that have no meaning for
+// a carousel post.
+func rejectSingleMediaOptions(opts *postsCreateOptions) error {
+	if opts.ImageURL != "" || opts.VideoURL != "" || opts.AltText != "" || opts.Poll != "" || opts.GIF != "" || opts.Ghost {
+		return &UserFriendlyError{
+			Message:    "Carousel posts cannot be combined with --image, --video, --alt-text, --poll, --gif, or --ghost",
+			Suggestion: "Put all media in --items, or drop --items for a single media post",
+		}
+	}
 	return nil
 }
 
+// publishCarouselContent uploads local media through uploads, creates and
+// waits for a container per item, then publishes the carousel with those
+// containers as children.
+func publishCarouselContent(ctx context.Context, client *api.Client, content *api.CarouselPostContent, items []carouselItem, timeoutSecs int, uploads *localMedia) (*api.Post, error) {
+	items, err := uploads.resolveItems(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	containerIDs, err := createCarouselChildren(ctx, client, items, timeoutSecs)
+	if err != nil {
+		return nil, err
+	}
+
+	withChildren := *content
+	withChildren.Children = containerIDs
+	post, err := client.CreateCarouselPost(ctx, &withChildren)
+	if err != nil && !errors.Is(err, api.ErrPublishedIDUnknown) {
+		return nil, err
+	}
+	uploads.cleanup(ctx)
+	return post, err
+}
+
+// carouselItem is a single carousel child before its container exists.
+type carouselItem struct {
+	MediaType string
+	URL       string
+	AltText   string
+}
+
+// carouselItemsFromURLs pairs media URLs with optional alt texts (in order)
+// and detects each item's media type from its URL.
+func carouselItemsFromURLs(urls []string, altTexts []string) []carouselItem {
+	items := make([]carouselItem, 0, len(urls))
+	for i, itemURL := range urls {
+		item := carouselItem{
+			MediaType: detectMediaType(itemURL),
+			URL:       itemURL,
+		}
+		if i < len(altTexts) {
+			item.AltText = altTexts[i]
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// createCarouselChildren creates a media container per item and waits for
+// each to finish processing, returning the container IDs in order.
+func createCarouselChildren(ctx context.Context, client *api.Client, items []carouselItem, timeoutSecs int) ([]string, error) {
+	containerIDs := make([]string, 0, len(items))
+	for i, item := range items {
+		containerID, err := client.CreateMediaContainer(ctx, item.MediaType, item.URL, item.AltText)
+		if err != nil {
+			return nil, WrapError(fmt.Sprintf("failed to create container for item %d", i+1), err)
+		}
+
+		if err := waitForContainer(ctx, client, containerID, timeoutSecs); err != nil {
+			return nil, WrapError(fmt.Sprintf("container %d not ready", i+1), err)
+		}
+
+		containerIDs = append(containerIDs, string(containerID))
+	}
+	return containerIDs, nil
+}
+
 func newPostsQuoteCmd(f *Factory) *cobra.Command {
 	var text string
 	var textFile string
@@ -1098,62 +1400,29 @@ func detectMediaType(rawURL string) string {
 	return "IMAGE"
 }
 
-// waitForContainer polls container status until ready or timeout
+// waitForContainer waits for a container to finish processing, giving up
+// after timeoutSecs.
 func waitForContainer(ctx context.Context, client *api.Client, containerID api.ContainerID, timeoutSecs int) error {
-	status, err := client.GetContainerStatus(ctx, containerID)
-	if err != nil {
-		return FormatError(err)
-	}
-	switch status.Status {
-	case "FINISHED":
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSecs)*time.Second)
+	defer cancel()
+
+	_, err := client.WaitForContainer(waitCtx, containerID)
+	switch {
+	case err == nil:
 		return nil
-	case "ERROR":
+	case ctx.Err() != nil:
 		return &UserFriendlyError{
-			Message:    fmt.Sprintf("Media processing failed: %s", status.ErrorMessage),
-			Suggestion: "Check that the media URL is accessible and the format is supported (JPEG, PNG for images; MP4 for videos)",
+			Message:    "Operation cancelled",
+			Suggestion: "Try again if this was unintentional",
+			Cause:      err,
 		}
-	case "EXPIRED":
+	case errors.Is(err, context.DeadlineExceeded):
 		return &UserFriendlyError{
-			Message:    "Media container expired before publishing",
-			Suggestion: "Re-upload the media and publish immediately after container creation",
-		}
-	}
-
-	timeout := time.After(time.Duration(timeoutSecs) * time.Second)
-	ticker := time.NewTicker(containerPollingInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return &UserFriendlyError{
-				Message:    "Operation cancelled",
-				Suggestion: "Try again if this was unintentional",
-			}
-		case <-timeout:
-			return &UserFriendlyError{
-				Message:    "Timeout waiting for media processing",
-				Suggestion: "Media processing is taking too long. Try using a smaller file or increase timeout with --timeout",
-			}
-		case <-ticker.C:
-			status, err := client.GetContainerStatus(ctx, containerID)
-			if err != nil {
-				return FormatError(err)
-			}
-			switch status.Status {
-			case "FINISHED":
-				return nil
-			case "ERROR":
-				return &UserFriendlyError{
-					Message:    fmt.Sprintf("Media processing failed: %s", status.ErrorMessage),
-					Suggestion: "Check that the media URL is accessible and the format is supported",
-				}
-			case "EXPIRED":
-				return &UserFriendlyError{
-					Message:    "Media container expired before publishing",
-					Suggestion: "Re-upload the media and publish immediately after container creation",
-				}
-			}
+			Message:    "Timeout waiting for media processing",
+			Suggestion: "Media processing is taking too long. Try using a smaller file or increase timeout with --timeout",
+			Cause:      err,
 		}
+	default:
+		return FormatError(err)
 	}
 }
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -37,6 +39,7 @@ func TestPostsCmd_Subcommands(t *testing.T) {
 		"list":       true,
 		"delete":     true,
 		"carousel":   true,
+		"thread":     true,
 		"quote":      true,
 		"repost":     true,
 		"unrepost":   true,
@@ -988,3 +991,40 @@ func TestPostsGet_AcceptsURLAndPrefixedIDs(t *testing.T) {
 		}
 	}
 }
+
+func TestPostsGet_IDsFile(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	first := server.AddPost("testuser", "first")
+	second := server.AddPost("testuser", "second")
+
+	path := filepath.Join(t.TempDir(), "ids.txt")
+	if err := os.WriteFile(path, []byte(first+"\npost:"+second+"\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runRoot(t, f, streams, "posts", "get", "--ids-file", path, "-o", "json")
+	if err != nil {
+		t.Fatalf("posts get --ids-file failed: %v", err)
+	}
+	var envelope struct {
+		Items []postBatchResult `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &envelope); err != nil {
+		t.Fatalf("invalid JSON output: %v: %s", err, out)
+	}
+	if len(envelope.Items) != 2 || envelope.Items[0].Post.Text != "first" || envelope.Items[1].Post.Text != "second" {
+		t.Errorf("unexpected items: %s", out)
+	}
+
+	streams.In.(*bytes.Buffer).WriteString(first + ",999999")
+	out, err = runRoot(t, f, streams, "insights", "post", "--ids-file", "-", "--metrics", "views")
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Errorf("expected the unknown post to fail the command, got %v", err)
+	}
+	if !strings.Contains(out, "VIEWS") || !strings.Contains(out, first) {
+		t.Errorf("expected a row for the known post, got %q", out)
+	}
+
+	if _, err := runRoot(t, f, streams, "posts", "get", first, "--ids-file", path); err == nil {
+		t.Error("expected an error when combining a post ID with --ids-file")
+	}
+}
@@ -0,0 +1,440 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/media"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+// threadMediaPattern matches a Markdown image line: ![alt text](url)
+var threadMediaPattern = regexp.MustCompile(`^\s*!\[([^\]]*)\]\((\S+)\)\s*$`)
+
+// threadPart is one post in a thread.
+type threadPart struct {
+	Text     string   `json:"text"`
+	Media    []string `json:"media,omitempty"`
+	AltTexts []string `json:"alt_texts,omitempty"`
+}
+
+// threadPartResult records a published thread part. ID is empty when the
+// part was published but its response was lost.
+type threadPartResult struct {
+	Part      int    `json:"part"`
+	ID        string `json:"id"`
+	Permalink string `json:"permalink,omitempty"`
+	ReplyTo   string `json:"reply_to_id,omitempty"`
+}
+
+// threadResult is the JSON output of 'posts thread'.
+type threadResult struct {
+	Total      int                `json:"total"`
+	Published  []threadPartResult `json:"published"`
+	Complete   bool               `json:"complete"`
+	FailedPart int                `json:"failed_part,omitempty"`
+	UnknownID  int                `json:"unknown_id_part,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+type postsThreadOptions struct {
+	File         string
+	Separator    string
+	Resume       string
+	From         int
+	Topic        string
+	ReplyControl string
+	DryRun       bool
+	TimeoutSecs  int
+}
+
+func newPostsThreadCmd(f *Factory) *cobra.Command {
+	opts := &postsThreadOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "thread",
+		Short: "Publish a multi-post thread",
+		Long: `Publish a thread where each part replies to the previous one.
+
+The input is split into parts on lines containing only the separator
+(default "---"). Parts longer than the 500 character limit are split
+automatically at paragraph or word boundaries.
+
+Attach media to a part with a Markdown image line:
+
+  ![alt text](https://example.com/photo.jpg)
+
+One media line creates an image or video post, and 2-20 lines create a
+carousel. --topic applies to the first part; --reply-control applies to
+every part.
+
+If publishing stops partway through, the parts already published are
+reported along with a command to resume from the last published part.`,
+		Example: `  # Publish a thread from a file
+  threads posts thread --file thread.md
+
+  # Preview how the input will be split
+  threads posts thread --file thread.md --dry-run
+
+  # Read from stdin with a custom separator
+  cat notes.txt | threads posts thread --file - --separator "==="
+
+  # Continue a thread that stopped after part 2 (post ID 17890)
+  threads posts thread --file thread.md --resume 17890 --from 3`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPostsThread(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.File, "file", "", "Read the thread from a file (or '-' for stdin)")
+	cmd.Flags().StringVar(&opts.Separator, "separator", "---", "Line that separates thread parts")
+	cmd.Flags().StringVar(&opts.Resume, "resume", "", "Post ID of the last published part to continue from")
+	cmd.Flags().IntVar(&opts.From, "from", 0, "Part number to resume publishing at (used with --resume)")
+	cmd.Flags().StringVar(&opts.Topic, "topic", "", "Add a topic tag to the first post")
+	cmd.Flags().StringVar(&opts.ReplyControl, "reply-control", "", "Control who can reply: everyone, accounts_you_follow, mentioned_only")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show how the thread will be split without publishing")
+	cmd.Flags().IntVar(&opts.TimeoutSecs, "timeout", 300, "Timeout in seconds for carousel media processing")
+	//nolint:errcheck,gosec // MarkFlagRequired cannot fail for a flag that exists
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func runPostsThread(cmd *cobra.Command, f *Factory, opts *postsThreadOptions) error {
+	ctx := cmd.Context()
+
+	input, err := readTextFileOrStdin(ctx, opts.File)
+	if err != nil {
+		return err
+	}
+
+	parts, err := parseThreadParts(input, opts.Separator)
+	if err != nil {
+		return err
+	}
+	if opts.File != "-" {
+		resolveThreadMediaPaths(parts, filepath.Dir(opts.File))
+	}
+
+	start := 1
+	if opts.Resume != "" || opts.From != 0 {
+		if opts.Resume == "" || opts.From < 2 || opts.From > len(parts) {
+			return &UserFriendlyError{
+				Message:    "--resume and --from must be used together",
+				Suggestion: fmt.Sprintf("Pass the last published post ID with --resume and the next part number (2-%d) with --from", len(parts)),
+			}
+		}
+		start = opts.From
+	}
+
+	// Validate every remaining part before publishing anything.
+	for i := start - 1; i < len(parts); i++ {
+		if errPart := validateThreadPart(parts[i], i, opts); errPart != nil {
+			return WrapError(fmt.Sprintf("part %d of %d is invalid", i+1, len(parts)), errPart)
+		}
+	}
+
+	io := iocontext.GetIO(ctx)
+	if opts.DryRun {
+		return printThreadPreview(ctx, f, parts, start)
+	}
+
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := threadResult{Total: len(parts), Published: []threadPartResult{}}
+	replyTo := opts.Resume
+	uploads := newLocalMedia(f)
+	for i := start - 1; i < len(parts); i++ {
+		post, errPublish := publishThreadPart(ctx, client, parts[i], i, replyTo, opts, uploads)
+		if errors.Is(errPublish, api.ErrPublishedIDUnknown) {
+			// The part is live, so it must not be published again, but the
+			// next part cannot reply to it without its ID.
+			result.Published = append(result.Published, threadPartResult{Part: i + 1, ReplyTo: replyTo})
+			result.UnknownID = i + 1
+			result.Error = errPublish.Error()
+			printThreadResult(ctx, f, &result)
+			return threadIDUnknownError(opts, &result, errPublish)
+		}
+		if errPublish != nil {
+			result.FailedPart = i + 1
+			result.Error = errPublish.Error()
+			printThreadResult(ctx, f, &result)
+			return threadStoppedError(opts, &result, replyTo, errPublish)
+		}
+		result.Published = append(result.Published, threadPartResult{
+			Part:      i + 1,
+			ID:        post.ID,
+			Permalink: post.Permalink,
+			ReplyTo:   replyTo,
+		})
+		replyTo = post.ID
+	}
+	result.Complete = true
+
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(result)
+	}
+	printThreadResult(ctx, f, &result)
+	return nil
+}
+
+// threadStoppedError explains how to resume a thread that failed partway.
+func threadStoppedError(opts *postsThreadOptions, result *threadResult, lastID string, cause error) error {
+	msg := fmt.Sprintf("Thread stopped at part %d of %d", result.FailedPart, result.Total)
+	if lastID == "" {
+		return &UserFriendlyError{
+			Message:    msg,
+			Suggestion: "Nothing was published; fix the problem and run the command again",
+			Cause:      cause,
+		}
+	}
+	return &UserFriendlyError{
+		Message: msg,
+		Suggestion: fmt.Sprintf("Resume with: threads posts thread --file %s --resume %s --from %d",
+			opts.File, lastID, result.FailedPart),
+		Cause: cause,
+	}
+}
+
+// threadIDUnknownError explains how to continue a thread after a part was
+// published without its ID being returned.
+func threadIDUnknownError(opts *postsThreadOptions, result *threadResult, cause error) error {
+	part := result.UnknownID
+	msg := fmt.Sprintf("Part %d of %d was published, but its ID could not be retrieved", part, result.Total)
+	if part == result.Total {
+		return &UserFriendlyError{
+			Message:    msg,
+			Suggestion: "The thread is complete; do not publish it again",
+			Cause:      cause,
+		}
+	}
+	return &UserFriendlyError{
+		Message: msg,
+		Suggestion: fmt.Sprintf("Find part %d's ID with 'threads posts list', then resume with: threads posts thread --file %s --resume <ID> --from %d",
+			part, opts.File, part+1),
+		Cause: cause,
+	}
+}
+
+func printThreadResult(ctx context.Context, f *Factory, result *threadResult) {
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		if result.Complete {
+			return
+		}
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		out.Output(result) //nolint:errcheck,gosec // Best-effort partial output before returning the error
+		return
+	}
+
+	if result.Complete {
+		f.UI(ctx).Success("Thread published (%d parts)", len(result.Published))
+	}
+	for _, part := range result.Published {
+		id := part.ID
+		if id == "" {
+			id = "(ID unknown)"
+		}
+		fmt.Fprintf(io.Out, "  %d/%d  %s  %s\n", part.Part, result.Total, id, part.Permalink) //nolint:errcheck // Best-effort output
+	}
+}
+
+func printThreadPreview(ctx context.Context, f *Factory, parts []threadPart, start int) error {
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(parts[start-1:])
+	}
+
+	f.UI(ctx).Info("Thread has %d parts (dry run, nothing published)", len(parts))
+	for i := start - 1; i < len(parts); i++ {
+		part := parts[i]
+		fmt.Fprintf(io.Out, "\n--- %d/%d (%d/%d characters) ---\n", i+1, len(parts), len(part.Text), api.MaxTextLength) //nolint:errcheck // Best-effort output
+		for _, url := range part.Media {
+			fmt.Fprintf(io.Out, "[%s] %s\n", strings.ToLower(detectMediaType(url)), url) //nolint:errcheck // Best-effort output
+		}
+		fmt.Fprintln(io.Out, part.Text) //nolint:errcheck // Best-effort output
+	}
+	return nil
+}
+
+// parseThreadParts splits input into thread parts on separator lines,
+// extracts Markdown media lines, and auto-splits parts over the text limit.
+func parseThreadParts(input, separator string) ([]threadPart, error) {
+	separator = strings.TrimSpace(separator)
+
+	var chunks [][]string
+	var current []string
+	for _, line := range strings.Split(strings.ReplaceAll(input, "\r\n", "\n"), "\n") {
+		if separator != "" && strings.TrimSpace(line) == separator {
+			chunks = append(chunks, current)
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+	chunks = append(chunks, current)
+
+	var parts []threadPart
+	for _, lines := range chunks {
+		var part threadPart
+		var text []string
+		for _, line := range lines {
+			if m := threadMediaPattern.FindStringSubmatch(line); m != nil {
+				part.Media = append(part.Media, m[2])
+				part.AltTexts = append(part.AltTexts, strings.TrimSpace(m[1]))
+				continue
+			}
+			text = append(text, line)
+		}
+		part.Text = strings.TrimSpace(strings.Join(text, "\n"))
+		if part.Text == "" && len(part.Media) == 0 {
+			continue
+		}
+		if len(part.Media) > api.MaxCarouselItems {
+			return nil, &UserFriendlyError{
+				Message:    fmt.Sprintf("Thread part %d has %d media items (max %d)", len(parts)+1, len(part.Media), api.MaxCarouselItems),
+				Suggestion: "Move some media into another part",
+			}
+		}
+
+		pieces := splitThreadText(part.Text, api.MaxTextLength)
+		part.Text = pieces[0]
+		parts = append(parts, part)
+		for _, piece := range pieces[1:] {
+			parts = append(parts, threadPart{Text: piece})
+		}
+	}
+
+	if len(parts) == 0 {
+		return nil, &UserFriendlyError{
+			Message:    "Thread is empty",
+			Suggestion: "Provide text separated by lines containing only the separator (default \"---\")",
+		}
+	}
+	return parts, nil
+}
+
+// splitThreadText breaks text into pieces that pass Validator.ValidateTextLength,
+// preferring paragraph breaks, then line breaks, then spaces.
+func splitThreadText(text string, limit int) []string {
+	validator := api.NewValidator()
+	var pieces []string
+	for validator.ValidateTextLength(text, "Text") != nil {
+		cut := threadCutPoint(text, limit)
+		pieces = append(pieces, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	return append(pieces, text)
+}
+
+// threadCutPoint returns the byte offset at which to split text so the first
+// piece is at most limit bytes.
+func threadCutPoint(text string, limit int) int {
+	window := text[:limit+1]
+	for _, sep := range []string{"\n\n", "\n"} {
+		if i := strings.LastIndex(window, sep); i > limit/2 {
+			return i
+		}
+	}
+	if i := strings.LastIndexFunc(window, unicode.IsSpace); i > 0 {
+		return i
+	}
+
+	// No whitespace: cut at the last rune boundary within the limit.
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return cut
+}
+
+// threadPartOptions maps a single-media or text part onto 'posts create' options.
+func threadPartOptions(part threadPart, index int, replyTo string, opts *postsThreadOptions) *postsCreateOptions {
+	create := &postsCreateOptions{
+		Text:         part.Text,
+		ReplyTo:      replyTo,
+		ReplyControl: opts.ReplyControl,
+	}
+	if index == 0 {
+		create.Topic = opts.Topic
+	}
+	if len(part.Media) == 1 {
+		if detectMediaType(part.Media[0]) == api.MediaTypeVideo {
+			create.VideoURL = part.Media[0]
+		} else {
+			create.ImageURL = part.Media[0]
+		}
+		create.AltText = part.AltTexts[0]
+	}
+	return create
+}
+
+// threadCarouselOptions maps a multi-media part onto 'posts carousel' options.
+func threadCarouselOptions(part threadPart, index int, replyTo string, opts *postsThreadOptions) *postsCarouselOptions {
+	carousel := &postsCarouselOptions{
+		Items:        part.Media,
+		Text:         part.Text,
+		AltTexts:     part.AltTexts,
+		ReplyTo:      replyTo,
+		ReplyControl: opts.ReplyControl,
+		TimeoutSecs:  opts.TimeoutSecs,
+	}
+	if index == 0 {
+		carousel.Topic = opts.Topic
+	}
+	return carousel
+}
+
+func validateThreadPart(part threadPart, index int, opts *postsThreadOptions) error {
+	if len(part.Media) > 1 {
+		_, _, err := buildCarouselContent(threadCarouselOptions(part, index, "", opts))
+		return err
+	}
+	_, err := buildPostContent(threadPartOptions(part, index, "", opts))
+	return err
+}
+
+// publishThreadPart publishes one part as a reply to replyTo (if set) through
+// the same helpers as 'posts create' and 'posts carousel'.
+func publishThreadPart(ctx context.Context, client *api.Client, part threadPart, index int, replyTo string, opts *postsThreadOptions, uploads *localMedia) (*api.Post, error) {
+	if len(part.Media) > 1 {
+		content, items, err := buildCarouselContent(threadCarouselOptions(part, index, replyTo, opts))
+		if err != nil {
+			return nil, err
+		}
+		return publishCarouselContent(ctx, client, content, items, opts.TimeoutSecs, uploads)
+	}
+
+	content, err := buildPostContent(threadPartOptions(part, index, replyTo, opts))
+	if err != nil {
+		return nil, err
+	}
+	return publishPostContent(ctx, client, content, uploads)
+}
+
+// resolveThreadMediaPaths makes relative local media paths relative to the
+// thread file, the way Markdown image links are.
+func resolveThreadMediaPaths(parts []threadPart, dir string) {
+	for i := range parts {
+		for j, ref := range parts[i].Media {
+			if media.IsLocal(ref) && !filepath.IsAbs(ref) {
+				parts[i].Media[j] = filepath.Join(dir, ref)
+			}
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+func TestParseThreadParts(t *testing.T) {
+	input := "First part\n---\nSecond part\n![A cat](https://example.com/cat.jpg)\n---\n\n---\n![](https://example.com/a.jpg)\n![](https://example.com/b.mp4)\nAlbum"
+
+	parts, err := parseThreadParts(input, "---")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts (empty part skipped), got %d: %+v", len(parts), parts)
+	}
+	if parts[0].Text != "First part" || len(parts[0].Media) != 0 {
+		t.Errorf("unexpected part 1: %+v", parts[0])
+	}
+	if parts[1].Text != "Second part" || len(parts[1].Media) != 1 || parts[1].AltTexts[0] != "A cat" {
+		t.Errorf("unexpected part 2: %+v", parts[1])
+	}
+	if parts[2].Text != "Album" || len(parts[2].Media) != 2 {
+		t.Errorf("unexpected part 3: %+v", parts[2])
+	}
+
+	if _, err := parseThreadParts("\n---\n  \n", "---"); err == nil {
+		t.Error("expected error for empty thread")
+	}
+}
+
+func TestParseThreadParts_AutoSplit(t *testing.T) {
+	long := strings.TrimSpace(strings.Repeat("word ", 250)) // 1249 bytes
+	parts, err := parseThreadParts(long+"\n![](https://example.com/a.jpg)", "---")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %d", len(parts))
+	}
+	if len(parts[0].Media) != 1 || len(parts[1].Media) != 0 {
+		t.Errorf("expected media to stay on the first piece, got %+v", parts)
+	}
+
+	validator := api.NewValidator()
+	var rejoined []string
+	for i, part := range parts {
+		if err := validator.ValidateTextLength(part.Text, "Text"); err != nil {
+			t.Errorf("part %d exceeds limit: %d bytes", i+1, len(part.Text))
+		}
+		rejoined = append(rejoined, part.Text)
+	}
+	if strings.Join(rejoined, " ") != long {
+		t.Error("expected auto-split to keep every word")
+	}
+}
+
+func TestSplitThreadText(t *testing.T) {
+	para := strings.Repeat("a", 300)
+	got := splitThreadText(para+"\n\n"+para, 500)
+	if len(got) != 2 || got[0] != para || got[1] != para {
+		t.Errorf("expected split at paragraph break, got %q", got)
+	}
+
+	// No whitespace and multi-byte runes: cut on a rune boundary.
+	runes := strings.Repeat("é", 400) // 800 bytes
+	got = splitThreadText(runes, 500)
+	if len(got) != 2 || strings.Join(got, "") != runes {
+		t.Fatalf("expected lossless split, got %d pieces", len(got))
+	}
+	for _, piece := range got {
+		if len(piece) > 500 || !strings.HasPrefix(piece, "é") {
+			t.Errorf("invalid piece of %d bytes", len(piece))
+		}
+	}
+}
+
+// threadTestServer publishes posts p1, p2, ... and records each post's reply_to_id.
+type threadTestServer struct {
+	mu        sync.Mutex
+	replyTo   []string
+	failAfter int
+	// loseResponse publishes that part but answers with a gateway timeout.
+	loseResponse int
+	published    map[string]bool
+}
+
+func (s *threadTestServer) handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/refresh_access_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "refreshed-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		case r.URL.Path == "/12345/threads":
+			_ = r.ParseForm()
+			if s.failAfter > 0 && len(s.replyTo) >= s.failAfter {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"error": map[string]any{"message": "invalid parameter", "code": 100},
+				})
+				return
+			}
+			s.replyTo = append(s.replyTo, r.Form.Get("reply_to_id"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": fmt.Sprintf("c%d", len(s.replyTo))})
+		case strings.HasPrefix(r.URL.Path, "/c"):
+			id := strings.TrimPrefix(r.URL.Path, "/")
+			status := "FINISHED"
+			if s.published[id] {
+				status = "PUBLISHED"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "status": status})
+		case r.URL.Path == "/12345/threads_publish":
+			_ = r.ParseForm()
+			creationID := r.Form.Get("creation_id")
+			if creationID == fmt.Sprintf("c%d", s.loseResponse) {
+				if s.published == nil {
+					s.published = map[string]bool{}
+				}
+				s.published[creationID] = true
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			id := "p" + strings.TrimPrefix(creationID, "c")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": id})
+		case strings.HasPrefix(r.URL.Path, "/p"):
+			id := strings.TrimPrefix(r.URL.Path, "/")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":        id,
+				"permalink": "https://www.threads.net/t/" + id,
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+				"username":  "testuser",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestPostsThread_ChainsRepliesAndResumes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "thread.md")
+	if err := os.WriteFile(file, []byte("one\n---\ntwo\n---\nthree"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := &threadTestServer{failAfter: 2}
+	server := httptest.NewServer(ts.handler())
+	defer server.Close()
+
+	f, io := newIntegrationTestFactory(t, server.URL)
+	f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+		cfg.RetryConfig.MaxRetries = 0
+	})
+	ctx := iocontext.WithIO(context.Background(), io)
+	ctx = outfmt.WithFormat(ctx, "json")
+	out := io.Out.(*bytes.Buffer)
+
+	// The third part fails; the error explains how to resume.
+	cmd := newPostsThreadCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--file", file})
+	err := cmd.Execute()
+	var ufe *UserFriendlyError
+	if !errors.As(err, &ufe) || !strings.Contains(ufe.Suggestion, "--resume p2 --from 3") {
+		t.Fatalf("expected resumable error, got %v", err)
+	}
+	var partial threadResult
+	if errJSON := json.Unmarshal(out.Bytes(), &partial); errJSON != nil {
+		t.Fatalf("failed to parse partial output: %v", errJSON)
+	}
+	if partial.Complete || partial.FailedPart != 3 || len(partial.Published) != 2 {
+		t.Fatalf("unexpected partial result: %+v", partial)
+	}
+	if got := strings.Join(ts.replyTo, ","); got != ",p1" {
+		t.Fatalf("expected part 2 to reply to part 1, got reply_to_id values %q", got)
+	}
+
+	// Resume from part 3.
+	ts.failAfter = 0
+	out.Reset()
+	cmd = newPostsThreadCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--file", file, "--resume", "p2", "--from", "3"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	var result threadResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if !result.Complete || len(result.Published) != 1 || result.Published[0].Part != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if got := strings.Join(ts.replyTo, ","); got != ",p1,p2" {
+		t.Errorf("expected part 3 to reply to part 2, got reply_to_id values %q", got)
+	}
+}
+
+func TestPostsThread_StopsWhenPublishedIDIsLost(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "thread.md")
+	if err := os.WriteFile(file, []byte("one\n---\ntwo\n---\nthree"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := &threadTestServer{loseResponse: 1}
+	server := httptest.NewServer(ts.handler())
+	defer server.Close()
+
+	f, io := newIntegrationTestFactory(t, server.URL)
+	f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+		cfg.RetryConfig.MaxRetries = 1
+	})
+	ctx := iocontext.WithIO(context.Background(), io)
+	ctx = outfmt.WithFormat(ctx, "json")
+	out := io.Out.(*bytes.Buffer)
+
+	cmd := newPostsThreadCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--file", file})
+	err := cmd.Execute()
+	var ufe *UserFriendlyError
+	if !errors.As(err, &ufe) || !errors.Is(err, api.ErrPublishedIDUnknown) {
+		t.Fatalf("expected a published-ID-unknown error, got %v", err)
+	}
+	if !strings.Contains(ufe.Suggestion, "--resume <ID> --from 2") || strings.Contains(ufe.Suggestion, "Nothing was published") {
+		t.Errorf("expected a suggestion to resume after part 1, got %q", ufe.Suggestion)
+	}
+	if len(ts.replyTo) != 1 {
+		t.Errorf("expected publishing to stop after part 1, got %d containers", len(ts.replyTo))
+	}
+
+	var partial threadResult
+	if errJSON := json.Unmarshal(out.Bytes(), &partial); errJSON != nil {
+		t.Fatalf("failed to parse partial output: %v", errJSON)
+	}
+	if partial.UnknownID != 1 || partial.FailedPart != 0 || len(partial.Published) != 1 || partial.Published[0].ID != "" {
+		t.Fatalf("expected part 1 to be recorded as published with an unknown ID, got %+v", partial)
+	}
+}
+
+func TestPostsThread_ResumeRequiresFrom(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "thread.md")
+	if err := os.WriteFile(file, []byte("one\n---\ntwo"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f := newTestFactory(t)
+	cmd := newPostsThreadCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), f.IO))
+	cmd.SetArgs([]string{"--file", file, "--resume", "p1"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error using --resume without --from")
+	}
+}
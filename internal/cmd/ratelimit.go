@@ -6,8 +6,10 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/salmonumbrella/threads-cli/internal/api"
 	"github.com/salmonumbrella/threads-cli/internal/iocontext"
 	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/quota"
 )
 
 // NewRateLimitCmd builds the ratelimit command group.
@@ -19,7 +21,8 @@ func NewRateLimitCmd(f *Factory) *cobra.Command {
 	}
 
 	cmd.AddCommand(newRateLimitStatusCmd(f))
-	cmd.AddCommand(newRateLimitPublishingCmd(f))
+	cmd.AddCommand(requireScopes(newRateLimitPublishingCmd(f), scopeBasic, scopeContentPublish))
+	cmd.AddCommand(requireScopes(newRateLimitHistoryCmd(f), scopeBasic, scopeContentPublish))
 
 	return cmd
 }
@@ -42,14 +45,18 @@ func newRateLimitStatusCmd(f *Factory) *cobra.Command {
 			io := iocontext.GetIO(ctx)
 			if outfmt.IsJSON(ctx) {
 				out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
-				return out.Output(map[string]interface{}{
+				result := map[string]interface{}{
 					"is_limited": isLimited,
 					"remaining":  status.Remaining,
 					"limit":      status.Limit,
 					"reset_at":   status.ResetTime,
 					"reset_in":   status.ResetIn.String(),
 					"near_limit": nearLimit,
-				})
+				}
+				if len(status.Throttle) > 0 {
+					result["throttle"] = status.Throttle
+				}
+				return out.Output(result)
 			}
 
 			// Text output
@@ -62,6 +69,16 @@ func newRateLimitStatusCmd(f *Factory) *cobra.Command {
 				}
 			}
 
+			for _, class := range []api.EndpointClass{api.EndpointRead, api.EndpointPublish, api.EndpointInsights, api.EndpointSearch} {
+				t, ok := status.Throttle[class]
+				if !ok {
+					continue
+				}
+				//nolint:errcheck // Best-effort output
+				fmt.Fprintf(io.Out, "Throttle %s: %g/s, %d requests, %d delayed, avg wait %s, max wait %s\n",
+					class, t.Rate, t.Requests, t.Delayed, t.AverageWait().Round(time.Millisecond), t.MaxWait.Round(time.Millisecond))
+			}
+
 			return nil
 		},
 	}
@@ -83,6 +100,11 @@ func newRateLimitPublishingCmd(f *Factory) *cobra.Command {
 			if err != nil {
 				return WrapError("failed to get publishing limits", err)
 			}
+			if creds, errCreds := f.ActiveCredentials(ctx); errCreds == nil {
+				if errSync := quota.NewLedger(quota.DefaultPath()).Reconcile(creds.UserID, limits); errSync != nil {
+					f.UI(ctx).Warning("Failed to update quota ledger: %v", errSync)
+				}
+			}
 
 			io := iocontext.GetIO(ctx)
 			if outfmt.IsJSON(ctx) {
@@ -97,3 +119,134 @@ func newRateLimitPublishingCmd(f *Factory) *cobra.Command {
 	}
 	return cmd
 }
+
+type rateLimitHistoryOptions struct {
+	Offline bool
+	Limit   int
+}
+
+func newRateLimitHistoryCmd(f *Factory) *cobra.Command {
+	opts := &rateLimitHistoryOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show posts, replies and deletes over the rolling 24-hour window",
+		Long: `Show how much of the 24-hour publishing quotas the active account has used.
+
+Every post, reply and delete made through the CLI is recorded in a local
+ledger under the data directory, and commands refuse an action that would
+exceed quota instead of sending it; 'threads schedule run' defers such posts
+until quota frees up. Threads allows 250 posts, 1000 replies and 25 deletes
+per rolling 24 hours.
+
+The ledger is reconciled against the API's publishing limits first, so
+actions taken outside the CLI are counted. Use --offline to skip this.`,
+		Example: `  threads ratelimit history
+  threads ratelimit history --offline -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRateLimitHistory(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Use the local ledger without fetching publishing limits")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 20, "Recent actions to list in text output")
+	return cmd
+}
+
+func runRateLimitHistory(cmd *cobra.Command, f *Factory, opts *rateLimitHistoryOptions) error {
+	ctx := cmd.Context()
+
+	creds, err := f.ActiveCredentials(ctx)
+	if err != nil {
+		return err
+	}
+	ledger := quota.NewLedger(quota.DefaultPath())
+
+	if !opts.Offline {
+		client, errClient := f.Client(ctx)
+		if errClient != nil {
+			return errClient
+		}
+		limits, errLimits := client.GetPublishingLimits(ctx)
+		if errLimits != nil {
+			return WrapError("failed to get publishing limits", errLimits)
+		}
+		if errSync := ledger.Reconcile(creds.UserID, limits); errSync != nil {
+			return WrapError("failed to update quota ledger", errSync)
+		}
+	}
+
+	usage, err := ledger.Usage(creds.UserID)
+	if err != nil {
+		return WrapError("failed to read quota ledger", err)
+	}
+	entries, err := ledger.History(creds.UserID)
+	if err != nil {
+		return WrapError("failed to read quota ledger", err)
+	}
+	reconciledAt, err := ledger.ReconciledAt(creds.UserID)
+	if err != nil {
+		return WrapError("failed to read quota ledger", err)
+	}
+
+	io := iocontext.GetIO(ctx)
+	out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+	if outfmt.IsJSON(ctx) {
+		result := map[string]any{
+			"window":  quota.Window.String(),
+			"usage":   usage,
+			"entries": entries,
+		}
+		if !reconciledAt.IsZero() {
+			result["reconciled_at"] = reconciledAt
+		}
+		return out.Output(result)
+	}
+
+	rows := make([][]string, len(usage))
+	for i, u := range usage {
+		next := "-"
+		if u.NextSlotAt != nil {
+			next = u.NextSlotAt.Local().Format("2006-01-02 15:04")
+		}
+		rows[i] = []string{
+			string(u.Action),
+			fmt.Sprintf("%d", u.Used),
+			fmt.Sprintf("%d", u.Limit),
+			fmt.Sprintf("%d", u.Remaining),
+			next,
+		}
+	}
+	if err := out.Table([]string{"ACTION", "USED", "LIMIT", "REMAINING", "NEXT SLOT"}, rows, []outfmt.ColumnType{
+		outfmt.ColumnPlain,
+		outfmt.ColumnPlain,
+		outfmt.ColumnPlain,
+		outfmt.ColumnPlain,
+		outfmt.ColumnDate,
+	}); err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+	}
+
+	fmt.Fprintln(io.Out) //nolint:errcheck // Best-effort output
+	rows = make([][]string, len(entries))
+	for i, e := range entries {
+		rows[i] = []string{
+			e.At.Local().Format("2006-01-02 15:04:05"),
+			string(e.Action),
+			e.ID,
+		}
+	}
+	return out.Table([]string{"TIME", "ACTION", "ID"}, rows, []outfmt.ColumnType{
+		outfmt.ColumnDate,
+		outfmt.ColumnPlain,
+		outfmt.ColumnID,
+	})
+}
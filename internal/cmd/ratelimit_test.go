@@ -1,6 +1,18 @@
 package cmd
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/quota"
+)
 
 func TestRateLimitCmd_Structure(t *testing.T) {
 	f := newTestFactory(t)
@@ -16,8 +28,8 @@ func TestRateLimitCmd_Structure(t *testing.T) {
 	}
 
 	subcommands := cmd.Commands()
-	if len(subcommands) != 2 {
-		t.Errorf("expected 2 subcommands, got %d", len(subcommands))
+	if len(subcommands) != 3 {
+		t.Errorf("expected 3 subcommands, got %d", len(subcommands))
 	}
 }
 
@@ -38,3 +50,58 @@ func TestRateLimitPublishingCmd_Structure(t *testing.T) {
 		t.Errorf("expected Use=publishing, got %s", cmd.Use)
 	}
 }
+
+func TestRateLimitHistory_Reconciles(t *testing.T) {
+	setTestDataDir(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/refresh_access_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "refreshed-token", "token_type": "Bearer", "expires_in": 3600})
+		case "/12345/threads_publishing_limit":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{
+				"quota_usage":        5,
+				"config":             map[string]any{"quota_total": 250, "quota_duration": 86400},
+				"reply_quota_usage":  0,
+				"reply_config":       map[string]any{"quota_total": 1000, "quota_duration": 86400},
+				"delete_quota_usage": 0,
+				"delete_config":      map[string]any{"quota_total": 25, "quota_duration": 86400},
+			}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ledger := quota.NewLedger(quota.DefaultPath())
+	for _, id := range []string{"p1", "p2"} {
+		if err := ledger.Record("12345", api.QuotaPost, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, streams := newIntegrationTestFactory(t, server.URL)
+	ctx := outfmt.WithFormat(iocontext.WithIO(context.Background(), streams), "json")
+	cmd := NewRateLimitCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"history"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+
+	var result struct {
+		Usage   []quota.Usage `json:"usage"`
+		Entries []quota.Entry `json:"entries"`
+	}
+	if err := json.Unmarshal(streams.Out.(*bytes.Buffer).Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(result.Usage) != 3 || len(result.Entries) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	post := result.Usage[0]
+	if post.Action != api.QuotaPost || post.Used != 5 || post.Recorded != 2 || post.Remaining != 245 {
+		t.Errorf("expected reconciled post usage, got %+v", post)
+	}
+}
@@ -20,11 +20,11 @@ func NewRepliesCmd(f *Factory) *cobra.Command {
 		Long:    `List, create, hide, and manage replies to Threads posts.`,
 	}
 
-	cmd.AddCommand(newRepliesListCmd(f))
-	cmd.AddCommand(newRepliesCreateCmd(f))
-	cmd.AddCommand(newRepliesHideCmd(f))
-	cmd.AddCommand(newRepliesUnhideCmd(f))
-	cmd.AddCommand(newRepliesConversationCmd(f))
+	cmd.AddCommand(requireScopes(newRepliesListCmd(f), scopeBasic, scopeReadReplies))
+	cmd.AddCommand(requireScopes(newRepliesCreateCmd(f), scopeBasic, scopeContentPublish, scopeManageReplies))
+	cmd.AddCommand(requireScopes(newRepliesHideCmd(f), scopeBasic, scopeManageReplies))
+	cmd.AddCommand(requireScopes(newRepliesUnhideCmd(f), scopeBasic, scopeManageReplies))
+	cmd.AddCommand(requireScopes(newRepliesConversationCmd(f), scopeBasic, scopeReadReplies))
 
 	return cmd
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/salmonumbrella/threads-cli/internal/api"
 	"github.com/salmonumbrella/threads-cli/internal/iocontext"
 	"github.com/salmonumbrella/threads-cli/internal/outfmt"
 )
@@ -20,15 +21,18 @@ var (
 
 // RootOptions captures global flags.
 type RootOptions struct {
-	Account  string
-	Output   string
-	JSON     bool
-	Color    string
-	NoColor  bool
-	Debug    bool
-	Query    string
-	Yes      bool
-	NoPrompt bool
+	Account   string
+	Output    string
+	JSON      bool
+	Color     string
+	NoColor   bool
+	Debug     bool
+	Query     string
+	Yes       bool
+	NoPrompt  bool
+	NoCache   bool
+	Refresh   bool
+	TraceFile string
 }
 
 // Execute runs the CLI with a new factory and root command.
@@ -58,6 +62,9 @@ func ExecuteCommand(cmd *cobra.Command, f *Factory) error {
 	}
 
 	err := cmd.Execute()
+	if f != nil {
+		f.finishTelemetry(cmd.Context(), err)
+	}
 	if err != nil {
 		if io == nil && f != nil {
 			io = f.IO
@@ -146,6 +153,21 @@ Designed to be agent-friendly for automation with Claude and other AI assistants
 				account = opts.Account
 			}
 
+			if opts.NoCache && opts.Refresh {
+				return &UserFriendlyError{
+					Message:    "--no-cache and --refresh cannot be used together",
+					Suggestion: "Use --refresh to update cached responses, or --no-cache to bypass the cache",
+				}
+			}
+			switch {
+			case opts.NoCache:
+				f.CacheMode = api.CacheOff
+			case opts.Refresh:
+				f.CacheMode = api.CacheRefresh
+			default:
+				f.CacheMode = api.CacheDefault
+			}
+
 			f.Output = outfmt.ParseFormat(output)
 			f.ColorMode = outfmt.ParseColorMode(color)
 			f.Debug = debug
@@ -155,6 +177,16 @@ Designed to be agent-friendly for automation with Claude and other AI assistants
 			ctx = outfmt.WithQuery(ctx, opts.Query)
 			ctx = outfmt.WithYes(ctx, opts.Yes || opts.NoPrompt)
 			ctx = outfmt.WithColorMode(ctx, f.ColorMode)
+			ctx = withRequiredScopes(ctx, commandScopes(cmd))
+
+			traceFile := opts.TraceFile
+			if !cmd.Flags().Changed("trace-file") && f.Config != nil && f.Config.Telemetry != nil {
+				traceFile = f.Config.Telemetry.TraceFile
+			}
+			ctx, err := f.startTelemetry(ctx, cmd, traceFile)
+			if err != nil {
+				return err
+			}
 			cmd.SetContext(ctx)
 
 			return nil
@@ -170,16 +202,26 @@ Designed to be agent-friendly for automation with Claude and other AI assistants
 	cmd.PersistentFlags().StringVarP(&opts.Query, "query", "q", "", "JQ query to filter JSON output")
 	cmd.PersistentFlags().BoolVarP(&opts.Yes, "yes", "y", false, "Skip confirmation prompts")
 	cmd.PersistentFlags().BoolVar(&opts.NoPrompt, "no-prompt", false, "Alias for --yes (skip confirmations)")
+	cmd.PersistentFlags().BoolVar(&opts.NoCache, "no-cache", false, "Bypass the response cache (or set THREADS_NO_CACHE)")
+	cmd.PersistentFlags().BoolVar(&opts.Refresh, "refresh", false, "Revalidate cached responses with the API")
+	cmd.PersistentFlags().StringVar(&opts.TraceFile, "trace-file", "", "Append OpenTelemetry traces and metrics of API calls to a file (or set THREADS_TRACE_FILE)")
 
 	cmd.AddCommand(NewAuthCmd(f))
+	cmd.AddCommand(NewCacheCmd(f))
 	cmd.AddCommand(NewCompletionCmd())
+	cmd.AddCommand(NewContainersCmd(f))
+	cmd.AddCommand(NewDevCmd(f))
+	cmd.AddCommand(NewDraftsCmd(f))
+	cmd.AddCommand(requireScopes(NewInboxCmd(f), scopeBasic, scopeManageMentions, scopeReadReplies))
 	cmd.AddCommand(NewInsightsCmd(f))
 	cmd.AddCommand(NewLocationsCmd(f))
-	cmd.AddCommand(NewUsersMeCmd(f))
+	cmd.AddCommand(NewMediaCmd(f))
+	cmd.AddCommand(requireScopes(NewUsersMeCmd(f), scopeBasic))
 	cmd.AddCommand(NewPostsCmd(f))
 	cmd.AddCommand(NewRateLimitCmd(f))
 	cmd.AddCommand(NewRepliesCmd(f))
-	cmd.AddCommand(NewSearchCmd(f))
+	cmd.AddCommand(NewScheduleCmd(f))
+	cmd.AddCommand(requireScopes(NewSearchCmd(f), scopeBasic, scopeKeywordSearch))
 	cmd.AddCommand(NewUsersCmd(f))
 	cmd.AddCommand(NewVersionCmd())
 	cmd.AddCommand(NewWebhooksCmd(f))
@@ -46,15 +46,22 @@ func TestRootCmd_HasSubcommands(t *testing.T) {
 
 	expectedSubs := []string{
 		"auth",
+		"cache",
 		"completion",
 		"config",
+		"containers",
+		"dev",
+		"drafts",
 		"help-json",
+		"inbox",
 		"insights",
 		"locations",
+		"media",
 		"me",
 		"posts",
 		"ratelimit",
 		"replies",
+		"schedule",
 		"search",
 		"users",
 		"version",
@@ -0,0 +1,638 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/schedule"
+	"github.com/salmonumbrella/threads-cli/internal/ui"
+)
+
+// scheduleTimeLayouts are the accepted --at formats besides RFC3339.
+// Times without a zone are interpreted in the local time zone.
+var scheduleTimeLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// NewScheduleCmd builds the schedule command group.
+func NewScheduleCmd(f *Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "schedule",
+		Aliases: []string{"sched"},
+		Short:   "Schedule posts for later publishing",
+		Long: `Queue posts locally and publish them at a later time.
+
+Scheduled posts are stored on disk and published by 'threads schedule run',
+which is designed to be called from cron (or kept running with --watch).
+Transient failures such as network errors and rate limits are retried
+with exponential backoff.`,
+	}
+
+	cmd.AddCommand(newScheduleAddCmd(f))
+	cmd.AddCommand(newScheduleListCmd(f))
+	cmd.AddCommand(newScheduleCancelCmd(f))
+	cmd.AddCommand(requireScopes(newScheduleRunCmd(f), scopeBasic, scopeContentPublish))
+
+	return cmd
+}
+
+type scheduleAddOptions struct {
+	postsCreateOptions
+	At          string
+	In          time.Duration
+	Items       []string
+	ItemAltText []string
+}
+
+func newScheduleAddCmd(f *Factory) *cobra.Command {
+	opts := &scheduleAddOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "add",
+		Aliases: []string{"new", "create"},
+		Short:   "Schedule a post",
+		Long: `Schedule a text, image, video, or carousel post for later publishing.
+
+Accepts the same content flags as 'threads posts create', plus --items for
+carousel posts. Use --at for an absolute time or --in for a relative delay.`,
+		Example: `  # Schedule a text post for a specific time (local time zone)
+  threads schedule add --text "Good morning!" --at "2025-01-15 09:00"
+
+  # Schedule an image post in two hours
+  threads schedule add --image https://example.com/a.jpg --text "Later" --in 2h
+
+  # Schedule a carousel
+  threads schedule add --items url1,url2,url3 --text "Album" --at 2025-01-15T18:00:00Z`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScheduleAdd(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.At, "at", "", "Publish time (RFC3339 or \"YYYY-MM-DD HH:MM\" in local time)")
+	cmd.Flags().DurationVar(&opts.In, "in", 0, "Publish after a delay (e.g. 30m, 2h)")
+	cmd.Flags().StringVarP(&opts.Text, "text", "t", "", "Post text content")
+	cmd.Flags().StringVar(&opts.TextFile, "text-file", "", "Read post text content from a file (or '-' for stdin)")
+	cmd.Flags().StringVar(&opts.ImageURL, "image", "", "Image URL or local file for image posts")
+	cmd.Flags().StringVar(&opts.VideoURL, "video", "", "Video URL or local file for video posts")
+	cmd.Flags().StringVar(&opts.AltText, "alt-text", "", "Alt text for media accessibility")
+	cmd.Flags().StringSliceVar(&opts.Items, "items", nil, "Carousel media URLs or local files (comma-separated)")
+	cmd.Flags().StringArrayVar(&opts.ItemAltText, "item-alt-text", nil, "Alt text for each carousel item (repeatable, in order)")
+	cmd.Flags().StringVar(&opts.ReplyTo, "reply-to", "", "Post ID to reply to")
+	cmd.Flags().StringVar(&opts.Poll, "poll", "", "Create a poll with comma-separated options (2-4 options)")
+	cmd.Flags().BoolVar(&opts.Ghost, "ghost", false, "Create a ghost post (text-only, expires 24 hours after publishing)")
+	cmd.Flags().StringVar(&opts.Topic, "topic", "", "Add a topic tag to the post")
+	cmd.Flags().StringVar(&opts.Location, "location", "", "Attach a location ID to the post")
+	cmd.Flags().StringVar(&opts.ReplyControl, "reply-control", "", "Control who can reply: everyone, accounts_you_follow, mentioned_only")
+	cmd.Flags().StringVar(&opts.GIF, "gif", "", "Attach a GIF using a Tenor GIF ID (text-only posts)")
+	cmd.Flags().BoolVar(&opts.SpoilerMedia, "spoiler-media", false, "Mark the media as a spoiler")
+	cmd.Flags().StringArrayVar(&opts.Spoilers, "spoiler", nil, "Mark a text range as a spoiler as OFFSET:LENGTH (repeatable)")
+
+	return cmd
+}
+
+func runScheduleAdd(cmd *cobra.Command, f *Factory, opts *scheduleAddOptions) error {
+	ctx := cmd.Context()
+
+	publishAt, err := parseScheduleTime(opts.At, opts.In, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(opts.TextFile) != "" {
+		if strings.TrimSpace(opts.Text) != "" {
+			return &UserFriendlyError{
+				Message:    "Cannot use both --text and --text-file",
+				Suggestion: "Use --text for inline text, or --text-file to read from file/stdin",
+			}
+		}
+		txt, errRead := readTextFileOrStdin(ctx, opts.TextFile)
+		if errRead != nil {
+			return errRead
+		}
+		opts.Text = txt
+	}
+
+	payload, err := buildSchedulePayload(opts)
+	if err != nil {
+		return err
+	}
+
+	account, err := f.resolveAccount()
+	if err != nil {
+		return err
+	}
+
+	queue := schedule.NewQueue(schedule.DefaultPath())
+	item, err := queue.Add(schedule.Item{
+		Account:   account,
+		PublishAt: publishAt,
+		Payload:   *payload,
+	})
+	if err != nil {
+		return WrapError("failed to schedule post", err)
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(item)
+	}
+
+	f.UI(ctx).Success("Post scheduled")
+	fmt.Fprintf(io.Out, "  ID:         %s\n", item.ID)                                                                         //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "  Type:       %s\n", item.Payload.Kind)                                                               //nolint:errcheck // Best-effort output
+	fmt.Fprintf(io.Out, "  Publish at: %s (%s)\n", item.PublishAt.Format(time.RFC3339), ui.FormatRelativeTime(item.PublishAt)) //nolint:errcheck // Best-effort output
+	return nil
+}
+
+// buildSchedulePayload validates options with the same rules as
+// 'posts create' / 'posts carousel' and returns the payload to store.
+func buildSchedulePayload(opts *scheduleAddOptions) (*schedule.Payload, error) {
+	if len(opts.Items) == 0 {
+		content, err := buildPostContent(&opts.postsCreateOptions)
+		if err != nil {
+			return nil, err
+		}
+		switch c := content.(type) {
+		case *api.ImagePostContent:
+			return &schedule.Payload{Kind: schedule.KindImage, Image: c}, nil
+		case *api.VideoPostContent:
+			return &schedule.Payload{Kind: schedule.KindVideo, Video: c}, nil
+		case *api.TextPostContent:
+			return &schedule.Payload{Kind: schedule.KindText, Text: c}, nil
+		default:
+			return nil, fmt.Errorf("unsupported post content type %T", content)
+		}
+	}
+
+	if err := rejectSingleMediaOptions(&opts.postsCreateOptions); err != nil {
+		return nil, err
+	}
+
+	content, items, err := buildCarouselContent(&postsCarouselOptions{
+		Items:        opts.Items,
+		Text:         opts.Text,
+		AltTexts:     opts.ItemAltText,
+		ReplyTo:      opts.ReplyTo,
+		Topic:        opts.Topic,
+		Location:     opts.Location,
+		ReplyControl: opts.ReplyControl,
+		SpoilerMedia: opts.SpoilerMedia,
+		Spoilers:     opts.Spoilers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &schedule.Payload{
+		Kind:     schedule.KindCarousel,
+		Carousel: content,
+	}
+	for _, item := range items {
+		payload.CarouselItems = append(payload.CarouselItems, schedule.CarouselItem(item))
+	}
+	return payload, nil
+}
+
+// parseScheduleTime resolves --at/--in into an absolute publish time.
+func parseScheduleTime(at string, in time.Duration, now time.Time) (time.Time, error) {
+	at = strings.TrimSpace(at)
+	switch {
+	case at != "" && in != 0:
+		return time.Time{}, &UserFriendlyError{
+			Message:    "Cannot use both --at and --in",
+			Suggestion: "Use --at for an absolute time or --in for a relative delay",
+		}
+	case at == "" && in == 0:
+		return time.Time{}, &UserFriendlyError{
+			Message:    "No publish time provided",
+			Suggestion: "Provide --at \"YYYY-MM-DD HH:MM\" or --in 2h",
+		}
+	case in < 0:
+		return time.Time{}, &UserFriendlyError{
+			Message:    "--in must be a positive duration",
+			Suggestion: "Use a value like 30m or 2h",
+		}
+	case in > 0:
+		return now.Add(in), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		return t, nil
+	}
+	for _, layout := range scheduleTimeLayouts {
+		if t, err := time.ParseInLocation(layout, at, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, &UserFriendlyError{
+		Message:    fmt.Sprintf("Invalid --at value: %s", at),
+		Suggestion: "Use RFC3339 (2025-01-15T09:00:00Z) or \"YYYY-MM-DD HH:MM\" in local time",
+	}
+}
+
+func newScheduleListCmd(f *Factory) *cobra.Command {
+	var status string
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List scheduled posts",
+		Long: `List scheduled posts, ordered by publish time.
+
+By default only pending posts are shown. Use --all to include published,
+failed, and cancelled entries, or --status to filter by a single status.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScheduleList(cmd, f, status, all)
+		},
+	}
+
+	cmd.Flags().StringVar(&status, "status", "", "Filter by status: pending, published, failed, cancelled")
+	cmd.Flags().BoolVar(&all, "all", false, "Include published, failed, and cancelled posts")
+	return cmd
+}
+
+func runScheduleList(cmd *cobra.Command, f *Factory, status string, all bool) error {
+	ctx := cmd.Context()
+
+	switch schedule.Status(status) {
+	case "", schedule.StatusPending, schedule.StatusPublished, schedule.StatusFailed, schedule.StatusCancelled:
+	default:
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Invalid status: %s", status),
+			Suggestion: "Valid values are: pending, published, failed, cancelled",
+		}
+	}
+
+	queue := schedule.NewQueue(schedule.DefaultPath())
+	items, err := queue.List()
+	if err != nil {
+		return WrapError("failed to read schedule", err)
+	}
+
+	filtered := []schedule.Item{}
+	for _, item := range items {
+		switch {
+		case status != "":
+			if item.Status != schedule.Status(status) {
+				continue
+			}
+		case !all:
+			if item.Status != schedule.StatusPending {
+				continue
+			}
+		}
+		filtered = append(filtered, item)
+	}
+
+	io := iocontext.GetIO(ctx)
+	out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+	if outfmt.IsJSONL(ctx) {
+		return out.Output(filtered)
+	}
+	if outfmt.IsJSON(ctx) {
+		return out.Output(itemsEnvelope(filtered, nil, ""))
+	}
+
+	if len(filtered) == 0 {
+		f.UI(ctx).Info("No scheduled posts")
+		return nil
+	}
+
+	out.Header("ID", "PUBLISH AT", "TYPE", "STATUS", "TEXT")
+	for _, item := range filtered {
+		text := strings.ReplaceAll(item.Payload.Summary(), "\n", " ")
+		if len(text) > 40 {
+			text = text[:40] + "..."
+		}
+		itemStatus := string(item.Status)
+		if item.Status == schedule.StatusPending && item.Attempts > 0 {
+			itemStatus = fmt.Sprintf("retrying (%d)", item.Attempts)
+		}
+		out.Row(
+			item.ID,
+			item.PublishAt.Local().Format("2006-01-02 15:04"),
+			string(item.Payload.Kind),
+			itemStatus,
+			text,
+		)
+	}
+	out.Flush()
+	return nil
+}
+
+func newScheduleCancelCmd(f *Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "cancel [schedule-id]",
+		Aliases: []string{"rm", "delete"},
+		Short:   "Cancel a scheduled post",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScheduleCancel(cmd, f, args[0])
+		},
+	}
+	return cmd
+}
+
+func runScheduleCancel(cmd *cobra.Command, f *Factory, id string) error {
+	ctx := cmd.Context()
+
+	queue := schedule.NewQueue(schedule.DefaultPath())
+	item, err := queue.Cancel(id)
+	switch {
+	case errors.Is(err, schedule.ErrNotFound):
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Scheduled post not found: %s", id),
+			Suggestion: "Run 'threads schedule list' to see scheduled post IDs",
+		}
+	case errors.Is(err, schedule.ErrNotPending):
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Scheduled post %s is no longer pending", id),
+			Suggestion: "Only pending posts can be cancelled. Use 'threads schedule list --all' to see its status",
+		}
+	case err != nil:
+		return WrapError("failed to cancel scheduled post", err)
+	}
+
+	io := iocontext.GetIO(ctx)
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		return out.Output(item)
+	}
+
+	f.UI(ctx).Success("Cancelled scheduled post %s", item.ID)
+	return nil
+}
+
+type scheduleRunOptions struct {
+	Watch       bool
+	Interval    time.Duration
+	TimeoutSecs int
+}
+
+// scheduleRunResult reports the outcome of one publish attempt.
+type scheduleRunResult struct {
+	ID            string          `json:"id"`
+	Status        schedule.Status `json:"status"`
+	Attempts      int             `json:"attempts"`
+	PostID        string          `json:"post_id,omitempty"`
+	Permalink     string          `json:"permalink,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	Deferred      bool            `json:"deferred,omitempty"`
+}
+
+func newScheduleRunCmd(f *Factory) *cobra.Command {
+	opts := &scheduleRunOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Publish scheduled posts that are due",
+		Long: `Publish every pending post whose publish time has passed.
+
+Only posts scheduled for the active account are published. Network errors
+and rate limits are retried with exponential backoff on later runs; other
+errors mark the post as failed. Posts that would exceed the account's 24-hour
+publishing quota are deferred until quota frees up.
+
+Run from cron, or keep it running with --watch.`,
+		Example: `  # Publish due posts once (e.g. from cron every minute)
+  threads schedule run
+
+  # Keep running and check every 30 seconds
+  threads schedule run --watch --interval 30s`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScheduleRun(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Watch, "watch", false, "Keep running and publish posts as they become due")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", time.Minute, "Polling interval for --watch")
+	cmd.Flags().IntVar(&opts.TimeoutSecs, "timeout", 300, "Timeout in seconds for carousel media processing")
+	return cmd
+}
+
+func runScheduleRun(cmd *cobra.Command, f *Factory, opts *scheduleRunOptions) error {
+	ctx := cmd.Context()
+
+	if opts.Watch && opts.Interval <= 0 {
+		return &UserFriendlyError{
+			Message:    "--interval must be positive",
+			Suggestion: "Use a value like 30s or 1m",
+		}
+	}
+
+	creds, err := f.ActiveCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	queue := schedule.NewQueue(schedule.DefaultPath())
+	lock, err := queue.Lock()
+	if errors.Is(err, schedule.ErrLocked) {
+		return &UserFriendlyError{
+			Message:    "Another 'threads schedule run' is already in progress",
+			Suggestion: "Wait for it to finish, or remove the stale lock at " + queue.Path() + ".lock",
+		}
+	}
+	if err != nil {
+		return WrapError("failed to lock schedule", err)
+	}
+	defer lock.Release() //nolint:errcheck // Best-effort cleanup
+
+	// Scheduled publishing yields to interactive requests when throttled.
+	ctx = api.WithPriority(ctx, api.PriorityBackground)
+	client, err := f.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	io := iocontext.GetIO(ctx)
+	out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+	policy := schedule.DefaultRetryPolicy()
+
+	for {
+		results, errRun := publishDueItems(ctx, f, client, queue, creds.Name, policy, opts.TimeoutSecs)
+		if errRun != nil {
+			return errRun
+		}
+
+		switch {
+		case outfmt.IsJSONL(ctx):
+			if errOut := out.Output(results); errOut != nil {
+				return errOut
+			}
+		case outfmt.IsJSON(ctx):
+			if !opts.Watch {
+				return out.Output(itemsEnvelope(results, nil, ""))
+			}
+			for _, res := range results {
+				if errOut := out.Output(res); errOut != nil {
+					return errOut
+				}
+			}
+		default:
+			printScheduleRunResults(ctx, f, results, opts.Watch)
+		}
+
+		if !opts.Watch {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(opts.Interval):
+		}
+		if errTouch := lock.Touch(); errTouch != nil {
+			return WrapError("failed to refresh schedule lock", errTouch)
+		}
+	}
+}
+
+// publishDueItems publishes every due item for account, persisting each
+// outcome before moving on so a crash never loses a recorded post ID. Each
+// item is re-read just before it is published, so one cancelled while the
+// run was in progress is skipped.
+func publishDueItems(ctx context.Context, f *Factory, client *api.Client, queue *schedule.Queue, account string, policy schedule.RetryPolicy, timeoutSecs int) ([]scheduleRunResult, error) {
+	due, err := queue.Due(account, time.Now())
+	if err != nil {
+		return nil, WrapError("failed to read schedule", err)
+	}
+
+	results := []scheduleRunResult{}
+	for _, listed := range due {
+		if ctx.Err() != nil {
+			break
+		}
+
+		item, errClaim := queue.Claim(listed.ID, time.Now())
+		if errors.Is(errClaim, schedule.ErrNotPending) || errors.Is(errClaim, schedule.ErrNotFound) {
+			continue
+		}
+		if errClaim != nil {
+			return results, WrapError("failed to read schedule", errClaim)
+		}
+
+		post, errPublish := publishSchedulePayload(ctx, client, &item.Payload, timeoutSecs, newLocalMedia(f))
+		if errors.Is(errPublish, api.ErrPublishedIDUnknown) {
+			// The post went out but its ID was lost with the response;
+			// retrying would publish it twice
+			post, errPublish = &api.Post{}, nil
+		}
+		now := time.Now()
+		var quotaErr *api.QuotaError
+		deferred := errors.As(errPublish, &quotaErr)
+		item, errUpdate := queue.Modify(item.ID, func(stored *schedule.Item) {
+			switch {
+			case errPublish != nil && stored.Status != schedule.StatusPending:
+				// Cancelled while the publish was in flight; nothing went out
+			case deferred:
+				stored.Defer(errPublish, quotaErr.RetryAt, now)
+			case errPublish != nil:
+				stored.MarkFailed(errPublish, isTransientPublishError(errPublish), retryAfterFromError(errPublish), policy, now)
+			default:
+				stored.MarkPublished(post, now)
+			}
+		})
+		if errUpdate != nil {
+			return results, WrapError("failed to update schedule", errUpdate)
+		}
+
+		res := scheduleRunResult{
+			ID:        item.ID,
+			Status:    item.Status,
+			Attempts:  item.Attempts,
+			PostID:    item.PostID,
+			Permalink: item.Permalink,
+			Error:     item.LastError,
+			Deferred:  deferred,
+		}
+		if item.Status == schedule.StatusPending {
+			next := item.NextAttemptAt
+			res.NextAttemptAt = &next
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// publishSchedulePayload publishes a stored payload through the same client
+// methods used by 'posts create' and 'posts carousel'.
+func publishSchedulePayload(ctx context.Context, client *api.Client, payload *schedule.Payload, timeoutSecs int, uploads *localMedia) (*api.Post, error) {
+	switch payload.Kind {
+	case schedule.KindText:
+		return publishPostContent(ctx, client, payload.Text, uploads)
+	case schedule.KindImage:
+		return publishPostContent(ctx, client, payload.Image, uploads)
+	case schedule.KindVideo:
+		return publishPostContent(ctx, client, payload.Video, uploads)
+	case schedule.KindCarousel:
+		items := make([]carouselItem, 0, len(payload.CarouselItems))
+		for _, item := range payload.CarouselItems {
+			items = append(items, carouselItem(item))
+		}
+		return publishCarouselContent(ctx, client, payload.Carousel, items, timeoutSecs, uploads)
+	default:
+		return nil, fmt.Errorf("unknown payload kind: %q", payload.Kind)
+	}
+}
+
+// isTransientPublishError reports whether a failed publish is worth retrying.
+// One whose outcome is unknown is not: the post may already be live, so the
+// item fails and is left for the user to check.
+func isTransientPublishError(err error) bool {
+	if errors.Is(err, api.ErrPublishOutcomeUnknown) {
+		return false
+	}
+	return api.IsNetworkError(err) || api.IsRateLimitError(err)
+}
+
+// retryAfterFromError extracts the server-provided retry delay, if any.
+func retryAfterFromError(err error) time.Duration {
+	var rateLimitErr *api.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.RetryAfter
+	}
+	return 0
+}
+
+func printScheduleRunResults(ctx context.Context, f *Factory, results []scheduleRunResult, watch bool) {
+	p := f.UI(ctx)
+	if len(results) == 0 {
+		if !watch {
+			p.Info("No scheduled posts are due")
+		}
+		return
+	}
+
+	for _, res := range results {
+		switch {
+		case res.Status == schedule.StatusPublished && res.PostID == "":
+			p.Success("Published %s, but its post ID could not be retrieved", res.ID)
+		case res.Status == schedule.StatusPublished:
+			p.Success("Published %s as post %s %s", res.ID, res.PostID, res.Permalink)
+		case res.Status == schedule.StatusCancelled:
+			p.Info("Skipped %s: cancelled while publishing", res.ID)
+		case res.Deferred:
+			p.Warning("Deferred %s until %s: %s", res.ID, res.NextAttemptAt.Local().Format("2006-01-02 15:04"), res.Error)
+		case res.Status == schedule.StatusPending:
+			p.Warning("Publishing %s failed (attempt %d), retrying after %s: %s",
+				res.ID, res.Attempts, res.NextAttemptAt.Local().Format("15:04:05"), res.Error)
+		default:
+			p.Error("Publishing %s failed permanently: %s", res.ID, res.Error)
+		}
+	}
+}
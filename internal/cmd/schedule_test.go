@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/schedule"
+)
+
+// setTestDataDir points config.DataDir (and friends) at a temp directory.
+func setTestDataDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+}
+
+func TestScheduleCmd_Structure(t *testing.T) {
+	f := newTestFactory(t)
+	cmd := NewScheduleCmd(f)
+
+	if cmd.Use != "schedule" {
+		t.Errorf("expected Use=schedule, got %s", cmd.Use)
+	}
+
+	expected := map[string]bool{"add": false, "list": false, "cancel": false, "run": false}
+	for _, sub := range cmd.Commands() {
+		if _, ok := expected[sub.Name()]; !ok {
+			t.Errorf("unexpected subcommand: %s", sub.Name())
+		}
+		expected[sub.Name()] = true
+	}
+	for name, found := range expected {
+		if !found {
+			t.Errorf("missing subcommand: %s", name)
+		}
+	}
+}
+
+func TestParseScheduleTime(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	got, err := parseScheduleTime("", 2*time.Hour, now)
+	if err != nil || !got.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("--in 2h: got %v, %v", got, err)
+	}
+
+	got, err = parseScheduleTime("2025-01-16T10:30:00Z", 0, now)
+	if err != nil || !got.Equal(time.Date(2025, 1, 16, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("RFC3339: got %v, %v", got, err)
+	}
+
+	got, err = parseScheduleTime("2025-01-16 10:30", 0, now)
+	if err != nil || !got.Equal(time.Date(2025, 1, 16, 10, 30, 0, 0, time.Local)) {
+		t.Errorf("local layout: got %v, %v", got, err)
+	}
+
+	for _, tc := range []struct {
+		at string
+		in time.Duration
+	}{
+		{"", 0},
+		{"2025-01-16 10:30", time.Hour},
+		{"", -time.Hour},
+		{"tomorrow", 0},
+	} {
+		if _, err := parseScheduleTime(tc.at, tc.in, now); err == nil {
+			t.Errorf("expected error for at=%q in=%v", tc.at, tc.in)
+		}
+	}
+}
+
+func TestBuildSchedulePayload(t *testing.T) {
+	text := &scheduleAddOptions{postsCreateOptions: postsCreateOptions{Text: "hi", Poll: "A,B"}}
+	payload, err := buildSchedulePayload(text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Kind != schedule.KindText || payload.Text.PollAttachment == nil {
+		t.Errorf("expected text payload with poll, got %+v", payload)
+	}
+
+	carousel := &scheduleAddOptions{
+		postsCreateOptions: postsCreateOptions{Text: "album"},
+		Items:              []string{"https://example.com/a.jpg", "https://example.com/b.mp4"},
+		ItemAltText:        []string{"first"},
+	}
+	payload, err = buildSchedulePayload(carousel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Kind != schedule.KindCarousel || len(payload.CarouselItems) != 2 {
+		t.Fatalf("expected carousel payload with 2 items, got %+v", payload)
+	}
+	if payload.CarouselItems[0].AltText != "first" || payload.CarouselItems[1].MediaType != "VIDEO" {
+		t.Errorf("unexpected carousel items: %+v", payload.CarouselItems)
+	}
+
+	invalid := &scheduleAddOptions{
+		postsCreateOptions: postsCreateOptions{ImageURL: "https://example.com/x.jpg"},
+		Items:              []string{"https://example.com/a.jpg", "https://example.com/b.jpg"},
+	}
+	if _, err := buildSchedulePayload(invalid); err == nil {
+		t.Error("expected error combining --items and --image")
+	}
+}
+
+func TestScheduleAddAndRun(t *testing.T) {
+	setTestDataDir(t)
+
+	var failures atomic.Int32
+	failures.Store(1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/refresh_access_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "refreshed-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		case "/12345/threads":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1"})
+		case "/c1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1", "status": "FINISHED"})
+		case "/12345/threads_publish":
+			if failures.Add(-1) >= 0 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"error": map[string]any{"message": "rate limited", "code": 4},
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "p1"})
+		case "/p1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":        "p1",
+				"permalink": "https://www.threads.net/t/p1",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+				"username":  "testuser",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	f, io := newIntegrationTestFactory(t, server.URL)
+	// Let the scheduler, not the HTTP client, handle the retry.
+	f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+		cfg.RetryConfig.MaxRetries = 0
+	})
+	ctx := iocontext.WithIO(context.Background(), io)
+	ctx = outfmt.WithFormat(ctx, "json")
+
+	add := newScheduleAddCmd(f)
+	add.SetContext(ctx)
+	add.SetArgs([]string{"--text", "scheduled hello", "--at", time.Now().Add(-time.Minute).Format(time.RFC3339)})
+	if err := add.Execute(); err != nil {
+		t.Fatalf("schedule add failed: %v", err)
+	}
+
+	var added schedule.Item
+	if err := json.Unmarshal(io.Out.(*bytes.Buffer).Bytes(), &added); err != nil {
+		t.Fatalf("failed to parse add output: %v", err)
+	}
+	if added.ID == "" || added.Account != "test-user" {
+		t.Fatalf("unexpected scheduled item: %+v", added)
+	}
+
+	queue := schedule.NewQueue(schedule.DefaultPath())
+	runOnce := func() {
+		t.Helper()
+		run := newScheduleRunCmd(f)
+		run.SetContext(ctx)
+		run.SetArgs([]string{})
+		if err := run.Execute(); err != nil {
+			t.Fatalf("schedule run failed: %v", err)
+		}
+	}
+
+	// First run hits a rate limit and is rescheduled.
+	runOnce()
+	item, err := queue.Get(added.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if item.Status != schedule.StatusPending || item.Attempts != 1 || item.LastError == "" {
+		t.Fatalf("expected pending retry after rate limit, got %+v", item)
+	}
+
+	// Pretend the backoff has elapsed, then publish.
+	item.NextAttemptAt = time.Now().Add(-time.Second)
+	if err := queue.Update(*item); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	runOnce()
+	item, _ = queue.Get(added.ID)
+	if item.Status != schedule.StatusPublished || item.PostID != "p1" {
+		t.Fatalf("expected published item with post ID p1, got %+v", item)
+	}
+}
+
+func TestScheduleRun_PublishedWithLostResponse(t *testing.T) {
+	setTestDataDir(t)
+
+	var publishes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/refresh_access_token":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "refreshed-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		case "/12345/threads":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1"})
+		case "/c1":
+			status := "FINISHED"
+			if publishes.Load() > 0 {
+				status = "PUBLISHED"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "c1", "status": status})
+		case "/12345/threads_publish":
+			// The post is created, but the response is lost
+			publishes.Add(1)
+			w.WriteHeader(http.StatusGatewayTimeout)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	f, io := newIntegrationTestFactory(t, server.URL)
+	f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+		cfg.RetryConfig.MaxRetries = 1
+	})
+	ctx := iocontext.WithIO(context.Background(), io)
+	ctx = outfmt.WithFormat(ctx, "json")
+
+	queue := schedule.NewQueue(schedule.DefaultPath())
+	added, err := queue.Add(schedule.Item{
+		Account:   "test-user",
+		PublishAt: time.Now().Add(-time.Minute),
+		Payload:   schedule.Payload{Kind: schedule.KindText, Text: &api.TextPostContent{Text: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	run := newScheduleRunCmd(f)
+	run.SetContext(ctx)
+	run.SetArgs([]string{})
+	if err := run.Execute(); err != nil {
+		t.Fatalf("schedule run failed: %v", err)
+	}
+
+	item, err := queue.Get(added.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if item.Status != schedule.StatusPublished || item.LastError != "" {
+		t.Fatalf("expected the item to be recorded as published, got %+v", item)
+	}
+	if publishes.Load() != 1 {
+		t.Errorf("expected a single publish request, got %d", publishes.Load())
+	}
+}
+
+func TestScheduleRun_AutoPublishOutcomeUnknown(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func(w http.ResponseWriter)
+	}{
+		{
+			name: "gateway timeout",
+			fail: func(w http.ResponseWriter) { w.WriteHeader(http.StatusGatewayTimeout) },
+		},
+		{
+			name: "connection dropped",
+			fail: func(w http.ResponseWriter) {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				_ = conn.Close()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestDataDir(t)
+
+			var creates atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/refresh_access_token":
+					_ = json.NewEncoder(w).Encode(map[string]any{
+						"access_token": "refreshed-token",
+						"token_type":   "Bearer",
+						"expires_in":   3600,
+					})
+				case "/12345/threads":
+					// The post may be created, but the response is lost
+					creates.Add(1)
+					tt.fail(w)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			f, io := newIntegrationTestFactory(t, server.URL)
+			f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+				cfg.RetryConfig.MaxRetries = 1
+			})
+			ctx := iocontext.WithIO(context.Background(), io)
+			ctx = outfmt.WithFormat(ctx, "json")
+
+			queue := schedule.NewQueue(schedule.DefaultPath())
+			added, err := queue.Add(schedule.Item{
+				Account:   "test-user",
+				PublishAt: time.Now().Add(-time.Minute),
+				Payload: schedule.Payload{Kind: schedule.KindText, Text: &api.TextPostContent{
+					Text:            "hello",
+					AutoPublishText: true,
+				}},
+			})
+			if err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+
+			run := newScheduleRunCmd(f)
+			run.SetContext(ctx)
+			run.SetArgs([]string{})
+			if err := run.Execute(); err != nil {
+				t.Fatalf("schedule run failed: %v", err)
+			}
+
+			item, err := queue.Get(added.ID)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if item.Status != schedule.StatusFailed || !strings.Contains(item.LastError, "may have been published") {
+				t.Fatalf("expected the item to fail for review, got %+v", item)
+			}
+			if creates.Load() != 1 {
+				t.Errorf("expected a single create request, got %d", creates.Load())
+			}
+		})
+	}
+}
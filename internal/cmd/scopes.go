@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+// OAuth scopes of the Threads API.
+const (
+	scopeBasic            = "threads_basic"
+	scopeContentPublish   = "threads_content_publish"
+	scopeManageReplies    = "threads_manage_replies"
+	scopeManageInsights   = "threads_manage_insights"
+	scopeReadReplies      = "threads_read_replies"
+	scopeManageMentions   = "threads_manage_mentions"
+	scopeKeywordSearch    = "threads_keyword_search"
+	scopeDelete           = "threads_delete"
+	scopeLocationTagging  = "threads_location_tagging"
+	scopeProfileDiscovery = "threads_profile_discovery"
+)
+
+// scopesAnnotation is the cobra annotation listing the scopes a command
+// needs, comma-separated.
+const scopesAnnotation = "threads_scopes"
+
+type scopesContextKey struct{}
+
+// requireScopes records the OAuth scopes cmd needs and returns cmd.
+func requireScopes(cmd *cobra.Command, scopes ...string) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[scopesAnnotation] = strings.Join(scopes, ",")
+	return cmd
+}
+
+// commandScopes returns the OAuth scopes cmd needs.
+func commandScopes(cmd *cobra.Command) []string {
+	value := cmd.Annotations[scopesAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// withRequiredScopes returns a context carrying the scopes the running
+// command needs, which Factory.Client checks before any API call.
+func withRequiredScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesContextKey{}, scopes)
+}
+
+func requiredScopes(ctx context.Context) []string {
+	scopes, _ := ctx.Value(scopesContextKey{}).([]string)
+	return scopes
+}
+
+// checkScopes fails when creds lack a scope the running command needs.
+// Accounts stored before scopes were recorded are not checked.
+func checkScopes(ctx context.Context, creds *secrets.Credentials) error {
+	missing := creds.MissingScopes(requiredScopes(ctx))
+	if len(missing) == 0 {
+		return nil
+	}
+
+	permission := "permission"
+	if len(missing) > 1 {
+		permission = "permissions"
+	}
+	return &UserFriendlyError{
+		Message:    fmt.Sprintf("Account %q has not granted the %s %s this command needs", creds.Name, strings.Join(missing, ", "), permission),
+		Suggestion: fmt.Sprintf("Run 'threads auth upgrade --account %s --scopes %s' to grant it", creds.Name, strings.Join(missing, ",")),
+	}
+}
+
+// additionalScopes returns the scopes in requested that creds have not been
+// granted, all of them when the granted scopes are unknown.
+func additionalScopes(creds *secrets.Credentials, requested []string) []string {
+	if creds.Scopes == nil {
+		return slices.Clone(requested)
+	}
+	return creds.MissingScopes(requested)
+}
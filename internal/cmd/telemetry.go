@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/telemetry"
+)
+
+// telemetryFlushTimeout bounds exporting traces after a command, so an
+// unreachable collector cannot hang the CLI.
+const telemetryFlushTimeout = 10 * time.Second
+
+// startTelemetry starts recording traces and metrics when a trace file or
+// OTLP collector is configured, and opens the span that covers the whole
+// command. It returns ctx carrying that span. The standard OTEL_SDK_DISABLED,
+// OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES and TRACEPARENT variables are
+// honored.
+func (f *Factory) startTelemetry(ctx context.Context, cmd *cobra.Command, traceFile string) (context.Context, error) {
+	if disabled, _ := strconv.ParseBool(os.Getenv("OTEL_SDK_DISABLED")); disabled {
+		f.Telemetry = nil
+		return ctx, nil
+	}
+
+	var exporters []telemetry.Exporter
+	if traceFile != "" {
+		exporters = append(exporters, &telemetry.FileExporter{Path: traceFile})
+	}
+	otlp, err := f.otlpExporter(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	if otlp != nil {
+		exporters = append(exporters, otlp)
+	}
+	if len(exporters) == 0 {
+		f.Telemetry = nil
+		return ctx, nil
+	}
+
+	resource, err := parseOTELKeyValues("OTEL_RESOURCE_ATTRIBUTES", "Use comma-separated key=value pairs, e.g. deployment.environment=ci")
+	if err != nil {
+		return ctx, err
+	}
+	service := "threads-cli"
+	if name := resource["service.name"]; name != "" {
+		service = name
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		service = name
+	}
+	attrs := make([]api.Attribute, 0, len(resource))
+	for key, value := range resource {
+		attrs = append(attrs, api.Attr(key, value))
+	}
+
+	f.Telemetry = telemetry.New(service, Version, exporters...)
+	f.Telemetry.SetResourceAttributes(attrs...)
+
+	// A TRACEPARENT from the environment, such as a CI job's, makes the
+	// command part of the caller's trace
+	parent, err := telemetry.WithRemoteParent(ctx, os.Getenv("TRACEPARENT"), os.Getenv("TRACESTATE"))
+	if err != nil {
+		f.UI(ctx).Warning("Ignoring invalid TRACEPARENT: %v", err)
+	}
+	ctx, f.commandSpan = f.Telemetry.StartSpan(parent, cmd.CommandPath())
+	return ctx, nil
+}
+
+// otlpExporter builds the collector exporter from the config and the
+// standard OTEL_EXPORTER_OTLP_* and OTEL_*_EXPORTER variables. It returns
+// nil when no collector endpoint is configured.
+func (f *Factory) otlpExporter(ctx context.Context) (*telemetry.OTLPExporter, error) {
+	exporter := &telemetry.OTLPExporter{
+		TracesEndpoint:  os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
+		MetricsEndpoint: os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"),
+		SkipTraces:      os.Getenv("OTEL_TRACES_EXPORTER") == "none",
+		SkipMetrics:     os.Getenv("OTEL_METRICS_EXPORTER") == "none",
+	}
+	if f.Config != nil && f.Config.Telemetry != nil {
+		exporter.Endpoint = f.Config.Telemetry.OTLPEndpoint
+		exporter.Headers = f.Config.Telemetry.OTLPHeaders
+	}
+	if exporter.Endpoint == "" && exporter.TracesEndpoint == "" && exporter.MetricsEndpoint == "" {
+		return nil, nil
+	}
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_HEADERS") != "" {
+		headers, err := parseOTELKeyValues("OTEL_EXPORTER_OTLP_HEADERS", "Use comma-separated key=value pairs, e.g. authorization=Bearer%20token")
+		if err != nil {
+			return nil, err
+		}
+		exporter.Headers = headers
+	}
+	if val := os.Getenv("OTEL_EXPORTER_OTLP_TIMEOUT"); val != "" {
+		ms, err := strconv.Atoi(val)
+		if err != nil || ms <= 0 {
+			return nil, &UserFriendlyError{
+				Message:    fmt.Sprintf("Invalid OTEL_EXPORTER_OTLP_TIMEOUT: %q", val),
+				Suggestion: "Give the export timeout in milliseconds, e.g. 10000",
+			}
+		}
+		exporter.Client = &http.Client{Timeout: time.Duration(ms) * time.Millisecond}
+	}
+	if protocol := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); protocol != "" && protocol != "http/json" {
+		f.UI(ctx).Warning("OTEL_EXPORTER_OTLP_PROTOCOL=%s is not supported; exporting with http/json", protocol)
+	}
+	return exporter, nil
+}
+
+// parseOTELKeyValues parses the key=value list in the environment variable
+// name, returning an empty map when it is unset.
+func parseOTELKeyValues(name, suggestion string) (map[string]string, error) {
+	values, err := telemetry.ParseKeyValues(os.Getenv(name))
+	if err != nil {
+		return nil, &UserFriendlyError{
+			Message:    fmt.Sprintf("Invalid %s: %v", name, err),
+			Suggestion: suggestion,
+		}
+	}
+	return values, nil
+}
+
+// finishTelemetry ends the command span with the command's error and
+// exports everything recorded. Export failures are reported as warnings.
+func (f *Factory) finishTelemetry(ctx context.Context, cmdErr error) {
+	if f.Telemetry == nil {
+		return
+	}
+	if f.commandSpan != nil {
+		if cmdErr != nil {
+			f.commandSpan.RecordError(cmdErr)
+		}
+		f.commandSpan.End()
+		f.commandSpan = nil
+	}
+
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), telemetryFlushTimeout)
+	defer cancel()
+	if err := f.Telemetry.Flush(flushCtx); err != nil {
+		f.UI(ctx).Warning("Failed to export telemetry: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/apitest"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+)
+
+func TestTraceFile(t *testing.T) {
+	_, f, streams := newFakeServerFactory(t)
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+
+	cmd := NewRootCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), streams))
+	cmd.SetArgs([]string{"me", "--trace-file", path})
+	if err := ExecuteCommand(cmd, f); err != nil {
+		t.Fatalf("me failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected a trace file: %v", err)
+	}
+	for _, want := range []string{`"threads me"`, `"threads.api.request"`, `"HTTP GET"`, `"threads.api.requests"`, `"threads.request_id"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in the trace file:\n%s", want, data)
+		}
+	}
+
+	// Without a destination nothing is recorded
+	cmd = NewRootCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), streams))
+	cmd.SetArgs([]string{"me"})
+	if err := ExecuteCommand(cmd, f); err != nil {
+		t.Fatalf("me failed: %v", err)
+	}
+	if f.Telemetry != nil {
+		t.Error("expected telemetry to be off without --trace-file")
+	}
+}
+
+func TestTelemetry_OTELEnvironment(t *testing.T) {
+	setTestDataDir(t)
+	fake := apitest.New()
+	fake.AddToken(testCredentials().AccessToken)
+	var traceParents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents = append(traceParents, r.Header.Get("traceparent"))
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var exported []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		exported = append(exported, r.URL.Path+" "+r.Header.Get("X-Team")+" "+string(body))
+	}))
+	defer collector.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", collector.URL+"/traces")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-team=threads")
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "5000")
+	t.Setenv("OTEL_SERVICE_NAME", "release-bot")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=ci")
+	t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	f, streams := newIntegrationTestFactory(t, server.URL)
+	f.NewClient = createMockClientFactoryWithConfig(server.URL, func(cfg *api.Config) {
+		cfg.RetryConfig.MaxRetries = 0
+	})
+	cmd := NewRootCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), streams))
+	cmd.SetArgs([]string{"me"})
+	if err := ExecuteCommand(cmd, f); err != nil {
+		t.Fatalf("me failed: %v", err)
+	}
+
+	if len(traceParents) == 0 {
+		t.Fatal("expected API requests")
+	}
+	for _, traceParent := range traceParents {
+		if !strings.HasPrefix(traceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+			t.Errorf("expected requests to continue the TRACEPARENT trace, got %q", traceParent)
+		}
+	}
+	if len(exported) != 1 {
+		t.Fatalf("expected only traces to be exported, got %d exports", len(exported))
+	}
+	for _, want := range []string{"/traces threads ", `"release-bot"`, `"deployment.environment"`, `"parentSpanId":"00f067aa0ba902b7"`} {
+		if !strings.Contains(exported[0], want) {
+			t.Errorf("expected %s in the export:\n%s", want, exported[0])
+		}
+	}
+
+	// OTEL_SDK_DISABLED turns everything off
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	cmd = NewRootCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), streams))
+	cmd.SetArgs([]string{"me"})
+	if err := ExecuteCommand(cmd, f); err != nil {
+		t.Fatalf("me failed: %v", err)
+	}
+	if f.Telemetry != nil || len(exported) != 1 {
+		t.Error("expected no telemetry with OTEL_SDK_DISABLED=true")
+	}
+}
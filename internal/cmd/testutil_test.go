@@ -51,7 +51,10 @@ type mockCredentialsStore struct {
 	creds *secrets.Credentials
 }
 
-func (m *mockCredentialsStore) Set(string, secrets.Credentials) error { return nil }
+func (m *mockCredentialsStore) Set(_ string, creds secrets.Credentials) error {
+	m.creds = &creds
+	return nil
+}
 func (m *mockCredentialsStore) Get(string) (*secrets.Credentials, error) {
 	return m.creds, nil
 }
@@ -66,7 +69,7 @@ func testCredentials() *secrets.Credentials {
 		AccessToken:  "test-access-token",
 		UserID:       "12345",
 		Username:     "testuser",
-		ExpiresAt:    time.Now().Add(24 * time.Hour),
+		ExpiresAt:    time.Now().Add(30 * 24 * time.Hour), // Outside the refresh-ahead window
 		CreatedAt:    time.Now(),
 		ClientID:     "test-client-id",
 		ClientSecret: "test-client-secret",
@@ -76,12 +79,19 @@ func testCredentials() *secrets.Credentials {
 
 // createMockClientFactory creates a NewClient function that uses a test server
 func createMockClientFactory(serverURL string) func(accessToken string, cfg *api.Config) (*api.Client, error) {
+	return createMockClientFactoryWithConfig(serverURL, nil)
+}
+
+// createMockClientFactoryWithConfig is like createMockClientFactory but lets
+// tests adjust the client config (e.g. disable retries) before creation.
+func createMockClientFactoryWithConfig(serverURL string, configure func(*api.Config)) func(accessToken string, cfg *api.Config) (*api.Client, error) {
 	return func(accessToken string, cfg *api.Config) (*api.Client, error) {
 		// Create config with test server URL - use the captured serverURL
 		config := api.NewConfig()
 		if cfg != nil {
 			config.ClientID = cfg.ClientID
 			config.ClientSecret = cfg.ClientSecret
+			config.Telemetry = cfg.Telemetry
 		}
 		if config.ClientID == "" {
 			config.ClientID = "test-client-id"
@@ -91,6 +101,9 @@ func createMockClientFactory(serverURL string) func(accessToken string, cfg *api
 		}
 		config.RedirectURI = "https://example.com/callback"
 		config.BaseURL = serverURL // Always use the test server URL
+		if configure != nil {
+			configure(config)
+		}
 
 		// Create client without token validation
 		client, err := api.NewClient(config)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"strings"
 
 	"github.com/salmonumbrella/threads-cli/internal/iocontext"
@@ -71,3 +72,66 @@ func readTextFileOrStdin(ctx context.Context, spec string) (string, error) {
 
 	return strings.TrimRight(string(b), "\n"), nil
 }
+
+// editTextInEditor opens initial text in $VISUAL or $EDITOR and returns the
+// saved result. Lines starting with '#' are treated as comments and removed.
+func editTextInEditor(ctx context.Context, initial string) (string, error) {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		return "", &UserFriendlyError{
+			Message:    "No editor configured",
+			Suggestion: "Set $EDITOR (e.g. export EDITOR=vim) or pass --text instead",
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "threads-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	path := tmp.Name()
+	defer os.Remove(path) //nolint:errcheck // Best-effort cleanup
+
+	content := initial + "\n\n# Write your post above. Lines starting with '#' are ignored.\n"
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // Already returning the write error
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	ioctx := iocontext.GetIO(ctx)
+	parts := strings.Fields(editor)
+	//nolint:gosec // Running the user's configured editor is intentional.
+	c := exec.CommandContext(ctx, parts[0], append(parts[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if ioctx != nil && ioctx.ErrOut != nil {
+		c.Stderr = ioctx.ErrOut
+	}
+	if err := c.Run(); err != nil {
+		return "", &UserFriendlyError{
+			Message:    fmt.Sprintf("Editor exited with an error: %v", err),
+			Suggestion: "Check your $EDITOR setting, or pass --text instead",
+			Cause:      err,
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited text: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+// tokenRefresher returns a refresher for the tokens in store that exchanges
+// them through the Threads API.
+func (f *Factory) tokenRefresher(store secrets.Store) *secrets.Refresher {
+	return secrets.NewRefresher(store, f.exchangeToken)
+}
+
+// exchangeToken trades the access token in creds for a refreshed one.
+func (f *Factory) exchangeToken(ctx context.Context, creds secrets.Credentials) (string, time.Time, error) {
+	cfg := &api.Config{
+		ClientID:     creds.ClientID,
+		ClientSecret: creds.ClientSecret,
+		RedirectURI:  creds.RedirectURI,
+		BaseURL:      os.Getenv("THREADS_BASE_URL"),
+		Debug:        f.Debug,
+	}
+	if f.Debug {
+		cfg.Logger = f.logger()
+	}
+
+	client, err := f.NewClient(creds.AccessToken, cfg)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := client.RefreshToken(ctx); err != nil {
+		return "", time.Time{}, err
+	}
+
+	tokenInfo := client.GetTokenInfo()
+	return tokenInfo.AccessToken, tokenInfo.ExpiresAt, nil
+}
+
+// refreshThreshold returns how long before expiry tokens are refreshed
+// automatically, or 0 when automatic refresh is turned off.
+func (f *Factory) refreshThreshold() time.Duration {
+	if f.Config == nil || f.Config.TokenRefresh == nil {
+		return secrets.DefaultRefreshThreshold
+	}
+	settings := f.Config.TokenRefresh
+	if settings.Disabled {
+		return 0
+	}
+	if settings.ThresholdDays > 0 {
+		return time.Duration(settings.ThresholdDays) * 24 * time.Hour
+	}
+	return secrets.DefaultRefreshThreshold
+}
+
+// refreshAhead refreshes the token in creds when it is about to expire and
+// returns the credentials to use. The current token keeps working until it
+// expires, so a failed refresh is only reported as a warning.
+func (f *Factory) refreshAhead(ctx context.Context, creds *secrets.Credentials) *secrets.Credentials {
+	threshold := f.refreshThreshold()
+	if threshold <= 0 || creds.ClientSecret == "" || !creds.IsExpiringSoon(threshold) {
+		return creds
+	}
+
+	store, err := f.Store()
+	if err != nil {
+		return creds
+	}
+
+	refreshed, ok, err := f.tokenRefresher(store).RefreshIfExpiring(ctx, creds.Name, threshold)
+	if err != nil {
+		io := iocontext.GetIO(ctx)
+		if io == nil {
+			io = f.IO
+		}
+		fmt.Fprintf(io.ErrOut, "warning: could not refresh the access token expiring %s: %v\n", creds.ExpiresAt.Format("2006-01-02"), err) //nolint:errcheck // Best-effort output
+		return creds
+	}
+	if ok && f.Debug {
+		f.logger().Info("Refreshed access token ahead of expiry", "account", refreshed.Name, "expires_at", refreshed.ExpiresAt)
+	}
+	return refreshed
+}
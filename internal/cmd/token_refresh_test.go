@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+)
+
+func TestClient_RefreshesExpiringToken(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	server.AddUser("alice")
+
+	store := &mockCredentialsStore{creds: testCredentials()}
+	store.creds.ExpiresAt = time.Now().Add(2 * 24 * time.Hour)
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	if _, err := runRoot(t, f, streams, "me"); err != nil {
+		t.Fatalf("me failed: %v", err)
+	}
+	if !strings.HasPrefix(store.creds.AccessToken, "fake-refreshed-") || store.creds.DaysUntilExpiry() < 50 {
+		t.Fatalf("expected a refreshed token to be stored, got %q expiring %s", store.creds.AccessToken, store.creds.ExpiresAt)
+	}
+
+	data, err := os.ReadFile(filepath.Join(config.DataDir(), "token-audit.jsonl"))
+	if err != nil {
+		t.Fatalf("expected an audit log: %v", err)
+	}
+	var entry secrets.AuditEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("invalid audit entry %q: %v", data, err)
+	}
+	if entry.Account != "test-user" || entry.Trigger != secrets.RefreshAuto || entry.Error != "" || !entry.NewExpiresAt.Equal(store.creds.ExpiresAt) {
+		t.Errorf("unexpected audit entry %+v", entry)
+	}
+
+	// The refreshed token is far from expiry, so it is used as is
+	token := store.creds.AccessToken
+	if _, err := runRoot(t, f, streams, "me"); err != nil {
+		t.Fatalf("me failed: %v", err)
+	}
+	if store.creds.AccessToken != token {
+		t.Error("expected no second refresh")
+	}
+}
+
+func TestClient_TokenRefreshDisabled(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	server.AddUser("alice")
+	f.Config.TokenRefresh = &config.TokenRefreshConfig{Disabled: true}
+
+	store := &mockCredentialsStore{creds: testCredentials()}
+	store.creds.ExpiresAt = time.Now().Add(2 * 24 * time.Hour)
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	if _, err := runRoot(t, f, streams, "me"); err != nil {
+		t.Fatalf("me failed: %v", err)
+	}
+	if store.creds.AccessToken != testCredentials().AccessToken {
+		t.Errorf("expected the token to be left alone, got %q", store.creds.AccessToken)
+	}
+}
+
+func TestClient_TokenRefreshFailureWarns(t *testing.T) {
+	server, f, streams := newFakeServerFactory(t)
+	server.AddUser("alice")
+
+	// The server does not know the token, so refreshing it fails
+	store := &mockCredentialsStore{creds: testCredentials()}
+	store.creds.ExpiresAt = time.Now().Add(2 * 24 * time.Hour)
+	store.creds.AccessToken = "unknown-token"
+	f.Store = func() (secrets.Store, error) { return store, nil }
+
+	_, _ = runRoot(t, f, streams, "me")
+	if stderr := streams.ErrOut.(*bytes.Buffer).String(); !strings.Contains(stderr, "could not refresh the access token") {
+		t.Errorf("expected a refresh warning, got %q", stderr)
+	}
+	if store.creds.AccessToken != "unknown-token" {
+		t.Errorf("expected the stored token to be kept, got %q", store.creds.AccessToken)
+	}
+}
+
+func TestExchangeToken_UsesStoredRedirectURI(t *testing.T) {
+	_, f, _ := newFakeServerFactory(t)
+	newClient := f.NewClient
+	var redirectURI string
+	f.NewClient = func(accessToken string, cfg *api.Config) (*api.Client, error) {
+		redirectURI = cfg.RedirectURI
+		return newClient(accessToken, cfg)
+	}
+
+	creds := testCredentials()
+	creds.RedirectURI = "https://localhost:8443/callback"
+	if _, _, err := f.exchangeToken(context.Background(), *creds); err != nil {
+		t.Fatalf("exchangeToken failed: %v", err)
+	}
+	if redirectURI != creds.RedirectURI {
+		t.Errorf("expected the account's redirect URI, got %q", redirectURI)
+	}
+}
@@ -21,10 +21,10 @@ func NewUsersCmd(f *Factory) *cobra.Command {
 		Long:    `Retrieve and view user profile information.`,
 	}
 
-	cmd.AddCommand(newUsersMeCmd(f))
-	cmd.AddCommand(newUsersGetCmd(f))
-	cmd.AddCommand(newUsersLookupCmd(f))
-	cmd.AddCommand(newUsersMentionsCmd(f))
+	cmd.AddCommand(requireScopes(newUsersMeCmd(f), scopeBasic))
+	cmd.AddCommand(requireScopes(newUsersGetCmd(f), scopeBasic))
+	cmd.AddCommand(requireScopes(newUsersLookupCmd(f), scopeBasic, scopeProfileDiscovery))
+	cmd.AddCommand(requireScopes(newUsersMentionsCmd(f), scopeBasic, scopeManageMentions))
 
 	return cmd
 }
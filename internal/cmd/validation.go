@@ -16,3 +16,15 @@ func ValidateHTTPSURL(url, fieldName string) error {
 	}
 	return nil
 }
+
+// ValidateHTTPURL validates that a URL uses the HTTP or HTTPS protocol.
+// Returns a UserFriendlyError if validation fails.
+func ValidateHTTPURL(url, fieldName string) error {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("%s must be an http(s) URL", fieldName),
+			Suggestion: "Use a URL starting with http:// or https://",
+		}
+	}
+	return nil
+}
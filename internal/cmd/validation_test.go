@@ -61,3 +61,16 @@ func TestValidateHTTPSURL(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateHTTPURL(t *testing.T) {
+	for _, url := range []string{"http://localhost:9000/hook", "https://example.com/hook"} {
+		if err := ValidateHTTPURL(url, "--to"); err != nil {
+			t.Errorf("ValidateHTTPURL(%q) = %v, want nil", url, err)
+		}
+	}
+	for _, url := range []string{"", "localhost:9000", "ftp://example.com"} {
+		if err := ValidateHTTPURL(url, "--to"); err == nil {
+			t.Errorf("ValidateHTTPURL(%q) = nil, want error", url)
+		}
+	}
+}
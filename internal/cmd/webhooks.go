@@ -36,6 +36,10 @@ Your callback URL must be:
 	cmd.AddCommand(newWebhooksSubscribeCmd(f))
 	cmd.AddCommand(newWebhooksListCmd(f))
 	cmd.AddCommand(newWebhooksDeleteCmd(f))
+	cmd.AddCommand(newWebhooksServeCmd(f))
+	cmd.AddCommand(newWebhooksSignCmd(f))
+	cmd.AddCommand(newWebhooksReplayCmd(f))
+	cmd.AddCommand(newWebhooksFixtureCmd(f))
 
 	return cmd
 }
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/secrets"
+	"github.com/salmonumbrella/threads-cli/internal/webhooks"
+)
+
+type webhooksServeOptions struct {
+	Addr        string
+	VerifyToken string
+	NoActions   bool
+}
+
+// webhookQueueSize bounds the events waiting for their actions to run. When
+// it is full, deliveries fail so Meta retries them later.
+const webhookQueueSize = 256
+
+func newWebhooksServeCmd(f *Factory) *cobra.Command {
+	opts := &webhooksServeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Receive webhook events and stream them as JSONL",
+		Long: `Run an HTTP server that receives Threads webhook deliveries.
+
+GET requests answer Meta's hub.challenge verification using --verify-token,
+which must match the token passed to 'threads webhooks subscribe'. POST
+deliveries must carry a valid X-Hub-Signature-256 header signed with the
+app secret stored for the account (or THREADS_CLIENT_SECRET); unsigned or
+mis-signed deliveries are rejected.
+
+Each event is written to stdout as one JSON line, so the output can be piped
+into other tools. Status messages go to stderr.
+
+Events are also passed to the actions under "webhooks" in the config file.
+Each action runs a local command with the event JSON on stdin, POSTs it to a
+URL (with retries), or appends it to a file, optionally limited to some event
+types and to events matching a jq filter:
+
+  "webhooks": {
+    "actions": [
+      {"type": "command", "events": ["mentions"], "command": ["./notify.sh"]},
+      {"type": "http", "url": "https://hooks.internal/threads",
+       "filter": ".publish.media_type == \"VIDEO\"", "retries": 5},
+      {"type": "file", "path": "$HOME/threads-events.jsonl"}
+    ]
+  }
+
+Meta only delivers to public HTTPS URLs, so run this behind a TLS-terminating
+proxy or tunnel that forwards to --addr.`,
+		Example: `  # Receive events on port 8080
+  threads webhooks serve --addr :8080 --verify-token my-secret
+
+  # Only print mention text
+  threads webhooks serve --verify-token my-secret --query 'select(.type == "mentions") | .mention.text'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWebhooksServe(cmd, f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Addr, "addr", ":8080", "Address to listen on")
+	cmd.Flags().StringVar(&opts.VerifyToken, "verify-token", "", "Verify token used when subscribing (or THREADS_WEBHOOK_VERIFY_TOKEN)")
+	cmd.Flags().BoolVar(&opts.NoActions, "no-actions", false, "Only print events; skip configured webhook actions")
+
+	return cmd
+}
+
+func runWebhooksServe(cmd *cobra.Command, f *Factory, opts *webhooksServeOptions) error {
+	// Events are always emitted as JSONL, which also routes status messages
+	// to stderr; --query still applies per event.
+	ctx := outfmt.NewContext(cmd.Context(), outfmt.JSONL)
+	io := iocontext.GetIO(ctx)
+
+	verifyToken := opts.VerifyToken
+	if verifyToken == "" {
+		verifyToken = os.Getenv("THREADS_WEBHOOK_VERIFY_TOKEN")
+	}
+	if verifyToken == "" {
+		return &UserFriendlyError{
+			Message:    "A verify token is required",
+			Suggestion: "Pass the token used with 'threads webhooks subscribe' via --verify-token or THREADS_WEBHOOK_VERIFY_TOKEN",
+		}
+	}
+
+	appSecret, err := webhookAppSecret(f)
+	if err != nil {
+		return err
+	}
+
+	var actions []config.WebhookAction
+	if f.Config != nil && f.Config.Webhooks != nil && !opts.NoActions {
+		actions = f.Config.Webhooks.Actions
+	}
+	dispatcher, err := webhooks.NewDispatcher(actions, nil, io.ErrOut)
+	if err != nil {
+		return &UserFriendlyError{
+			Message:    "Invalid webhook action configuration",
+			Suggestion: fmt.Sprintf("Check the \"webhooks\" section in %s", config.ConfigPath()),
+			Cause:      err,
+		}
+	}
+
+	listener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return WrapError(fmt.Sprintf("failed to listen on %s", opts.Addr), err)
+	}
+
+	queue := make(chan webhooks.Event, webhookQueueSize)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runWebhookActions(ctx, f, dispatcher, queue)
+	}()
+
+	out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+	handler := webhooks.NewHandler(verifyToken, appSecret, webhookEventSink(out, dispatcher, queue))
+
+	if dispatcher.Len() > 0 {
+		f.UI(ctx).Info("Running %d webhook action(s) for received events", dispatcher.Len())
+	}
+
+	fmt.Fprintf(io.ErrOut, "Listening for webhooks on %s\n", listener.Addr()) //nolint:errcheck // Best-effort output
+
+	err = serveHTTP(ctx, listener, handler)
+	close(queue)
+	<-done
+	return err
+}
+
+// webhookEventSink prints each received event and queues it for the
+// dispatcher's actions. Events are queued before they are printed: a full
+// queue rejects the delivery so Meta redelivers it, and the event must not
+// have been printed yet or JSONL consumers would see it twice.
+func webhookEventSink(out *outfmt.Formatter, dispatcher *webhooks.Dispatcher, queue chan<- webhooks.Event) func(webhooks.Event) error {
+	return func(event webhooks.Event) error {
+		if dispatcher.Len() > 0 {
+			select {
+			case queue <- event:
+			default:
+				return fmt.Errorf("webhook action queue is full")
+			}
+		}
+		return out.Output(event)
+	}
+}
+
+// runWebhookActions dispatches queued events in arrival order. Failures are
+// reported and do not stop the server. Events were already acknowledged to
+// Meta, so the queue is drained on shutdown rather than dropped; each action
+// is still bounded by its timeout.
+func runWebhookActions(ctx context.Context, f *Factory, dispatcher *webhooks.Dispatcher, queue <-chan webhooks.Event) {
+	dispatchCtx := context.WithoutCancel(ctx)
+	for event := range queue {
+		if err := dispatcher.Dispatch(dispatchCtx, event); err != nil {
+			f.UI(ctx).Warning("Webhook action failed for %s event: %v", event.Type, err)
+		}
+	}
+}
+
+// serveHTTP serves handler on listener until ctx is cancelled.
+func serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return WrapError("server failed", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		//nolint:errcheck,gosec // Shutdown errors are not actionable here
+		server.Shutdown(shutdownCtx)
+		return nil
+	}
+}
+
+// webhookAppSecret returns the Meta app secret deliveries are signed with:
+// the client secret stored for the active account, or THREADS_CLIENT_SECRET.
+// Unlike ActiveCredentials it does not require an unexpired access token.
+func webhookAppSecret(f *Factory) (string, error) {
+	if creds := storedCredentials(f); creds != nil && creds.ClientSecret != "" {
+		return creds.ClientSecret, nil
+	}
+
+	if secret := os.Getenv("THREADS_CLIENT_SECRET"); secret != "" {
+		return secret, nil
+	}
+
+	return "", &UserFriendlyError{
+		Message:    "No app secret available to verify webhook signatures",
+		Suggestion: "Run 'threads auth login' to store the app credentials, or set THREADS_CLIENT_SECRET",
+	}
+}
+
+// storedCredentials returns the active account's stored credentials, or nil
+// if there are none. Expired tokens are not an error here.
+func storedCredentials(f *Factory) *secrets.Credentials {
+	account, err := f.resolveAccount()
+	if err != nil {
+		return nil
+	}
+	store, err := f.Store()
+	if err != nil {
+		return nil
+	}
+	creds, err := store.Get(account)
+	if err != nil {
+		return nil
+	}
+	return creds
+}
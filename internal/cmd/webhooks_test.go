@@ -4,13 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
 	"github.com/salmonumbrella/threads-cli/internal/iocontext"
 	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/webhooks"
 )
 
 func TestWebhooksCmd_Structure(t *testing.T) {
@@ -34,6 +42,10 @@ func TestWebhooksCmd_Subcommands(t *testing.T) {
 		"subscribe": true,
 		"list":      true,
 		"delete":    true,
+		"serve":     true,
+		"sign":      true,
+		"replay":    true,
+		"fixture":   true,
 	}
 
 	for _, sub := range cmd.Commands() {
@@ -460,3 +472,192 @@ func TestWebhooksList_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestWebhooksServe_StreamsSignedEvents(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	f, streams := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+	eventLog := filepath.Join(t.TempDir(), "events.jsonl")
+	f.Config.Webhooks = &config.WebhooksConfig{Actions: []config.WebhookAction{
+		{Type: "file", Path: eventLog, Events: []string{"publishes"}},
+		{Type: "file", Path: eventLog, Events: []string{"deletes"}},
+	}}
+	ctx, cancel := context.WithCancel(iocontext.WithIO(context.Background(), streams))
+	defer cancel()
+
+	cmd := newWebhooksServeCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--addr", addr, "--verify-token", "tok"})
+	done := make(chan error, 1)
+	go func() { done <- cmd.Execute() }()
+
+	base := "http://" + addr
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		resp, err = http.Get(base + "/?hub.mode=subscribe&hub.verify_token=tok&hub.challenge=abc")
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("server did not start: %v", err)
+	}
+	challenge, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(challenge) != "abc" {
+		t.Errorf("expected challenge echo, got %q", challenge)
+	}
+
+	body := `{"object":"user","entry":[{"id":"12345","time":1700000000,"changes":[{"field":"publishes","value":{"id":"p1","text":"hello"}}]}]}`
+	req, _ := http.NewRequest(http.MethodPost, base+"/", strings.NewReader(body))
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign("test-client-secret", []byte(body)))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("delivery status = %d", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("serve returned error: %v", err)
+	}
+
+	var event webhooks.Event
+	if err := json.Unmarshal(streams.Out.(*bytes.Buffer).Bytes(), &event); err != nil {
+		t.Fatalf("expected one JSON line, got %q: %v", streams.Out.(*bytes.Buffer).String(), err)
+	}
+	if event.Type != "publishes" || event.Publish == nil || event.Publish.Text != "hello" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	logged, err := os.ReadFile(eventLog)
+	if err != nil {
+		t.Fatalf("expected the file action to run: %v", err)
+	}
+	if strings.Count(string(logged), "\n") != 1 || !strings.Contains(string(logged), `"text":"hello"`) {
+		t.Errorf("unexpected action output: %s", logged)
+	}
+}
+
+func TestWebhookEventSink_FullQueuePrintsNothing(t *testing.T) {
+	dir := t.TempDir()
+	dispatcher, err := webhooks.NewDispatcher([]config.WebhookAction{
+		{Type: "file", Path: filepath.Join(dir, "events.jsonl")},
+	}, nil, io.Discard)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	ctx := outfmt.WithFormat(context.Background(), "jsonl")
+	out := outfmt.FromContext(ctx, outfmt.WithWriter(&buf))
+	event := webhooks.Event{Type: api.WebhookEventMentions, TargetID: "m1"}
+
+	// Nobody is draining an unbuffered queue, so it is always full.
+	if err := webhookEventSink(out, dispatcher, make(chan webhooks.Event))(event); err == nil {
+		t.Fatal("expected a full queue to reject the delivery")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected a rejected event not to be printed, got %q", buf.String())
+	}
+
+	queue := make(chan webhooks.Event, 1)
+	if err := webhookEventSink(out, dispatcher, queue)(event); err != nil {
+		t.Fatalf("sink failed: %v", err)
+	}
+	if len(queue) != 1 || !strings.Contains(buf.String(), `"m1"`) {
+		t.Errorf("expected the event to be queued and printed, got %d queued, output %q", len(queue), buf.String())
+	}
+}
+
+func TestWebhooksServe_RequiresVerifyToken(t *testing.T) {
+	t.Setenv("THREADS_WEBHOOK_VERIFY_TOKEN", "")
+	f, io := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+	cmd := newWebhooksServeCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), io))
+	cmd.SetArgs([]string{"--addr", "127.0.0.1:0"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "verify token") {
+		t.Fatalf("expected verify token error, got %v", err)
+	}
+}
+
+func TestWebhooksSign(t *testing.T) {
+	payload := `{"object":"user","entry":[]}`
+	f, streams := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+	streams.In = strings.NewReader(payload)
+
+	cmd := newWebhooksSignCmd(f)
+	cmd.SetContext(iocontext.WithIO(context.Background(), streams))
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+
+	got := strings.TrimSpace(streams.Out.(*bytes.Buffer).String())
+	if want := webhooks.Sign("test-client-secret", []byte(payload)); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+}
+
+func TestWebhooksFixtureAndReplay(t *testing.T) {
+	var received []webhooks.Event
+	server := httptest.NewServer(webhooks.NewHandler("tok", "replay-secret", func(e webhooks.Event) error {
+		received = append(received, e)
+		return nil
+	}))
+	defer server.Close()
+
+	f, streams := newIntegrationTestFactory(t, "http://127.0.0.1:0")
+	ctx := outfmt.WithFormat(iocontext.WithIO(context.Background(), streams), "jsonl")
+
+	var captured bytes.Buffer
+	for _, eventType := range []string{"mentions", "deletes"} {
+		streams.Out.(*bytes.Buffer).Reset()
+		cmd := newWebhooksFixtureCmd(f)
+		cmd.SetContext(ctx)
+		cmd.SetArgs([]string{eventType})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("fixture %s failed: %v", eventType, err)
+		}
+		captured.Write(streams.Out.(*bytes.Buffer).Bytes())
+	}
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, captured.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	streams.Out.(*bytes.Buffer).Reset()
+	cmd := newWebhooksReplayCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{path, "--to", server.URL, "--speed", "0", "--app-secret", "replay-secret"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+
+	if len(received) != 2 || received[0].Mention == nil || received[1].Delete == nil {
+		t.Fatalf("unexpected events received: %+v", received)
+	}
+	if received[0].TargetID != "12345" {
+		t.Errorf("expected fixtures for the active account, got %q", received[0].TargetID)
+	}
+	if lines := strings.Count(streams.Out.(*bytes.Buffer).String(), "\n"); lines != 2 {
+		t.Errorf("expected 2 JSONL results, got %d", lines)
+	}
+
+	// A wrong secret is rejected by the consumer and reported as a failure.
+	cmd = newWebhooksReplayCmd(f)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{path, "--to", server.URL, "--speed", "0"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "2 of 2") {
+		t.Fatalf("expected signature rejections, got %v", err)
+	}
+}
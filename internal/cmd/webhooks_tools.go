@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/iocontext"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+	"github.com/salmonumbrella/threads-cli/internal/webhooks"
+)
+
+// fixtureUserID is used for fixtures when no account is stored.
+const fixtureUserID = "17841400000000000"
+
+func newWebhooksSignCmd(f *Factory) *cobra.Command {
+	var appSecret string
+
+	cmd := &cobra.Command{
+		Use:   "sign [file]",
+		Short: "Compute the X-Hub-Signature-256 header for a payload",
+		Long: `Compute the X-Hub-Signature-256 value Meta would send with a webhook payload.
+
+The payload is read from a file, or from stdin when the file is omitted or
+"-". The signature covers the exact bytes read, including any trailing
+newline, so send the payload the same way (e.g. curl --data-binary @file).`,
+		Example: `  threads webhooks sign payload.json
+  threads webhooks fixture mentions | threads webhooks sign --app-secret test-secret
+
+  # Post a signed payload to a local consumer
+  curl -H "X-Hub-Signature-256: $(threads webhooks sign payload.json)" \
+    -H "Content-Type: application/json" --data-binary @payload.json http://localhost:8080/`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			body, err := readWebhookInput(ctx, args)
+			if err != nil {
+				return err
+			}
+			secret, err := resolveWebhookSecret(f, appSecret)
+			if err != nil {
+				return err
+			}
+
+			signature := webhooks.Sign(secret, body)
+
+			io := iocontext.GetIO(ctx)
+			if outfmt.IsJSON(ctx) {
+				out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+				return out.Output(map[string]any{
+					"header":    webhooks.SignatureHeader,
+					"signature": signature,
+				})
+			}
+			fmt.Fprintln(io.Out, signature) //nolint:errcheck // Best-effort output
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&appSecret, "app-secret", "", "App secret to sign with (default: stored app secret or THREADS_CLIENT_SECRET)")
+	return cmd
+}
+
+type webhooksReplayOptions struct {
+	To        string
+	Speed     float64
+	AppSecret string
+}
+
+// replayResult reports one re-sent delivery.
+type replayResult struct {
+	Line       int    `json:"line"`
+	Type       string `json:"type"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func newWebhooksReplayCmd(f *Factory) *cobra.Command {
+	opts := &webhooksReplayOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "replay <file.jsonl>",
+		Short: "Re-send captured webhook events to a URL",
+		Long: `Re-send captured webhook deliveries to a consumer, signed with the app secret.
+
+Each line of the file is either a raw Meta payload or an event as printed by
+'threads webhooks serve', so the output of serve can be replayed directly.
+Events are sent with their original spacing; --speed 10 replays ten times
+faster and --speed 0 sends them back to back.`,
+		Example: `  # Capture events, then replay them against a local consumer
+  threads webhooks serve --verify-token tok > events.jsonl
+  threads webhooks replay events.jsonl --to http://localhost:9000/hook
+
+  # Replay as fast as possible
+  threads webhooks replay events.jsonl --to http://localhost:9000/hook --speed 0`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWebhooksReplay(cmd, f, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.To, "to", "", "URL to send the events to (required)")
+	cmd.Flags().Float64Var(&opts.Speed, "speed", 1, "Replay speed relative to the original timing (0 = no delay)")
+	cmd.Flags().StringVar(&opts.AppSecret, "app-secret", "", "App secret to sign with (default: stored app secret or THREADS_CLIENT_SECRET)")
+	//nolint:errcheck,gosec // MarkFlagRequired cannot fail for flags that exist
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func runWebhooksReplay(cmd *cobra.Command, f *Factory, path string, opts *webhooksReplayOptions) error {
+	ctx := cmd.Context()
+	io := iocontext.GetIO(ctx)
+
+	if err := ValidateHTTPURL(opts.To, "--to"); err != nil {
+		return err
+	}
+
+	data, err := readWebhookInput(ctx, []string{path})
+	if err != nil {
+		return err
+	}
+	captures, err := webhooks.ReadCaptures(bytes.NewReader(data))
+	if err != nil {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("Invalid capture file %s", path),
+			Suggestion: "Each line must be a webhook payload or an event printed by 'threads webhooks serve'",
+			Cause:      err,
+		}
+	}
+	if len(captures) == 0 {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("No events found in %s", path),
+			Suggestion: "Capture events with 'threads webhooks serve > events.jsonl' or generate one with 'threads webhooks fixture'",
+		}
+	}
+
+	secret, err := resolveWebhookSecret(f, opts.AppSecret)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	results := make([]replayResult, 0, len(captures))
+	failed := 0
+	p := f.UI(ctx)
+
+	for i, capture := range captures {
+		if i > 0 {
+			if err := sleepContext(ctx, webhooks.ReplayDelay(captures[i-1], capture, opts.Speed)); err != nil {
+				return err
+			}
+		}
+
+		result := replayResult{Line: capture.Line, Type: capture.Type}
+		status, err := sendWebhook(ctx, client, opts.To, secret, capture.Body)
+		result.StatusCode = status
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case status < 200 || status >= 300:
+			result.Error = http.StatusText(status)
+		}
+		results = append(results, result)
+
+		if result.Error != "" {
+			failed++
+			if !outfmt.IsJSON(ctx) {
+				p.Error("line %d (%s): %s", result.Line, result.Type, result.Error)
+			}
+		} else if !outfmt.IsJSON(ctx) {
+			p.Success("line %d (%s): %d", result.Line, result.Type, status)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := outfmt.FromContext(ctx, outfmt.WithWriter(io.Out))
+		if outfmt.IsJSONL(ctx) {
+			if err := out.Output(results); err != nil {
+				return err
+			}
+		} else if err := out.Output(itemsEnvelope(results, nil, "")); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return &UserFriendlyError{
+			Message:    fmt.Sprintf("%d of %d events were not accepted by %s", failed, len(results), opts.To),
+			Suggestion: "Check the consumer's logs, and that it verifies signatures with the same app secret",
+		}
+	}
+	return nil
+}
+
+// sendWebhook POSTs a signed payload and returns the response status.
+func sendWebhook(ctx context.Context, client *http.Client, url, secret string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()               //nolint:errcheck // Best-effort cleanup
+	_, _ = io.Copy(io.Discard, resp.Body) // Drain so the connection is reused
+	return resp.StatusCode, nil
+}
+
+func newWebhooksFixtureCmd(f *Factory) *cobra.Command {
+	var userID string
+
+	cmd := &cobra.Command{
+		Use:       "fixture <event-type>",
+		Short:     "Print a sample webhook payload",
+		ValidArgs: []string{"mentions", "publishes", "deletes"},
+		Long: `Print a realistic sample payload for a webhook event type (mentions,
+publishes or deletes), in the envelope Meta delivers. The payload is always
+JSON; --query can be used to adjust it.`,
+		Example: `  threads webhooks fixture mentions > mention.json
+  threads webhooks fixture deletes --user-id 1234567890
+
+  # Send a signed sample event to a local consumer
+  threads webhooks fixture publishes -o jsonl > events.jsonl
+  threads webhooks replay events.jsonl --to http://localhost:9000/hook`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if userID == "" {
+				userID = fixtureUserID
+				if creds := storedCredentials(f); creds != nil && creds.UserID != "" {
+					userID = creds.UserID
+				}
+			}
+
+			event, err := webhooks.Fixture(api.WebhookEventType(strings.ToLower(args[0])), userID, time.Now())
+			if err != nil {
+				return &UserFriendlyError{
+					Message:    fmt.Sprintf("Invalid event type: %s", args[0]),
+					Suggestion: "Valid event types are: mentions, publishes, deletes",
+				}
+			}
+			payload, err := webhooks.NewPayload(event)
+			if err != nil {
+				return err
+			}
+
+			io := iocontext.GetIO(ctx)
+			if outfmt.IsJSONL(ctx) {
+				return outfmt.FromContext(ctx, outfmt.WithWriter(io.Out)).Output(payload)
+			}
+			return outfmt.WriteJSONTo(io.Out, payload, outfmt.GetQuery(ctx))
+		},
+	}
+
+	cmd.Flags().StringVar(&userID, "user-id", "", "Threads user ID the event is for (default: active account)")
+	return cmd
+}
+
+// resolveWebhookSecret returns the --app-secret flag value, or falls back to
+// the stored app secret.
+func resolveWebhookSecret(f *Factory, flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	return webhookAppSecret(f)
+}
+
+// readWebhookInput reads args[0], or stdin when it is missing or "-".
+func readWebhookInput(ctx context.Context, args []string) ([]byte, error) {
+	if len(args) == 0 || args[0] == "-" {
+		in := iocontext.GetIO(ctx).In
+		if in == nil {
+			in = os.Stdin
+		}
+		data, err := io.ReadAll(in)
+		if err != nil {
+			return nil, WrapError("failed to read stdin", err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return nil, &UserFriendlyError{
+			Message:    fmt.Sprintf("Failed to read %s", args[0]),
+			Suggestion: "Check that the file exists and is readable",
+			Cause:      err,
+		}
+	}
+	return data, nil
+}
+
+// sleepContext waits for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
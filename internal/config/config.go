@@ -3,9 +3,11 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 const configFileName = "config.json"
@@ -16,6 +18,265 @@ type Config struct {
 	Output  string `json:"output,omitempty"` // text|json
 	Color   string `json:"color,omitempty"`  // auto|always|never
 	Debug   bool   `json:"debug,omitempty"`
+
+	// SkipMediaCheck disables inspecting media before creating containers.
+	SkipMediaCheck bool `json:"skip_media_check,omitempty"`
+
+	// Media is the default host for uploading local media files.
+	Media *MediaHost `json:"media,omitempty"`
+	// Accounts holds per-account settings, keyed by account name.
+	Accounts map[string]*AccountConfig `json:"accounts,omitempty"`
+
+	// Webhooks configures what 'webhooks serve' does with received events.
+	Webhooks *WebhooksConfig `json:"webhooks,omitempty"`
+
+	// Throttle caps API request rates before requests are sent.
+	Throttle *ThrottleConfig `json:"throttle,omitempty"`
+
+	// Cache configures the on-disk cache of API responses.
+	Cache *CacheConfig `json:"cache,omitempty"`
+
+	// Telemetry exports traces and metrics of API calls.
+	Telemetry *TelemetryConfig `json:"telemetry,omitempty"`
+
+	// TokenRefresh controls refreshing access tokens before they expire.
+	TokenRefresh *TokenRefreshConfig `json:"token_refresh,omitempty"`
+
+	// Secrets selects where account credentials are stored.
+	Secrets *SecretsConfig `json:"secrets,omitempty"`
+}
+
+// SecretsConfig selects the credential store.
+type SecretsConfig struct {
+	// Backend is keyring (the OS keyring, default), file, exec or vault.
+	Backend string `json:"backend,omitempty"`
+
+	// File configures the encrypted file backend.
+	File *FileSecretsConfig `json:"file,omitempty"`
+
+	// Exec configures the external command backend.
+	Exec *ExecSecretsConfig `json:"exec,omitempty"`
+
+	// Vault configures the HashiCorp Vault KV v2 backend.
+	Vault *VaultSecretsConfig `json:"vault,omitempty"`
+}
+
+// ExecSecretsConfig configures a credential store implemented by an
+// external command, such as a wrapper around pass or a cloud secret
+// manager. The command is run with the action and account name appended
+// (get NAME, set NAME, delete NAME or list) and a JSON request on stdin; get
+// and list answer with JSON on stdout.
+type ExecSecretsConfig struct {
+	Command []string `json:"command"`
+
+	// Timeout bounds a single run, e.g. "30s" (default 30s).
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// VaultSecretsConfig configures a credential store in a HashiCorp Vault KV
+// version 2 secrets engine, one secret per account. Values may reference
+// environment variables as $VAR or ${VAR}.
+type VaultSecretsConfig struct {
+	// Address is the Vault server URL (default: VAULT_ADDR).
+	Address string `json:"address,omitempty"`
+
+	// Token authenticates requests (default: VAULT_TOKEN).
+	Token string `json:"token,omitempty"`
+
+	// Namespace is the Vault Enterprise namespace (default: VAULT_NAMESPACE).
+	Namespace string `json:"namespace,omitempty"`
+
+	// Mount is where the KV v2 engine is mounted (default: secret).
+	Mount string `json:"mount,omitempty"`
+
+	// Path is the secret path accounts are stored under (default:
+	// threads-cli).
+	Path string `json:"path,omitempty"`
+}
+
+// FileSecretsConfig configures the encrypted credentials file. Its
+// passphrase comes from THREADS_KEYRING_PASSWORD when set, otherwise from
+// KeyFile, otherwise from the output of KeyCommand.
+type FileSecretsConfig struct {
+	// Path is the credentials file (default: credentials.enc in the config
+	// directory).
+	Path string `json:"path,omitempty"`
+
+	// KeyFile holds the passphrase on its first line.
+	KeyFile string `json:"key_file,omitempty"`
+
+	// KeyCommand is the argv of a command printing the passphrase, such as
+	// ["pass", "show", "threads-cli"].
+	KeyCommand []string `json:"key_command,omitempty"`
+}
+
+// TokenRefreshConfig controls the automatic refresh of access tokens that
+// are about to expire. Tokens are refreshed when a command uses them, so
+// accounts that are never used still expire.
+type TokenRefreshConfig struct {
+	// Disabled turns automatic refresh off; 'threads auth refresh' still works.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// ThresholdDays is how many days before expiry a token is refreshed
+	// (default 7).
+	ThresholdDays int `json:"threshold_days,omitempty"`
+}
+
+// TelemetryConfig selects where traces and metrics of API calls are
+// exported, as OpenTelemetry (OTLP) JSON. Nothing is recorded when neither
+// destination is set.
+type TelemetryConfig struct {
+	// TraceFile receives one OTLP export request per line.
+	TraceFile string `json:"trace_file,omitempty"`
+
+	// OTLPEndpoint is the base URL of an OTLP/HTTP collector, such as
+	// http://localhost:4318.
+	OTLPEndpoint string `json:"otlp_endpoint,omitempty"`
+
+	// OTLPHeaders are sent with every export to OTLPEndpoint.
+	OTLPHeaders map[string]string `json:"otlp_headers,omitempty"`
+}
+
+// CacheConfig configures the on-disk response cache for read endpoints.
+type CacheConfig struct {
+	// Disabled turns the cache off, as if --no-cache were always given.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// TTL overrides how long responses are cached per endpoint (post, user,
+	// insights, location), as a duration such as "10m". "0" disables
+	// caching for that endpoint.
+	TTL map[string]string `json:"ttl,omitempty"`
+}
+
+// ThrottleConfig sets client-side request rates, in requests per second,
+// for each endpoint class. A zero rate leaves the class unthrottled.
+type ThrottleConfig struct {
+	Read     float64 `json:"read,omitempty"`
+	Publish  float64 `json:"publish,omitempty"`
+	Insights float64 `json:"insights,omitempty"`
+	Search   float64 `json:"search,omitempty"`
+
+	// Burst is how many requests of a class may go out back to back.
+	Burst int `json:"burst,omitempty"`
+}
+
+// ParseThrottle parses a throttle spec such as "read=5,publish=0.5,burst=2".
+func ParseThrottle(spec string) (*ThrottleConfig, error) {
+	cfg := &ThrottleConfig{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid throttle setting %q (want class=rate)", part)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "burst" {
+			burst, err := strconv.Atoi(value)
+			if err != nil || burst < 1 {
+				return nil, fmt.Errorf("invalid throttle burst %q", value)
+			}
+			cfg.Burst = burst
+			continue
+		}
+
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid throttle rate %q for %s", value, key)
+		}
+		switch key {
+		case "read":
+			cfg.Read = rate
+		case "publish":
+			cfg.Publish = rate
+		case "insights":
+			cfg.Insights = rate
+		case "search":
+			cfg.Search = rate
+		default:
+			return nil, fmt.Errorf("unknown throttle class %q (valid: read, publish, insights, search)", key)
+		}
+	}
+	return cfg, nil
+}
+
+// WebhooksConfig holds the actions run for received webhook events.
+type WebhooksConfig struct {
+	Actions []WebhookAction `json:"actions,omitempty"`
+}
+
+// WebhookAction runs for every received event whose type is listed in
+// Events (all types when empty) and for which Filter, a jq expression over
+// the event JSON, yields a value other than false or null. URL, header and
+// path values may reference environment variables as $VAR or ${VAR}.
+type WebhookAction struct {
+	Name   string   `json:"name,omitempty"`
+	Type   string   `json:"type"`             // command|http|file
+	Events []string `json:"events,omitempty"` // mentions|publishes|deletes
+	Filter string   `json:"filter,omitempty"`
+
+	// Command is the argv of a local command; the event JSON is its stdin.
+	Command []string `json:"command,omitempty"`
+
+	// URL receives the event JSON as a POST body, retried up to Retries
+	// times (default 3, -1 for none) on network errors, 429 and 5xx
+	// responses.
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Retries int               `json:"retries,omitempty"`
+
+	// Path is a file the event JSON is appended to, one line per event.
+	Path string `json:"path,omitempty"`
+
+	// Timeout bounds a single command run or HTTP attempt, e.g. "30s".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// AccountConfig holds settings that apply to a single account.
+type AccountConfig struct {
+	Media *MediaHost `json:"media,omitempty"`
+}
+
+// MediaHost configures where local media files are uploaded so Threads can
+// fetch them by URL. Credential and header values may reference environment
+// variables as $VAR or ${VAR}.
+type MediaHost struct {
+	Type string `json:"type"` // s3|http
+
+	// S3-compatible storage (AWS S3, MinIO, R2, ...).
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	Prefix          string `json:"prefix,omitempty"`
+	PathStyle       bool   `json:"path_style,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	SessionToken    string `json:"session_token,omitempty"`
+	Presign         bool   `json:"presign,omitempty"`
+
+	// Generic HTTP PUT endpoint. {key} in UploadURL is replaced with the
+	// object key; otherwise the key is appended as a path segment.
+	UploadURL string            `json:"upload_url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+
+	// PublicURL is the base (or {key} template) of the URL Threads fetches.
+	PublicURL string `json:"public_url,omitempty"`
+
+	// DeleteAfterPublish removes uploaded files once the post is published.
+	DeleteAfterPublish bool `json:"delete_after_publish,omitempty"`
+}
+
+// MediaHostFor returns the media host for account, falling back to the
+// default host. It returns nil when none is configured.
+func (c *Config) MediaHostFor(account string) *MediaHost {
+	if acct, ok := c.Accounts[account]; ok && acct != nil && acct.Media != nil {
+		return acct.Media
+	}
+	return c.Media
 }
 
 // Default returns a Config with default values.
@@ -94,6 +355,64 @@ func applyEnv(cfg *Config) {
 			cfg.Debug = true
 		}
 	}
+	if val := os.Getenv("THREADS_SKIP_MEDIA_CHECK"); val != "" {
+		if parsed, err := strconv.ParseBool(val); err == nil {
+			cfg.SkipMediaCheck = parsed
+		} else {
+			cfg.SkipMediaCheck = true
+		}
+	}
+	if val := os.Getenv("THREADS_NO_CACHE"); val != "" {
+		disabled, err := strconv.ParseBool(val)
+		if err != nil {
+			disabled = true
+		}
+		if cfg.Cache == nil {
+			cfg.Cache = &CacheConfig{}
+		}
+		cfg.Cache.Disabled = disabled
+	}
+	if val := os.Getenv("THREADS_THROTTLE"); val != "" {
+		if parsed, err := ParseThrottle(val); err == nil {
+			cfg.Throttle = parsed
+		}
+	}
+	if val := os.Getenv("THREADS_TRACE_FILE"); val != "" {
+		if cfg.Telemetry == nil {
+			cfg.Telemetry = &TelemetryConfig{}
+		}
+		cfg.Telemetry.TraceFile = val
+	}
+	if val := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); val != "" {
+		if cfg.Telemetry == nil {
+			cfg.Telemetry = &TelemetryConfig{}
+		}
+		cfg.Telemetry.OTLPEndpoint = val
+	}
+	if val := os.Getenv("THREADS_NO_TOKEN_REFRESH"); val != "" {
+		disabled, err := strconv.ParseBool(val)
+		if err != nil {
+			disabled = true
+		}
+		if cfg.TokenRefresh == nil {
+			cfg.TokenRefresh = &TokenRefreshConfig{}
+		}
+		cfg.TokenRefresh.Disabled = disabled
+	}
+	if val := os.Getenv("THREADS_TOKEN_REFRESH_DAYS"); val != "" {
+		if days, err := strconv.Atoi(val); err == nil && days > 0 {
+			if cfg.TokenRefresh == nil {
+				cfg.TokenRefresh = &TokenRefreshConfig{}
+			}
+			cfg.TokenRefresh.ThresholdDays = days
+		}
+	}
+	if val := os.Getenv("THREADS_SECRETS_BACKEND"); val != "" {
+		if cfg.Secrets == nil {
+			cfg.Secrets = &SecretsConfig{}
+		}
+		cfg.Secrets.Backend = val
+	}
 	if os.Getenv("NO_COLOR") != "" {
 		cfg.Color = "never"
 	}
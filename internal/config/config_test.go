@@ -0,0 +1,73 @@
+package config
+
+import "testing"
+
+func TestParseThrottle(t *testing.T) {
+	cfg, err := ParseThrottle("read=5, publish=0.5,Search=1,burst=3")
+	if err != nil {
+		t.Fatalf("ParseThrottle failed: %v", err)
+	}
+	if cfg.Read != 5 || cfg.Publish != 0.5 || cfg.Search != 1 || cfg.Insights != 0 || cfg.Burst != 3 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	for _, spec := range []string{"read", "read=fast", "write=1", "burst=0", "publish=-1"} {
+		if _, err := ParseThrottle(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestApplyEnv_Throttle(t *testing.T) {
+	t.Setenv("THREADS_THROTTLE", "insights=2")
+	cfg := Default()
+	applyEnv(cfg)
+	if cfg.Throttle == nil || cfg.Throttle.Insights != 2 {
+		t.Errorf("expected insights throttle from env, got %+v", cfg.Throttle)
+	}
+}
+
+func TestApplyEnv_NoCache(t *testing.T) {
+	t.Setenv("THREADS_NO_CACHE", "1")
+	cfg := Default()
+	applyEnv(cfg)
+	if cfg.Cache == nil || !cfg.Cache.Disabled {
+		t.Errorf("expected the cache to be disabled from env, got %+v", cfg.Cache)
+	}
+
+	t.Setenv("THREADS_NO_CACHE", "false")
+	cfg = &Config{Cache: &CacheConfig{Disabled: true}}
+	applyEnv(cfg)
+	if cfg.Cache.Disabled {
+		t.Error("expected THREADS_NO_CACHE=false to enable the cache")
+	}
+}
+
+func TestApplyEnv_TokenRefresh(t *testing.T) {
+	t.Setenv("THREADS_NO_TOKEN_REFRESH", "1")
+	t.Setenv("THREADS_TOKEN_REFRESH_DAYS", "14")
+	cfg := Default()
+	applyEnv(cfg)
+	if cfg.TokenRefresh == nil || !cfg.TokenRefresh.Disabled || cfg.TokenRefresh.ThresholdDays != 14 {
+		t.Errorf("expected token refresh settings from env, got %+v", cfg.TokenRefresh)
+	}
+}
+
+func TestApplyEnv_SecretsBackend(t *testing.T) {
+	t.Setenv("THREADS_SECRETS_BACKEND", "file")
+	cfg := &Config{Secrets: &SecretsConfig{File: &FileSecretsConfig{KeyFile: "/run/secrets/threads"}}}
+	applyEnv(cfg)
+	if cfg.Secrets.Backend != "file" || cfg.Secrets.File.KeyFile != "/run/secrets/threads" {
+		t.Errorf("expected the backend from env and the file settings kept, got %+v", cfg.Secrets)
+	}
+}
+
+func TestApplyEnv_Telemetry(t *testing.T) {
+	t.Setenv("THREADS_TRACE_FILE", "/tmp/trace.jsonl")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	cfg := Default()
+	applyEnv(cfg)
+	if cfg.Telemetry == nil || cfg.Telemetry.TraceFile != "/tmp/trace.jsonl" || cfg.Telemetry.OTLPEndpoint != "http://localhost:4318" {
+		t.Errorf("expected telemetry destinations from env, got %+v", cfg.Telemetry)
+	}
+}
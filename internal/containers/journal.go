@@ -0,0 +1,228 @@
+// Package containers implements a file-backed journal of media containers
+// created by the CLI, so publishes interrupted between container creation
+// and publishing can be finished later.
+package containers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+const (
+	journalFileName = "containers.json"
+
+	// ContainerLifetime is how long Threads keeps an unpublished container.
+	ContainerLifetime = 24 * time.Hour
+
+	// retention is how long records are kept before being pruned.
+	retention = 2 * ContainerLifetime
+)
+
+// ErrNotFound is returned when a container is not in the journal.
+var ErrNotFound = errors.New("container not found in journal")
+
+// Journal stores container records in a JSON file. It implements
+// api.ContainerJournal.
+type Journal struct {
+	path string
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+var _ api.ContainerJournal = (*Journal)(nil)
+
+// DefaultPath returns the default journal location under the data directory.
+func DefaultPath() string {
+	return filepath.Join(config.DataDir(), journalFileName)
+}
+
+// NewJournal returns a journal backed by the file at path.
+func NewJournal(path string) *Journal {
+	return &Journal{path: path, now: time.Now}
+}
+
+// Path returns the file backing the journal.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record inserts or updates a container record. Empty fields in rec keep
+// their previously recorded values. When a carousel is recorded as published,
+// its children are marked published too. Records older than the retention
+// window are pruned.
+func (j *Journal) Record(rec api.ContainerRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Other CLI processes may be publishing at the same time
+	return fsutil.WithLock(j.path+".lock", func() error {
+		return j.record(rec)
+	})
+}
+
+// record implements Record while the journal is locked.
+func (j *Journal) record(rec api.ContainerRecord) error {
+	records, err := j.load()
+	if err != nil {
+		return err
+	}
+
+	now := j.now()
+	merged := false
+	for i := range records {
+		if records[i].ContainerID != rec.ContainerID {
+			continue
+		}
+		merge(&records[i], rec)
+		rec = records[i]
+		merged = true
+		break
+	}
+	if !merged {
+		if rec.CreatedAt.IsZero() {
+			rec.CreatedAt = now
+		}
+		if rec.UpdatedAt.IsZero() {
+			rec.UpdatedAt = now
+		}
+		records = append(records, rec)
+	}
+
+	if rec.State == api.PublishStatePublished {
+		for i := range records {
+			for _, child := range rec.Children {
+				if records[i].ContainerID == child {
+					records[i].State = api.PublishStatePublished
+					records[i].PostID = rec.PostID
+					records[i].UpdatedAt = rec.UpdatedAt
+				}
+			}
+		}
+	}
+
+	kept := records[:0]
+	for _, r := range records {
+		if now.Sub(r.CreatedAt) < retention {
+			kept = append(kept, r)
+		}
+	}
+	return j.save(kept)
+}
+
+// List returns all records, newest first.
+func (j *Journal) List() ([]api.ContainerRecord, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(a, b int) bool {
+		return records[a].CreatedAt.After(records[b].CreatedAt)
+	})
+	return records, nil
+}
+
+// Get returns the record for a container.
+func (j *Journal) Get(containerID string) (*api.ContainerRecord, error) {
+	records, err := j.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		if records[i].ContainerID == containerID {
+			return &records[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Resumable returns top-level containers for userID that were created but
+// never published and have not yet expired, oldest first. An empty userID
+// matches every user.
+func (j *Journal) Resumable(userID string) ([]api.ContainerRecord, error) {
+	records, err := j.List()
+	if err != nil {
+		return nil, err
+	}
+
+	now := j.now()
+	var resumable []api.ContainerRecord
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		if IsResumable(&r, now) && (userID == "" || r.UserID == userID) {
+			resumable = append(resumable, r)
+		}
+	}
+	return resumable, nil
+}
+
+// IsResumable reports whether rec is an unpublished top-level container that
+// has not yet expired.
+func IsResumable(rec *api.ContainerRecord, now time.Time) bool {
+	if rec.Kind == api.MediaTypeCarouselItem {
+		return false
+	}
+	if rec.State != api.PublishStateCreated && rec.State != api.PublishStateReady {
+		return false
+	}
+	return now.Sub(rec.CreatedAt) < ContainerLifetime
+}
+
+func merge(dst *api.ContainerRecord, src api.ContainerRecord) {
+	if src.UserID != "" {
+		dst.UserID = src.UserID
+	}
+	if src.Kind != "" {
+		dst.Kind = src.Kind
+	}
+	if src.State != "" {
+		dst.State = src.State
+	}
+	if len(src.Children) > 0 {
+		dst.Children = src.Children
+	}
+	if src.PostID != "" {
+		dst.PostID = src.PostID
+	}
+	if src.Error != "" || src.State != api.PublishStateFailed {
+		dst.Error = src.Error
+	}
+	if !src.UpdatedAt.IsZero() {
+		dst.UpdatedAt = src.UpdatedAt
+	}
+}
+
+func (j *Journal) load() ([]api.ContainerRecord, error) {
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []api.ContainerRecord{}, nil
+		}
+		return nil, fmt.Errorf("failed to read container journal: %w", err)
+	}
+
+	var records []api.ContainerRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse container journal %s: %w", j.path, err)
+	}
+	return records, nil
+}
+
+func (j *Journal) save(records []api.ContainerRecord) error {
+	if err := fsutil.WriteJSON(j.path, records); err != nil {
+		return fmt.Errorf("failed to write container journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package containers
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+func newTestJournal(t *testing.T, now time.Time) *Journal {
+	t.Helper()
+	j := NewJournal(filepath.Join(t.TempDir(), "containers.json"))
+	j.now = func() time.Time { return now }
+	return j
+}
+
+func TestJournal_RecordMerges(t *testing.T) {
+	now := time.Now()
+	j := newTestJournal(t, now)
+
+	if err := j.Record(api.ContainerRecord{ContainerID: "c1", UserID: "u1", Kind: api.MediaTypeVideo, State: api.PublishStateCreated, CreatedAt: now}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	// A later update without kind/user keeps the earlier values.
+	if err := j.Record(api.ContainerRecord{ContainerID: "c1", State: api.PublishStatePublished, PostID: "p1"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	rec, err := j.Get("c1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if rec.Kind != api.MediaTypeVideo || rec.UserID != "u1" || rec.State != api.PublishStatePublished || rec.PostID != "p1" {
+		t.Errorf("unexpected merged record: %+v", rec)
+	}
+
+	if _, err := j.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestJournal_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "containers.json")
+
+	// Separate journals share no in-process mutex, like two CLI processes.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			j := NewJournal(path)
+			rec := api.ContainerRecord{ContainerID: fmt.Sprintf("c%d", i), Kind: api.MediaTypeText, State: api.PublishStateCreated}
+			if err := j.Record(rec); err != nil {
+				t.Errorf("Record failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	records, err := NewJournal(path).List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(records) != 10 {
+		t.Errorf("expected 10 records, got %d", len(records))
+	}
+}
+
+func TestJournal_PublishedCarouselMarksChildren(t *testing.T) {
+	now := time.Now()
+	j := newTestJournal(t, now)
+
+	for _, id := range []string{"i1", "i2"} {
+		if err := j.Record(api.ContainerRecord{ContainerID: id, Kind: api.MediaTypeCarouselItem, State: api.PublishStateCreated}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Record(api.ContainerRecord{ContainerID: "c1", Kind: api.MediaTypeCarousel, State: api.PublishStatePublished, Children: []string{"i1", "i2"}, PostID: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"i1", "i2"} {
+		rec, _ := j.Get(id)
+		if rec.State != api.PublishStatePublished || rec.PostID != "p1" {
+			t.Errorf("expected child %s to be published, got %+v", id, rec)
+		}
+	}
+}
+
+func TestJournal_Resumable(t *testing.T) {
+	now := time.Now()
+	j := newTestJournal(t, now)
+
+	records := []api.ContainerRecord{
+		{ContainerID: "old", UserID: "u1", Kind: api.MediaTypeImage, State: api.PublishStateCreated, CreatedAt: now.Add(-30 * time.Hour)},
+		{ContainerID: "ready", UserID: "u1", Kind: api.MediaTypeVideo, State: api.PublishStateReady, CreatedAt: now.Add(-2 * time.Hour)},
+		{ContainerID: "created", UserID: "u1", Kind: api.MediaTypeText, State: api.PublishStateCreated, CreatedAt: now.Add(-time.Hour)},
+		{ContainerID: "other-user", UserID: "u2", Kind: api.MediaTypeText, State: api.PublishStateCreated, CreatedAt: now},
+		{ContainerID: "item", UserID: "u1", Kind: api.MediaTypeCarouselItem, State: api.PublishStateCreated, CreatedAt: now},
+		{ContainerID: "done", UserID: "u1", Kind: api.MediaTypeText, State: api.PublishStatePublished, CreatedAt: now},
+		{ContainerID: "pruned", UserID: "u1", Kind: api.MediaTypeText, State: api.PublishStateCreated, CreatedAt: now.Add(-72 * time.Hour)},
+	}
+	for _, rec := range records {
+		if err := j.Record(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := j.Resumable("u1")
+	if err != nil {
+		t.Fatalf("Resumable failed: %v", err)
+	}
+	if len(got) != 2 || got[0].ContainerID != "ready" || got[1].ContainerID != "created" {
+		t.Errorf("expected [ready created] oldest first, got %+v", got)
+	}
+
+	all, _ := j.List()
+	for _, rec := range all {
+		if rec.ContainerID == "pruned" {
+			t.Error("expected records past the retention window to be pruned")
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package drafts persists unpublished posts on disk so they can be edited,
+// previewed, and published later.
+package drafts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+const dirName = "drafts"
+
+// ErrNotFound is returned when a draft does not exist.
+var ErrNotFound = errors.New("draft not found")
+
+// Draft captures every field supported by 'threads posts create' and
+// 'threads posts carousel'. Fields hold the same values as the CLI flags so
+// a draft can be published through the exact same code path.
+type Draft struct {
+	ID           string     `json:"id"`
+	Account      string     `json:"account,omitempty"`
+	Text         string     `json:"text,omitempty"`
+	ImageURL     string     `json:"image_url,omitempty"`
+	VideoURL     string     `json:"video_url,omitempty"`
+	AltText      string     `json:"alt_text,omitempty"`
+	Items        []string   `json:"items,omitempty"`
+	ItemAltTexts []string   `json:"item_alt_texts,omitempty"`
+	ReplyTo      string     `json:"reply_to,omitempty"`
+	Poll         string     `json:"poll,omitempty"`
+	Ghost        bool       `json:"ghost,omitempty"`
+	Topic        string     `json:"topic,omitempty"`
+	Location     string     `json:"location,omitempty"`
+	ReplyControl string     `json:"reply_control,omitempty"`
+	GIF          string     `json:"gif,omitempty"`
+	SpoilerMedia bool       `json:"spoiler_media,omitempty"`
+	Spoilers     []string   `json:"spoilers,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	PostID       string     `json:"post_id,omitempty"`
+	PublishedAt  *time.Time `json:"published_at,omitempty"`
+}
+
+// IsCarousel reports whether the draft is a carousel post.
+func (d *Draft) IsCarousel() bool {
+	return len(d.Items) > 0
+}
+
+// Kind returns a short description of the draft's post type.
+func (d *Draft) Kind() string {
+	switch {
+	case d.IsCarousel():
+		return "carousel"
+	case d.ImageURL != "":
+		return "image"
+	case d.VideoURL != "":
+		return "video"
+	default:
+		return "text"
+	}
+}
+
+// Store keeps one JSON file per draft in a directory.
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// DefaultDir returns the default drafts directory under the data directory.
+func DefaultDir() string {
+	return filepath.Join(config.DataDir(), dirName)
+}
+
+// NewStore returns a store rooted at dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, now: time.Now}
+}
+
+// Dir returns the directory backing the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// List returns all drafts, most recently updated first.
+func (s *Store) List() ([]Draft, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Draft{}, nil
+		}
+		return nil, fmt.Errorf("failed to read drafts: %w", err)
+	}
+
+	drafts := []Draft{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		d, err := s.Get(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, *d)
+	}
+
+	sort.SliceStable(drafts, func(a, b int) bool {
+		return drafts[a].UpdatedAt.After(drafts[b].UpdatedAt)
+	})
+	return drafts, nil
+}
+
+// Get loads a draft by ID.
+func (s *Store) Get(id string) (*Draft, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+
+	var d Draft
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("failed to parse draft %s: %w", id, err)
+	}
+	return &d, nil
+}
+
+// Save writes a draft, assigning an ID and timestamps as needed.
+func (s *Store) Save(d *Draft) error {
+	now := s.now()
+	if d.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		d.ID = id
+		d.CreatedAt = now
+	}
+	d.UpdatedAt = now
+
+	path, err := s.path(d.ID)
+	if err != nil {
+		return err
+	}
+	if err := fsutil.WriteJSON(path, d); err != nil {
+		return fmt.Errorf("failed to write draft: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a draft.
+func (s *Store) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
+
+// path maps an ID to its file, rejecting IDs that could escape the directory.
+func (s *Store) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return "", ErrNotFound
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate draft ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,125 @@
+package drafts
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore_SaveGetListDelete(t *testing.T) {
+	s := NewStore(t.TempDir())
+
+	first := &Draft{Text: "first"}
+	if err := s.Save(first); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if first.ID == "" || first.CreatedAt.IsZero() {
+		t.Fatalf("expected ID and timestamps to be assigned, got %+v", first)
+	}
+
+	second := &Draft{Text: "second", ImageURL: "https://example.com/a.jpg"}
+	s.now = func() time.Time { return time.Now().Add(time.Minute) }
+	if err := s.Save(second); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	drafts, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(drafts) != 2 || drafts[0].ID != second.ID {
+		t.Fatalf("expected most recently updated draft first, got %+v", drafts)
+	}
+
+	got, err := s.Get(first.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	got.Text = "edited"
+	createdAt := got.CreatedAt
+	if err := s.Save(got); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got, _ = s.Get(first.ID)
+	if got.Text != "edited" || !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("expected edit to keep ID and created time, got %+v", got)
+	}
+
+	if err := s.Delete(first.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Get(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := s.Delete(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestStore_ConcurrentSaves(t *testing.T) {
+	s := NewStore(t.TempDir())
+	base := &Draft{Text: "v0"}
+	if err := s.Save(base); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d := &Draft{ID: base.ID, Text: fmt.Sprintf("v%d", i)}
+			if err := s.Save(d); err != nil {
+				t.Errorf("Save failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	drafts, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(drafts) != 1 {
+		t.Errorf("expected a single draft and no leftover temp files, got %d", len(drafts))
+	}
+}
+
+func TestStore_ListEmpty(t *testing.T) {
+	s := NewStore(t.TempDir() + "/missing")
+	drafts, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(drafts) != 0 {
+		t.Errorf("expected no drafts, got %d", len(drafts))
+	}
+}
+
+func TestStore_RejectsPathTraversal(t *testing.T) {
+	s := NewStore(t.TempDir())
+	for _, id := range []string{"../secret", "a/b", "", ".."} {
+		if _, err := s.Get(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): expected ErrNotFound, got %v", id, err)
+		}
+	}
+}
+
+func TestDraft_Kind(t *testing.T) {
+	tests := []struct {
+		draft Draft
+		want  string
+	}{
+		{Draft{Text: "hi"}, "text"},
+		{Draft{ImageURL: "https://example.com/a.jpg"}, "image"},
+		{Draft{VideoURL: "https://example.com/a.mp4"}, "video"},
+		{Draft{Items: []string{"a", "b"}}, "carousel"},
+	}
+	for _, tt := range tests {
+		if got := tt.draft.Kind(); got != tt.want {
+			t.Errorf("Kind() = %q, want %q", got, tt.want)
+		}
+	}
+}
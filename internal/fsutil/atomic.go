@@ -0,0 +1,50 @@
+// Package fsutil provides the atomic writes and cross-process lock files
+// shared by the CLI's on-disk stores.
+package fsutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to path through a temporary file in the same
+// directory followed by a rename, so readers and a crash mid-write never see
+// a truncated file. Missing parent directories are created with mode 0700.
+// The file is created with mode 0600.
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()        //nolint:errcheck,gosec // Already returning the write error
+		os.Remove(tmpName) //nolint:errcheck,gosec // Best-effort cleanup
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName) //nolint:errcheck,gosec // Best-effort cleanup
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName) //nolint:errcheck,gosec // Best-effort cleanup
+		return err
+	}
+	return nil
+}
+
+// WriteJSON writes v to path as indented JSON using WriteFileAtomic.
+func WriteJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data)
+}
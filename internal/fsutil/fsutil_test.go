@@ -0,0 +1,133 @@
+package fsutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "data.json")
+
+	if err := WriteJSON(path, map[string]int{"a": 1}); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(data) != "{\n  \"a\": 1\n}" {
+		t.Errorf("unexpected contents %q", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected mode 0600, got %o", perm)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected temp files to be cleaned up, got %d entries", len(entries))
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.lock")
+
+	lock, err := TryLock(path, time.Hour)
+	if err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	if _, err := TryLock(path, time.Hour); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if err := lock.Touch(); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+
+	lock, err = TryLock(path, time.Hour)
+	if err != nil {
+		t.Fatalf("expected lock after release, got %v", err)
+	}
+	_ = lock.Release()
+}
+
+func TestTryLock_TakesOverStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.lock")
+	if err := os.WriteFile(path, []byte("1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := TryLock(path, time.Minute)
+	if err != nil {
+		t.Fatalf("expected stale lock to be taken over, got %v", err)
+	}
+	_ = lock.Release()
+}
+
+func TestAcquireLock_Timeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.lock")
+	lock, err := TryLock(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release() //nolint:errcheck
+
+	if _, err := AcquireLock(context.Background(), path, 100*time.Millisecond, time.Hour); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked after timeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := AcquireLock(ctx, path, time.Minute, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWithLock_SerializesWriters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "counter")
+	lockPath := path + ".lock"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := WithLock(lockPath, func() error {
+				n := 0
+				if data, err := os.ReadFile(path); err == nil {
+					n, _ = strconv.Atoi(string(data))
+				}
+				return WriteFileAtomic(path, []byte(strconv.Itoa(n+1)))
+			})
+			if err != nil {
+				t.Errorf("WithLock failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "20" {
+		t.Errorf("expected 20 increments, got %s", data)
+	}
+}
@@ -0,0 +1,289 @@
+// Package inbox remembers which mentions and replies have been seen, read or
+// archived so 'threads inbox' only surfaces what is new.
+package inbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+const fileName = "inbox.json"
+
+// ErrNotFound is returned when an inbox item does not exist.
+var ErrNotFound = errors.New("inbox item not found")
+
+// Kind identifies where an inbox item came from.
+type Kind string
+
+const (
+	KindMention Kind = "mention"
+	KindReply   Kind = "reply"
+)
+
+// State is the read state of an inbox item.
+type State string
+
+const (
+	StateUnread   State = "unread"
+	StateRead     State = "read"
+	StateArchived State = "archived"
+)
+
+// Item is a mention or reply tracked by the inbox.
+type Item struct {
+	ID          string     `json:"id"`
+	Kind        Kind       `json:"kind"`
+	Username    string     `json:"username,omitempty"`
+	Text        string     `json:"text,omitempty"`
+	Permalink   string     `json:"permalink,omitempty"`
+	Timestamp   time.Time  `json:"timestamp"`
+	InReplyTo   string     `json:"in_reply_to,omitempty"` // our post, for replies
+	State       State      `json:"state"`
+	FirstSeenAt time.Time  `json:"first_seen_at"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+}
+
+// account holds one account's items and the time of its last check.
+type account struct {
+	LastCheckedAt time.Time        `json:"last_checked_at,omitempty"`
+	Items         map[string]*Item `json:"items"`
+}
+
+// Store is a JSON file holding the inbox of every account.
+type Store struct {
+	path string
+	now  func() time.Time
+}
+
+// DefaultPath returns the default inbox location under the data directory.
+func DefaultPath() string {
+	return filepath.Join(config.DataDir(), fileName)
+}
+
+// NewStore returns a store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path, now: time.Now}
+}
+
+// Path returns the file backing the store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Sync records fetched items for acct. Items not seen before are stored as
+// unread and returned, newest first; known items keep their state. The
+// account's last check time is updated, and the previous one returned.
+func (s *Store) Sync(acct string, fetched []Item) (added []Item, lastChecked time.Time, err error) {
+	err = s.update(func(accounts map[string]*account) (bool, error) {
+		a := accountFor(accounts, acct)
+		lastChecked = a.LastCheckedAt
+		now := s.now()
+
+		for _, item := range fetched {
+			if _, ok := a.Items[item.ID]; ok {
+				continue
+			}
+			item.State = StateUnread
+			item.FirstSeenAt = now
+			item.ReadAt = nil
+			item.ArchivedAt = nil
+			a.Items[item.ID] = &item
+			added = append(added, item)
+		}
+		a.LastCheckedAt = now
+		return true, nil
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	sortItems(added)
+	return added, lastChecked, nil
+}
+
+// List returns acct's items in any of states (all states when none are
+// given), newest first.
+func (s *Store) List(acct string, states ...State) ([]Item, error) {
+	accounts, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	items := []Item{}
+	a, ok := accounts[acct]
+	if !ok {
+		return items, nil
+	}
+	for _, item := range a.Items {
+		if len(states) == 0 || hasState(states, item.State) {
+			items = append(items, *item)
+		}
+	}
+	sortItems(items)
+	return items, nil
+}
+
+// LastChecked returns when acct's inbox was last synced.
+func (s *Store) LastChecked(acct string) (time.Time, error) {
+	accounts, err := s.load()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if a, ok := accounts[acct]; ok {
+		return a.LastCheckedAt, nil
+	}
+	return time.Time{}, nil
+}
+
+// SetState moves the items with the given IDs to state. Unknown IDs fail
+// the whole update with ErrNotFound.
+func (s *Store) SetState(acct string, ids []string, state State) ([]Item, error) {
+	var updated []Item
+	err := s.update(func(accounts map[string]*account) (bool, error) {
+		a := accountFor(accounts, acct)
+		for _, id := range ids {
+			if _, ok := a.Items[id]; !ok {
+				return false, fmt.Errorf("%w: %s", ErrNotFound, id)
+			}
+		}
+
+		now := s.now()
+		updated = make([]Item, 0, len(ids))
+		for _, id := range ids {
+			item := a.Items[id]
+			setState(item, state, now)
+			updated = append(updated, *item)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SetStateWhere moves every item of acct currently in one of from to state
+// and returns how many changed.
+func (s *Store) SetStateWhere(acct string, state State, from ...State) (int, error) {
+	changed := 0
+	err := s.update(func(accounts map[string]*account) (bool, error) {
+		a, ok := accounts[acct]
+		if !ok {
+			return false, nil
+		}
+
+		now := s.now()
+		for _, item := range a.Items {
+			if item.State != state && hasState(from, item.State) {
+				setState(item, state, now)
+				changed++
+			}
+		}
+		return changed > 0, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+func setState(item *Item, state State, now time.Time) {
+	item.State = state
+	switch state {
+	case StateUnread:
+		item.ReadAt = nil
+		item.ArchivedAt = nil
+	case StateRead:
+		if item.ReadAt == nil {
+			item.ReadAt = &now
+		}
+		item.ArchivedAt = nil
+	case StateArchived:
+		if item.ReadAt == nil {
+			item.ReadAt = &now
+		}
+		item.ArchivedAt = &now
+	}
+}
+
+func accountFor(accounts map[string]*account, acct string) *account {
+	a, ok := accounts[acct]
+	if !ok || a == nil {
+		a = &account{}
+		accounts[acct] = a
+	}
+	if a.Items == nil {
+		a.Items = map[string]*Item{}
+	}
+	return a
+}
+
+func hasState(states []State, state State) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func sortItems(items []Item) {
+	sort.SliceStable(items, func(a, b int) bool {
+		if !items[a].Timestamp.Equal(items[b].Timestamp) {
+			return items[a].Timestamp.After(items[b].Timestamp)
+		}
+		return items[a].ID > items[b].ID
+	})
+}
+
+func (s *Store) load() (map[string]*account, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]*account{}, nil
+		}
+		return nil, fmt.Errorf("failed to read inbox: %w", err)
+	}
+
+	var accounts map[string]*account
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse inbox %s: %w", s.path, err)
+	}
+	if accounts == nil {
+		accounts = map[string]*account{}
+	}
+	return accounts, nil
+}
+
+// update runs a load/modify/save cycle under the store's lock file, so
+// concurrent CLI processes never drop each other's changes. fn reports
+// whether anything changed; nothing is saved when it did not or it fails.
+func (s *Store) update(fn func(accounts map[string]*account) (bool, error)) error {
+	return fsutil.WithLock(s.path+".lock", func() error {
+		accounts, err := s.load()
+		if err != nil {
+			return err
+		}
+		changed, err := fn(accounts)
+		if err != nil || !changed {
+			return err
+		}
+		return s.save(accounts)
+	})
+}
+
+// save writes the inbox atomically so a crash mid-write never truncates it.
+func (s *Store) save(accounts map[string]*account) error {
+	if err := fsutil.WriteJSON(s.path, accounts); err != nil {
+		return fmt.Errorf("failed to write inbox: %w", err)
+	}
+	return nil
+}
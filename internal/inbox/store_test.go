@@ -0,0 +1,127 @@
+package inbox
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return NewStore(filepath.Join(t.TempDir(), "inbox.json"))
+}
+
+func mention(id string, at time.Time) Item {
+	return Item{ID: id, Kind: KindMention, Username: "bob", Text: "hi " + id, Timestamp: at}
+}
+
+func TestStore_SyncOnlyAddsNewItems(t *testing.T) {
+	s := newTestStore(t)
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	added, last, err := s.Sync("alice", []Item{mention("m1", base), mention("m2", base.Add(time.Minute))})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(added) != 2 || added[0].ID != "m2" || added[0].State != StateUnread {
+		t.Fatalf("expected two new unread items newest first, got %+v", added)
+	}
+	if !last.IsZero() {
+		t.Errorf("expected no previous check, got %v", last)
+	}
+
+	if _, err := s.SetState("alice", []string{"m1"}, StateRead); err != nil {
+		t.Fatalf("SetState failed: %v", err)
+	}
+
+	added, last, err = s.Sync("alice", []Item{mention("m1", base), mention("m3", base.Add(2*time.Minute))})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(added) != 1 || added[0].ID != "m3" {
+		t.Fatalf("expected only m3 to be new, got %+v", added)
+	}
+	if last.IsZero() {
+		t.Error("expected the previous check time")
+	}
+
+	unread, err := s.List("alice", StateUnread)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unread) != 2 || unread[0].ID != "m3" || unread[1].ID != "m2" {
+		t.Errorf("unexpected unread items: %+v", unread)
+	}
+
+	// Accounts are kept apart.
+	other, _ := s.List("bob")
+	if len(other) != 0 {
+		t.Errorf("expected an empty inbox for another account, got %+v", other)
+	}
+}
+
+func TestStore_ArchiveAndMarkAll(t *testing.T) {
+	s := newTestStore(t)
+	base := time.Now()
+	if _, _, err := s.Sync("alice", []Item{mention("m1", base), mention("m2", base), mention("m3", base)}); err != nil {
+		t.Fatal(err)
+	}
+
+	archived, err := s.SetState("alice", []string{"m1"}, StateArchived)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived[0].ArchivedAt == nil || archived[0].ReadAt == nil {
+		t.Errorf("expected archive to set read and archive times: %+v", archived[0])
+	}
+
+	changed, err := s.SetStateWhere("alice", StateRead, StateUnread)
+	if err != nil || changed != 2 {
+		t.Fatalf("expected 2 items marked read, got %d (%v)", changed, err)
+	}
+
+	// Archived items stay archived even when fetched again.
+	if added, _, _ := s.Sync("alice", []Item{mention("m1", base)}); len(added) != 0 {
+		t.Errorf("archived item came back: %+v", added)
+	}
+	items, _ := s.List("alice", StateArchived)
+	if len(items) != 1 || items[0].ID != "m1" {
+		t.Errorf("unexpected archived items: %+v", items)
+	}
+
+	if _, err := s.SetState("alice", []string{"m2", "nope"}, StateArchived); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if items, _ := s.List("alice", StateArchived); len(items) != 1 {
+		t.Errorf("a failed update must not change anything, got %+v", items)
+	}
+}
+
+func TestStore_ConcurrentSyncs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inbox.json")
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	// Separate stores share nothing in memory, like two CLI processes.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, _, err := NewStore(path).Sync("alice", []Item{mention(fmt.Sprintf("m%d", i), base)}); err != nil {
+				t.Errorf("Sync failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	items, err := NewStore(path).List("alice")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != 10 {
+		t.Errorf("expected 10 items, got %d", len(items))
+	}
+}
@@ -0,0 +1,227 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+// Limits are the media requirements Threads publishes for posts.
+type Limits struct {
+	ImageFormats   []string
+	MaxImageSize   int64
+	MaxImageAspect float64 // applies both ways, e.g. 10 allows 1:10 to 10:1
+
+	VideoFormats       []string
+	VideoCodecs        []string
+	AudioCodecs        []string
+	MaxVideoSize       int64
+	MaxVideoWidth      int
+	MinVideoAspect     float64
+	MaxVideoAspect     float64
+	MaxVideoDuration   time.Duration
+	MinFrameRate       float64
+	MaxFrameRate       float64
+	MaxAudioSampleRate int
+	MaxAudioChannels   int
+}
+
+// ThreadsLimits are the limits from the Threads API media specifications.
+var ThreadsLimits = Limits{
+	ImageFormats:   []string{"jpeg", "png"},
+	MaxImageSize:   8 << 20,
+	MaxImageAspect: 10,
+
+	VideoFormats:       []string{"mp4", "mov"},
+	VideoCodecs:        []string{"h264", "hevc"},
+	AudioCodecs:        []string{"aac"},
+	MaxVideoSize:       1 << 30,
+	MaxVideoWidth:      1920,
+	MinVideoAspect:     0.01,
+	MaxVideoAspect:     10,
+	MaxVideoDuration:   5 * time.Minute,
+	MinFrameRate:       23,
+	MaxFrameRate:       60,
+	MaxAudioSampleRate: 48000,
+	MaxAudioChannels:   2,
+}
+
+// Violation is a single way media fails the limits. Field names the
+// property that failed, e.g. "duration" or "aspect_ratio".
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Check returns every limit info violates when posted as mediaType ("image"
+// or "video", case-insensitive). An empty mediaType uses the detected type.
+// Properties that could not be read are not checked.
+func (l Limits) Check(info *Info, mediaType string) []Violation {
+	mediaType = strings.ToUpper(mediaType)
+	if mediaType == "" {
+		mediaType = info.MediaType
+	}
+
+	var violations []Violation
+	add := func(field, format string, args ...any) {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if info.MediaType != "" && mediaType != "" && info.MediaType != mediaType {
+		add("media_type", "File is %s, not a %s", describe(info), strings.ToLower(mediaType))
+		return violations
+	}
+
+	switch mediaType {
+	case "IMAGE":
+		if info.Format != "" && !contains(l.ImageFormats, info.Format) {
+			add("content_type", "Image format %s is not supported (use %s)", describe(info), strings.ToUpper(strings.Join(l.ImageFormats, " or ")))
+		}
+		if info.Size > l.MaxImageSize {
+			add("size", "Image is %s; the limit is %s", formatBytes(info.Size), formatBytes(l.MaxImageSize))
+		}
+		if ratio := info.AspectRatio(); ratio > 0 && (ratio > l.MaxImageAspect || ratio < 1/l.MaxImageAspect) {
+			add("aspect_ratio", "Image aspect ratio %dx%d is outside %g:1 to 1:%g", info.Width, info.Height, l.MaxImageAspect, l.MaxImageAspect)
+		}
+	case "VIDEO":
+		if info.Format != "" && !contains(l.VideoFormats, info.Format) {
+			add("container", "Video container %s is not supported (use MP4 or MOV)", describe(info))
+		}
+		if info.MetadataAtEnd {
+			add("container", "Video metadata (moov atom) must be at the start of the file; re-encode with fast start (ffmpeg -movflags +faststart)")
+		}
+		if info.Size > l.MaxVideoSize {
+			add("size", "Video is %s; the limit is %s", formatBytes(info.Size), formatBytes(l.MaxVideoSize))
+		}
+		if info.VideoCodec != "" && !contains(l.VideoCodecs, info.VideoCodec) {
+			add("video_codec", "Video codec %s is not supported (use H.264 or HEVC)", info.VideoCodec)
+		}
+		if info.AudioCodec != "" && !contains(l.AudioCodecs, info.AudioCodec) {
+			add("audio_codec", "Audio codec %s is not supported (use AAC)", info.AudioCodec)
+		}
+		if info.AudioSampleRate > l.MaxAudioSampleRate {
+			add("audio_sample_rate", "Audio sample rate %d Hz exceeds %d Hz", info.AudioSampleRate, l.MaxAudioSampleRate)
+		}
+		if info.AudioChannels > l.MaxAudioChannels {
+			add("audio_channels", "Audio has %d channels; use mono or stereo", info.AudioChannels)
+		}
+		if info.Width > l.MaxVideoWidth {
+			add("width", "Video is %d pixels wide; the limit is %d", info.Width, l.MaxVideoWidth)
+		}
+		if ratio := info.AspectRatio(); ratio > 0 && (ratio > l.MaxVideoAspect || ratio < l.MinVideoAspect) {
+			add("aspect_ratio", "Video aspect ratio %dx%d is outside %g:1 to %g:1", info.Width, info.Height, l.MinVideoAspect, l.MaxVideoAspect)
+		}
+		if info.Duration > l.MaxVideoDuration.Seconds() {
+			add("duration", "Video is %.0fs long; the limit is %.0fs", info.Duration, l.MaxVideoDuration.Seconds())
+		}
+		if info.FrameRate > 0 && (info.FrameRate < l.MinFrameRate || info.FrameRate > l.MaxFrameRate) {
+			add("frame_rate", "Video frame rate %.2f fps is outside %g-%g fps", info.FrameRate, l.MinFrameRate, l.MaxFrameRate)
+		}
+	}
+	return violations
+}
+
+// Checker inspects media and checks it against ThreadsLimits. It implements
+// api.MediaChecker.
+type Checker struct {
+	client *http.Client
+	limits Limits
+}
+
+var _ api.MediaChecker = (*Checker)(nil)
+
+// NewChecker returns a checker that fetches URLs with client (nil for a
+// client with a 30 second timeout).
+func NewChecker(client *http.Client) *Checker {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Checker{client: client, limits: ThreadsLimits}
+}
+
+// Inspect describes the media at ref and lists its violations.
+func (c *Checker) Inspect(ctx context.Context, ref, mediaType string) (*Info, []Violation, error) {
+	info, err := Inspect(ctx, c.client, ref)
+	if err != nil {
+		return info, nil, err
+	}
+	return info, c.limits.Check(info, mediaType), nil
+}
+
+// CheckMedia implements api.MediaChecker. Media that breaks the limits or
+// that the host refuses (4xx) is a *api.ValidationError. A fetch that fails
+// in transit, times out, or gets a 429 or 5xx is a temporary
+// *api.NetworkError, so callers that retry can try again later.
+func (c *Checker) CheckMedia(ctx context.Context, ref, mediaType string) error {
+	info, violations, err := c.Inspect(ctx, ref, mediaType)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		var fetchErr *FetchError
+		if errors.As(err, &fetchErr) {
+			if fetchErr.StatusCode == http.StatusTooManyRequests || fetchErr.StatusCode >= 500 {
+				return api.NewNetworkError(fetchErr.StatusCode, "Media URL is temporarily unavailable",
+					fmt.Sprintf("%s returned HTTP %d", ref, fetchErr.StatusCode), true)
+			}
+			return api.NewValidationError(400, "Media URL is not reachable",
+				fmt.Sprintf("%s returned HTTP %d; Threads must be able to fetch it", ref, fetchErr.StatusCode),
+				"media_url")
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return api.NewNetworkError(0, "Media URL could not be fetched", err.Error(), true)
+		}
+		return api.NewValidationError(400, "Media could not be inspected", err.Error(), "media_url")
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.Message)
+	}
+	return api.NewValidationError(400,
+		fmt.Sprintf("%s does not meet Threads media requirements", describe(info)),
+		strings.Join(messages, "; "),
+		violations[0].Field)
+}
+
+func describe(info *Info) string {
+	if info.Format != "" {
+		return strings.ToUpper(info.Format)
+	}
+	if info.ContentType != "" {
+		return info.ContentType
+	}
+	return "Media"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
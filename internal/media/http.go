@@ -0,0 +1,109 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HTTPConfig configures an HTTPUploader.
+type HTTPConfig struct {
+	// UploadURL receives a PUT per file. {key} is replaced with the object
+	// key; otherwise the key is appended as a path segment.
+	UploadURL string
+	// PublicURL is where the file can be fetched. When empty, a "url" field
+	// in the JSON upload response is used, then the upload URL itself.
+	PublicURL string
+	// Headers are sent with every request (e.g. Authorization).
+	Headers map[string]string
+}
+
+// HTTPUploader uploads files with a plain HTTP PUT and removes them with
+// DELETE on the same URL. It works with WebDAV servers, nginx with
+// dav_methods, and most "upload endpoint" style file hosts.
+type HTTPUploader struct {
+	cfg    HTTPConfig
+	client *http.Client
+}
+
+var _ Uploader = (*HTTPUploader)(nil)
+
+// NewHTTPUploader returns an uploader for cfg.
+func NewHTTPUploader(cfg HTTPConfig, client *http.Client) (*HTTPUploader, error) {
+	if !strings.HasPrefix(cfg.UploadURL, "http://") && !strings.HasPrefix(cfg.UploadURL, "https://") {
+		return nil, fmt.Errorf("media host upload_url must be an http(s) URL")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPUploader{cfg: cfg, client: client}, nil
+}
+
+// Upload implements Uploader.
+func (u *HTTPUploader) Upload(ctx context.Context, obj *Object) (*Uploaded, error) {
+	uploadURL := joinURL(u.cfg.UploadURL, obj.Key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, obj.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = obj.Size
+	req.Header.Set("Content-Type", obj.ContentType)
+	u.setHeaders(req)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // Response body is fully read below
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck // Body is only used for error details
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("upload failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	uploaded := &Uploaded{Key: obj.Key, URL: uploadURL}
+	switch {
+	case u.cfg.PublicURL != "":
+		uploaded.URL = joinURL(u.cfg.PublicURL, obj.Key)
+	case len(body) > 0:
+		var parsed struct {
+			URL string `json:"url"`
+		}
+		if json.Unmarshal(body, &parsed) == nil && parsed.URL != "" {
+			uploaded.URL = parsed.URL
+		}
+	}
+	return uploaded, nil
+}
+
+// Delete implements Uploader.
+func (u *HTTPUploader) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, joinURL(u.cfg.UploadURL, key), nil)
+	if err != nil {
+		return err
+	}
+	u.setHeaders(req)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // Body is not needed
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("delete failed: %s", resp.Status)
+	}
+	return nil
+}
+
+func (u *HTTPUploader) setHeaders(req *http.Request) {
+	for k, v := range u.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+}
@@ -0,0 +1,203 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF so unsupported formats still report dimensions
+	_ "image/jpeg" // Register JPEG for image.DecodeConfig
+	_ "image/png"  // Register PNG for image.DecodeConfig
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Info describes a media file as read from its headers.
+type Info struct {
+	Source      string `json:"source"`
+	MediaType   string `json:"media_type,omitempty"` // IMAGE or VIDEO
+	Format      string `json:"format,omitempty"`     // jpeg, png, gif, webp, mp4, mov
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"` // bytes; 0 when unknown
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+
+	// Video only.
+	Duration        float64 `json:"duration_seconds,omitempty"`
+	FrameRate       float64 `json:"frame_rate,omitempty"`
+	VideoCodec      string  `json:"video_codec,omitempty"`
+	AudioCodec      string  `json:"audio_codec,omitempty"`
+	AudioSampleRate int     `json:"audio_sample_rate,omitempty"`
+	AudioChannels   int     `json:"audio_channels,omitempty"`
+	// MetadataAtEnd is set when the moov atom follows the media data, which
+	// Threads rejects ("fast start" files keep it at the front).
+	MetadataAtEnd bool `json:"metadata_at_end,omitempty"`
+}
+
+// AspectRatio returns width divided by height, or 0 when unknown.
+func (i *Info) AspectRatio() float64 {
+	if i.Width == 0 || i.Height == 0 {
+		return 0
+	}
+	return float64(i.Width) / float64(i.Height)
+}
+
+// Inspect reads just enough of a local file or URL to describe it. URLs are
+// fetched with a GET that is abandoned once the headers are parsed.
+func Inspect(ctx context.Context, client *http.Client, ref string) (*Info, error) {
+	if IsLocal(ref) {
+		return inspectFile(ref)
+	}
+	return inspectURL(ctx, client, ref)
+}
+
+func inspectFile(path string) (*Info, error) {
+	file, err := os.Open(path) //nolint:gosec // The path is chosen by the local user running the CLI.
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() //nolint:errcheck // Read-only file
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	info := &Info{
+		Source:      path,
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(strings.ToLower(filepath.Ext(path))),
+	}
+	return info, inspectStream(file, file, info)
+}
+
+func inspectURL(ctx context.Context, client *http.Client, mediaURL string) (*Info, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch media: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // The body is intentionally abandoned after the headers
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &FetchError{URL: mediaURL, StatusCode: resp.StatusCode}
+	}
+
+	info := &Info{Source: mediaURL, ContentType: resp.Header.Get("Content-Type")}
+	if resp.ContentLength > 0 {
+		info.Size = resp.ContentLength
+	}
+	return info, inspectStream(resp.Body, nil, info)
+}
+
+// FetchError reports a media URL that did not return a successful response.
+type FetchError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("media URL returned HTTP %d", e.StatusCode)
+}
+
+// inspectStream sniffs the format and parses the headers. seeker, when set,
+// lets the MP4 parser skip media data to reach a trailing moov atom.
+func inspectStream(r io.Reader, seeker io.Seeker, info *Info) error {
+	br := bufio.NewReaderSize(r, 64<<10)
+	head, err := br.Peek(12)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+
+	info.Format = sniffFormat(head)
+	if info.Format == "" {
+		info.Format = formatFromContentType(info.ContentType)
+	}
+
+	switch info.Format {
+	case "jpeg", "png", "gif", "webp":
+		info.MediaType = "IMAGE"
+		if info.ContentType == "" {
+			info.ContentType = "image/" + info.Format
+		}
+		if info.Format == "webp" {
+			return nil
+		}
+		cfg, _, errDecode := image.DecodeConfig(br)
+		if errDecode != nil {
+			return fmt.Errorf("failed to read %s header: %w", info.Format, errDecode)
+		}
+		info.Width, info.Height = cfg.Width, cfg.Height
+		return nil
+	case "mp4", "mov":
+		info.MediaType = "VIDEO"
+		errParse := parseMP4(br, r, seeker, info)
+		if info.ContentType == "" {
+			info.ContentType = "video/mp4"
+			if info.Format == "mov" {
+				info.ContentType = "video/quicktime"
+			}
+		}
+		return errParse
+	default:
+		if strings.HasPrefix(info.ContentType, "video/") {
+			info.MediaType = "VIDEO"
+		} else if strings.HasPrefix(info.ContentType, "image/") {
+			info.MediaType = "IMAGE"
+		}
+		return nil
+	}
+}
+
+// sniffFormat identifies a media format from its first bytes.
+func sniffFormat(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg"
+	case bytes.HasPrefix(head, []byte("\x89PNG")):
+		return "png"
+	case bytes.HasPrefix(head, []byte("GIF8")):
+		return "gif"
+	case len(head) >= 12 && bytes.HasPrefix(head, []byte("RIFF")) && string(head[8:12]) == "WEBP":
+		return "webp"
+	case len(head) >= 8:
+		switch string(head[4:8]) {
+		case "ftyp", "moov", "mdat", "free", "wide", "skip":
+			return "mp4"
+		}
+	}
+	return ""
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "image/jpeg":
+		return "jpeg"
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,192 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+func box(typ string, payloads ...[]byte) []byte {
+	body := bytes.Join(payloads, nil)
+	out := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(out, uint32(8+len(body)))
+	copy(out[4:], typ)
+	return append(out, body...)
+}
+
+func u32(values ...uint32) []byte {
+	out := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(out[i*4:], v)
+	}
+	return out
+}
+
+// timeHeader builds a version 0 mvhd/mdhd payload.
+func timeHeader(timescale, duration uint32) []byte {
+	return u32(0, 0, 0, timescale, duration)
+}
+
+func track(handler, codec string, tkhd, entry []byte, timescale, duration, samples uint32) []byte {
+	stsd := append(u32(0, 1), box(codec, entry)...)
+	return box("trak",
+		box("tkhd", tkhd),
+		box("mdia",
+			box("mdhd", timeHeader(timescale, duration)),
+			box("hdlr", u32(0, 0), []byte(handler), make([]byte, 12)),
+			box("minf", box("stbl",
+				box("stsd", stsd),
+				box("stts", u32(0, 1, samples, 1)),
+			)),
+		),
+	)
+}
+
+// testMP4 builds a minimal MP4 with an H.264 video track and an AAC track.
+func testMP4(width, height uint32, seconds uint32, moovFirst bool) []byte {
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], width<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], height<<16)
+
+	audioEntry := make([]byte, 28)
+	binary.BigEndian.PutUint16(audioEntry[16:], 2)         // channels
+	binary.BigEndian.PutUint32(audioEntry[24:], 44100<<16) // sample rate
+
+	moov := box("moov",
+		box("mvhd", timeHeader(1000, seconds*1000), make([]byte, 80)),
+		track("vide", "avc1", tkhd, make([]byte, 70), 30, seconds*30, seconds*30),
+		track("soun", "mp4a", make([]byte, 84), audioEntry, 44100, seconds*44100, seconds*43),
+	)
+	ftyp := box("ftyp", []byte("isom"), u32(512), []byte("isomiso2avc1mp41"))
+	mdat := box("mdat", make([]byte, 4096))
+
+	if moovFirst {
+		return bytes.Join([][]byte{ftyp, moov, mdat}, nil)
+	}
+	return bytes.Join([][]byte{ftyp, mdat, moov}, nil)
+}
+
+func writeTemp(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInspect_MP4(t *testing.T) {
+	path := writeTemp(t, "clip.mp4", testMP4(1080, 1920, 12, true))
+
+	info, err := Inspect(context.Background(), nil, path)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if info.MediaType != "VIDEO" || info.Format != "mp4" || info.ContentType != "video/mp4" {
+		t.Errorf("unexpected type: %+v", info)
+	}
+	if info.Width != 1080 || info.Height != 1920 || info.Duration != 12 || info.FrameRate != 30 {
+		t.Errorf("unexpected video properties: %+v", info)
+	}
+	if info.VideoCodec != "h264" || info.AudioCodec != "aac" || info.AudioChannels != 2 || info.AudioSampleRate != 44100 {
+		t.Errorf("unexpected codecs: %+v", info)
+	}
+	if info.MetadataAtEnd {
+		t.Error("expected fast-start file")
+	}
+	if v := ThreadsLimits.Check(info, "video"); len(v) != 0 {
+		t.Errorf("expected no violations, got %+v", v)
+	}
+}
+
+func TestInspect_MP4MetadataAtEnd(t *testing.T) {
+	data := testMP4(2560, 1440, 400, false)
+
+	// Local files are seekable, so the trailing moov is still parsed.
+	info, err := Inspect(context.Background(), nil, writeTemp(t, "clip.mp4", data))
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if !info.MetadataAtEnd || info.VideoCodec != "h264" {
+		t.Fatalf("expected trailing moov to be parsed, got %+v", info)
+	}
+
+	fields := map[string]bool{}
+	for _, v := range ThreadsLimits.Check(info, "video") {
+		fields[v.Field] = true
+	}
+	for _, want := range []string{"container", "width", "duration"} {
+		if !fields[want] {
+			t.Errorf("expected a %s violation, got %v", want, fields)
+		}
+	}
+}
+
+func TestChecker_URL(t *testing.T) {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewGray(image.Rect(0, 0, 20, 300))); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tall.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(img.Bytes())
+		case "/busy.png":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewChecker(server.Client())
+
+	err := checker.CheckMedia(context.Background(), server.URL+"/tall.png", "image")
+	var valErr *api.ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "aspect_ratio" {
+		t.Fatalf("expected aspect_ratio ValidationError, got %v", err)
+	}
+
+	err = checker.CheckMedia(context.Background(), server.URL+"/missing.png", "image")
+	if !errors.As(err, &valErr) || valErr.Field != "media_url" {
+		t.Fatalf("expected media_url ValidationError, got %v", err)
+	}
+
+	err = checker.CheckMedia(context.Background(), server.URL+"/tall.png", "video")
+	if !errors.As(err, &valErr) || valErr.Field != "media_type" {
+		t.Fatalf("expected media_type ValidationError, got %v", err)
+	}
+}
+
+func TestChecker_TransientFailuresAreRetryable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	checker := NewChecker(server.Client())
+
+	var netErr *api.NetworkError
+	err := checker.CheckMedia(context.Background(), server.URL+"/photo.png", "image")
+	if !errors.As(err, &netErr) || !netErr.Temporary || api.IsValidationError(err) {
+		t.Fatalf("expected a temporary NetworkError for HTTP 502, got %v", err)
+	}
+
+	// Nothing is listening once the server is closed
+	url := server.URL + "/photo.png"
+	server.Close()
+	err = checker.CheckMedia(context.Background(), url, "image")
+	if !errors.As(err, &netErr) || !netErr.Temporary {
+		t.Fatalf("expected a temporary NetworkError for a refused connection, got %v", err)
+	}
+}
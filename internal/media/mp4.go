@@ -0,0 +1,272 @@
+package media
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxMoovSize bounds how much of a moov atom is read into memory.
+const maxMoovSize = 64 << 20
+
+// parseMP4 walks the top-level boxes of an MP4/QuickTime file and fills info
+// from the ftyp and moov boxes. Media data is skipped by seeking when seeker
+// is set; otherwise parsing stops at the first mdat box that precedes moov.
+func parseMP4(br *bufio.Reader, src io.Reader, seeker io.Seeker, info *Info) error {
+	var offset int64
+	for {
+		typ, size, headerLen, err := readBoxHeader(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read video header: %w", err)
+		}
+		offset += headerLen
+
+		switch typ {
+		case "ftyp":
+			payload, errRead := readPayload(br, size, 4<<10)
+			if errRead != nil {
+				return errRead
+			}
+			if len(payload) >= 4 && string(payload[:4]) == "qt  " {
+				info.Format = "mov"
+			}
+			offset += size
+			continue
+		case "moov":
+			payload, errRead := readPayload(br, size, maxMoovSize)
+			if errRead != nil {
+				return errRead
+			}
+			parseMoov(payload, info)
+			return nil
+		case "mdat":
+			info.MetadataAtEnd = true
+			if seeker == nil {
+				return nil
+			}
+		}
+
+		if size < 0 {
+			return nil
+		}
+		if seeker != nil && size > int64(br.Buffered()) {
+			if _, err := seeker.Seek(offset+size, io.SeekStart); err != nil {
+				return err
+			}
+			br.Reset(src)
+		} else if _, err := br.Discard(int(size)); err != nil {
+			return nil //nolint:nilerr // Truncated trailing box; keep what was parsed
+		}
+		offset += size
+	}
+}
+
+// readBoxHeader returns a box's type, payload size (-1 if the box runs to
+// the end of the file), and header length.
+func readBoxHeader(r io.Reader) (string, int64, int64, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return "", 0, 0, io.EOF
+		}
+		return "", 0, 0, err
+	}
+	size := int64(binary.BigEndian.Uint32(header[:4]))
+	typ := string(header[4:8])
+
+	switch size {
+	case 0:
+		return typ, -1, 8, nil
+	case 1:
+		var large [8]byte
+		if _, err := io.ReadFull(r, large[:]); err != nil {
+			return "", 0, 0, err
+		}
+		size = int64(binary.BigEndian.Uint64(large[:])) //nolint:gosec // Box sizes beyond int64 are not valid files
+		if size < 16 {
+			return "", 0, 0, fmt.Errorf("invalid %q box size", typ)
+		}
+		return typ, size - 16, 16, nil
+	default:
+		if size < 8 {
+			return "", 0, 0, fmt.Errorf("invalid %q box size", typ)
+		}
+		return typ, size - 8, 8, nil
+	}
+}
+
+func readPayload(r io.Reader, size, limit int64) ([]byte, error) {
+	if size < 0 || size > limit {
+		return nil, fmt.Errorf("video header box is too large to inspect (%d bytes)", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("failed to read video header: %w", err)
+	}
+	return payload, nil
+}
+
+// eachBox calls fn for every complete child box in buf.
+func eachBox(buf []byte, fn func(typ string, payload []byte)) {
+	for len(buf) >= 8 {
+		size := int(binary.BigEndian.Uint32(buf[:4]))
+		typ := string(buf[4:8])
+		header := 8
+		switch size {
+		case 0:
+			size = len(buf)
+		case 1:
+			if len(buf) < 16 {
+				return
+			}
+			size = int(binary.BigEndian.Uint64(buf[8:16])) //nolint:gosec // Bounds are checked below
+			header = 16
+		}
+		if size < header || size > len(buf) {
+			return
+		}
+		fn(typ, buf[header:size])
+		buf = buf[size:]
+	}
+}
+
+// mp4Track is what parseTrak extracts from one trak box.
+type mp4Track struct {
+	handler    string
+	codec      string
+	width      int
+	height     int
+	timescale  uint32
+	duration   uint64
+	samples    uint64
+	sampleRate int
+	channels   int
+}
+
+func parseMoov(moov []byte, info *Info) {
+	eachBox(moov, func(typ string, payload []byte) {
+		switch typ {
+		case "mvhd":
+			if timescale, duration, ok := parseTimeHeader(payload); ok && timescale > 0 {
+				info.Duration = float64(duration) / float64(timescale)
+			}
+		case "trak":
+			track := parseTrak(payload)
+			switch track.handler {
+			case "vide":
+				if info.VideoCodec != "" {
+					return
+				}
+				info.VideoCodec = track.codec
+				info.Width, info.Height = track.width, track.height
+				if track.timescale > 0 && track.duration > 0 {
+					info.FrameRate = float64(track.samples) / (float64(track.duration) / float64(track.timescale))
+				}
+			case "soun":
+				if info.AudioCodec != "" {
+					return
+				}
+				info.AudioCodec = track.codec
+				info.AudioSampleRate = track.sampleRate
+				info.AudioChannels = track.channels
+			}
+		}
+	})
+}
+
+func parseTrak(trak []byte) mp4Track {
+	var track mp4Track
+	eachBox(trak, func(typ string, payload []byte) {
+		switch typ {
+		case "tkhd":
+			// Width and height are 16.16 fixed point after the matrix.
+			at := 76
+			if len(payload) > 0 && payload[0] == 1 {
+				at = 88
+			}
+			if len(payload) >= at+8 {
+				track.width = int(binary.BigEndian.Uint32(payload[at:]) >> 16)
+				track.height = int(binary.BigEndian.Uint32(payload[at+4:]) >> 16)
+			}
+		case "mdia":
+			eachBox(payload, func(typ string, payload []byte) {
+				switch typ {
+				case "mdhd":
+					track.timescale, track.duration, _ = parseTimeHeader(payload)
+				case "hdlr":
+					if len(payload) >= 12 {
+						track.handler = string(payload[8:12])
+					}
+				case "minf":
+					eachBox(payload, func(typ string, payload []byte) {
+						if typ == "stbl" {
+							parseSampleTable(payload, &track)
+						}
+					})
+				}
+			})
+		}
+	})
+	return track
+}
+
+func parseSampleTable(stbl []byte, track *mp4Track) {
+	eachBox(stbl, func(typ string, payload []byte) {
+		switch typ {
+		case "stsd":
+			// version/flags, entry count, then the first sample entry.
+			if len(payload) < 16 {
+				return
+			}
+			entry := payload[8:]
+			track.codec = codecName(string(entry[4:8]))
+			if track.handler == "soun" && len(entry) >= 36 {
+				track.channels = int(binary.BigEndian.Uint16(entry[24:]))
+				track.sampleRate = int(binary.BigEndian.Uint32(entry[32:]) >> 16)
+			}
+		case "stts":
+			if len(payload) < 8 {
+				return
+			}
+			count := int(binary.BigEndian.Uint32(payload[4:]))
+			for i := 0; i < count && len(payload) >= 16+i*8; i++ {
+				track.samples += uint64(binary.BigEndian.Uint32(payload[8+i*8:]))
+			}
+		}
+	})
+}
+
+// parseTimeHeader reads the timescale and duration from an mvhd or mdhd box.
+func parseTimeHeader(payload []byte) (uint32, uint64, bool) {
+	if len(payload) == 0 {
+		return 0, 0, false
+	}
+	if payload[0] == 1 {
+		if len(payload) < 32 {
+			return 0, 0, false
+		}
+		return binary.BigEndian.Uint32(payload[20:]), binary.BigEndian.Uint64(payload[24:]), true
+	}
+	if len(payload) < 20 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint32(payload[12:]), uint64(binary.BigEndian.Uint32(payload[16:])), true
+}
+
+func codecName(fourcc string) string {
+	switch fourcc {
+	case "avc1", "avc3":
+		return "h264"
+	case "hvc1", "hev1":
+		return "hevc"
+	case "mp4a":
+		return "aac"
+	default:
+		return strings.TrimSpace(fourcc)
+	}
+}
@@ -0,0 +1,296 @@
+package media
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+
+	// presignExpiry covers the lifetime of an unpublished Threads container.
+	presignExpiry = 24 * time.Hour
+)
+
+// S3Config configures an S3Uploader.
+type S3Config struct {
+	// Endpoint is the service URL, e.g. https://s3.us-east-1.amazonaws.com
+	// or http://localhost:9000 for MinIO. Defaults to AWS for Region.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// PathStyle addresses objects as endpoint/bucket/key instead of
+	// bucket.endpoint/key. Most self-hosted servers need this.
+	PathStyle bool
+	// PublicURL is the base (or {key} template) of the URL Threads fetches,
+	// e.g. a CDN in front of the bucket.
+	PublicURL string
+	// Presign returns a presigned GET URL instead of the plain object URL,
+	// for buckets that are not publicly readable.
+	Presign bool
+}
+
+// S3Uploader uploads files to S3-compatible object storage using AWS
+// Signature Version 4.
+type S3Uploader struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+var _ Uploader = (*S3Uploader)(nil)
+
+// NewS3Uploader returns an uploader for cfg.
+func NewS3Uploader(cfg S3Config, client *http.Client) (*S3Uploader, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("media host bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("media host credentials are required (access_key_id and secret_access_key, or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	if _, err := url.Parse(cfg.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid media host endpoint: %w", err)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &S3Uploader{cfg: cfg, client: client, now: time.Now}, nil
+}
+
+// Upload implements Uploader.
+func (u *S3Uploader) Upload(ctx context.Context, obj *Object) (*Uploaded, error) {
+	key := u.cfg.Prefix + obj.Key
+	objectURL, err := u.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), obj.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = obj.Size
+	req.Header.Set("Content-Type", obj.ContentType)
+	u.sign(req)
+
+	if err := u.do(req); err != nil {
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+
+	uploaded := &Uploaded{Key: key, URL: objectURL.String()}
+	switch {
+	case u.cfg.PublicURL != "":
+		uploaded.URL = joinURL(u.cfg.PublicURL, key)
+	case u.cfg.Presign:
+		uploaded.URL = u.presign(objectURL, presignExpiry)
+	}
+	return uploaded, nil
+}
+
+// Delete implements Uploader.
+func (u *S3Uploader) Delete(ctx context.Context, key string) error {
+	objectURL, err := u.objectURL(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, objectURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	u.sign(req)
+
+	if err := u.do(req); err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+	return nil
+}
+
+func (u *S3Uploader) do(req *http.Request) error {
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Body is only used for error details
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10)) //nolint:errcheck // Body is only used for error details
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// objectURL returns the path-style or virtual-hosted-style URL for key.
+func (u *S3Uploader) objectURL(key string) (*url.URL, error) {
+	endpoint, err := url.Parse(u.cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = uriEncode(s)
+	}
+	escapedKey := strings.Join(segments, "/")
+
+	objectURL := &url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host}
+	base := strings.TrimRight(endpoint.Path, "/")
+	if u.cfg.PathStyle {
+		objectURL.Path = base + "/" + u.cfg.Bucket + "/" + key
+		objectURL.RawPath = base + "/" + uriEncode(u.cfg.Bucket) + "/" + escapedKey
+	} else {
+		objectURL.Host = u.cfg.Bucket + "." + endpoint.Host
+		objectURL.Path = base + "/" + key
+		objectURL.RawPath = base + "/" + escapedKey
+	}
+	return objectURL, nil
+}
+
+// sign adds SigV4 headers to req. The payload is sent unsigned so files can
+// be streamed without hashing them first.
+func (u *S3Uploader) sign(req *http.Request) {
+	now := u.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	if u.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", u.cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := u.scope(now)
+	signature := u.signature(now, amzDate, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, u.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// presign returns a GET URL for objectURL that is valid for expiry.
+func (u *S3Uploader) presign(objectURL *url.URL, expiry time.Duration) string {
+	now := u.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := u.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", u.cfg.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", fmt.Sprintf("%d", int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if u.cfg.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", u.cfg.SessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		objectURL.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + objectURL.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	signed := *objectURL
+	signed.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + u.signature(now, amzDate, scope, canonicalRequest)
+	return signed.String()
+}
+
+func (u *S3Uploader) scope(now time.Time) string {
+	return fmt.Sprintf("%s/%s/s3/aws4_request", now.Format("20060102"), u.cfg.Region)
+}
+
+func (u *S3Uploader) signature(now time.Time, amzDate, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hex.EncodeToString(hash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+u.cfg.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, u.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data)) //nolint:errcheck,gosec // hash.Hash writes never fail
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query sorted by key, as SigV4 requires.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything except RFC 3986 unreserved characters.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,173 @@
+// Package media uploads local media files to a user-configured host so they
+// can be referenced by URL when creating Threads media containers.
+package media
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+)
+
+// Uploader stores media files and returns a public URL for them.
+type Uploader interface {
+	// Upload stores obj and returns where Threads can fetch it.
+	Upload(ctx context.Context, obj *Object) (*Uploaded, error)
+	// Delete removes a previously uploaded object by key.
+	Delete(ctx context.Context, key string) error
+}
+
+// Object is a file to upload.
+type Object struct {
+	Key         string
+	Body        io.Reader
+	Size        int64
+	ContentType string
+}
+
+// Uploaded describes a stored object.
+type Uploaded struct {
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
+
+// IsLocal reports whether ref names a local file rather than a URL.
+func IsLocal(ref string) bool {
+	return ref != "" && !strings.HasPrefix(ref, "http://") && !strings.HasPrefix(ref, "https://")
+}
+
+// NewUploader builds the uploader described by host. client may be nil.
+func NewUploader(host *config.MediaHost, client *http.Client) (Uploader, error) {
+	if host == nil {
+		return nil, fmt.Errorf("no media host configured")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Minute}
+	}
+
+	switch host.Type {
+	case "s3":
+		return NewS3Uploader(S3Config{
+			Endpoint:        host.Endpoint,
+			Region:          firstNonEmpty(host.Region, os.Getenv("AWS_REGION"), "us-east-1"),
+			Bucket:          host.Bucket,
+			Prefix:          host.Prefix,
+			PathStyle:       host.PathStyle,
+			AccessKeyID:     firstNonEmpty(os.ExpandEnv(host.AccessKeyID), os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: firstNonEmpty(os.ExpandEnv(host.SecretAccessKey), os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:    firstNonEmpty(os.ExpandEnv(host.SessionToken), os.Getenv("AWS_SESSION_TOKEN")),
+			PublicURL:       host.PublicURL,
+			Presign:         host.Presign,
+		}, client)
+	case "http":
+		headers := make(map[string]string, len(host.Headers))
+		for k, v := range host.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		return NewHTTPUploader(HTTPConfig{
+			UploadURL: host.UploadURL,
+			PublicURL: host.PublicURL,
+			Headers:   headers,
+		}, client)
+	default:
+		return nil, fmt.Errorf("unknown media host type %q (expected s3 or http)", host.Type)
+	}
+}
+
+// UploadFile uploads the local file at path under a unique key.
+func UploadFile(ctx context.Context, u Uploader, path string) (*Uploaded, error) {
+	file, err := os.Open(path) //nolint:gosec // The path is chosen by the local user running the CLI.
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() //nolint:errcheck // Read-only file
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	contentType, err := detectContentType(file, path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := objectKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.Upload(ctx, &Object{
+		Key:         key,
+		Body:        file,
+		Size:        info.Size(),
+		ContentType: contentType,
+	})
+}
+
+// detectContentType uses the file extension, falling back to sniffing the
+// first bytes. The file is rewound afterwards.
+func detectContentType(file *os.File, path string) (string, error) {
+	if ct := mime.TypeByExtension(strings.ToLower(filepath.Ext(path))); ct != "" {
+		return ct, nil
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// objectKey returns a collision-resistant key that keeps the file name
+// readable, e.g. "20250115-a1b2c3d4-photo.jpg".
+func objectKey(path string) (string, error) {
+	var random [4]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", err
+	}
+
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, filepath.Base(path))
+
+	return fmt.Sprintf("%s-%s-%s", time.Now().UTC().Format("20060102"), hex.EncodeToString(random[:]), base), nil
+}
+
+// joinURL appends key to base, or substitutes it for {key} when present.
+func joinURL(base, key string) string {
+	if strings.Contains(base, "{key}") {
+		return strings.ReplaceAll(base, "{key}", key)
+	}
+	return strings.TrimRight(base, "/") + "/" + key
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
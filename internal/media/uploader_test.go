@@ -0,0 +1,81 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+)
+
+func TestIsLocal(t *testing.T) {
+	tests := map[string]bool{
+		"":                          false,
+		"https://example.com/a.jpg": false,
+		"http://example.com/a.jpg":  false,
+		"./photo.jpg":               true,
+		"/tmp/video.mp4":            true,
+	}
+	for ref, want := range tests {
+		if got := IsLocal(ref); got != want {
+			t.Errorf("IsLocal(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
+
+func TestUploadFile_HTTPHost(t *testing.T) {
+	var gotKey, gotType, gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = strings.TrimPrefix(r.URL.Path, "/upload/")
+		gotType = r.Header.Get("Content-Type")
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		_ = json.NewEncoder(w).Encode(map[string]string{"url": "https://files.example.com/" + gotKey})
+	}))
+	defer server.Close()
+
+	t.Setenv("MEDIA_TOKEN", "secret")
+	u, err := NewUploader(&config.MediaHost{
+		Type:      "http",
+		UploadURL: server.URL + "/upload/{key}",
+		Headers:   map[string]string{"Authorization": "Bearer ${MEDIA_TOKEN}"},
+	}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "my photo.png")
+	if err := os.WriteFile(path, []byte("png-bytes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	up, err := UploadFile(context.Background(), u, path)
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if !strings.HasSuffix(gotKey, "-my-photo.png") || up.Key != gotKey {
+		t.Errorf("unexpected key %q (result %+v)", gotKey, up)
+	}
+	if gotType != "image/png" || gotAuth != "Bearer secret" || gotBody != "png-bytes" {
+		t.Errorf("unexpected request: type=%q auth=%q body=%q", gotType, gotAuth, gotBody)
+	}
+	if up.URL != "https://files.example.com/"+gotKey {
+		t.Errorf("expected URL from the upload response, got %s", up.URL)
+	}
+}
+
+func TestNewUploader_RejectsUnknownType(t *testing.T) {
+	if _, err := NewUploader(&config.MediaHost{Type: "ftp"}, nil); err == nil {
+		t.Error("expected error for unknown media host type")
+	}
+	if _, err := NewUploader(nil, nil); err == nil {
+		t.Error("expected error for missing media host")
+	}
+}
@@ -279,43 +279,85 @@ func writeFilteredJSON(data any, query string) error {
 }
 
 func writeFilteredJSONTo(w io.Writer, data any, query string) error {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return err
+	}
+
+	results, err := q.Run(data)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	for _, v := range results {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query is a compiled jq expression, as accepted by --query.
+type Query struct {
+	code *gojq.Code
+}
+
+// ParseQuery parses and compiles a jq expression.
+func ParseQuery(query string) (*Query, error) {
 	q, err := gojq.Parse(query)
 	if err != nil {
-		return fmt.Errorf("invalid jq query: %w", err)
+		return nil, fmt.Errorf("invalid jq query: %w", err)
 	}
 
 	code, err := gojq.Compile(q)
 	if err != nil {
-		return fmt.Errorf("failed to compile jq query: %w", err)
+		return nil, fmt.Errorf("failed to compile jq query: %w", err)
 	}
+	return &Query{code: code}, nil
+}
 
-	// Convert data to interface{} for gojq
+// Run evaluates the query against data, which is first converted to its
+// generic JSON form, and returns every value it produces.
+func (q *Query) Run(data any) ([]any, error) {
 	jsonBytes, err := json.Marshal(data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var input any
 	if err := json.Unmarshal(jsonBytes, &input); err != nil {
-		return err
+		return nil, err
 	}
 
-	iter := code.Run(input)
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-
+	var results []any
+	iter := q.code.Run(input)
 	for {
 		v, ok := iter.Next()
 		if !ok {
 			break
 		}
 		if err, ok := v.(error); ok {
-			return err
+			return nil, err
 		}
-		if err := enc.Encode(v); err != nil {
-			return err
+		results = append(results, v)
+	}
+	return results, nil
+}
+
+// Match reports whether the query produces at least one value other than
+// false or null for data, the way jq's select() treats its argument.
+func (q *Query) Match(data any) (bool, error) {
+	results, err := q.Run(data)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range results {
+		if v != nil && v != false {
+			return true, nil
 		}
 	}
-	return nil
+	return false, nil
 }
 
 // OutputOption configures the Formatter
@@ -486,77 +528,23 @@ func rowsToObjects(headers []string, rows [][]string) []map[string]string {
 
 // writeFilteredJSONTo applies JQ filter and writes to output
 func (f *Formatter) writeFilteredJSONTo(data any, query string) error {
-	q, err := gojq.Parse(query)
-	if err != nil {
-		return fmt.Errorf("invalid jq query: %w", err)
-	}
-
-	code, err := gojq.Compile(q)
-	if err != nil {
-		return fmt.Errorf("failed to compile jq query: %w", err)
-	}
-
-	// Convert data to interface{} for gojq
-	jsonBytes, err := json.Marshal(data)
-	if err != nil {
-		return err
-	}
-	var input any
-	if err := json.Unmarshal(jsonBytes, &input); err != nil {
-		return err
-	}
-
-	iter := code.Run(input)
-	enc := json.NewEncoder(f.out)
-	enc.SetIndent("", "  ")
-
-	for {
-		v, ok := iter.Next()
-		if !ok {
-			break
-		}
-		if err, ok := v.(error); ok {
-			return err
-		}
-		if err := enc.Encode(v); err != nil {
-			return err
-		}
-	}
-	return nil
+	return writeFilteredJSONTo(f.out, data, query)
 }
 
 // writeFilteredJSONLinesTo applies JQ filter and writes each result as a JSON line (JSONL).
 func (f *Formatter) writeFilteredJSONLinesTo(data any, query string) error {
-	q, err := gojq.Parse(query)
-	if err != nil {
-		return fmt.Errorf("invalid jq query: %w", err)
-	}
-
-	code, err := gojq.Compile(q)
+	q, err := ParseQuery(query)
 	if err != nil {
-		return fmt.Errorf("failed to compile jq query: %w", err)
+		return err
 	}
 
-	jsonBytes, err := json.Marshal(data)
+	results, err := q.Run(data)
 	if err != nil {
 		return err
 	}
-	var input any
-	if err := json.Unmarshal(jsonBytes, &input); err != nil {
-		return err
-	}
 
-	iter := code.Run(input)
 	enc := json.NewEncoder(f.out)
-
-	for {
-		v, ok := iter.Next()
-		if !ok {
-			break
-		}
-		if err, ok := v.(error); ok {
-			return err
-		}
+	for _, v := range results {
 		if err := enc.Encode(v); err != nil {
 			return err
 		}
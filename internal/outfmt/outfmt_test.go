@@ -898,3 +898,36 @@ func TestColorEnabled_WithFile(t *testing.T) {
 		t.Error("colorEnabled should return false for non-TTY file")
 	}
 }
+
+func TestQueryMatch(t *testing.T) {
+	data := map[string]any{"type": "mentions", "count": 2, "tags": []string{"a"}}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`.type == "mentions"`, true},
+		{`.type == "deletes"`, false},
+		{`.missing`, false},
+		{`.count`, true},
+		{`.tags[] | select(. == "b")`, false},
+		{`empty`, false},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+		}
+		got, err := q.Match(data)
+		if err != nil {
+			t.Fatalf("Match(%q): %v", tt.query, err)
+		}
+		if got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if _, err := ParseQuery(".["); err == nil {
+		t.Error("expected parse error")
+	}
+}
@@ -0,0 +1,328 @@
+// Package quota keeps a local ledger of posts, replies and deletes so the
+// CLI can tell when an action would exceed Threads' rolling 24-hour
+// publishing quotas before it is sent.
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+const fileName = "quota.json"
+
+// Window is the rolling period Threads publishing quotas apply to.
+const Window = 24 * time.Hour
+
+// Actions lists the tracked quota actions in display order.
+var Actions = []api.QuotaAction{api.QuotaPost, api.QuotaReply, api.QuotaDelete}
+
+// DefaultLimits are the documented quotas per rolling window, used until the
+// ledger is reconciled against the API.
+var DefaultLimits = map[api.QuotaAction]int{
+	api.QuotaPost:   250,
+	api.QuotaReply:  1000,
+	api.QuotaDelete: 25,
+}
+
+// Entry is one recorded action.
+type Entry struct {
+	Action api.QuotaAction `json:"action"`
+	ID     string          `json:"id,omitempty"`
+	At     time.Time       `json:"at"`
+}
+
+// Snapshot is the usage and limits reported by the API at a point in time.
+type Snapshot struct {
+	At     time.Time               `json:"at"`
+	Usage  map[api.QuotaAction]int `json:"usage"`
+	Limits map[api.QuotaAction]int `json:"limits"`
+}
+
+// Usage is the quota state of one action over the rolling window.
+type Usage struct {
+	Action    api.QuotaAction `json:"action"`
+	Used      int             `json:"used"`
+	Limit     int             `json:"limit"`
+	Remaining int             `json:"remaining"`
+	// Recorded is how many of Used were recorded locally.
+	Recorded int `json:"recorded"`
+	// NextSlotAt is when the oldest counted action leaves the window.
+	NextSlotAt *time.Time `json:"next_slot_at,omitempty"`
+}
+
+// account holds one account's recorded entries and last reconciliation.
+type account struct {
+	Entries    []Entry   `json:"entries"`
+	Reconciled *Snapshot `json:"reconciled,omitempty"`
+}
+
+// Ledger is a JSON file recording quota usage for every account, keyed by
+// Threads user ID.
+type Ledger struct {
+	path string
+	now  func() time.Time
+}
+
+// DefaultPath returns the default ledger location under the data directory.
+func DefaultPath() string {
+	return filepath.Join(config.DataDir(), fileName)
+}
+
+// NewLedger returns a ledger backed by the file at path.
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path, now: time.Now}
+}
+
+// Path returns the file backing the ledger.
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Check implements api.QuotaLedger. It returns an *api.QuotaError when
+// userID has no quota left for action.
+func (l *Ledger) Check(userID string, action api.QuotaAction) error {
+	usage, err := l.usage(userID, action)
+	if err != nil {
+		return err
+	}
+	if usage.Remaining > 0 {
+		return nil
+	}
+
+	retryAt := l.now().Add(Window)
+	if usage.NextSlotAt != nil {
+		retryAt = *usage.NextSlotAt
+	}
+	return api.NewQuotaError(action, usage.Used, usage.Limit, retryAt)
+}
+
+// Record implements api.QuotaLedger.
+func (l *Ledger) Record(userID string, action api.QuotaAction, id string) error {
+	return l.update(func(accounts map[string]*account) {
+		a := accountFor(accounts, userID)
+		a.Entries = append(a.Entries, Entry{Action: action, ID: id, At: l.now()})
+	})
+}
+
+// Reconcile stores the usage and limits reported by the API. Until the
+// snapshot leaves the window, usage is never counted below what the API
+// reported plus what was recorded since, so actions taken outside the CLI
+// are accounted for.
+func (l *Ledger) Reconcile(userID string, limits *api.PublishingLimits) error {
+	if limits == nil {
+		return nil
+	}
+
+	return l.update(func(accounts map[string]*account) {
+		a := accountFor(accounts, userID)
+		a.Reconciled = &Snapshot{
+			At: l.now(),
+			Usage: map[api.QuotaAction]int{
+				api.QuotaPost:   limits.QuotaUsage,
+				api.QuotaReply:  limits.ReplyQuotaUsage,
+				api.QuotaDelete: limits.DeleteQuotaUsage,
+			},
+			Limits: map[api.QuotaAction]int{
+				api.QuotaPost:   limits.Config.QuotaTotal,
+				api.QuotaReply:  limits.ReplyConfig.QuotaTotal,
+				api.QuotaDelete: limits.DeleteConfig.QuotaTotal,
+			},
+		}
+	})
+}
+
+// Usage returns userID's usage of every action over the rolling window.
+func (l *Ledger) Usage(userID string) ([]Usage, error) {
+	accounts, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+
+	a := accounts[userID]
+	now := l.now()
+	usage := make([]Usage, 0, len(Actions))
+	for _, action := range Actions {
+		usage = append(usage, a.usage(action, now))
+	}
+	return usage, nil
+}
+
+// History returns userID's recorded entries within the rolling window,
+// newest first.
+func (l *Ledger) History(userID string) ([]Entry, error) {
+	accounts, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	a, ok := accounts[userID]
+	if !ok {
+		return entries, nil
+	}
+	cutoff := l.now().Add(-Window)
+	for _, e := range a.Entries {
+		if e.At.After(cutoff) {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].At.After(entries[j].At)
+	})
+	return entries, nil
+}
+
+// ReconciledAt returns when userID's ledger was last reconciled, or the
+// zero time if it never was.
+func (l *Ledger) ReconciledAt(userID string) (time.Time, error) {
+	accounts, err := l.load()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if a, ok := accounts[userID]; ok && a.Reconciled != nil {
+		return a.Reconciled.At, nil
+	}
+	return time.Time{}, nil
+}
+
+func (l *Ledger) usage(userID string, action api.QuotaAction) (Usage, error) {
+	accounts, err := l.load()
+	if err != nil {
+		return Usage{}, err
+	}
+	return accounts[userID].usage(action, l.now()), nil
+}
+
+// usage counts action over the window ending at now. a may be nil.
+func (a *account) usage(action api.QuotaAction, now time.Time) Usage {
+	cutoff := now.Add(-Window)
+	u := Usage{Action: action, Limit: DefaultLimits[action]}
+
+	var recorded []time.Time
+	var snap *Snapshot
+	if a != nil {
+		for _, e := range a.Entries {
+			if e.Action == action && e.At.After(cutoff) {
+				recorded = append(recorded, e.At)
+			}
+		}
+		if a.Reconciled != nil && a.Reconciled.At.After(cutoff) {
+			snap = a.Reconciled
+		}
+	}
+	sort.Slice(recorded, func(i, j int) bool { return recorded[i].Before(recorded[j]) })
+	u.Recorded = len(recorded)
+	u.Used = len(recorded)
+
+	// The API's count covers everything up to the snapshot; add what was
+	// recorded since.
+	fromServer := false
+	if snap != nil {
+		if limit := snap.Limits[action]; limit > 0 {
+			u.Limit = limit
+		}
+		used := snap.Usage[action]
+		for _, at := range recorded {
+			if at.After(snap.At) {
+				used++
+			}
+		}
+		if used > u.Used {
+			u.Used = used
+			fromServer = true
+		}
+	}
+
+	u.Remaining = max(u.Limit-u.Used, 0)
+	switch {
+	case u.Used == 0:
+	case fromServer:
+		// The API does not say when its counted actions happened, only that
+		// they are all older than the snapshot.
+		next := snap.At.Add(Window)
+		u.NextSlotAt = &next
+	default:
+		// Once full, a slot frees when enough of the oldest entries expire.
+		i := 0
+		if u.Used >= u.Limit {
+			i = u.Used - u.Limit
+		}
+		next := recorded[i].Add(Window)
+		u.NextSlotAt = &next
+	}
+	return u
+}
+
+func accountFor(accounts map[string]*account, userID string) *account {
+	a, ok := accounts[userID]
+	if !ok || a == nil {
+		a = &account{}
+		accounts[userID] = a
+	}
+	return a
+}
+
+func (l *Ledger) load() (map[string]*account, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]*account{}, nil
+		}
+		return nil, fmt.Errorf("failed to read quota ledger: %w", err)
+	}
+
+	var accounts map[string]*account
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse quota ledger %s: %w", l.path, err)
+	}
+	if accounts == nil {
+		accounts = map[string]*account{}
+	}
+	return accounts, nil
+}
+
+// update applies fn to the loaded ledger and saves it, all under the
+// ledger's lock file so concurrent CLI processes never drop each other's
+// entries.
+func (l *Ledger) update(fn func(accounts map[string]*account)) error {
+	return fsutil.WithLock(l.path+".lock", func() error {
+		accounts, err := l.load()
+		if err != nil {
+			return err
+		}
+		fn(accounts)
+		return l.save(accounts)
+	})
+}
+
+// save drops entries that have left the window and writes the ledger
+// atomically so a crash mid-write never truncates it.
+func (l *Ledger) save(accounts map[string]*account) error {
+	cutoff := l.now().Add(-Window)
+	for _, a := range accounts {
+		kept := a.Entries[:0]
+		for _, e := range a.Entries {
+			if e.At.After(cutoff) {
+				kept = append(kept, e)
+			}
+		}
+		a.Entries = kept
+		if a.Reconciled != nil && !a.Reconciled.At.After(cutoff) {
+			a.Reconciled = nil
+		}
+	}
+
+	if err := fsutil.WriteJSON(l.path, accounts); err != nil {
+		return fmt.Errorf("failed to write quota ledger: %w", err)
+	}
+	return nil
+}
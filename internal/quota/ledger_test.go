@@ -0,0 +1,164 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+func newTestLedger(t *testing.T, now *time.Time) *Ledger {
+	t.Helper()
+	l := NewLedger(filepath.Join(t.TempDir(), "quota.json"))
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func usageFor(t *testing.T, l *Ledger, userID string, action api.QuotaAction) Usage {
+	t.Helper()
+	usage, err := l.Usage(userID)
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	for _, u := range usage {
+		if u.Action == action {
+			return u
+		}
+	}
+	t.Fatalf("no usage for %s", action)
+	return Usage{}
+}
+
+func TestLedger_RollingWindow(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestLedger(t, &now)
+	first := now
+
+	for i := 0; i < DefaultLimits[api.QuotaDelete]; i++ {
+		if err := l.Check("u1", api.QuotaDelete); err != nil {
+			t.Fatalf("delete %d refused: %v", i, err)
+		}
+		if err := l.Record("u1", api.QuotaDelete, "p"); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	err := l.Check("u1", api.QuotaDelete)
+	var quotaErr *api.QuotaError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("expected QuotaError, got %v", err)
+	}
+	if quotaErr.Used != 25 || quotaErr.Limit != 25 || !quotaErr.RetryAt.Equal(first.Add(Window)) {
+		t.Errorf("unexpected quota error: %+v", quotaErr)
+	}
+
+	// Other actions and accounts are unaffected.
+	if err := l.Check("u1", api.QuotaPost); err != nil {
+		t.Errorf("post refused: %v", err)
+	}
+	if err := l.Check("u2", api.QuotaDelete); err != nil {
+		t.Errorf("other account refused: %v", err)
+	}
+
+	// The oldest delete leaves the window after 24 hours.
+	now = first.Add(Window + time.Second)
+	if err := l.Check("u1", api.QuotaDelete); err != nil {
+		t.Errorf("expected a free slot, got %v", err)
+	}
+	if u := usageFor(t, l, "u1", api.QuotaDelete); u.Used != 24 || u.Remaining != 1 {
+		t.Errorf("unexpected usage: %+v", u)
+	}
+
+	// Saving prunes expired entries.
+	if err := l.Record("u1", api.QuotaPost, "p2"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := l.History("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 25 || entries[0].ID != "p2" {
+		t.Errorf("unexpected history: %d entries, newest %+v", len(entries), entries[0])
+	}
+}
+
+func TestLedger_Reconcile(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestLedger(t, &now)
+
+	if err := l.Record("u1", api.QuotaPost, "p1"); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	err := l.Reconcile("u1", &api.PublishingLimits{
+		QuotaUsage:       10,
+		Config:           api.QuotaConfig{QuotaTotal: 12, QuotaDuration: 86400},
+		ReplyQuotaUsage:  0,
+		ReplyConfig:      api.QuotaConfig{QuotaTotal: 1000, QuotaDuration: 86400},
+		DeleteQuotaUsage: 0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Posts made outside the CLI are counted, and the API's limit is used.
+	if u := usageFor(t, l, "u1", api.QuotaPost); u.Used != 10 || u.Limit != 12 || u.Recorded != 1 {
+		t.Errorf("unexpected usage after reconcile: %+v", u)
+	}
+	// A missing limit falls back to the default.
+	if u := usageFor(t, l, "u1", api.QuotaDelete); u.Limit != 25 {
+		t.Errorf("expected default delete limit, got %+v", u)
+	}
+
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := l.Record("u1", api.QuotaPost, "p"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = l.Check("u1", api.QuotaPost)
+	var quotaErr *api.QuotaError
+	if !errors.As(err, &quotaErr) || quotaErr.Used != 12 {
+		t.Fatalf("expected QuotaError at 12 posts, got %v", err)
+	}
+	// The API's counted posts are only known to be older than the snapshot.
+	if want := now.Add(-time.Minute).Add(Window); !quotaErr.RetryAt.Equal(want) {
+		t.Errorf("RetryAt = %v, want %v", quotaErr.RetryAt, want)
+	}
+
+	// Once the snapshot leaves the window only local entries count.
+	now = now.Add(Window)
+	if u := usageFor(t, l, "u1", api.QuotaPost); u.Used != 0 || u.Limit != 250 {
+		t.Errorf("expected expired snapshot to be ignored, got %+v", u)
+	}
+}
+
+func TestLedger_ConcurrentRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+
+	// Separate ledgers share nothing in memory, like two CLI processes.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := NewLedger(path).Record("u1", api.QuotaPost, fmt.Sprintf("p%d", i)); err != nil {
+				t.Errorf("Record failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	history, err := NewLedger(path).History("u1")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 10 {
+		t.Errorf("expected 10 entries, got %d", len(history))
+	}
+}
@@ -0,0 +1,481 @@
+// Package schedule implements a persistent, file-backed queue of posts to be
+// published at a later time.
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+const (
+	queueFileName = "schedule.json"
+
+	// staleLockAge is how long a run lock may go untouched before another
+	// runner assumes its owner crashed and takes it over.
+	staleLockAge = time.Hour
+)
+
+var (
+	// ErrNotFound is returned when a scheduled item does not exist.
+	ErrNotFound = errors.New("scheduled item not found")
+
+	// ErrNotPending is returned when an operation requires a pending item.
+	ErrNotPending = errors.New("scheduled item is not pending")
+
+	// ErrLocked is returned when another runner holds the queue lock.
+	ErrLocked = errors.New("schedule queue is locked by another runner")
+)
+
+// Kind identifies which payload a scheduled item carries.
+type Kind string
+
+const (
+	KindText     Kind = "text"
+	KindImage    Kind = "image"
+	KindVideo    Kind = "video"
+	KindCarousel Kind = "carousel"
+)
+
+// Status is the lifecycle state of a scheduled item.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPublished Status = "published"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// CarouselItem describes a single carousel child. Containers are created at
+// publish time because Threads expires unpublished containers after 24 hours.
+type CarouselItem struct {
+	MediaType string `json:"media_type"`
+	URL       string `json:"url"`
+	AltText   string `json:"alt_text,omitempty"`
+}
+
+// Payload holds the fully-formed post content for a scheduled item.
+// Exactly one of the content fields is set, matching Kind.
+type Payload struct {
+	Kind          Kind                     `json:"kind"`
+	Text          *api.TextPostContent     `json:"text,omitempty"`
+	Image         *api.ImagePostContent    `json:"image,omitempty"`
+	Video         *api.VideoPostContent    `json:"video,omitempty"`
+	Carousel      *api.CarouselPostContent `json:"carousel,omitempty"`
+	CarouselItems []CarouselItem           `json:"carousel_items,omitempty"`
+}
+
+// Validate checks that the payload's content matches its kind.
+func (p *Payload) Validate() error {
+	switch p.Kind {
+	case KindText:
+		if p.Text == nil {
+			return fmt.Errorf("text payload is missing content")
+		}
+	case KindImage:
+		if p.Image == nil {
+			return fmt.Errorf("image payload is missing content")
+		}
+	case KindVideo:
+		if p.Video == nil {
+			return fmt.Errorf("video payload is missing content")
+		}
+	case KindCarousel:
+		if p.Carousel == nil {
+			return fmt.Errorf("carousel payload is missing content")
+		}
+		if len(p.CarouselItems) < api.MinCarouselItems || len(p.CarouselItems) > api.MaxCarouselItems {
+			return fmt.Errorf("carousel payload must have %d-%d items, got %d",
+				api.MinCarouselItems, api.MaxCarouselItems, len(p.CarouselItems))
+		}
+	default:
+		return fmt.Errorf("unknown payload kind: %q", p.Kind)
+	}
+	return nil
+}
+
+// Summary returns a short human-readable description of the payload text.
+func (p *Payload) Summary() string {
+	switch p.Kind {
+	case KindText:
+		if p.Text != nil {
+			return p.Text.Text
+		}
+	case KindImage:
+		if p.Image != nil {
+			return p.Image.Text
+		}
+	case KindVideo:
+		if p.Video != nil {
+			return p.Video.Text
+		}
+	case KindCarousel:
+		if p.Carousel != nil {
+			return p.Carousel.Text
+		}
+	}
+	return ""
+}
+
+// Item is a single entry in the schedule queue.
+type Item struct {
+	ID            string     `json:"id"`
+	Account       string     `json:"account,omitempty"`
+	PublishAt     time.Time  `json:"publish_at"`
+	Payload       Payload    `json:"payload"`
+	Status        Status     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	PostID        string     `json:"post_id,omitempty"`
+	Permalink     string     `json:"permalink,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+}
+
+// IsDue reports whether a pending item should be attempted at now.
+func (i *Item) IsDue(now time.Time) bool {
+	if i.Status != StatusPending {
+		return false
+	}
+	if now.Before(i.PublishAt) {
+		return false
+	}
+	return !now.Before(i.NextAttemptAt)
+}
+
+// MarkPublished records a successful publish.
+func (i *Item) MarkPublished(post *api.Post, now time.Time) {
+	i.Status = StatusPublished
+	i.Attempts++
+	i.LastError = ""
+	i.PostID = post.ID
+	i.Permalink = post.Permalink
+	i.PublishedAt = &now
+	i.UpdatedAt = now
+}
+
+// MarkFailed records a failed publish attempt. Transient failures are
+// rescheduled according to policy until MaxAttempts is reached; permanent
+// failures move the item to StatusFailed immediately. retryAfter, when
+// positive, is used as a lower bound for the next delay.
+func (i *Item) MarkFailed(err error, transient bool, retryAfter time.Duration, policy RetryPolicy, now time.Time) {
+	i.Attempts++
+	i.LastError = err.Error()
+	i.UpdatedAt = now
+
+	if !transient || i.Attempts >= policy.MaxAttempts {
+		i.Status = StatusFailed
+		return
+	}
+
+	delay := policy.Delay(i.Attempts)
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	i.NextAttemptAt = now.Add(delay)
+}
+
+// Defer postpones a pending item until the given time without counting an
+// attempt, for example when publishing now would exceed the account's quota.
+func (i *Item) Defer(reason error, until, now time.Time) {
+	i.LastError = reason.Error()
+	i.NextAttemptAt = until
+	i.UpdatedAt = now
+}
+
+// RetryPolicy controls how transient publish failures are retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used by 'threads schedule run'.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Minute,
+		MaxDelay:    30 * time.Minute,
+	}
+}
+
+// Delay returns the backoff delay after the given number of attempts.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := p.BaseDelay
+	for n := 1; n < attempts; n++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Queue is a JSON file containing scheduled items.
+type Queue struct {
+	path string
+	now  func() time.Time
+}
+
+// DefaultPath returns the default queue location under the data directory.
+func DefaultPath() string {
+	return filepath.Join(config.DataDir(), queueFileName)
+}
+
+// NewQueue returns a queue backed by the file at path.
+func NewQueue(path string) *Queue {
+	return &Queue{path: path, now: time.Now}
+}
+
+// Path returns the file backing the queue.
+func (q *Queue) Path() string {
+	return q.path
+}
+
+// List returns all items ordered by publish time.
+func (q *Queue) List() ([]Item, error) {
+	items, err := q.load()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(items, func(a, b int) bool {
+		return items[a].PublishAt.Before(items[b].PublishAt)
+	})
+	return items, nil
+}
+
+// Get returns the item with the given ID.
+func (q *Queue) Get(id string) (*Item, error) {
+	items, err := q.load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].ID == id {
+			return &items[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Add validates and appends a new pending item, assigning its ID.
+func (q *Queue) Add(item Item) (*Item, error) {
+	if err := item.Payload.Validate(); err != nil {
+		return nil, err
+	}
+	if item.PublishAt.IsZero() {
+		return nil, fmt.Errorf("publish time is required")
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := q.now()
+	item.ID = id
+	item.Status = StatusPending
+	item.Attempts = 0
+	item.NextAttemptAt = time.Time{}
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	err = q.mutate(func(items []Item) ([]Item, error) {
+		return append(items, item), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Cancel marks a pending item as cancelled.
+func (q *Queue) Cancel(id string) (*Item, error) {
+	var cancelled Item
+	err := q.mutate(func(items []Item) ([]Item, error) {
+		for i := range items {
+			if items[i].ID != id {
+				continue
+			}
+			if items[i].Status != StatusPending {
+				return nil, ErrNotPending
+			}
+			items[i].Status = StatusCancelled
+			items[i].UpdatedAt = q.now()
+			cancelled = items[i]
+			return items, nil
+		}
+		return nil, ErrNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cancelled, nil
+}
+
+// Update replaces the stored item with the same ID.
+func (q *Queue) Update(item Item) error {
+	return q.mutate(func(items []Item) ([]Item, error) {
+		for i := range items {
+			if items[i].ID == item.ID {
+				items[i] = item
+				return items, nil
+			}
+		}
+		return nil, ErrNotFound
+	})
+}
+
+// Modify applies fn to the stored item with the given ID and saves the
+// result, all under the queue's store lock, so changes made by other
+// processes in the meantime (such as a cancel) are seen by fn.
+func (q *Queue) Modify(id string, fn func(item *Item)) (*Item, error) {
+	var modified Item
+	err := q.mutate(func(items []Item) ([]Item, error) {
+		for i := range items {
+			if items[i].ID == id {
+				fn(&items[i])
+				modified = items[i]
+				return items, nil
+			}
+		}
+		return nil, ErrNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &modified, nil
+}
+
+// Claim re-reads the item under the store lock and returns it if it is
+// still due at now, or ErrNotPending if it was cancelled, published or
+// rescheduled since it was listed. Runners call it immediately before
+// publishing an item.
+func (q *Queue) Claim(id string, now time.Time) (*Item, error) {
+	var claimed *Item
+	err := fsutil.WithLock(q.storeLockPath(), func() error {
+		items, err := q.load()
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			if items[i].ID != id {
+				continue
+			}
+			if !items[i].IsDue(now) {
+				return ErrNotPending
+			}
+			claimed = &items[i]
+			return nil
+		}
+		return ErrNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// Due returns pending items for account that should be attempted at now,
+// ordered by publish time. Items without an account match any account.
+func (q *Queue) Due(account string, now time.Time) ([]Item, error) {
+	items, err := q.List()
+	if err != nil {
+		return nil, err
+	}
+	var due []Item
+	for _, item := range items {
+		if item.Account != "" && account != "" && item.Account != account {
+			continue
+		}
+		if item.IsDue(now) {
+			due = append(due, item)
+		}
+	}
+	return due, nil
+}
+
+// Lock acquires an exclusive run lock so overlapping runners (for example
+// cron jobs) never publish the same item twice. The returned lock must be
+// released; long-running holders should call Touch periodically. The run
+// lock is separate from the store lock taken by every change to the queue,
+// so items can be added and cancelled while a runner is active.
+func (q *Queue) Lock() (*fsutil.Lock, error) {
+	lock, err := fsutil.TryLock(q.path+".lock", staleLockAge)
+	if errors.Is(err, fsutil.ErrLocked) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schedule lock: %w", err)
+	}
+	return lock, nil
+}
+
+// storeLockPath is the lock file held while the queue file is modified.
+func (q *Queue) storeLockPath() string {
+	return q.path + ".write.lock"
+}
+
+// mutate runs a load/modify/save cycle under the store lock. fn returns the
+// items to save, or an error to abort without saving.
+func (q *Queue) mutate(fn func(items []Item) ([]Item, error)) error {
+	return fsutil.WithLock(q.storeLockPath(), func() error {
+		items, err := q.load()
+		if err != nil {
+			return err
+		}
+		items, err = fn(items)
+		if err != nil {
+			return err
+		}
+		return q.save(items)
+	})
+}
+
+func (q *Queue) load() ([]Item, error) {
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Item{}, nil
+		}
+		return nil, fmt.Errorf("failed to read schedule queue: %w", err)
+	}
+
+	var items []Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule queue %s: %w", q.path, err)
+	}
+	return items, nil
+}
+
+// save writes the queue atomically so a crash mid-write never truncates it.
+func (q *Queue) save(items []Item) error {
+	if err := fsutil.WriteJSON(q.path, items); err != nil {
+		return fmt.Errorf("failed to write schedule queue: %w", err)
+	}
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate schedule ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,324 @@
+package schedule
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+func newTestQueue(t *testing.T) *Queue {
+	t.Helper()
+	return NewQueue(filepath.Join(t.TempDir(), "schedule.json"))
+}
+
+func textItem(text string, at time.Time) Item {
+	return Item{
+		Account:   "alice",
+		PublishAt: at,
+		Payload: Payload{
+			Kind: KindText,
+			Text: &api.TextPostContent{Text: text},
+		},
+	}
+}
+
+func TestQueue_AddListGet(t *testing.T) {
+	q := newTestQueue(t)
+	now := time.Now()
+
+	later, err := q.Add(textItem("later", now.Add(2*time.Hour)))
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	sooner, err := q.Add(textItem("sooner", now.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if later.ID == "" || later.ID == sooner.ID {
+		t.Fatalf("expected unique IDs, got %q and %q", later.ID, sooner.ID)
+	}
+	if later.Status != StatusPending {
+		t.Errorf("expected pending status, got %s", later.Status)
+	}
+
+	items, err := q.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[0].ID != sooner.ID {
+		t.Errorf("expected items ordered by publish time")
+	}
+
+	got, err := q.Get(later.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Payload.Text.Text != "later" {
+		t.Errorf("expected payload text 'later', got %q", got.Payload.Text.Text)
+	}
+
+	if _, err := q.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQueue_AddRejectsInvalidPayload(t *testing.T) {
+	q := newTestQueue(t)
+
+	tests := []struct {
+		name string
+		item Item
+	}{
+		{"missing publish time", Item{Payload: Payload{Kind: KindText, Text: &api.TextPostContent{Text: "x"}}}},
+		{"missing content", Item{PublishAt: time.Now(), Payload: Payload{Kind: KindImage}}},
+		{"unknown kind", Item{PublishAt: time.Now(), Payload: Payload{Kind: "audio"}}},
+		{"short carousel", Item{PublishAt: time.Now(), Payload: Payload{
+			Kind:          KindCarousel,
+			Carousel:      &api.CarouselPostContent{},
+			CarouselItems: []CarouselItem{{MediaType: "IMAGE", URL: "https://example.com/a.jpg"}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := q.Add(tt.item); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	q := newTestQueue(t)
+
+	item, err := q.Add(textItem("x", time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	cancelled, err := q.Cancel(item.ID)
+	if err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if cancelled.Status != StatusCancelled {
+		t.Errorf("expected cancelled status, got %s", cancelled.Status)
+	}
+
+	if _, err := q.Cancel(item.ID); !errors.Is(err, ErrNotPending) {
+		t.Errorf("expected ErrNotPending, got %v", err)
+	}
+	if _, err := q.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQueue_Due(t *testing.T) {
+	q := newTestQueue(t)
+	now := time.Now()
+
+	past, _ := q.Add(textItem("past", now.Add(-time.Minute)))
+	_, _ = q.Add(textItem("future", now.Add(time.Hour)))
+	other := textItem("other account", now.Add(-time.Minute))
+	other.Account = "bob"
+	_, _ = q.Add(other)
+
+	due, err := q.Due("alice", now)
+	if err != nil {
+		t.Fatalf("Due failed: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != past.ID {
+		t.Fatalf("expected only %s to be due, got %+v", past.ID, due)
+	}
+
+	// An item waiting on backoff is not due yet.
+	item := due[0]
+	item.MarkFailed(errors.New("timeout"), true, 0, DefaultRetryPolicy(), now)
+	if err := q.Update(item); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	due, _ = q.Due("alice", now)
+	if len(due) != 0 {
+		t.Errorf("expected no due items during backoff, got %d", len(due))
+	}
+	due, _ = q.Due("alice", now.Add(2*time.Minute))
+	if len(due) != 1 {
+		t.Errorf("expected item to be due after backoff, got %d", len(due))
+	}
+}
+
+func TestQueue_ClaimSkipsCancelled(t *testing.T) {
+	q := newTestQueue(t)
+	now := time.Now()
+
+	item, err := q.Add(textItem("x", now.Add(-time.Minute)))
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	due, _ := q.Due("alice", now)
+	if len(due) != 1 {
+		t.Fatalf("expected 1 due item, got %d", len(due))
+	}
+
+	claimed, err := q.Claim(item.ID, now)
+	if err != nil || claimed.ID != item.ID {
+		t.Fatalf("expected claim to succeed, got %+v, %v", claimed, err)
+	}
+
+	// A cancel after the run listed the item must win over the stale snapshot.
+	if _, err := q.Cancel(item.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if _, err := q.Claim(due[0].ID, now); !errors.Is(err, ErrNotPending) {
+		t.Fatalf("expected ErrNotPending, got %v", err)
+	}
+	if _, err := q.Claim("missing", now); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQueue_ModifySeesConcurrentChanges(t *testing.T) {
+	q := newTestQueue(t)
+	now := time.Now()
+
+	item, _ := q.Add(textItem("x", now.Add(-time.Minute)))
+	if _, err := q.Cancel(item.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	modified, err := q.Modify(item.ID, func(stored *Item) {
+		if stored.Status != StatusCancelled {
+			t.Errorf("expected Modify to see the cancel, got %s", stored.Status)
+		}
+		stored.LastError = "seen"
+	})
+	if err != nil {
+		t.Fatalf("Modify failed: %v", err)
+	}
+	got, _ := q.Get(item.ID)
+	if modified.LastError != "seen" || got.LastError != "seen" || got.Status != StatusCancelled {
+		t.Errorf("unexpected stored item %+v", got)
+	}
+}
+
+func TestQueue_ConcurrentAdds(t *testing.T) {
+	q := newTestQueue(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := q.Add(textItem("x", time.Now().Add(time.Hour))); err != nil {
+				t.Errorf("Add failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	items, err := q.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != 10 {
+		t.Errorf("expected 10 items, got %d", len(items))
+	}
+}
+
+func TestItem_MarkFailed(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 3 * time.Minute}
+	now := time.Now()
+
+	item := textItem("x", now)
+	item.Status = StatusPending
+
+	item.MarkFailed(errors.New("boom"), true, 0, policy, now)
+	if item.Status != StatusPending || !item.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected retry in 1m, got status=%s next=%v", item.Status, item.NextAttemptAt.Sub(now))
+	}
+
+	item.MarkFailed(errors.New("boom"), true, 10*time.Minute, policy, now)
+	if !item.NextAttemptAt.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("expected retry-after to win, got %v", item.NextAttemptAt.Sub(now))
+	}
+
+	item.MarkFailed(errors.New("boom"), true, 0, policy, now)
+	if item.Status != StatusFailed {
+		t.Errorf("expected failed after max attempts, got %s", item.Status)
+	}
+
+	permanent := textItem("y", now)
+	permanent.Status = StatusPending
+	permanent.MarkFailed(errors.New("invalid"), false, 0, policy, now)
+	if permanent.Status != StatusFailed || permanent.LastError != "invalid" {
+		t.Errorf("expected permanent failure, got status=%s err=%q", permanent.Status, permanent.LastError)
+	}
+}
+
+func TestItem_Defer(t *testing.T) {
+	now := time.Now()
+	until := now.Add(5 * time.Hour)
+
+	item := textItem("x", now)
+	item.Status = StatusPending
+	item.Defer(errors.New("quota"), until, now)
+
+	if item.Status != StatusPending || item.Attempts != 0 || item.LastError != "quota" {
+		t.Errorf("expected pending item with no attempts, got %+v", item)
+	}
+	if item.IsDue(until.Add(-time.Second)) || !item.IsDue(until) {
+		t.Errorf("expected item to be due at %v", until)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Minute, MaxDelay: 5 * time.Minute}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 5 * time.Minute},
+		{9, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := policy.Delay(tt.attempts); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestQueue_Lock(t *testing.T) {
+	q := newTestQueue(t)
+
+	lock, err := q.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if _, err := q.Lock(); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if err := lock.Touch(); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+
+	lock, err = q.Lock()
+	if err != nil {
+		t.Fatalf("expected lock after release, got %v", err)
+	}
+	_ = lock.Release()
+}
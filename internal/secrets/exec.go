@@ -0,0 +1,156 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultExecTimeout bounds a single run of an ExecStore command.
+const defaultExecTimeout = 30 * time.Second
+
+// Actions an ExecStore command is run with.
+const (
+	execGet    = "get"
+	execSet    = "set"
+	execDelete = "delete"
+	execList   = "list"
+)
+
+// execRequest is written to the command's stdin.
+type execRequest struct {
+	Action      string             `json:"action"`
+	Name        string             `json:"name,omitempty"`
+	Credentials *storedCredentials `json:"credentials,omitempty"`
+}
+
+// ExecStore implements Store with an external command, so credentials can
+// live in any secret manager a script can reach. Each operation runs the
+// command with the action and account name appended as arguments ("get
+// NAME", "set NAME", "delete NAME" or "list") and a JSON request on stdin:
+//
+//	{"action": "set", "name": "main", "credentials": {"access_token": "...", ...}}
+//
+// For get, the command prints the credentials object, or null or nothing
+// when the account does not exist. For list, it prints a JSON array of
+// account names. A non-zero exit fails the operation with its stderr.
+type ExecStore struct {
+	command []string
+	timeout time.Duration
+}
+
+// NewExecStore returns a store backed by command. A zero timeout uses the
+// default of 30 seconds.
+func NewExecStore(command []string, timeout time.Duration) (*ExecStore, error) {
+	if len(command) == 0 {
+		return nil, errors.New("exec secrets backend needs a command")
+	}
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	return &ExecStore{command: command, timeout: timeout}, nil
+}
+
+// Set stores credentials for an account
+func (s *ExecStore) Set(name string, creds Credentials) error {
+	name = normalizeName(name)
+	if name == "" {
+		return fmt.Errorf("account name cannot be empty")
+	}
+	if creds.AccessToken == "" {
+		return fmt.Errorf("access token cannot be empty")
+	}
+
+	stored := toStored(creds)
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	_, err := s.run(execRequest{Action: execSet, Name: name, Credentials: &stored})
+	return err
+}
+
+// Get retrieves credentials for an account
+func (s *ExecStore) Get(name string) (*Credentials, error) {
+	name = normalizeName(name)
+	out, err := s.run(execRequest{Action: execGet, Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, fmt.Errorf("account %q not found", name)
+	}
+	var stored *storedCredentials
+	if err := json.Unmarshal(out, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+	if stored == nil || stored.AccessToken == "" {
+		return nil, fmt.Errorf("account %q not found", name)
+	}
+	return fromStored(name, *stored), nil
+}
+
+// Delete removes credentials for an account
+func (s *ExecStore) Delete(name string) error {
+	_, err := s.run(execRequest{Action: execDelete, Name: normalizeName(name)})
+	return err
+}
+
+// List returns all account names
+func (s *ExecStore) List() ([]string, error) {
+	return s.Keys()
+}
+
+// Keys returns all account names
+func (s *ExecStore) Keys() ([]string, error) {
+	out, err := s.run(execRequest{Action: execList})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if len(bytes.TrimSpace(out)) == 0 {
+		return names, nil
+	}
+	if err := json.Unmarshal(out, &names); err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	return names, nil
+}
+
+// run runs the command for req and returns its stdout.
+func (s *ExecStore) run(req execRequest) ([]byte, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	args := append(append([]string{}, s.command[1:]...), req.Action)
+	if req.Name != "" {
+		args = append(args, req.Name)
+	}
+	cmd := exec.CommandContext(ctx, s.command[0], args...) //nolint:gosec // The command is chosen by the local user
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("secrets command timed out after %s", s.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("secrets command failed to %s: %s", req.Action, msg)
+		}
+		return nil, fmt.Errorf("secrets command failed to %s: %w", req.Action, err)
+	}
+	return out, nil
+}
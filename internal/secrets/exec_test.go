@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// execStoreScript keeps each account's stdin request in a file under $DIR.
+const execStoreScript = `#!/bin/sh
+set -e
+case "$1" in
+  get) if [ -f "$DIR/$2" ]; then jq '.credentials' "$DIR/$2"; else echo null; fi ;;
+  set) cat > "$DIR/$2" ;;
+  delete) rm "$DIR/$2" ;;
+  list) ls "$DIR" | jq -R . | jq -s . ;;
+  *) echo "unknown action $1" >&2; exit 1 ;;
+esac
+`
+
+func newTestExecStore(t *testing.T) *ExecStore {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	if _, err := exec.LookPath("jq"); err != nil {
+		t.Skip("needs jq")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "store.sh")
+	if err := os.WriteFile(script, []byte(execStoreScript), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	accounts := filepath.Join(dir, "accounts")
+	if err := os.Mkdir(accounts, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DIR", accounts)
+
+	store, err := NewExecStore([]string{"sh", script}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestExecStore_RoundTrip(t *testing.T) {
+	store := newTestExecStore(t)
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	if err := store.Set("Main", Credentials{AccessToken: "token-1", ClientSecret: "app-secret", ExpiresAt: expires}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	creds, err := store.Get("main")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if creds.Name != "main" || creds.AccessToken != "token-1" || creds.ClientSecret != "app-secret" || !creds.ExpiresAt.Equal(expires) {
+		t.Errorf("unexpected credentials %+v", creds)
+	}
+
+	names, err := store.List()
+	if err != nil || strings.Join(names, ",") != "main" {
+		t.Errorf("expected [main], got %v, %v", names, err)
+	}
+
+	if err := store.Delete("main"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get("main"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestExecStore_CommandFailure(t *testing.T) {
+	store := newTestExecStore(t)
+
+	// rm fails for an account that does not exist
+	err := store.Delete("missing")
+	if err == nil || !strings.Contains(err.Error(), "failed to delete") || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected the command's stderr in the error, got %v", err)
+	}
+
+	if _, err := NewExecStore(nil, 0); err == nil {
+		t.Error("expected an empty command to be rejected")
+	}
+}
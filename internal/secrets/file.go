@@ -0,0 +1,319 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+const (
+	credentialsFileName = "credentials.enc"
+
+	// PasswordEnv holds the passphrase for the encrypted credentials file
+	// and the keyring's file fallback.
+	PasswordEnv = "THREADS_KEYRING_PASSWORD"
+
+	fileStoreVersion = 1
+	fileStoreKDF     = "pbkdf2-sha256"
+	pbkdf2Iterations = 600_000
+	saltSize         = 16
+)
+
+// ErrWrongPassphrase is returned when the credentials file cannot be
+// decrypted with the configured passphrase.
+var ErrWrongPassphrase = errors.New("cannot decrypt credentials file: wrong passphrase or corrupted file")
+
+// PassphraseFunc returns the passphrase protecting a FileStore.
+type PassphraseFunc func() (string, error)
+
+// fileEnvelope is the on-disk format of a FileStore. Byte fields are
+// base64-encoded by encoding/json.
+type fileEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// FileStore implements Store in a single file encrypted with AES-256-GCM,
+// for hosts without an OS keyring such as CI runners and containers. The
+// key is derived from a passphrase with PBKDF2-SHA256 and a random salt kept
+// in the file, so the file works on any host given the same passphrase.
+type FileStore struct {
+	path       string
+	passphrase PassphraseFunc
+	iterations int
+
+	mu        sync.Mutex
+	salt      []byte // salt and iterations the cached key was derived with
+	keyRounds int
+	key       []byte
+}
+
+// DefaultFilePath returns the default encrypted credentials file location
+// under the config directory.
+func DefaultFilePath() string {
+	return filepath.Join(config.ConfigDir(), credentialsFileName)
+}
+
+// NewFileStore returns a store backed by the encrypted file at path. The
+// passphrase is requested the first time the file is read or written.
+func NewFileStore(path string, passphrase PassphraseFunc) *FileStore {
+	return &FileStore{path: path, passphrase: passphrase, iterations: pbkdf2Iterations}
+}
+
+// Path returns the file backing the store.
+func (s *FileStore) Path() string {
+	return s.path
+}
+
+// FilePassphrase returns the passphrase from the THREADS_KEYRING_PASSWORD
+// environment variable, or else from the first line of keyFile, or else
+// from the output of keyCommand.
+func FilePassphrase(keyFile string, keyCommand []string) PassphraseFunc {
+	return func() (string, error) {
+		if password := os.Getenv(PasswordEnv); password != "" {
+			return password, nil
+		}
+		if keyFile != "" {
+			data, err := os.ReadFile(keyFile) //nolint:gosec // The key file is chosen by the local user
+			if err != nil {
+				return "", fmt.Errorf("failed to read key file: %w", err)
+			}
+			return firstLine(data), nil
+		}
+		if len(keyCommand) > 0 {
+			cmd := exec.Command(keyCommand[0], keyCommand[1:]...) //nolint:gosec // The key command is chosen by the local user
+			cmd.Stderr = os.Stderr
+			out, err := cmd.Output()
+			if err != nil {
+				return "", fmt.Errorf("key command failed: %w", err)
+			}
+			return firstLine(out), nil
+		}
+		return "", fmt.Errorf("no passphrase for the credentials file: set %s, or key_file or key_command in the config", PasswordEnv)
+	}
+}
+
+// firstLine returns the first line of data without surrounding whitespace.
+func firstLine(data []byte) string {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	return strings.TrimSpace(string(line))
+}
+
+// Set stores credentials for an account
+func (s *FileStore) Set(name string, creds Credentials) error {
+	name = normalizeName(name)
+	if name == "" {
+		return fmt.Errorf("account name cannot be empty")
+	}
+	if creds.AccessToken == "" {
+		return fmt.Errorf("access token cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(accounts map[string]storedCredentials) error {
+		stored := toStored(creds)
+		if stored.CreatedAt.IsZero() {
+			stored.CreatedAt = time.Now()
+		}
+		accounts[name] = stored
+		return nil
+	})
+}
+
+// Get retrieves credentials for an account
+func (s *FileStore) Get(name string) (*Credentials, error) {
+	name = normalizeName(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	stored, ok := accounts[name]
+	if !ok {
+		return nil, fmt.Errorf("account %q not found", name)
+	}
+	return fromStored(name, stored), nil
+}
+
+// Delete removes credentials for an account
+func (s *FileStore) Delete(name string) error {
+	name = normalizeName(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(accounts map[string]storedCredentials) error {
+		if _, ok := accounts[name]; !ok {
+			return fmt.Errorf("account %q not found", name)
+		}
+		delete(accounts, name)
+		return nil
+	})
+}
+
+// List returns all account names
+func (s *FileStore) List() ([]string, error) {
+	return s.Keys()
+}
+
+// Keys returns all account names
+func (s *FileStore) Keys() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// update runs a decrypt/modify/encrypt cycle under the file's lock, so
+// concurrent CLI processes never drop each other's accounts. Nothing is
+// saved if fn fails.
+func (s *FileStore) update(fn func(accounts map[string]storedCredentials) error) error {
+	return fsutil.WithLock(s.path+".lock", func() error {
+		accounts, err := s.load()
+		if err != nil {
+			return err
+		}
+		if err := fn(accounts); err != nil {
+			return err
+		}
+		return s.save(accounts)
+	})
+}
+
+// load decrypts the file. A missing file holds no accounts.
+func (s *FileStore) load() (map[string]storedCredentials, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]storedCredentials{}, nil
+		}
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var envelope fileEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+	if envelope.Version != fileStoreVersion || envelope.KDF != fileStoreKDF {
+		return nil, fmt.Errorf("unsupported credentials file format (version %d, kdf %q)", envelope.Version, envelope.KDF)
+	}
+
+	aead, err := s.cipher(envelope.Salt, envelope.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	accounts := map[string]storedCredentials{}
+	if err := json.Unmarshal(plaintext, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+	return accounts, nil
+}
+
+// save encrypts accounts with a fresh nonce and replaces the file.
+func (s *FileStore) save(accounts map[string]storedCredentials) error {
+	plaintext, err := json.Marshal(accounts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	// Keep the salt of the file, so the derived key stays valid
+	salt, iterations := s.salt, s.keyRounds
+	if salt == nil {
+		salt, iterations = make([]byte, saltSize), s.iterations
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+	aead, err := s.cipher(salt, iterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(fileEnvelope{
+		Version:    fileStoreVersion,
+		KDF:        fileStoreKDF,
+		Iterations: iterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials file: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	return nil
+}
+
+// cipher returns the AES-GCM cipher for the key derived from the passphrase
+// and salt. The key is derived once per salt.
+func (s *FileStore) cipher(salt []byte, iterations int) (cipher.AEAD, error) {
+	if s.key == nil || !bytes.Equal(s.salt, salt) || s.keyRounds != iterations {
+		passphrase, err := s.passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if passphrase == "" {
+			return nil, fmt.Errorf("passphrase for the credentials file is empty")
+		}
+		key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+		s.salt, s.keyRounds, s.key = salt, iterations, key
+	}
+
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestFileStore returns a file store with cheap key derivation.
+func newTestFileStore(path, passphrase string) *FileStore {
+	store := NewFileStore(path, func() (string, error) { return passphrase, nil })
+	store.iterations = 1000
+	return store
+}
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	store := newTestFileStore(path, "correct horse")
+
+	creds := Credentials{
+		AccessToken:  "token-1",
+		UserID:       "12345",
+		Username:     "alice",
+		ExpiresAt:    time.Now().Add(60 * 24 * time.Hour).UTC().Truncate(time.Second),
+		ClientID:     "app",
+		ClientSecret: "app-secret",
+		RedirectURI:  "https://example.com/callback",
+		Scopes:       []string{"threads_basic", "threads_delete"},
+	}
+	if err := store.Set("Main", creds); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set("other", Credentials{AccessToken: "token-2"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "token-1") || strings.Contains(string(data), "app-secret") {
+		t.Fatal("expected secrets to be encrypted on disk")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	// A new store with the same passphrase reads what the first one wrote
+	reopened := newTestFileStore(path, "correct horse")
+	got, err := reopened.Get("main")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Name != "main" || got.AccessToken != "token-1" || got.ClientSecret != "app-secret" || !got.ExpiresAt.Equal(creds.ExpiresAt) || got.CreatedAt.IsZero() || len(got.Scopes) != 2 {
+		t.Errorf("unexpected credentials %+v", got)
+	}
+
+	names, err := reopened.List()
+	if err != nil || strings.Join(names, ",") != "main,other" {
+		t.Errorf("expected main,other, got %v, %v", names, err)
+	}
+
+	if err := reopened.Delete("other"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get("other"); err == nil {
+		t.Error("expected the deleted account to be gone")
+	}
+	if err := reopened.Delete("other"); err == nil {
+		t.Error("expected deleting a missing account to fail")
+	}
+}
+
+func TestFileStore_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+
+	// Separate stores share no mutex, like two CLI processes.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store := newTestFileStore(path, "secret")
+			if err := store.Set(fmt.Sprintf("account%d", i), Credentials{AccessToken: "token"}); err != nil {
+				t.Errorf("Set failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	names, err := newTestFileStore(path, "secret").Keys()
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	if len(names) != 5 {
+		t.Errorf("expected 5 accounts, got %v", names)
+	}
+}
+
+func TestFileStore_WrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	if err := newTestFileStore(path, "right").Set("main", Credentials{AccessToken: "token"}); err != nil {
+		t.Fatal(err)
+	}
+
+	wrong := newTestFileStore(path, "wrong")
+	if _, err := wrong.Get("main"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+	if err := wrong.Set("other", Credentials{AccessToken: "token"}); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected writes to fail too, got %v", err)
+	}
+}
+
+func TestFileStore_MissingFile(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "credentials.enc"), func() (string, error) {
+		t.Error("the passphrase is not needed to read a missing file")
+		return "", nil
+	})
+	if names, err := store.List(); err != nil || len(names) != 0 {
+		t.Errorf("expected no accounts, got %v, %v", names, err)
+	}
+}
+
+func TestFilePassphrase(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("from-file\nignored\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(PasswordEnv, "")
+	tests := []struct {
+		name    string
+		env     string
+		keyFile string
+		command []string
+		want    string
+	}{
+		{"environment first", "from-env", keyFile, nil, "from-env"},
+		{"key file", "", keyFile, []string{"echo", "from-command"}, "from-file"},
+		{"key command", "", "", []string{"echo", "from-command"}, "from-command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PasswordEnv, tt.env)
+			got, err := FilePassphrase(tt.keyFile, tt.command)()
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := FilePassphrase("", nil)(); err == nil || !strings.Contains(err.Error(), PasswordEnv) {
+		t.Errorf("expected an error naming %s, got %v", PasswordEnv, err)
+	}
+}
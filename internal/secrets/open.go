@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+)
+
+// Credential store backends.
+const (
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+	BackendExec    = "exec"
+	BackendVault   = "vault"
+)
+
+// Backends lists the credential store backends.
+var Backends = []string{BackendKeyring, BackendFile, BackendExec, BackendVault}
+
+// BackendName returns the backend selected by settings, the OS keyring by
+// default.
+func BackendName(settings *config.SecretsConfig) string {
+	if settings == nil || settings.Backend == "" {
+		return BackendKeyring
+	}
+	return strings.ToLower(strings.TrimSpace(settings.Backend))
+}
+
+// Open opens the credential store selected by settings.
+func Open(settings *config.SecretsConfig) (Store, error) {
+	return OpenBackend(BackendName(settings), settings)
+}
+
+// OpenBackend opens the named credential store backend, configured by
+// settings where it applies.
+func OpenBackend(backend string, settings *config.SecretsConfig) (Store, error) {
+	switch backend {
+	case BackendKeyring:
+		store, err := OpenDefault()
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case BackendFile:
+		file := &config.FileSecretsConfig{}
+		if settings != nil && settings.File != nil {
+			file = settings.File
+		}
+		path := file.Path
+		if path == "" {
+			path = DefaultFilePath()
+		}
+		return NewFileStore(path, FilePassphrase(file.KeyFile, file.KeyCommand)), nil
+	case BackendExec:
+		if settings == nil || settings.Exec == nil {
+			return nil, fmt.Errorf("exec secrets backend needs secrets.exec.command in the config")
+		}
+		var timeout time.Duration
+		if settings.Exec.Timeout != "" {
+			parsed, err := time.ParseDuration(settings.Exec.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid secrets.exec.timeout %q: %w", settings.Exec.Timeout, err)
+			}
+			timeout = parsed
+		}
+		store, err := NewExecStore(settings.Exec.Command, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case BackendVault:
+		vault := &config.VaultSecretsConfig{}
+		if settings != nil && settings.Vault != nil {
+			vault = settings.Vault
+		}
+		store, err := NewVaultStore(
+			firstNonEmpty(os.ExpandEnv(vault.Address), os.Getenv("VAULT_ADDR")),
+			firstNonEmpty(os.ExpandEnv(vault.Token), os.Getenv("VAULT_TOKEN")),
+			firstNonEmpty(os.ExpandEnv(vault.Namespace), os.Getenv("VAULT_NAMESPACE")),
+			os.ExpandEnv(vault.Mount),
+			os.ExpandEnv(vault.Path),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %q (valid: %s)", backend, strings.Join(Backends, ", "))
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
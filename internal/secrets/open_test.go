@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+)
+
+func TestOpenBackend(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "from-env")
+
+	store, err := Open(&config.SecretsConfig{Backend: "File"})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if file, ok := store.(*FileStore); !ok || file.Path() != DefaultFilePath() {
+		t.Errorf("expected a file store at the default path, got %#v", store)
+	}
+
+	store, err = Open(&config.SecretsConfig{Backend: "vault", Vault: &config.VaultSecretsConfig{Address: "http://127.0.0.1:8200/"}})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if vault, ok := store.(*VaultStore); !ok || vault.token != "from-env" || vault.url("data", "main") != "http://127.0.0.1:8200/v1/secret/data/threads-cli/main" {
+		t.Errorf("expected a vault store with defaults, got %#v", store)
+	}
+
+	failures := []*config.SecretsConfig{
+		{Backend: "floppy"},
+		{Backend: "vault"},
+		{Backend: "exec"},
+		{Backend: "exec", Exec: &config.ExecSecretsConfig{Command: []string{"pass-wrapper"}, Timeout: "soon"}},
+	}
+	for _, settings := range failures {
+		if _, err := Open(settings); err == nil {
+			t.Errorf("expected %+v to fail", settings)
+		}
+	}
+
+	if BackendName(nil) != BackendKeyring {
+		t.Errorf("expected the keyring by default, got %s", BackendName(nil))
+	}
+}
@@ -0,0 +1,214 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/fsutil"
+)
+
+// DefaultRefreshThreshold is how long before expiry a token is refreshed
+// automatically when no threshold is configured.
+const DefaultRefreshThreshold = 7 * 24 * time.Hour
+
+const (
+	auditFileName = "token-audit.jsonl"
+
+	// defaultLockTimeout bounds waiting for another process's refresh.
+	defaultLockTimeout = 30 * time.Second
+
+	// staleLockAge is when a refresh lock is assumed to be left behind by a
+	// process that died while holding it.
+	staleLockAge = 2 * time.Minute
+)
+
+// Refresh triggers recorded in the audit log.
+const (
+	RefreshAuto   = "auto"
+	RefreshManual = "manual"
+)
+
+var (
+	// ErrRefreshLocked is returned when another process is still refreshing
+	// the account's token after the lock timeout.
+	ErrRefreshLocked = errors.New("another process is refreshing the token")
+
+	// ErrNoClientSecret is returned when refreshing an account whose client
+	// secret was not stored at login.
+	ErrNoClientSecret = errors.New("client secret not stored")
+)
+
+// RefreshFunc exchanges the access token in creds for a new one.
+type RefreshFunc func(ctx context.Context, creds Credentials) (accessToken string, expiresAt time.Time, err error)
+
+// AuditEntry records one token refresh attempt.
+type AuditEntry struct {
+	Time         time.Time `json:"time"`
+	Account      string    `json:"account"`
+	Trigger      string    `json:"trigger"` // auto|manual
+	PID          int       `json:"pid"`
+	OldExpiresAt time.Time `json:"old_expires_at,omitzero"`
+	NewExpiresAt time.Time `json:"new_expires_at,omitzero"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Refresher refreshes access tokens kept in a Store. A lock file per account
+// keeps concurrent CLI processes from refreshing the same token at once, and
+// every attempt is appended to a JSON-lines audit log.
+type Refresher struct {
+	Store    Store
+	Exchange RefreshFunc
+
+	// Dir holds the lock files and the audit log.
+	Dir string
+
+	// LockTimeout bounds waiting for another process to finish refreshing
+	// the same account (default 30s).
+	LockTimeout time.Duration
+}
+
+// NewRefresher returns a Refresher keeping its locks and audit log in the
+// data directory.
+func NewRefresher(store Store, exchange RefreshFunc) *Refresher {
+	return &Refresher{Store: store, Exchange: exchange, Dir: config.DataDir()}
+}
+
+// AuditPath returns the file refresh attempts are logged to.
+func (r *Refresher) AuditPath() string {
+	return filepath.Join(r.Dir, auditFileName)
+}
+
+// RefreshIfExpiring refreshes the account's token when it expires within
+// threshold and can still be refreshed: it has not expired and its client
+// secret is stored. It returns the current credentials and whether this
+// call refreshed them. A token refreshed meanwhile by another process is
+// returned as is.
+func (r *Refresher) RefreshIfExpiring(ctx context.Context, account string, threshold time.Duration) (*Credentials, bool, error) {
+	creds, err := r.Store.Get(account)
+	if err != nil {
+		return nil, false, err
+	}
+	if !needsRefresh(creds, threshold) {
+		return creds, false, nil
+	}
+
+	unlock, err := r.lock(ctx, account)
+	if err != nil {
+		return creds, false, err
+	}
+	defer unlock()
+
+	// Another process may have refreshed the token while we waited
+	if creds, err = r.Store.Get(account); err != nil {
+		return nil, false, err
+	}
+	if !needsRefresh(creds, threshold) {
+		return creds, false, nil
+	}
+
+	refreshed, err := r.refreshLocked(ctx, account, creds, RefreshAuto)
+	if err != nil {
+		return creds, false, err
+	}
+	return refreshed, true, nil
+}
+
+// Refresh refreshes the account's token regardless of its expiry and
+// returns the updated credentials.
+func (r *Refresher) Refresh(ctx context.Context, account string) (*Credentials, error) {
+	unlock, err := r.lock(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	creds, err := r.Store.Get(account)
+	if err != nil {
+		return nil, err
+	}
+	if creds.ClientSecret == "" {
+		return nil, ErrNoClientSecret
+	}
+	return r.refreshLocked(ctx, account, creds, RefreshManual)
+}
+
+// needsRefresh reports whether creds expire within threshold and can be
+// refreshed.
+func needsRefresh(creds *Credentials, threshold time.Duration) bool {
+	return creds.ClientSecret != "" && !creds.IsExpired() && creds.IsExpiringSoon(threshold)
+}
+
+// refreshLocked exchanges the token in creds, stores the result and audits
+// the attempt. The caller holds the account's lock.
+func (r *Refresher) refreshLocked(ctx context.Context, account string, creds *Credentials, trigger string) (*Credentials, error) {
+	entry := AuditEntry{
+		Time:         time.Now(),
+		Account:      normalizeName(account),
+		Trigger:      trigger,
+		PID:          os.Getpid(),
+		OldExpiresAt: creds.ExpiresAt,
+	}
+
+	token, expiresAt, err := r.Exchange(ctx, *creds)
+	if err == nil {
+		updated := *creds
+		updated.AccessToken = token
+		updated.ExpiresAt = expiresAt
+		if err = r.Store.Set(account, updated); err == nil {
+			entry.NewExpiresAt = expiresAt
+			r.audit(entry) //nolint:errcheck // The refreshed token is stored either way
+			return &updated, nil
+		}
+		err = fmt.Errorf("failed to store refreshed token: %w", err)
+	}
+
+	entry.Error = err.Error()
+	r.audit(entry) //nolint:errcheck // The refresh error matters more
+	return nil, err
+}
+
+// audit appends entry to the audit log.
+func (r *Refresher) audit(entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.Dir, 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.AuditPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close() //nolint:errcheck,gosec // Already failing
+		return err
+	}
+	return file.Close()
+}
+
+// lock acquires the account's refresh lock, waiting up to LockTimeout for
+// another process to release it. Locks older than staleLockAge are taken
+// over. The returned function releases the lock.
+func (r *Refresher) lock(ctx context.Context, account string) (func(), error) {
+	timeout := r.LockTimeout
+	if timeout <= 0 {
+		timeout = defaultLockTimeout
+	}
+
+	path := filepath.Join(r.Dir, "refresh-"+normalizeName(account)+".lock")
+	lock, err := fsutil.AcquireLock(ctx, path, timeout, staleLockAge)
+	if errors.Is(err, fsutil.ErrLocked) {
+		return nil, ErrRefreshLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() { lock.Release() }, nil //nolint:errcheck,gosec // A leftover lock goes stale
+}
@@ -0,0 +1,193 @@
+package secrets
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryStore is a Store safe for concurrent use.
+type memoryStore struct {
+	mu    sync.Mutex
+	creds map[string]Credentials
+}
+
+func (m *memoryStore) Set(name string, creds Credentials) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.creds[name] = creds
+	return nil
+}
+
+func (m *memoryStore) Get(name string) (*Credentials, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	creds, ok := m.creds[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &creds, nil
+}
+
+func (m *memoryStore) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.creds, name)
+	return nil
+}
+
+func (m *memoryStore) List() ([]string, error) { return m.Keys() }
+
+func (m *memoryStore) Keys() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name := range m.creds {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func newTestRefresher(t *testing.T, expiresIn time.Duration, exchange RefreshFunc) (*Refresher, *memoryStore) {
+	t.Helper()
+	store := &memoryStore{creds: map[string]Credentials{
+		"main": {Name: "main", AccessToken: "old", ClientSecret: "secret", ExpiresAt: time.Now().Add(expiresIn)},
+	}}
+	return &Refresher{Store: store, Exchange: exchange, Dir: t.TempDir(), LockTimeout: 5 * time.Second}, store
+}
+
+func readAudit(t *testing.T, path string) []AuditEntry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid audit entry %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRefresher_RefreshIfExpiring(t *testing.T) {
+	newExpiry := time.Now().Add(60 * 24 * time.Hour)
+	var exchanges atomic.Int32
+	refresher, store := newTestRefresher(t, 48*time.Hour, func(_ context.Context, creds Credentials) (string, time.Time, error) {
+		exchanges.Add(1)
+		if creds.AccessToken != "old" {
+			t.Errorf("expected the stored token to be exchanged, got %q", creds.AccessToken)
+		}
+		time.Sleep(10 * time.Millisecond)
+		return "new", newExpiry, nil
+	})
+
+	// Concurrent callers wait for the first refresh instead of repeating it
+	var wg sync.WaitGroup
+	var refreshed atomic.Int32
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			creds, ok, err := refresher.RefreshIfExpiring(context.Background(), "main", DefaultRefreshThreshold)
+			if err != nil || creds.AccessToken != "new" {
+				t.Errorf("expected the new token, got %+v, %v", creds, err)
+			}
+			if ok {
+				refreshed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if exchanges.Load() != 1 || refreshed.Load() != 1 {
+		t.Errorf("expected a single refresh, got %d exchanges and %d refreshes", exchanges.Load(), refreshed.Load())
+	}
+	if stored, _ := store.Get("main"); stored.AccessToken != "new" || !stored.ExpiresAt.Equal(newExpiry) {
+		t.Errorf("expected the new token to be stored, got %+v", stored)
+	}
+
+	entries := readAudit(t, refresher.AuditPath())
+	if len(entries) != 1 || entries[0].Account != "main" || entries[0].Trigger != RefreshAuto || !entries[0].NewExpiresAt.Equal(newExpiry) {
+		t.Errorf("expected one audit entry for the refresh, got %+v", entries)
+	}
+}
+
+func TestRefresher_SkipsTokensNotDue(t *testing.T) {
+	exchange := func(context.Context, Credentials) (string, time.Time, error) {
+		t.Error("unexpected refresh")
+		return "", time.Time{}, nil
+	}
+
+	refresher, _ := newTestRefresher(t, 30*24*time.Hour, exchange)
+	if _, ok, err := refresher.RefreshIfExpiring(context.Background(), "main", DefaultRefreshThreshold); ok || err != nil {
+		t.Errorf("expected no refresh, got %v, %v", ok, err)
+	}
+
+	// An expired token can no longer be refreshed
+	refresher, _ = newTestRefresher(t, -time.Hour, exchange)
+	if _, ok, err := refresher.RefreshIfExpiring(context.Background(), "main", DefaultRefreshThreshold); ok || err != nil {
+		t.Errorf("expected no refresh, got %v, %v", ok, err)
+	}
+}
+
+func TestRefresher_AuditsFailures(t *testing.T) {
+	refresher, store := newTestRefresher(t, 48*time.Hour, func(context.Context, Credentials) (string, time.Time, error) {
+		return "", time.Time{}, errors.New("token revoked")
+	})
+
+	creds, ok, err := refresher.RefreshIfExpiring(context.Background(), "main", DefaultRefreshThreshold)
+	if err == nil || ok || creds.AccessToken != "old" {
+		t.Fatalf("expected the failure with the old credentials, got %+v, %v, %v", creds, ok, err)
+	}
+	if stored, _ := store.Get("main"); stored.AccessToken != "old" {
+		t.Errorf("expected the stored token to be kept, got %q", stored.AccessToken)
+	}
+	if entries := readAudit(t, refresher.AuditPath()); len(entries) != 1 || entries[0].Error != "token revoked" {
+		t.Errorf("expected the failure to be audited, got %+v", entries)
+	}
+}
+
+func TestRefresher_Lock(t *testing.T) {
+	refresher, _ := newTestRefresher(t, 48*time.Hour, func(context.Context, Credentials) (string, time.Time, error) {
+		return "new", time.Now().Add(60 * 24 * time.Hour), nil
+	})
+	refresher.LockTimeout = 200 * time.Millisecond
+
+	path := filepath.Join(refresher.Dir, "refresh-main.lock")
+	if err := os.WriteFile(path, []byte("1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := refresher.Refresh(context.Background(), "main"); !errors.Is(err, ErrRefreshLocked) {
+		t.Fatalf("expected ErrRefreshLocked while another process holds the lock, got %v", err)
+	}
+
+	// A lock left behind by a crashed process is taken over
+	old := time.Now().Add(-staleLockAge - time.Minute)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	creds, err := refresher.Refresh(context.Background(), "main")
+	if err != nil || creds.AccessToken != "new" {
+		t.Fatalf("expected the stale lock to be taken over, got %+v, %v", creds, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the lock to be released, got %v", err)
+	}
+	if entries := readAudit(t, refresher.AuditPath()); len(entries) != 1 || entries[0].Trigger != RefreshManual {
+		t.Errorf("expected a manual refresh entry, got %+v", entries)
+	}
+}
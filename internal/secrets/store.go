@@ -3,6 +3,8 @@ package secrets
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -26,6 +28,7 @@ type Credentials struct {
 	ClientID     string    `json:"client_id,omitempty"`
 	ClientSecret string    `json:"-"` // Excluded from JSON for security
 	RedirectURI  string    `json:"redirect_uri,omitempty"`
+	Scopes       []string  `json:"scopes,omitempty"` // Granted scopes, nil when unknown
 }
 
 // storedCredentials is the internal format for keyring storage
@@ -38,6 +41,38 @@ type storedCredentials struct {
 	ClientID     string    `json:"client_id,omitempty"`
 	ClientSecret string    `json:"client_secret,omitempty"`
 	RedirectURI  string    `json:"redirect_uri,omitempty"`
+	Scopes       []string  `json:"scopes,omitempty"`
+}
+
+// toStored converts creds to the stored format.
+func toStored(creds Credentials) storedCredentials {
+	return storedCredentials{
+		AccessToken:  creds.AccessToken,
+		UserID:       creds.UserID,
+		Username:     creds.Username,
+		ExpiresAt:    creds.ExpiresAt,
+		CreatedAt:    creds.CreatedAt,
+		ClientID:     creds.ClientID,
+		ClientSecret: creds.ClientSecret,
+		RedirectURI:  creds.RedirectURI,
+		Scopes:       creds.Scopes,
+	}
+}
+
+// fromStored converts stored credentials of the named account.
+func fromStored(name string, stored storedCredentials) *Credentials {
+	return &Credentials{
+		Name:         name,
+		AccessToken:  stored.AccessToken,
+		UserID:       stored.UserID,
+		Username:     stored.Username,
+		ExpiresAt:    stored.ExpiresAt,
+		CreatedAt:    stored.CreatedAt,
+		ClientID:     stored.ClientID,
+		ClientSecret: stored.ClientSecret,
+		RedirectURI:  stored.RedirectURI,
+		Scopes:       stored.Scopes,
+	}
 }
 
 // Store provides secure credential storage
@@ -55,8 +90,14 @@ type KeyringStore struct {
 	warnedAccounts map[string]bool
 }
 
-// OpenDefault opens the default keyring store
+// OpenDefault opens the default keyring store. Its file fallback takes the
+// passphrase from THREADS_KEYRING_PASSWORD when set, and prompts otherwise.
 func OpenDefault() (*KeyringStore, error) {
+	prompt := keyring.TerminalPrompt
+	if password := os.Getenv(PasswordEnv); password != "" {
+		prompt = keyring.FixedStringPrompt(password)
+	}
+
 	ring, err := keyring.Open(keyring.Config{
 		ServiceName: serviceName,
 		// macOS Keychain
@@ -68,7 +109,7 @@ func OpenDefault() (*KeyringStore, error) {
 		WinCredPrefix: serviceName,
 		// File-based fallback
 		FileDir:          "~/.config/threads-cli/keyring",
-		FilePasswordFunc: keyring.TerminalPrompt,
+		FilePasswordFunc: prompt,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to open keyring: %w", err)
@@ -89,16 +130,7 @@ func (s *KeyringStore) Set(name string, creds Credentials) error {
 		return fmt.Errorf("access token cannot be empty")
 	}
 
-	stored := storedCredentials{
-		AccessToken:  creds.AccessToken,
-		UserID:       creds.UserID,
-		Username:     creds.Username,
-		ExpiresAt:    creds.ExpiresAt,
-		CreatedAt:    creds.CreatedAt,
-		ClientID:     creds.ClientID,
-		ClientSecret: creds.ClientSecret,
-		RedirectURI:  creds.RedirectURI,
-	}
+	stored := toStored(creds)
 	if stored.CreatedAt.IsZero() {
 		stored.CreatedAt = time.Now()
 	}
@@ -130,17 +162,7 @@ func (s *KeyringStore) Get(name string) (*Credentials, error) {
 		return nil, fmt.Errorf("failed to unmarshal credentials: %w", err)
 	}
 
-	creds := &Credentials{
-		Name:         name,
-		AccessToken:  stored.AccessToken,
-		UserID:       stored.UserID,
-		Username:     stored.Username,
-		ExpiresAt:    stored.ExpiresAt,
-		CreatedAt:    stored.CreatedAt,
-		ClientID:     stored.ClientID,
-		ClientSecret: stored.ClientSecret,
-		RedirectURI:  stored.RedirectURI,
-	}
+	creds := fromStored(name, stored)
 
 	// Warn about expiring tokens (once per session)
 	if !stored.ExpiresAt.IsZero() && !s.warnedAccounts[name] {
@@ -205,6 +227,21 @@ func (c *Credentials) DaysUntilExpiry() float64 {
 	return time.Until(c.ExpiresAt).Hours() / 24
 }
 
+// MissingScopes returns the scopes in required that were not granted, or
+// nil when the granted scopes are unknown.
+func (c *Credentials) MissingScopes(required []string) []string {
+	if c.Scopes == nil {
+		return nil
+	}
+	var missing []string
+	for _, scope := range required {
+		if !slices.Contains(c.Scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
 func normalizeName(name string) string {
 	return strings.ToLower(strings.TrimSpace(name))
 }
@@ -183,6 +183,23 @@ func TestCredentials_DaysUntilExpiry(t *testing.T) {
 	}
 }
 
+func TestCredentials_MissingScopes(t *testing.T) {
+	required := []string{"threads_basic", "threads_delete"}
+
+	unknown := Credentials{}
+	if missing := unknown.MissingScopes(required); missing != nil {
+		t.Errorf("expected no missing scopes when unknown, got %v", missing)
+	}
+
+	granted := Credentials{Scopes: []string{"threads_basic", "threads_content_publish"}}
+	if missing := granted.MissingScopes(required); len(missing) != 1 || missing[0] != "threads_delete" {
+		t.Errorf("expected [threads_delete], got %v", missing)
+	}
+	if missing := granted.MissingScopes(required[:1]); len(missing) != 0 {
+		t.Errorf("expected nothing missing, got %v", missing)
+	}
+}
+
 // Tests for normalizeName
 
 func TestNormalizeName(t *testing.T) {
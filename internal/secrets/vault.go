@@ -0,0 +1,200 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultVaultMount = "secret"
+	defaultVaultPath  = "threads-cli"
+	vaultTimeout      = 30 * time.Second
+)
+
+// errVaultNotFound is returned by VaultStore.do for a 404 response.
+var errVaultNotFound = errors.New("not found")
+
+// VaultStore implements Store in a HashiCorp Vault KV version 2 secrets
+// engine. Each account is one secret at MOUNT/data/PATH/NAME, and deleting
+// an account removes all of its versions.
+type VaultStore struct {
+	address   string
+	token     string
+	namespace string
+	mount     string
+	path      string
+	client    *http.Client
+}
+
+// NewVaultStore returns a store for the KV v2 engine mounted at mount on the
+// Vault server at address, keeping accounts under path. Empty mount and
+// path default to "secret" and "threads-cli".
+func NewVaultStore(address, token, namespace, mount, path string) (*VaultStore, error) {
+	if address == "" {
+		return nil, errors.New("vault secrets backend needs an address (set VAULT_ADDR or secrets.vault.address)")
+	}
+	if token == "" {
+		return nil, errors.New("vault secrets backend needs a token (set VAULT_TOKEN or secrets.vault.token)")
+	}
+	if mount == "" {
+		mount = defaultVaultMount
+	}
+	if path == "" {
+		path = defaultVaultPath
+	}
+	return &VaultStore{
+		address:   strings.TrimRight(address, "/"),
+		token:     token,
+		namespace: namespace,
+		mount:     strings.Trim(mount, "/"),
+		path:      strings.Trim(path, "/"),
+		client:    &http.Client{Timeout: vaultTimeout},
+	}, nil
+}
+
+// Set stores credentials for an account
+func (s *VaultStore) Set(name string, creds Credentials) error {
+	name = normalizeName(name)
+	if name == "" {
+		return fmt.Errorf("account name cannot be empty")
+	}
+	if creds.AccessToken == "" {
+		return fmt.Errorf("access token cannot be empty")
+	}
+
+	stored := toStored(creds)
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	body, err := json.Marshal(map[string]any{"data": stored})
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	if err := s.do(http.MethodPost, s.url("data", name), body, nil); err != nil {
+		return fmt.Errorf("failed to store credentials in vault: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves credentials for an account
+func (s *VaultStore) Get(name string) (*Credentials, error) {
+	name = normalizeName(name)
+
+	var resp struct {
+		Data struct {
+			Data *storedCredentials `json:"data"`
+		} `json:"data"`
+	}
+	err := s.do(http.MethodGet, s.url("data", name), nil, &resp)
+	// A deleted version reads as null data
+	if errors.Is(err, errVaultNotFound) || (err == nil && resp.Data.Data == nil) {
+		return nil, fmt.Errorf("account %q not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials from vault: %w", err)
+	}
+	return fromStored(name, *resp.Data.Data), nil
+}
+
+// Delete removes credentials for an account
+func (s *VaultStore) Delete(name string) error {
+	if err := s.do(http.MethodDelete, s.url("metadata", normalizeName(name)), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete credentials from vault: %w", err)
+	}
+	return nil
+}
+
+// List returns all account names
+func (s *VaultStore) List() ([]string, error) {
+	return s.Keys()
+}
+
+// Keys returns all account names
+func (s *VaultStore) Keys() ([]string, error) {
+	var resp struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	err := s.do("LIST", s.url("metadata", ""), nil, &resp)
+	if errors.Is(err, errVaultNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts in vault: %w", err)
+	}
+
+	var accounts []string
+	for _, key := range resp.Data.Keys {
+		// Keys ending in / are nested paths, not accounts
+		if !strings.HasSuffix(key, "/") {
+			accounts = append(accounts, key)
+		}
+	}
+	return accounts, nil
+}
+
+// url returns the API URL of the account's secret under the KV v2 data or
+// metadata endpoint, or of the account path itself when name is empty.
+func (s *VaultStore) url(kind, name string) string {
+	u := s.address + "/v1/" + s.mount + "/" + kind + "/" + s.path
+	if name != "" {
+		u += "/" + url.PathEscape(name)
+	}
+	return u
+}
+
+// do sends a Vault API request and decodes the JSON response into out when
+// out is non-nil.
+func (s *VaultStore) do(method, target string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Best-effort close
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errVaultNotFound
+	}
+	if resp.StatusCode >= 300 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(data, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %d", resp.StatusCode)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,122 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newFakeVault serves a minimal KV v2 engine mounted at kv/.
+func newFakeVault(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	secrets := map[string]json.RawMessage{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		switch {
+		case r.Method == "LIST" && r.URL.Path == "/v1/kv/metadata/apps/threads":
+			keys := []string{}
+			for key := range secrets {
+				keys = append(keys, key)
+			}
+			if len(keys) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			sort.Strings(keys)
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"keys": append(keys, "nested/")}})
+		case strings.HasPrefix(r.URL.Path, "/v1/kv/data/apps/threads/"):
+			name := strings.TrimPrefix(r.URL.Path, "/v1/kv/data/apps/threads/")
+			switch r.Method {
+			case http.MethodPost:
+				var body struct {
+					Data json.RawMessage `json:"data"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				secrets[name] = body.Data
+				_, _ = w.Write([]byte(`{"data":{"version":1}}`))
+			case http.MethodGet:
+				data, ok := secrets[name]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"errors":[]}`))
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+			}
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/kv/metadata/apps/threads/"):
+			delete(secrets, strings.TrimPrefix(r.URL.Path, "/v1/kv/metadata/apps/threads/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultStore_RoundTrip(t *testing.T) {
+	server := newFakeVault(t, "root")
+	store, err := NewVaultStore(server.URL, "root", "", "kv", "/apps/threads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names, err := store.List(); err != nil || len(names) != 0 {
+		t.Fatalf("expected no accounts, got %v, %v", names, err)
+	}
+
+	if err := store.Set("Main", Credentials{AccessToken: "token-1", ClientSecret: "app-secret", UserID: "12345"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	creds, err := store.Get("main")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if creds.Name != "main" || creds.AccessToken != "token-1" || creds.ClientSecret != "app-secret" || creds.UserID != "12345" {
+		t.Errorf("unexpected credentials %+v", creds)
+	}
+
+	names, err := store.List()
+	if err != nil || strings.Join(names, ",") != "main" {
+		t.Errorf("expected [main] without nested paths, got %v, %v", names, err)
+	}
+
+	if err := store.Delete("main"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get("main"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestVaultStore_Errors(t *testing.T) {
+	server := newFakeVault(t, "root")
+	store, err := NewVaultStore(server.URL, "wrong", "", "kv", "apps/threads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("main"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("expected Vault's error message, got %v", err)
+	}
+
+	if _, err := NewVaultStore(server.URL, "", "", "", ""); err == nil {
+		t.Error("expected a missing token to be rejected")
+	}
+}
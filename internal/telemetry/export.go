@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileExporter appends each export request to a file as one line of JSON,
+// the format the OpenTelemetry Collector's file exporter writes and its
+// otlpjsonfile receiver reads.
+type FileExporter struct {
+	Path string
+}
+
+// Export implements Exporter.
+func (e *FileExporter) Export(_ context.Context, traces, metrics []byte) error {
+	if dir := filepath.Dir(e.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create trace file directory: %w", err)
+		}
+	}
+	//nolint:gosec // The trace file path is chosen by the local user
+	file, err := os.OpenFile(e.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, payload := range [][]byte{traces, metrics} {
+		if payload != nil {
+			buf.Write(payload)
+			buf.WriteByte('\n')
+		}
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close() //nolint:errcheck,gosec // Already returning the write error
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	return file.Close()
+}
+
+// OTLPExporter posts export requests to an OTLP/HTTP collector using the
+// JSON encoding.
+type OTLPExporter struct {
+	// Endpoint is the collector's base URL, such as http://localhost:4318.
+	// Traces go to /v1/traces and metrics to /v1/metrics under it.
+	Endpoint string
+
+	// TracesEndpoint and MetricsEndpoint are full URLs for one signal and
+	// take precedence over Endpoint, like
+	// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT and
+	// OTEL_EXPORTER_OTLP_METRICS_ENDPOINT. A signal with no URL is not
+	// exported.
+	TracesEndpoint  string
+	MetricsEndpoint string
+
+	// SkipTraces and SkipMetrics drop a signal, like
+	// OTEL_TRACES_EXPORTER=none and OTEL_METRICS_EXPORTER=none.
+	SkipTraces  bool
+	SkipMetrics bool
+
+	// Headers are added to every request, e.g. for authentication.
+	Headers map[string]string
+
+	// Client sends the requests. If nil, a client with a 10 second
+	// timeout is used.
+	Client *http.Client
+}
+
+// Export implements Exporter.
+func (e *OTLPExporter) Export(ctx context.Context, traces, metrics []byte) error {
+	if target := e.target(e.TracesEndpoint, "/v1/traces"); traces != nil && !e.SkipTraces && target != "" {
+		if err := e.post(ctx, target, traces); err != nil {
+			return err
+		}
+	}
+	if target := e.target(e.MetricsEndpoint, "/v1/metrics"); metrics != nil && !e.SkipMetrics && target != "" {
+		if err := e.post(ctx, target, metrics); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// target returns the URL a signal is exported to: its own endpoint if set,
+// otherwise path under Endpoint, or "" when neither is set.
+func (e *OTLPExporter) target(signalEndpoint, path string) string {
+	if signalEndpoint != "" {
+		return signalEndpoint
+	}
+	if e.Endpoint == "" {
+		return ""
+	}
+	return strings.TrimSuffix(e.Endpoint, "/") + path
+}
+
+func (e *OTLPExporter) post(ctx context.Context, target string, payload []byte) error {
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export to %s: %w", target, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // Best-effort close
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512)) //nolint:errcheck // Only used in the error message
+		return fmt.Errorf("failed to export to %s: HTTP %d: %s", target, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck,gosec // Draining so the connection can be reused
+	return nil
+}
+
+// ParseKeyValues parses the "key=value,key=value" lists used by
+// OTEL_EXPORTER_OTLP_HEADERS and OTEL_RESOURCE_ATTRIBUTES. Values may be
+// percent-encoded.
+func ParseKeyValues(spec string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid entry %q (expected key=value)", pair)
+		}
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,302 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+// The types below follow the JSON encoding of the OTLP trace and metrics
+// export requests. 64-bit integers are strings and IDs are hex, as the
+// encoding requires.
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"` // 2 is STATUS_CODE_ERROR
+	Message string `json:"message,omitempty"`
+}
+
+type otlpMetrics struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpMetric struct {
+	Name      string         `json:"name"`
+	Unit      string         `json:"unit,omitempty"`
+	Sum       *otlpSum       `json:"sum,omitempty"`
+	Histogram *otlpHistogram `json:"histogram,omitempty"`
+}
+
+// aggregationCumulative is AGGREGATION_TEMPORALITY_CUMULATIVE.
+const aggregationCumulative = 2
+
+type otlpSum struct {
+	DataPoints             []otlpNumberPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+type otlpNumberPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsInt             string         `json:"asInt"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []otlpHistogramPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+type otlpHistogramPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	Count             string         `json:"count"`
+	Sum               float64        `json:"sum"`
+	Min               float64        `json:"min"`
+	Max               float64        `json:"max"`
+	BucketCounts      []string       `json:"bucketCounts"`
+	ExplicitBounds    []float64      `json:"explicitBounds"`
+}
+
+// encodeLocked renders the recorded spans and metrics as OTLP JSON export
+// requests. A payload is nil when there is nothing of its kind. r.mu must
+// be held.
+func (r *Recorder) encodeLocked() (traces, metrics []byte) {
+	resource := otlpResource{Attributes: encodeAttrs(append([]api.Attribute{
+		api.Attr("service.name", r.service),
+		api.Attr("service.version", r.version),
+	}, r.resource...))}
+	scope := otlpScope{Name: scopeName, Version: r.version}
+
+	if len(r.spans) > 0 {
+		spans := make([]otlpSpan, 0, len(r.spans))
+		for _, s := range r.spans {
+			spans = append(spans, encodeSpan(s))
+		}
+		traces = mustMarshal(otlpTraces{ResourceSpans: []otlpResourceSpans{{
+			Resource:   resource,
+			ScopeSpans: []otlpScopeSpans{{Scope: scope, Spans: spans}},
+		}}})
+	}
+
+	if len(r.counters) == 0 && len(r.histograms) == 0 {
+		return traces, nil
+	}
+
+	start, now := unixNano(r.start), unixNano(r.now())
+	byName := map[string]*otlpMetric{}
+	var ordered []*otlpMetric
+	metric := func(name string) *otlpMetric {
+		if m, ok := byName[name]; ok {
+			return m
+		}
+		m := &otlpMetric{Name: name, Unit: units[name]}
+		if m.Unit == "" {
+			m.Unit = "1"
+		}
+		byName[name] = m
+		ordered = append(ordered, m)
+		return m
+	}
+
+	for _, key := range sortedKeys(r.counters) {
+		c := r.counters[key]
+		m := metric(c.name)
+		if m.Sum == nil {
+			m.Sum = &otlpSum{AggregationTemporality: aggregationCumulative, IsMonotonic: true}
+		}
+		m.Sum.DataPoints = append(m.Sum.DataPoints, otlpNumberPoint{
+			Attributes:        encodeAttrs(c.attrs),
+			StartTimeUnixNano: start,
+			TimeUnixNano:      now,
+			AsInt:             strconv.FormatInt(c.value, 10),
+		})
+	}
+	for _, key := range sortedKeys(r.histograms) {
+		h := r.histograms[key]
+		m := metric(h.name)
+		if m.Histogram == nil {
+			m.Histogram = &otlpHistogram{AggregationTemporality: aggregationCumulative}
+		}
+		buckets := make([]string, len(h.buckets))
+		for i, n := range h.buckets {
+			buckets[i] = strconv.FormatUint(n, 10)
+		}
+		m.Histogram.DataPoints = append(m.Histogram.DataPoints, otlpHistogramPoint{
+			Attributes:        encodeAttrs(h.attrs),
+			StartTimeUnixNano: start,
+			TimeUnixNano:      now,
+			Count:             strconv.FormatUint(h.count, 10),
+			Sum:               h.sum,
+			Min:               h.min,
+			Max:               h.max,
+			BucketCounts:      buckets,
+			ExplicitBounds:    DefaultBounds,
+		})
+	}
+
+	list := make([]otlpMetric, len(ordered))
+	for i, m := range ordered {
+		list[i] = *m
+	}
+	metrics = mustMarshal(otlpMetrics{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     resource,
+		ScopeMetrics: []otlpScopeMetrics{{Scope: scope, Metrics: list}},
+	}}})
+	return traces, metrics
+}
+
+func encodeSpan(s *span) otlpSpan {
+	encoded := otlpSpan{
+		TraceID:           s.traceID,
+		SpanID:            s.spanID,
+		ParentSpanID:      s.parentID,
+		Name:              s.name,
+		Kind:              s.kind(),
+		StartTimeUnixNano: unixNano(s.start),
+		EndTimeUnixNano:   unixNano(s.end),
+		Attributes:        encodeAttrs(s.attrs),
+	}
+	if s.err != nil {
+		encoded.Status = &otlpStatus{Code: 2, Message: s.err.Error()}
+		encoded.Events = []otlpEvent{{
+			TimeUnixNano: unixNano(s.end),
+			Name:         "exception",
+			Attributes: encodeAttrs([]api.Attribute{
+				api.Attr("exception.type", fmt.Sprintf("%T", s.err)),
+				api.Attr("exception.message", s.err.Error()),
+			}),
+		}}
+	}
+	return encoded
+}
+
+func encodeAttrs(attrs []api.Attribute) []otlpKeyValue {
+	encoded := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		encoded = append(encoded, otlpKeyValue{Key: attr.Key, Value: encodeValue(attr.Value)})
+	}
+	return encoded
+}
+
+func encodeValue(value any) otlpAnyValue {
+	integer := func(n int64) otlpAnyValue {
+		s := strconv.FormatInt(n, 10)
+		return otlpAnyValue{IntValue: &s}
+	}
+	switch v := value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		return integer(int64(v))
+	case int32:
+		return integer(int64(v))
+	case int64:
+		return integer(v)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			s := strconv.FormatFloat(v, 'g', -1, 64)
+			return otlpAnyValue{StringValue: &s}
+		}
+		return otlpAnyValue{DoubleValue: &v}
+	case time.Duration:
+		return integer(v.Milliseconds())
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// Only plain structs, strings and finite numbers are marshalled
+		panic(fmt.Sprintf("telemetry: %v", err))
+	}
+	return data
+}
@@ -0,0 +1,81 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FormatTraceParent returns the W3C Trace Context
+// (https://www.w3.org/TR/trace-context/) traceparent header value for a
+// sampled span.
+func FormatTraceParent(traceID, spanID string) string {
+	return fmt.Sprintf("00-%s-%s-01", traceID, spanID)
+}
+
+// ParseTraceParent returns the trace and parent span IDs of a traceparent
+// header value. Versions after 00 are accepted as long as they start with
+// the version 00 fields, as the specification requires.
+func ParseTraceParent(value string) (traceID, spanID string, err error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 55 || (len(value) > 55 && value[55] != '-') {
+		return "", "", errors.New("traceparent must be version-traceid-parentid-flags")
+	}
+	fields := strings.Split(value[:55], "-")
+	if len(fields) != 4 || len(fields[0]) != 2 || len(fields[1]) != 32 || len(fields[2]) != 16 || len(fields[3]) != 2 {
+		return "", "", errors.New("traceparent must be version-traceid-parentid-flags")
+	}
+	for _, field := range fields {
+		if !isLowerHex(field) {
+			return "", "", fmt.Errorf("traceparent field %q is not lowercase hex", field)
+		}
+	}
+	version, traceID, spanID := fields[0], fields[1], fields[2]
+	if version == "ff" || (version == "00" && len(value) != 55) {
+		return "", "", fmt.Errorf("unsupported traceparent version %s", version)
+	}
+	if isZero(traceID) || isZero(spanID) {
+		return "", "", errors.New("traceparent IDs must not be all zeros")
+	}
+	return traceID, spanID, nil
+}
+
+// WithRemoteParent returns ctx carrying the span described by a traceparent
+// header value, so spans started from it join that trace. traceState is
+// passed on unchanged with outgoing requests. An empty or invalid
+// traceparent returns ctx unchanged with the parse error, if any.
+func WithRemoteParent(ctx context.Context, traceParent, traceState string) (context.Context, error) {
+	if strings.TrimSpace(traceParent) == "" {
+		return ctx, nil
+	}
+	traceID, spanID, err := ParseTraceParent(traceParent)
+	if err != nil {
+		return ctx, err
+	}
+	remote := &span{traceID: traceID, spanID: spanID, traceState: strings.TrimSpace(traceState)}
+	return context.WithValue(ctx, spanKey{}, remote), nil
+}
+
+// TraceParent implements api.TraceContextSpan.
+func (s *span) TraceParent() string {
+	return FormatTraceParent(s.traceID, s.spanID)
+}
+
+// TraceState implements api.TraceContextSpan.
+func (s *span) TraceState() string {
+	return s.traceState
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
@@ -0,0 +1,293 @@
+// Package telemetry records the spans and metrics the API client reports
+// through api.Config.Telemetry and exports them in the OpenTelemetry
+// protocol's JSON encoding, either to a file or to an OTLP/HTTP collector.
+// Span context is propagated with W3C Trace Context headers.
+//
+// The OpenTelemetry Go SDK is not used on purpose: the CLI records a few
+// spans per command and flushes once on exit, which this package covers
+// without pulling the SDK's dependency tree into the binary.
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+// scopeName identifies the instrumentation in exported data.
+const scopeName = "github.com/salmonumbrella/threads-cli/internal/api"
+
+// Span kinds from the OTLP specification.
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+)
+
+// DefaultBounds are the histogram bucket boundaries in milliseconds. They
+// extend OpenTelemetry's defaults to cover container processing waits.
+var DefaultBounds = []float64{0, 5, 10, 25, 50, 75, 100, 250, 500, 750, 1000, 2500, 5000, 7500, 10000, 30000, 60000, 120000, 300000}
+
+// units of the metrics the API client records; others are unitless.
+var units = map[string]string{
+	api.MetricRequestDuration: "ms",
+	api.MetricRateLimitWait:   "ms",
+	api.MetricContainerWait:   "ms",
+}
+
+// Exporter sends encoded traces and metrics somewhere. Either payload may
+// be nil when there is nothing of that kind to export.
+type Exporter interface {
+	Export(ctx context.Context, traces, metrics []byte) error
+}
+
+// Recorder implements api.Telemetry. It keeps finished spans and
+// aggregated metrics in memory until Flush exports them.
+type Recorder struct {
+	service   string
+	version   string
+	resource  []api.Attribute
+	exporters []Exporter
+	now       func() time.Time
+
+	mu         sync.Mutex
+	start      time.Time
+	spans      []*span
+	counters   map[string]*counter
+	histograms map[string]*histogram
+}
+
+type span struct {
+	recorder   *Recorder
+	traceID    string
+	spanID     string
+	parentID   string
+	traceState string
+	name       string
+	start      time.Time
+	end        time.Time
+	attrs      []api.Attribute
+	err        error
+	ended      bool
+}
+
+var _ api.TraceContextSpan = (*span)(nil)
+
+type counter struct {
+	name  string
+	attrs []api.Attribute
+	value int64
+}
+
+type histogram struct {
+	name    string
+	attrs   []api.Attribute
+	count   uint64
+	sum     float64
+	min     float64
+	max     float64
+	buckets []uint64
+}
+
+// spanKey carries the current span in a context.
+type spanKey struct{}
+
+// New returns a recorder that exports to exporters when flushed. service
+// and version describe the program in exported data.
+func New(service, version string, exporters ...Exporter) *Recorder {
+	r := &Recorder{
+		service:   service,
+		version:   version,
+		exporters: exporters,
+		now:       time.Now,
+	}
+	r.reset()
+	return r
+}
+
+// SetResourceAttributes adds attributes, such as those from
+// OTEL_RESOURCE_ATTRIBUTES, to the resource describing the program in
+// exported data. service.name and service.version are always the values
+// given to New. Call it before recording anything.
+func (r *Recorder) SetResourceAttributes(attrs ...api.Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resource = r.resource[:0]
+	for _, attr := range sortedAttrs(attrs) {
+		if attr.Key != "service.name" && attr.Key != "service.version" {
+			r.resource = append(r.resource, attr)
+		}
+	}
+}
+
+// StartSpan implements api.Telemetry.
+func (r *Recorder) StartSpan(ctx context.Context, name string, attrs ...api.Attribute) (context.Context, api.Span) {
+	s := &span{
+		recorder: r,
+		spanID:   newID(8),
+		name:     name,
+		start:    r.now(),
+		attrs:    append([]api.Attribute(nil), attrs...),
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+		s.traceState = parent.traceState
+	} else {
+		s.traceID = newID(16)
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Count implements api.Telemetry.
+func (r *Recorder) Count(_ context.Context, name string, delta int64, attrs ...api.Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := seriesKey(name, attrs)
+	c, ok := r.counters[key]
+	if !ok {
+		c = &counter{name: name, attrs: sortedAttrs(attrs)}
+		r.counters[key] = c
+	}
+	c.value += delta
+}
+
+// Observe implements api.Telemetry.
+func (r *Recorder) Observe(_ context.Context, name string, value float64, attrs ...api.Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := seriesKey(name, attrs)
+	h, ok := r.histograms[key]
+	if !ok {
+		h = &histogram{
+			name:    name,
+			attrs:   sortedAttrs(attrs),
+			min:     value,
+			max:     value,
+			buckets: make([]uint64, len(DefaultBounds)+1),
+		}
+		r.histograms[key] = h
+	}
+	h.count++
+	h.sum += value
+	h.min = min(h.min, value)
+	h.max = max(h.max, value)
+	h.buckets[sort.SearchFloat64s(DefaultBounds, value)]++
+}
+
+// Flush exports everything recorded since the last flush and starts over.
+// Spans that have not ended are left out.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	traces, metrics := r.encodeLocked()
+	r.reset()
+	r.mu.Unlock()
+
+	if traces == nil && metrics == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, exporter := range r.exporters {
+		if err := exporter.Export(ctx, traces, metrics); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reset clears recorded data; r.mu must be held or r not yet shared.
+func (r *Recorder) reset() {
+	r.start = r.now()
+	r.spans = nil
+	r.counters = map[string]*counter{}
+	r.histograms = map[string]*histogram{}
+}
+
+// SetAttributes implements api.Span.
+func (s *span) SetAttributes(attrs ...api.Attribute) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	for _, attr := range attrs {
+		replaced := false
+		for i := range s.attrs {
+			if s.attrs[i].Key == attr.Key {
+				s.attrs[i] = attr
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.attrs = append(s.attrs, attr)
+		}
+	}
+}
+
+// RecordError implements api.Span.
+func (s *span) RecordError(err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.err = err
+}
+
+// End implements api.Span. Only the first call has an effect.
+func (s *span) End() {
+	end := s.recorder.now()
+
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.ended = true
+	s.end = end
+	s.recorder.spans = append(s.recorder.spans, s)
+}
+
+// kind reports whether the span covers a single HTTP exchange.
+func (s *span) kind() int {
+	if strings.HasPrefix(s.name, "HTTP ") {
+		return spanKindClient
+	}
+	return spanKindInternal
+}
+
+// seriesKey identifies a metric series by name and attribute set.
+func seriesKey(name string, attrs []api.Attribute) string {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, attr := range sortedAttrs(attrs) {
+		fmt.Fprintf(&b, "|%s=%v", attr.Key, attr.Value)
+	}
+	return b.String()
+}
+
+func sortedAttrs(attrs []api.Attribute) []api.Attribute {
+	sorted := append([]api.Attribute(nil), attrs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
+
+// newID returns n random bytes, hex encoded, for trace and span IDs.
+func newID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to
+		// the clock so IDs stay unique enough for a local trace
+		return fmt.Sprintf("%0*x", n*2, time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
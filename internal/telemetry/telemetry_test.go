@@ -0,0 +1,270 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+func TestRecorder_SpansAndMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "trace.jsonl")
+	r := New("threads-cli", "1.2.3", &FileExporter{Path: path})
+
+	ctx, parent := r.StartSpan(context.Background(), "threads posts create")
+	_, child := r.StartSpan(ctx, "HTTP POST", api.Attr("threads.endpoint", "/me/threads"), api.Attr("threads.attempt", 1))
+	child.SetAttributes(api.Attr("http.response.status_code", 500), api.Attr("threads.attempt", 2))
+	child.RecordError(errors.New("server error"))
+	child.End()
+	child.End()
+	parent.End()
+
+	r.Count(ctx, api.MetricRequests, 1, api.Attr("threads.endpoint", "/me"))
+	r.Count(ctx, api.MetricRequests, 2, api.Attr("threads.endpoint", "/me"))
+	r.Observe(ctx, api.MetricRequestDuration, 30, api.Attr("threads.endpoint", "/me"))
+	r.Observe(ctx, api.MetricRequestDuration, 7000, api.Attr("threads.endpoint", "/me"))
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	// Nothing new was recorded, so a second flush writes nothing
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("second Flush failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a traces line and a metrics line, got %d:\n%s", len(lines), data)
+	}
+
+	var traces otlpTraces
+	if err := json.Unmarshal([]byte(lines[0]), &traces); err != nil {
+		t.Fatalf("invalid traces JSON: %v", err)
+	}
+	spans := traces.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	httpSpan, cmdSpan := spans[0], spans[1]
+	if httpSpan.TraceID != cmdSpan.TraceID || httpSpan.ParentSpanID != cmdSpan.SpanID || cmdSpan.ParentSpanID != "" {
+		t.Errorf("expected the HTTP span to be a child of the command span: %+v %+v", httpSpan, cmdSpan)
+	}
+	if len(httpSpan.TraceID) != 32 || len(httpSpan.SpanID) != 16 {
+		t.Errorf("unexpected ID lengths: %q %q", httpSpan.TraceID, httpSpan.SpanID)
+	}
+	if httpSpan.Kind != spanKindClient || cmdSpan.Kind != spanKindInternal {
+		t.Errorf("unexpected span kinds: %d %d", httpSpan.Kind, cmdSpan.Kind)
+	}
+	if httpSpan.Status == nil || httpSpan.Status.Code != 2 || httpSpan.Status.Message != "server error" {
+		t.Errorf("expected an error status, got %+v", httpSpan.Status)
+	}
+	attrs := map[string]otlpAnyValue{}
+	for _, kv := range httpSpan.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs["threads.attempt"].IntValue; v == nil || *v != "2" {
+		t.Errorf("expected the replaced attempt attribute, got %+v", attrs["threads.attempt"])
+	}
+	if v := attrs["threads.endpoint"].StringValue; v == nil || *v != "/me/threads" {
+		t.Errorf("expected the endpoint attribute, got %+v", attrs["threads.endpoint"])
+	}
+
+	var metrics otlpMetrics
+	if err := json.Unmarshal([]byte(lines[1]), &metrics); err != nil {
+		t.Fatalf("invalid metrics JSON: %v", err)
+	}
+	byName := map[string]otlpMetric{}
+	for _, m := range metrics.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		byName[m.Name] = m
+	}
+	requests := byName[api.MetricRequests]
+	if requests.Sum == nil || len(requests.Sum.DataPoints) != 1 || requests.Sum.DataPoints[0].AsInt != "3" {
+		t.Errorf("expected the request counter to sum to 3, got %+v", requests)
+	}
+	duration := byName[api.MetricRequestDuration]
+	if duration.Unit != "ms" || duration.Histogram == nil {
+		t.Fatalf("expected a duration histogram in ms, got %+v", duration)
+	}
+	point := duration.Histogram.DataPoints[0]
+	if point.Count != "2" || point.Sum != 7030 || point.Min != 30 || point.Max != 7000 {
+		t.Errorf("unexpected histogram point: %+v", point)
+	}
+	if len(point.BucketCounts) != len(point.ExplicitBounds)+1 {
+		t.Errorf("expected one more bucket than bounds, got %d and %d", len(point.BucketCounts), len(point.ExplicitBounds))
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received[r.URL.Path] = r.Header.Get("Content-Type") + " " + r.Header.Get("Authorization") + " " + string(body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	r := New("threads-cli", "dev", &OTLPExporter{
+		Endpoint: server.URL + "/",
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+	})
+	_, span := r.StartSpan(context.Background(), "threads me")
+	span.End()
+	r.Count(context.Background(), api.MetricRetries, 1)
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got := received["/v1/traces"]; !strings.HasPrefix(got, "application/json Bearer secret ") || !strings.Contains(got, `"threads me"`) {
+		t.Errorf("unexpected traces export: %s", got)
+	}
+	if got := received["/v1/metrics"]; !strings.Contains(got, api.MetricRetries) {
+		t.Errorf("unexpected metrics export: %s", got)
+	}
+}
+
+func TestOTLPExporter_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	r := New("threads-cli", "dev", &OTLPExporter{Endpoint: server.URL})
+	_, span := r.StartSpan(context.Background(), "threads me")
+	span.End()
+
+	err := r.Flush(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "HTTP 400: bad payload") {
+		t.Errorf("expected the collector error, got %v", err)
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	headers, err := ParseKeyValues("authorization=Bearer%20abc, x-team = threads ,")
+	if err != nil {
+		t.Fatalf("ParseKeyValues failed: %v", err)
+	}
+	if headers["authorization"] != "Bearer abc" || headers["x-team"] != "threads" || len(headers) != 2 {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+
+	if _, err := ParseKeyValues("missing-value"); err == nil {
+		t.Error("expected an error for a pair without =")
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	traceID, spanID, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil || traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || spanID != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected parse: %q %q %v", traceID, spanID, err)
+	}
+	if _, _, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future"); err != nil {
+		t.Errorf("expected a later version with extra fields to parse, got %v", err)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-600f067aa0ba902b7-01",
+	} {
+		if _, _, err := ParseTraceParent(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestWithRemoteParent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	r := New("threads-cli", "dev", &FileExporter{Path: path})
+	r.SetResourceAttributes(api.Attr("deployment.environment", "ci"), api.Attr("service.name", "ignored"))
+
+	ctx, err := WithRemoteParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=value")
+	if err != nil {
+		t.Fatalf("WithRemoteParent failed: %v", err)
+	}
+	_, s := r.StartSpan(ctx, "threads me")
+	tc, ok := s.(api.TraceContextSpan)
+	if !ok {
+		t.Fatal("expected spans to carry a trace context")
+	}
+	if got := tc.TraceParent(); !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || strings.Contains(got, "00f067aa0ba902b7") {
+		t.Errorf("expected a new span in the remote trace, got %q", got)
+	}
+	if tc.TraceState() != "vendor=value" {
+		t.Errorf("expected the tracestate to be passed on, got %q", tc.TraceState())
+	}
+	s.End()
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var traces otlpTraces
+	if err := json.Unmarshal([]byte(strings.SplitN(string(data), "\n", 2)[0]), &traces); err != nil {
+		t.Fatalf("invalid traces JSON: %v", err)
+	}
+	if got := traces.ResourceSpans[0].ScopeSpans[0].Spans[0].ParentSpanID; got != "00f067aa0ba902b7" {
+		t.Errorf("expected the remote span as parent, got %q", got)
+	}
+	resource := map[string]string{}
+	for _, kv := range traces.ResourceSpans[0].Resource.Attributes {
+		if kv.Value.StringValue != nil {
+			resource[kv.Key] = *kv.Value.StringValue
+		}
+	}
+	if resource["service.name"] != "threads-cli" || resource["deployment.environment"] != "ci" {
+		t.Errorf("unexpected resource attributes: %v", resource)
+	}
+
+	if _, err := WithRemoteParent(context.Background(), "garbage", ""); err == nil {
+		t.Error("expected an invalid traceparent to be reported")
+	}
+}
+
+func TestOTLPExporter_SignalEndpoints(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	r := New("threads-cli", "dev", &OTLPExporter{
+		Endpoint:       server.URL,
+		TracesEndpoint: server.URL + "/custom/traces",
+		SkipMetrics:    true,
+	})
+	_, span := r.StartSpan(context.Background(), "threads me")
+	span.End()
+	r.Count(context.Background(), api.MetricRetries, 1)
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if strings.Join(paths, ",") != "/custom/traces" {
+		t.Errorf("expected only traces at the signal endpoint, got %v", paths)
+	}
+}
@@ -0,0 +1,297 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+	"github.com/salmonumbrella/threads-cli/internal/outfmt"
+)
+
+const (
+	defaultActionTimeout = 30 * time.Second
+	defaultRetries       = 3
+)
+
+// Action does something with a received event. body is the event's JSON.
+type Action interface {
+	Run(ctx context.Context, event Event, body []byte) error
+}
+
+// Dispatcher routes events to the actions configured for their type.
+type Dispatcher struct {
+	routes []*route
+}
+
+type route struct {
+	name    string
+	events  map[api.WebhookEventType]bool
+	filter  *outfmt.Query
+	timeout time.Duration
+	action  Action
+}
+
+// NewDispatcher builds a Dispatcher from configured actions. Command output
+// is written to stderr, keeping stdout free for the event stream.
+func NewDispatcher(actions []config.WebhookAction, client *http.Client, stderr io.Writer) (*Dispatcher, error) {
+	if client == nil {
+		client = &http.Client{}
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	d := &Dispatcher{}
+	for i, cfg := range actions {
+		name := cfg.Name
+		if name == "" {
+			name = fmt.Sprintf("action %d (%s)", i+1, cfg.Type)
+		}
+
+		r, err := newRoute(name, cfg, client, stderr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		d.routes = append(d.routes, r)
+	}
+	return d, nil
+}
+
+func newRoute(name string, cfg config.WebhookAction, client *http.Client, stderr io.Writer) (*route, error) {
+	r := &route{name: name, timeout: defaultActionTimeout}
+
+	if len(cfg.Events) > 0 {
+		r.events = make(map[api.WebhookEventType]bool, len(cfg.Events))
+		for _, e := range cfg.Events {
+			switch t := api.WebhookEventType(strings.ToLower(e)); t {
+			case api.WebhookEventMentions, api.WebhookEventPublishes, api.WebhookEventDeletes:
+				r.events[t] = true
+			default:
+				return nil, fmt.Errorf("unknown event type %q (valid: mentions, publishes, deletes)", e)
+			}
+		}
+	}
+
+	if cfg.Filter != "" {
+		q, err := outfmt.ParseQuery(cfg.Filter)
+		if err != nil {
+			return nil, err
+		}
+		r.filter = q
+	}
+
+	if cfg.Timeout != "" {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", cfg.Timeout)
+		}
+		r.timeout = timeout
+	}
+
+	switch cfg.Type {
+	case "command":
+		if len(cfg.Command) == 0 {
+			return nil, errors.New("command actions need a command")
+		}
+		r.action = &CommandAction{Argv: cfg.Command, Stderr: stderr}
+	case "http":
+		if cfg.URL == "" {
+			return nil, errors.New("http actions need a url")
+		}
+		headers := make(map[string]string, len(cfg.Headers))
+		for k, v := range cfg.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		retries := cfg.Retries
+		switch {
+		case retries == 0:
+			retries = defaultRetries
+		case retries < 0:
+			retries = 0
+		}
+		r.action = &HTTPAction{
+			URL:     os.ExpandEnv(cfg.URL),
+			Headers: headers,
+			Retries: retries,
+			Timeout: r.timeout,
+			Client:  client,
+			Backoff: time.Second,
+		}
+	case "file":
+		if cfg.Path == "" {
+			return nil, errors.New("file actions need a path")
+		}
+		r.action = &FileAction{Path: os.ExpandEnv(cfg.Path)}
+	default:
+		return nil, fmt.Errorf("unknown action type %q (valid: command, http, file)", cfg.Type)
+	}
+
+	return r, nil
+}
+
+// Len returns the number of configured actions.
+func (d *Dispatcher) Len() int {
+	return len(d.routes)
+}
+
+// Dispatch runs every matching action for event in configuration order. A
+// failing action does not stop the others; their errors are joined.
+func (d *Dispatcher) Dispatch(ctx context.Context, event Event) error {
+	if len(d.routes) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, r := range d.routes {
+		if r.events != nil && !r.events[event.Type] {
+			continue
+		}
+		if r.filter != nil {
+			matched, err := r.filter.Match(event)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: filter: %w", r.name, err))
+				continue
+			}
+			if !matched {
+				continue
+			}
+		}
+		if err := r.run(ctx, event, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *route) run(ctx context.Context, event Event, body []byte) error {
+	// HTTP actions apply the timeout per attempt.
+	if _, ok := r.action.(*HTTPAction); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	return r.action.Run(ctx, event, body)
+}
+
+// CommandAction runs a local command with the event JSON on stdin. The
+// event type is also exported as THREADS_WEBHOOK_EVENT.
+type CommandAction struct {
+	Argv   []string
+	Stderr io.Writer
+}
+
+// Run implements Action.
+func (a *CommandAction) Run(ctx context.Context, event Event, body []byte) error {
+	//nolint:gosec // The command comes from the user's own config file.
+	cmd := exec.CommandContext(ctx, a.Argv[0], a.Argv[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = a.Stderr
+	cmd.Stderr = a.Stderr
+	cmd.Env = append(os.Environ(), "THREADS_WEBHOOK_EVENT="+string(event.Type))
+	return cmd.Run()
+}
+
+// HTTPAction POSTs the event JSON to a URL, retrying network errors, 429 and
+// 5xx responses with exponential backoff.
+type HTTPAction struct {
+	URL     string
+	Headers map[string]string
+	Retries int
+	Timeout time.Duration
+	Client  *http.Client
+	Backoff time.Duration
+}
+
+// Run implements Action.
+func (a *HTTPAction) Run(ctx context.Context, event Event, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= a.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(a.Backoff << (attempt - 1)):
+			}
+		}
+
+		retry, err := a.post(ctx, event, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (a *HTTPAction) post(ctx context.Context, event Event, body []byte) (retry bool, err error) {
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Threads-Webhook-Event", string(event.Type))
+	for k, v := range a.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()               //nolint:errcheck // Best-effort cleanup
+	_, _ = io.Copy(io.Discard, resp.Body) // Drain so the connection is reused
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("POST %s returned %s", a.URL, resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// FileAction appends the event JSON to a file, one line per event.
+type FileAction struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Run implements Action.
+func (a *FileAction) Run(_ context.Context, _ Event, body []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	//nolint:gosec // The path comes from the user's own config file.
+	file, err := os.OpenFile(a.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(body, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,155 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+	"github.com/salmonumbrella/threads-cli/internal/config"
+)
+
+func testEvents() []Event {
+	return []Event{
+		{Type: api.WebhookEventMentions, Mention: &MentionEvent{Post: Post{ID: "m1", Username: "alice", Text: "urgent: help"}}},
+		{Type: api.WebhookEventMentions, Mention: &MentionEvent{Post: Post{ID: "m2", Username: "bob", Text: "hello"}}},
+		{Type: api.WebhookEventDeletes, Delete: &DeleteEvent{ID: "d1"}},
+	}
+}
+
+func TestDispatcher_FileActionWithEventsAndFilter(t *testing.T) {
+	dir := t.TempDir()
+	all := filepath.Join(dir, "all.jsonl")
+	urgent := filepath.Join(dir, "urgent.jsonl")
+
+	d, err := NewDispatcher([]config.WebhookAction{
+		{Type: "file", Path: all},
+		{Type: "file", Path: urgent, Events: []string{"mentions"}, Filter: `.mention.text | startswith("urgent")`},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+
+	for _, e := range testEvents() {
+		if err := d.Dispatch(context.Background(), e); err != nil {
+			t.Fatalf("Dispatch: %v", err)
+		}
+	}
+
+	if lines := readLines(t, all); len(lines) != 3 {
+		t.Errorf("expected 3 events in %s, got %d", all, len(lines))
+	}
+	lines := readLines(t, urgent)
+	if len(lines) != 1 || !strings.Contains(lines[0], `"id":"m1"`) {
+		t.Errorf("expected only the urgent mention, got %v", lines)
+	}
+}
+
+func TestDispatcher_CommandAction(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.json")
+	d, err := NewDispatcher([]config.WebhookAction{
+		{Type: "command", Events: []string{"deletes"}, Command: []string{"sh", "-c", `cat > "$0"; echo "$THREADS_WEBHOOK_EVENT" >> "$0"`, out}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+
+	for _, e := range testEvents() {
+		if err := d.Dispatch(context.Background(), e); err != nil {
+			t.Fatalf("Dispatch: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"delete":{"id":"d1"}`) || !strings.HasSuffix(string(data), "deletes\n") {
+		t.Errorf("unexpected command input: %s", data)
+	}
+}
+
+func TestDispatcher_HTTPActionRetries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" || r.Header.Get("X-Threads-Webhook-Event") != "deletes" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	t.Setenv("FORWARD_TOKEN", "s3cret")
+	d, err := NewDispatcher([]config.WebhookAction{
+		{Type: "http", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer $FORWARD_TOKEN"}},
+	}, server.Client(), nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	d.routes[0].action.(*HTTPAction).Backoff = 0
+
+	if err := d.Dispatch(context.Background(), testEvents()[2]); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts.Load())
+	}
+}
+
+func TestDispatcher_HTTPActionDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	d, err := NewDispatcher([]config.WebhookAction{{Name: "forward", Type: "http", URL: server.URL}}, server.Client(), nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	d.routes[0].action.(*HTTPAction).Backoff = 0
+
+	err = d.Dispatch(context.Background(), testEvents()[0])
+	if err == nil || !strings.Contains(err.Error(), "forward: POST") {
+		t.Fatalf("expected a named action error, got %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts.Load())
+	}
+}
+
+func TestNewDispatcher_InvalidConfig(t *testing.T) {
+	tests := []config.WebhookAction{
+		{Type: "email"},
+		{Type: "command"},
+		{Type: "http"},
+		{Type: "file"},
+		{Type: "file", Path: "x", Events: []string{"likes"}},
+		{Type: "file", Path: "x", Filter: ".["},
+		{Type: "file", Path: "x", Timeout: "soon"},
+	}
+	for _, action := range tests {
+		if _, err := NewDispatcher([]config.WebhookAction{action}, nil, nil); err == nil {
+			t.Errorf("expected error for %+v", action)
+		}
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
@@ -0,0 +1,104 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+// timestampLayout is the layout the Threads API uses for post timestamps.
+const timestampLayout = "2006-01-02T15:04:05-0700"
+
+// NewPayload wraps events in the Graph API envelope Meta delivers, one entry
+// per event. It is the inverse of Decode.
+func NewPayload(events ...Event) (*Payload, error) {
+	payload := &Payload{Object: "user"}
+	for _, event := range events {
+		if event.Object != "" {
+			payload.Object = event.Object
+		}
+
+		var value any
+		switch {
+		case event.Mention != nil:
+			value = event.Mention
+		case event.Publish != nil:
+			value = event.Publish
+		case event.Delete != nil:
+			value = event.Delete
+		case event.Raw != nil:
+			value = event.Raw
+		default:
+			return nil, fmt.Errorf("%s event has no value", event.Type)
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		var sec int64
+		if !event.Time.IsZero() {
+			sec = event.Time.Unix()
+		}
+		payload.Entry = append(payload.Entry, Entry{
+			ID:      event.TargetID,
+			Time:    sec,
+			Changes: []Change{{Field: string(event.Type), Value: raw}},
+		})
+	}
+	return payload, nil
+}
+
+// Fixture returns a realistic sample event of the given type for userID,
+// timestamped at now.
+func Fixture(eventType api.WebhookEventType, userID string, now time.Time) (Event, error) {
+	now = now.UTC().Truncate(time.Second)
+	event := Event{
+		Type:     eventType,
+		Object:   "user",
+		TargetID: userID,
+		Time:     now,
+	}
+	stamp := now.Format(timestampLayout)
+
+	switch eventType {
+	case api.WebhookEventMentions:
+		event.Mention = &MentionEvent{
+			Post: Post{
+				ID:        "18062724436543210",
+				Username:  "sample_friend",
+				Text:      "Have you seen this, @you? Would love your take.",
+				MediaType: "TEXT_POST",
+				Permalink: "https://www.threads.net/@sample_friend/post/C9xQ2mVv1Ab",
+				Shortcode: "C9xQ2mVv1Ab",
+				Timestamp: stamp,
+			},
+			RootPost: &PostRef{
+				ID:       "18062724436543210",
+				OwnerID:  "17841400000000001",
+				Username: "sample_friend",
+			},
+		}
+	case api.WebhookEventPublishes:
+		event.Publish = &PublishEvent{Post: Post{
+			ID:        "18044512987654321",
+			Username:  "you",
+			Text:      "Shipping a new release today 🚀",
+			MediaType: "IMAGE",
+			MediaURL:  "https://scontent.cdninstagram.com/v/t51.2885-15/sample.jpg",
+			Permalink: "https://www.threads.net/@you/post/C9yR3nWw2Bc",
+			Shortcode: "C9yR3nWw2Bc",
+			Timestamp: stamp,
+		}}
+	case api.WebhookEventDeletes:
+		event.Delete = &DeleteEvent{
+			ID:        "18044512987654321",
+			Timestamp: stamp,
+		}
+	default:
+		return Event{}, fmt.Errorf("unknown event type %q (valid: mentions, publishes, deletes)", eventType)
+	}
+	return event, nil
+}
@@ -0,0 +1,159 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+// Payload is the body Meta POSTs to a webhook callback. Deliveries use either
+// the Graph API envelope (object/entry/changes) or the Threads form, which
+// carries a single change under "values".
+type Payload struct {
+	Object string  `json:"object,omitempty"`
+	Entry  []Entry `json:"entry,omitempty"`
+
+	AppID          string  `json:"app_id,omitempty"`
+	Topic          string  `json:"topic,omitempty"`
+	TargetID       string  `json:"target_id,omitempty"`
+	Time           int64   `json:"time,omitempty"`
+	SubscriptionID string  `json:"subscription_id,omitempty"`
+	Values         *Change `json:"values,omitempty"`
+}
+
+// Entry groups the changes for one subscribed object.
+type Entry struct {
+	ID      string   `json:"id"`
+	Time    int64    `json:"time"`
+	Changes []Change `json:"changes"`
+}
+
+// Change is a single field change inside an entry.
+type Change struct {
+	Field string          `json:"field"`
+	Value json.RawMessage `json:"value"`
+}
+
+// PostRef identifies a related post, such as the root of a conversation.
+type PostRef struct {
+	ID       string `json:"id"`
+	OwnerID  string `json:"owner_id,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// Post holds the post fields Meta includes in mention and publish events.
+type Post struct {
+	ID          string `json:"id"`
+	Username    string `json:"username,omitempty"`
+	Text        string `json:"text,omitempty"`
+	MediaType   string `json:"media_type,omitempty"`
+	MediaURL    string `json:"media_url,omitempty"`
+	Permalink   string `json:"permalink,omitempty"`
+	Shortcode   string `json:"shortcode,omitempty"`
+	Timestamp   string `json:"timestamp,omitempty"`
+	IsQuotePost bool   `json:"is_quote_post,omitempty"`
+}
+
+// MentionEvent is delivered when someone mentions the account in a post.
+type MentionEvent struct {
+	Post
+	RootPost  *PostRef `json:"root_post,omitempty"`
+	RepliedTo *PostRef `json:"replied_to,omitempty"`
+}
+
+// PublishEvent is delivered when the account publishes a post.
+type PublishEvent struct {
+	Post
+}
+
+// DeleteEvent is delivered when one of the account's posts is deleted.
+type DeleteEvent struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// Event is one decoded webhook change. Exactly one of Mention, Publish or
+// Delete is set for the known event types; other fields keep their raw value.
+type Event struct {
+	Type     api.WebhookEventType `json:"type"`
+	Object   string               `json:"object,omitempty"`
+	TargetID string               `json:"target_id,omitempty"`
+	Time     time.Time            `json:"time"`
+
+	Mention *MentionEvent   `json:"mention,omitempty"`
+	Publish *PublishEvent   `json:"publish,omitempty"`
+	Delete  *DeleteEvent    `json:"delete,omitempty"`
+	Raw     json.RawMessage `json:"raw,omitempty"`
+}
+
+// Decode parses a webhook body into its events.
+func Decode(body []byte) ([]Event, error) {
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	var events []Event
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			event, err := decodeChange(change)
+			if err != nil {
+				return nil, err
+			}
+			event.Object = payload.Object
+			event.TargetID = entry.ID
+			event.Time = unixTime(entry.Time)
+			events = append(events, event)
+		}
+	}
+
+	if payload.Values != nil {
+		event, err := decodeChange(*payload.Values)
+		if err != nil {
+			return nil, err
+		}
+		event.Object = payload.Object
+		event.TargetID = payload.TargetID
+		event.Time = unixTime(payload.Time)
+		events = append(events, event)
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("webhook payload contains no changes")
+	}
+	return events, nil
+}
+
+func decodeChange(change Change) (Event, error) {
+	event := Event{Type: api.WebhookEventType(change.Field)}
+
+	var target any
+	switch event.Type {
+	case api.WebhookEventMentions:
+		event.Mention = &MentionEvent{}
+		target = event.Mention
+	case api.WebhookEventPublishes:
+		event.Publish = &PublishEvent{}
+		target = event.Publish
+	case api.WebhookEventDeletes:
+		event.Delete = &DeleteEvent{}
+		target = event.Delete
+	default:
+		event.Raw = change.Value
+		return event, nil
+	}
+
+	if err := json.Unmarshal(change.Value, target); err != nil {
+		return Event{}, fmt.Errorf("invalid %s event: %w", change.Field, err)
+	}
+	return event, nil
+}
+
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
@@ -0,0 +1,90 @@
+package webhooks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Capture is one delivery read from a capture file, ready to be re-sent.
+type Capture struct {
+	Line int
+	Type string
+	Time time.Time
+	Body []byte
+}
+
+// ReadCaptures reads a JSONL capture file. Each line is either a raw Meta
+// payload (with "entry" or "values") or an event as printed by
+// 'webhooks serve', which is wrapped back into a payload. Blank lines are
+// skipped.
+func ReadCaptures(r io.Reader) ([]Capture, error) {
+	var captures []Capture
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBodyBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		capture, err := parseCapture(data)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		capture.Line = line
+		captures = append(captures, capture)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return captures, nil
+}
+
+func parseCapture(data []byte) (Capture, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return Capture{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var body []byte
+	if _, ok := keys["entry"]; ok {
+		body = append([]byte(nil), data...)
+	} else if _, ok := keys["values"]; ok {
+		body = append([]byte(nil), data...)
+	} else {
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil || event.Type == "" {
+			return Capture{}, fmt.Errorf("not a webhook payload or event")
+		}
+		payload, err := NewPayload(event)
+		if err != nil {
+			return Capture{}, err
+		}
+		if body, err = json.Marshal(payload); err != nil {
+			return Capture{}, err
+		}
+	}
+
+	events, err := Decode(body)
+	if err != nil {
+		return Capture{}, err
+	}
+	return Capture{Type: string(events[0].Type), Time: events[0].Time, Body: body}, nil
+}
+
+// ReplayDelay returns how long to wait before sending next after prev to
+// keep their original spacing, divided by speed. A speed of zero or less, or
+// captures without timestamps, mean no delay.
+func ReplayDelay(prev, next Capture, speed float64) time.Duration {
+	if speed <= 0 || prev.Time.IsZero() || next.Time.IsZero() || !next.Time.After(prev.Time) {
+		return 0
+	}
+	return time.Duration(float64(next.Time.Sub(prev.Time)) / speed)
+}
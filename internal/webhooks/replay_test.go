@@ -0,0 +1,74 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+func TestFixture_RoundTrip(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, eventType := range []api.WebhookEventType{api.WebhookEventMentions, api.WebhookEventPublishes, api.WebhookEventDeletes} {
+		event, err := Fixture(eventType, "12345", now)
+		if err != nil {
+			t.Fatalf("Fixture(%s): %v", eventType, err)
+		}
+		payload, err := NewPayload(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := json.Marshal(payload)
+
+		events, err := Decode(body)
+		if err != nil {
+			t.Fatalf("Decode(%s): %v", eventType, err)
+		}
+		if len(events) != 1 || events[0].Type != eventType || events[0].TargetID != "12345" || !events[0].Time.Equal(now) {
+			t.Errorf("round trip mismatch for %s: %+v", eventType, events)
+		}
+	}
+
+	if _, err := Fixture("likes", "1", now); err == nil {
+		t.Error("expected error for unknown event type")
+	}
+}
+
+func TestReadCaptures(t *testing.T) {
+	input := strings.Join([]string{
+		`{"object":"user","entry":[{"id":"1","time":1700000000,"changes":[{"field":"deletes","value":{"id":"d1"}}]}]}`,
+		``,
+		`{"type":"mentions","target_id":"1","time":"2023-11-14T22:13:30Z","mention":{"id":"m1","text":"hi"}}`,
+		`{"app_id":"9","target_id":"1","time":1700000030,"values":{"field":"publishes","value":{"id":"p1"}}}`,
+	}, "\n")
+
+	captures, err := ReadCaptures(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadCaptures: %v", err)
+	}
+	if len(captures) != 3 {
+		t.Fatalf("expected 3 captures, got %d", len(captures))
+	}
+	if captures[0].Type != "deletes" || captures[1].Type != "mentions" || captures[2].Type != "publishes" {
+		t.Errorf("unexpected types: %+v", captures)
+	}
+	if captures[1].Line != 3 || !strings.Contains(string(captures[1].Body), `"entry"`) {
+		t.Errorf("expected event line to be wrapped in a payload: %s", captures[1].Body)
+	}
+
+	if d := ReplayDelay(captures[0], captures[1], 1); d != 10*time.Second {
+		t.Errorf("delay = %v, want 10s", d)
+	}
+	if d := ReplayDelay(captures[1], captures[2], 10); d != 2*time.Second {
+		t.Errorf("delay at 10x = %v, want 2s", d)
+	}
+	if d := ReplayDelay(captures[0], captures[2], 0); d != 0 {
+		t.Errorf("delay with speed 0 = %v, want 0", d)
+	}
+
+	if _, err := ReadCaptures(strings.NewReader(`{"hello":"world"}`)); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected a line error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"crypto/subtle"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// maxBodyBytes bounds the size of a webhook delivery.
+const maxBodyBytes = 1 << 20
+
+// Handler receives webhook deliveries from Meta. GET requests answer the
+// hub.challenge verification handshake; POST requests are checked against
+// the X-Hub-Signature-256 header, decoded, and passed to OnEvent.
+type Handler struct {
+	verifyToken string
+	appSecret   string
+	onEvent     func(Event) error
+	mu          sync.Mutex
+}
+
+// NewHandler creates a Handler. verifyToken must match the token passed when
+// subscribing, and appSecret is the Meta app secret deliveries are signed
+// with. onEvent is called once per event, never concurrently; returning an
+// error makes the delivery fail so Meta retries it.
+func NewHandler(verifyToken, appSecret string, onEvent func(Event) error) *Handler {
+	return &Handler{
+		verifyToken: verifyToken,
+		appSecret:   appSecret,
+		onEvent:     onEvent,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleVerify(w, r)
+	case http.MethodPost:
+		h.handleDelivery(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	token := q.Get("hub.verify_token")
+	if q.Get("hub.mode") != "subscribe" || h.verifyToken == "" ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(h.verifyToken)) != 1 {
+		slog.Warn("webhook verification rejected", "mode", q.Get("hub.mode"))
+		http.Error(w, "Verification failed", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, q.Get("hub.challenge"))
+}
+
+func (h *Handler) handleDelivery(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxBodyBytes {
+		http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if !VerifySignature(h.appSecret, body, r.Header.Get(SignatureHeader)) {
+		slog.Warn("webhook signature mismatch", "remote", r.RemoteAddr)
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	events, err := Decode(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, event := range events {
+		if err := h.onEvent(event); err != nil {
+			slog.Error("webhook event handler failed", "type", event.Type, "error", err)
+			http.Error(w, "Event handler failed", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, "EVENT_RECEIVED")
+}
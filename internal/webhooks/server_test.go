@@ -0,0 +1,131 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/threads-cli/internal/api"
+)
+
+const testSecret = "app-secret"
+
+func TestHandler_Verify(t *testing.T) {
+	h := NewHandler("verify-me", testSecret, func(Event) error { return nil })
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"valid", "hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=1158201444", http.StatusOK, "1158201444"},
+		{"wrong token", "hub.mode=subscribe&hub.verify_token=nope&hub.challenge=1", http.StatusForbidden, ""},
+		{"wrong mode", "hub.mode=unsubscribe&hub.verify_token=verify-me&hub.challenge=1", http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Delivery(t *testing.T) {
+	body := `{"object":"user","entry":[{"id":"12345","time":1700000000,"changes":[
+		{"field":"mentions","value":{"id":"m1","username":"alice","text":"hi @me","root_post":{"id":"r1"}}},
+		{"field":"publishes","value":{"id":"p1","text":"hello","media_type":"TEXT_POST"}},
+		{"field":"deletes","value":{"id":"d1"}},
+		{"field":"replies","value":{"id":"x1"}}
+	]}]}`
+
+	var got []Event
+	h := NewHandler("verify-me", testSecret, func(e Event) error {
+		got = append(got, e)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, Sign(testSecret, []byte(body)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(got))
+	}
+	if got[0].Type != api.WebhookEventMentions || got[0].Mention == nil || got[0].Mention.Username != "alice" || got[0].Mention.RootPost.ID != "r1" {
+		t.Errorf("unexpected mention event: %+v", got[0])
+	}
+	if got[0].TargetID != "12345" || got[0].Time.Unix() != 1700000000 {
+		t.Errorf("unexpected entry metadata: %+v", got[0])
+	}
+	if got[1].Publish == nil || got[1].Publish.MediaType != "TEXT_POST" {
+		t.Errorf("unexpected publish event: %+v", got[1])
+	}
+	if got[2].Delete == nil || got[2].Delete.ID != "d1" {
+		t.Errorf("unexpected delete event: %+v", got[2])
+	}
+	if got[3].Type != "replies" || string(got[3].Raw) != `{"id":"x1"}` {
+		t.Errorf("unexpected passthrough event: %+v", got[3])
+	}
+}
+
+func TestHandler_RejectsBadSignature(t *testing.T) {
+	body := `{"object":"user","entry":[{"id":"1","changes":[{"field":"deletes","value":{"id":"d1"}}]}]}`
+	called := false
+	h := NewHandler("verify-me", testSecret, func(Event) error {
+		called = true
+		return nil
+	})
+
+	for _, sig := range []string{"", "sha256=00", Sign("other-secret", []byte(body))} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(SignatureHeader, sig)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("signature %q: status = %d, want 401", sig, rec.Code)
+		}
+	}
+	if called {
+		t.Error("handler should not run for unsigned deliveries")
+	}
+}
+
+func TestHandler_EventErrorFailsDelivery(t *testing.T) {
+	body := `{"app_id":"1","topic":"moderate","target_id":"42","time":1700000000,"values":{"field":"deletes","value":{"id":"d1"}}}`
+	h := NewHandler("verify-me", testSecret, func(e Event) error {
+		if e.Delete == nil || e.TargetID != "42" {
+			t.Errorf("unexpected event: %+v", e)
+		}
+		return errors.New("disk full")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, Sign(testSecret, []byte(body)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	for _, body := range []string{"not json", `{"object":"user","entry":[]}`, `{"entry":[{"changes":[{"field":"mentions","value":"oops"}]}]}`} {
+		if _, err := Decode([]byte(body)); err == nil {
+			t.Errorf("expected error for %q", body)
+		}
+	}
+}